@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -10,13 +12,20 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server         ServerConfig         `yaml:"server"`
-	GOWA           GOWAConfig           `yaml:"gowa"`
-	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
-	Retry          RetryConfig          `yaml:"retry"`
-	Logging        LoggingConfig        `yaml:"logging"`
-	Disclaimer     DisclaimerConfig     `yaml:"disclaimer"`
-	QuietHours     QuietHoursConfig     `yaml:"quiet_hours"`
+	Server           ServerConfig           `yaml:"server"`
+	GOWA             GOWAConfig             `yaml:"gowa"`
+	CircuitBreaker   CircuitBreakerConfig   `yaml:"circuit_breaker"`
+	Retry            RetryConfig            `yaml:"retry"`
+	Logging          LoggingConfig          `yaml:"logging"`
+	Disclaimer       DisclaimerConfig       `yaml:"disclaimer"`
+	QuietHours       QuietHoursConfig       `yaml:"quiet_hours"`
+	Links            LinksConfig            `yaml:"links"`
+	Campaigns        CampaignsConfig        `yaml:"campaigns"`
+	Appointments     AppointmentsConfig     `yaml:"appointments"`
+	Medications      MedicationsConfig      `yaml:"medications"`
+	Priorities       PrioritiesConfig       `yaml:"priorities"`
+	SSE              SSEConfig              `yaml:"sse"`
+	OutboundWebhooks OutboundWebhooksConfig `yaml:"outbound_webhooks"`
 }
 
 // ServerConfig holds server-related configuration
@@ -27,17 +36,59 @@ type ServerConfig struct {
 
 // GOWAConfig holds GOWA service configuration
 type GOWAConfig struct {
-	Endpoint      string        `yaml:"endpoint"`
-	User          string        `yaml:"user"`
-	Password      string        `yaml:"password"`
-	WebhookSecret string        `yaml:"webhook_secret"`
-	Timeout       time.Duration `yaml:"timeout"`
+	Endpoint            string        `yaml:"endpoint"`
+	User                string        `yaml:"user"`
+	Password            string        `yaml:"password"`
+	WebhookSecret       string        `yaml:"webhook_secret"`
+	Timeout             time.Duration `yaml:"timeout"`
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"` // How often GOWA device status is probed
+	HealthCheckTimeout  time.Duration `yaml:"health_check_timeout"`  // Timeout for a single status probe
+	MediaMessages       *bool         `yaml:"media_messages"`        // Send article hero images with the reminder as caption
+
+	WebhookPreviousSecrets  []string      `yaml:"webhook_previous_secrets"`  // Still accepted while the sender moves to webhook_secret
+	WebhookTolerance        time.Duration `yaml:"webhook_tolerance"`         // Max clock difference for a webhook's signed timestamp
+	WebhookRequireTimestamp *bool         `yaml:"webhook_require_timestamp"` // Reject webhooks without a signed timestamp and nonce
+	WebhookMaxBodyBytes     int64         `yaml:"webhook_max_body_bytes"`    // Larger webhook requests are rejected unread
+	WebhookEventRetention   time.Duration `yaml:"webhook_event_retention"`   // How long webhook events, and their idempotency keys, are kept
+}
+
+// WebhookSecrets returns the accepted webhook secrets, current first
+func (g *GOWAConfig) WebhookSecrets() []string {
+	var secrets []string
+	for _, s := range append([]string{g.WebhookSecret}, g.WebhookPreviousSecrets...) {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
+}
+
+// WebhookTimestampRequired reports whether webhooks must carry a signed timestamp and nonce
+func (g *GOWAConfig) WebhookTimestampRequired() bool {
+	return g.WebhookRequireTimestamp != nil && *g.WebhookRequireTimestamp
+}
+
+// Validate checks if the GOWA configuration is valid
+func (g *GOWAConfig) Validate() error {
+	if g.WebhookTolerance <= 0 || g.WebhookTolerance > time.Hour {
+		return fmt.Errorf("gowa.webhook_tolerance must be between 1s and 1h, got %s", g.WebhookTolerance)
+	}
+	if g.WebhookMaxBodyBytes < 1024 || g.WebhookMaxBodyBytes > 10*1024*1024 {
+		return fmt.Errorf("gowa.webhook_max_body_bytes must be between 1024 and 10485760, got %d", g.WebhookMaxBodyBytes)
+	}
+	if g.WebhookEventRetention < 24*time.Hour {
+		return fmt.Errorf("gowa.webhook_event_retention must be at least 24h, got %s", g.WebhookEventRetention)
+	}
+	return nil
 }
 
 // CircuitBreakerConfig holds circuit breaker settings
 type CircuitBreakerConfig struct {
-	FailureThreshold int           `yaml:"failure_threshold"`
-	CooldownDuration time.Duration `yaml:"cooldown_duration"`
+	FailureThreshold    int           `yaml:"failure_threshold"`
+	CooldownDuration    time.Duration `yaml:"cooldown_duration"`
+	FailureWindow       time.Duration `yaml:"failure_window"`         // Only failures within this window count towards the threshold
+	HalfOpenMaxRequests int           `yaml:"half_open_max_requests"` // Concurrent probe requests allowed while half-open
+	SuccessThreshold    int           `yaml:"success_threshold"`      // Consecutive probe successes needed to close
 }
 
 // RetryConfig holds retry settings
@@ -58,6 +109,259 @@ type DisclaimerConfig struct {
 	Enabled *bool  `yaml:"enabled"`
 }
 
+// LinksConfig holds public URL and tracked short link settings for content sent to patients
+type LinksConfig struct {
+	PublicBaseURL    string `yaml:"public_base_url"`     // Base URL of the public site, used for article links
+	ShortLinks       *bool  `yaml:"short_links"`         // Replace content links with tracked redirect links
+	ShortLinkBaseURL string `yaml:"short_link_base_url"` // Base URL that serves /l/:token, defaults to public_base_url
+}
+
+// ShortLinksEnabled reports whether content links should be tracked
+func (l *LinksConfig) ShortLinksEnabled() bool {
+	return l.ShortLinks != nil && *l.ShortLinks
+}
+
+// Validate checks if the links configuration is valid
+func (l *LinksConfig) Validate() error {
+	for name, value := range map[string]string{
+		"links.public_base_url":     l.PublicBaseURL,
+		"links.short_link_base_url": l.ShortLinkBaseURL,
+	} {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s must be an absolute http(s) URL, got %q", name, value)
+		}
+	}
+	return nil
+}
+
+// CampaignsConfig holds bulk campaign settings
+type CampaignsConfig struct {
+	RatePerMinute int `yaml:"rate_per_minute"` // Campaign messages sent per minute across all campaigns
+}
+
+// Validate checks if the campaigns configuration is valid
+func (c *CampaignsConfig) Validate() error {
+	if c.RatePerMinute <= 0 || c.RatePerMinute > 600 {
+		return fmt.Errorf("campaigns.rate_per_minute must be between 1 and 600, got %d", c.RatePerMinute)
+	}
+	return nil
+}
+
+// AppointmentsConfig holds appointment reminder settings
+type AppointmentsConfig struct {
+	Notices []AppointmentNotice `yaml:"notices"` // Reminders created for every appointment, none if empty
+}
+
+// AppointmentNotice is a reminder sent a number of days before an appointment at a fixed local time
+type AppointmentNotice struct {
+	Name       string `yaml:"name"`        // Identifies the notice on generated reminders, e.g. "day_before"
+	DaysBefore int    `yaml:"days_before"` // 0 sends on the day of the appointment
+	Time       string `yaml:"time"`        // Local HH:MM
+}
+
+// Validate checks if the appointments configuration is valid
+func (c *AppointmentsConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Notices))
+	for i, notice := range c.Notices {
+		if notice.Name == "" {
+			return fmt.Errorf("appointments.notices[%d].name is required", i)
+		}
+		if seen[notice.Name] {
+			return fmt.Errorf("appointments.notices[%d]: duplicate name %q", i, notice.Name)
+		}
+		seen[notice.Name] = true
+		if notice.DaysBefore < 0 || notice.DaysBefore > 30 {
+			return fmt.Errorf("appointments.notices[%d].days_before must be between 0 and 30, got %d", i, notice.DaysBefore)
+		}
+		if _, err := time.Parse("15:04", notice.Time); err != nil {
+			return fmt.Errorf("appointments.notices[%d].time must be HH:MM, got %q", i, notice.Time)
+		}
+	}
+	return nil
+}
+
+// MedicationsConfig holds medication schedule settings
+type MedicationsConfig struct {
+	HorizonDays      int `yaml:"horizon_days"`       // Days ahead that dose reminders are generated for
+	ReplyWindowHours int `yaml:"reply_window_hours"` // How long after a dose reminder a patient reply confirms it
+}
+
+// Validate checks if the medications configuration is valid
+func (c *MedicationsConfig) Validate() error {
+	if c.HorizonDays < 1 || c.HorizonDays > 60 {
+		return fmt.Errorf("medications.horizon_days must be between 1 and 60, got %d", c.HorizonDays)
+	}
+	if c.ReplyWindowHours < 1 || c.ReplyWindowHours > 72 {
+		return fmt.Errorf("medications.reply_window_hours must be between 1 and 72, got %d", c.ReplyWindowHours)
+	}
+	return nil
+}
+
+// SSEConfig holds Server-Sent Events settings
+type SSEConfig struct {
+	ReplayBufferSize      int           `yaml:"replay_buffer_size"`       // Recent events kept for Last-Event-ID replay
+	PersistReplayBuffer   *bool         `yaml:"persist_replay_buffer"`    // Keep the replay buffer across restarts
+	HeartbeatInterval     time.Duration `yaml:"heartbeat_interval"`       // Keep-alive comment interval on idle streams
+	ClientBufferSize      int           `yaml:"client_buffer_size"`       // Events queued per connection before they are dropped
+	SlowClientTimeout     time.Duration `yaml:"slow_client_timeout"`      // Disconnect a client whose queue stays full this long
+	MaxConnectionsPerUser int           `yaml:"max_connections_per_user"` // Concurrent streams allowed per user
+	TicketTTL             time.Duration `yaml:"ticket_ttl"`               // How long a connection ticket stays redeemable
+}
+
+// Validate checks if the SSE configuration is valid
+func (c *SSEConfig) Validate() error {
+	if c.ReplayBufferSize < 1 || c.ReplayBufferSize > 100000 {
+		return fmt.Errorf("sse.replay_buffer_size must be between 1 and 100000, got %d", c.ReplayBufferSize)
+	}
+	if c.HeartbeatInterval < time.Second || c.HeartbeatInterval > 5*time.Minute {
+		return fmt.Errorf("sse.heartbeat_interval must be between 1s and 5m, got %s", c.HeartbeatInterval)
+	}
+	if c.ClientBufferSize < 1 || c.ClientBufferSize > 10000 {
+		return fmt.Errorf("sse.client_buffer_size must be between 1 and 10000, got %d", c.ClientBufferSize)
+	}
+	if c.SlowClientTimeout <= 0 {
+		return fmt.Errorf("sse.slow_client_timeout must be positive, got %s", c.SlowClientTimeout)
+	}
+	if c.MaxConnectionsPerUser < 1 || c.MaxConnectionsPerUser > 100 {
+		return fmt.Errorf("sse.max_connections_per_user must be between 1 and 100, got %d", c.MaxConnectionsPerUser)
+	}
+	if c.TicketTTL < time.Second || c.TicketTTL > 5*time.Minute {
+		return fmt.Errorf("sse.ticket_ttl must be between 1s and 5m, got %s", c.TicketTTL)
+	}
+	return nil
+}
+
+// OutboundWebhooksConfig holds settings for webhooks sent to partner systems
+type OutboundWebhooksConfig struct {
+	Timeout              time.Duration   `yaml:"timeout"`                // Per request
+	MaxAttempts          int             `yaml:"max_attempts"`           // Attempts per event before the delivery fails
+	Delays               []time.Duration `yaml:"delays"`                 // Wait before each retry; the last delay repeats
+	DisableAfterFailures int             `yaml:"disable_after_failures"` // Consecutive failed deliveries before a subscription is disabled
+	DeliveryRetention    time.Duration   `yaml:"delivery_retention"`     // How long delivery attempts are kept in the log
+}
+
+// Validate checks if the outbound webhooks configuration is valid
+func (c *OutboundWebhooksConfig) Validate() error {
+	if c.Timeout < time.Second || c.Timeout > time.Minute {
+		return fmt.Errorf("outbound_webhooks.timeout must be between 1s and 1m, got %s", c.Timeout)
+	}
+	if c.MaxAttempts < 1 || c.MaxAttempts > 20 {
+		return fmt.Errorf("outbound_webhooks.max_attempts must be between 1 and 20, got %d", c.MaxAttempts)
+	}
+	for i, delay := range c.Delays {
+		if delay < 0 {
+			return fmt.Errorf("outbound_webhooks.delays[%d] must be >= 0, got %v", i, delay)
+		}
+	}
+	if c.DisableAfterFailures < 1 || c.DisableAfterFailures > 1000 {
+		return fmt.Errorf("outbound_webhooks.disable_after_failures must be between 1 and 1000, got %d", c.DisableAfterFailures)
+	}
+	if c.DeliveryRetention < 24*time.Hour {
+		return fmt.Errorf("outbound_webhooks.delivery_retention must be at least 24h, got %s", c.DeliveryRetention)
+	}
+	return nil
+}
+
+// PrioritiesConfig holds the reminder priority levels and their delivery policy
+type PrioritiesConfig struct {
+	Default string          `yaml:"default"` // Level of reminders without a priority
+	Levels  []PriorityLevel `yaml:"levels"`  // Highest priority first, sent first
+}
+
+// PriorityLevel is the delivery policy of one reminder priority
+type PriorityLevel struct {
+	Name                 string `yaml:"name"`
+	MaxAttempts          int    `yaml:"max_attempts"`           // Send attempts before failing, retry.max_attempts if 0
+	BypassQuietHours     bool   `yaml:"bypass_quiet_hours"`     // Send during quiet hours
+	BypassBlackouts      bool   `yaml:"bypass_blackouts"`       // Send during blackouts
+	EscalationAfterHours int    `yaml:"escalation_after_hours"` // Escalate if unread, unless the reminder has its own policy; never if 0
+}
+
+// Validate checks if the priorities configuration is valid
+func (c *PrioritiesConfig) Validate() error {
+	if len(c.Levels) == 0 {
+		return fmt.Errorf("priorities.levels must not be empty")
+	}
+	seen := make(map[string]bool, len(c.Levels))
+	for i, level := range c.Levels {
+		if level.Name == "" {
+			return fmt.Errorf("priorities.levels[%d].name is required", i)
+		}
+		if seen[level.Name] {
+			return fmt.Errorf("priorities.levels[%d]: duplicate name %q", i, level.Name)
+		}
+		seen[level.Name] = true
+		if level.MaxAttempts < 0 {
+			return fmt.Errorf("priorities.levels[%d].max_attempts must be >= 0, got %d", i, level.MaxAttempts)
+		}
+		if level.EscalationAfterHours < 0 || level.EscalationAfterHours > 168 {
+			return fmt.Errorf("priorities.levels[%d].escalation_after_hours must be between 0 and 168, got %d", i, level.EscalationAfterHours)
+		}
+	}
+	if !seen[c.Default] {
+		return fmt.Errorf("priorities.default must be one of the levels, got %q", c.Default)
+	}
+	return nil
+}
+
+// Valid reports whether a reminder priority is one of the levels. An empty priority
+// (the default level) is valid, and any priority is valid if no levels are configured.
+func (c *PrioritiesConfig) Valid(name string) bool {
+	if name == "" || len(c.Levels) == 0 {
+		return true
+	}
+	for _, level := range c.Levels {
+		if level.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the level names, highest priority first
+func (c *PrioritiesConfig) Names() []string {
+	names := make([]string, len(c.Levels))
+	for i, level := range c.Levels {
+		names[i] = level.Name
+	}
+	return names
+}
+
+// Rank returns the send order of a priority, 0 for the highest. Unknown priorities
+// rank as the default level.
+func (c *PrioritiesConfig) Rank(name string) int {
+	for i, level := range c.Levels {
+		if level.Name == name {
+			return i
+		}
+	}
+	for i, level := range c.Levels {
+		if level.Name == c.Default {
+			return i
+		}
+	}
+	return len(c.Levels)
+}
+
+// Level returns the policy of a priority. Unknown priorities get the default level's
+// policy, or the zero policy if that is not configured either.
+func (c *PrioritiesConfig) Level(name string) PriorityLevel {
+	rank := c.Rank(name)
+	if rank < len(c.Levels) {
+		return c.Levels[rank]
+	}
+	return PriorityLevel{}
+}
+
+// MaxAttemptsFor returns how many send attempts a reminder of the given priority gets
+func (c *Config) MaxAttemptsFor(priority string) int {
+	if attempts := c.Priorities.Level(priority).MaxAttempts; attempts > 0 {
+		return attempts
+	}
+	return c.Retry.MaxAttempts
+}
+
 // QuietHoursConfig holds quiet hours settings for reminder delivery
 type QuietHoursConfig struct {
 	StartHour *int   `yaml:"start_hour"` // 21 (9 PM) - pointer to distinguish 0 from unset
@@ -109,6 +413,15 @@ func (c *CircuitBreakerConfig) Validate() error {
 	if c.CooldownDuration <= 0 {
 		return fmt.Errorf("circuit_breaker.cooldown_duration must be > 0, got %v", c.CooldownDuration)
 	}
+	if c.FailureWindow < 0 {
+		return fmt.Errorf("circuit_breaker.failure_window must be >= 0, got %v", c.FailureWindow)
+	}
+	if c.HalfOpenMaxRequests <= 0 {
+		return fmt.Errorf("circuit_breaker.half_open_max_requests must be > 0, got %d", c.HalfOpenMaxRequests)
+	}
+	if c.SuccessThreshold <= 0 {
+		return fmt.Errorf("circuit_breaker.success_threshold must be > 0, got %d", c.SuccessThreshold)
+	}
 	return nil
 }
 
@@ -143,6 +456,11 @@ func Load(path string) (*Config, error) {
 	// Apply defaults
 	cfg.applyDefaults()
 
+	// Validate GOWA config
+	if err := cfg.GOWA.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	// Validate circuit breaker config
 	if err := cfg.CircuitBreaker.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
@@ -158,6 +476,31 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	// Validate links config
+	if err := cfg.Links.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Validate campaigns config
+	if err := cfg.Campaigns.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Appointments.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Medications.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Priorities.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.SSE.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.OutboundWebhooks.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	return &cfg, nil
 }
 
@@ -178,6 +521,29 @@ func (c *Config) applyDefaults() {
 	if c.GOWA.Timeout == 0 {
 		c.GOWA.Timeout = 30 * time.Second
 	}
+	if c.GOWA.HealthCheckInterval == 0 {
+		c.GOWA.HealthCheckInterval = 60 * time.Second
+	}
+	if c.GOWA.HealthCheckTimeout == 0 {
+		c.GOWA.HealthCheckTimeout = 5 * time.Second
+	}
+	if c.GOWA.MediaMessages == nil {
+		enabled := true
+		c.GOWA.MediaMessages = &enabled
+	}
+	if c.GOWA.WebhookTolerance == 0 {
+		c.GOWA.WebhookTolerance = 5 * time.Minute
+	}
+	if c.GOWA.WebhookRequireTimestamp == nil {
+		required := true
+		c.GOWA.WebhookRequireTimestamp = &required
+	}
+	if c.GOWA.WebhookMaxBodyBytes == 0 {
+		c.GOWA.WebhookMaxBodyBytes = 1024 * 1024
+	}
+	if c.GOWA.WebhookEventRetention == 0 {
+		c.GOWA.WebhookEventRetention = 30 * 24 * time.Hour
+	}
 
 	// Circuit breaker defaults
 	if c.CircuitBreaker.FailureThreshold == 0 {
@@ -186,6 +552,15 @@ func (c *Config) applyDefaults() {
 	if c.CircuitBreaker.CooldownDuration == 0 {
 		c.CircuitBreaker.CooldownDuration = 5 * time.Minute
 	}
+	if c.CircuitBreaker.FailureWindow == 0 {
+		c.CircuitBreaker.FailureWindow = 10 * time.Minute
+	}
+	if c.CircuitBreaker.HalfOpenMaxRequests == 0 {
+		c.CircuitBreaker.HalfOpenMaxRequests = 1
+	}
+	if c.CircuitBreaker.SuccessThreshold == 0 {
+		c.CircuitBreaker.SuccessThreshold = 2
+	}
 
 	// Retry defaults
 	if c.Retry.MaxAttempts == 0 {
@@ -230,6 +605,100 @@ func (c *Config) applyDefaults() {
 	if c.QuietHours.Timezone == "" {
 		c.QuietHours.Timezone = "WIB" // UTC+7
 	}
+
+	// Links defaults
+	if c.Links.PublicBaseURL == "" {
+		c.Links.PublicBaseURL = "https://prima.app"
+	}
+	c.Links.PublicBaseURL = strings.TrimRight(c.Links.PublicBaseURL, "/")
+	if c.Links.ShortLinkBaseURL == "" {
+		c.Links.ShortLinkBaseURL = c.Links.PublicBaseURL
+	}
+	c.Links.ShortLinkBaseURL = strings.TrimRight(c.Links.ShortLinkBaseURL, "/")
+	if c.Links.ShortLinks == nil {
+		enabled := true
+		c.Links.ShortLinks = &enabled
+	}
+
+	// Campaign defaults
+	if c.Campaigns.RatePerMinute == 0 {
+		c.Campaigns.RatePerMinute = 20
+	}
+
+	// Appointment defaults: evening before and morning of, unless configured (an empty list disables notices)
+	if c.Appointments.Notices == nil {
+		c.Appointments.Notices = []AppointmentNotice{
+			{Name: "day_before", DaysBefore: 1, Time: "18:00"},
+			{Name: "morning_of", DaysBefore: 0, Time: "07:00"},
+		}
+	}
+
+	// Medication defaults
+	if c.Medications.HorizonDays == 0 {
+		c.Medications.HorizonDays = 7
+	}
+	if c.Medications.ReplyWindowHours == 0 {
+		c.Medications.ReplyWindowHours = 12
+	}
+
+	// Priority defaults: high gets more attempts, low and medium use the retry settings.
+	// Escalation is opt-in, as enabling it escalates every reminder already unread that long.
+	if c.Priorities.Levels == nil {
+		c.Priorities.Levels = []PriorityLevel{
+			{Name: "high", MaxAttempts: 8},
+			{Name: "medium"},
+			{Name: "low"},
+		}
+	}
+	if c.Priorities.Default == "" {
+		c.Priorities.Default = "medium"
+	}
+
+	// SSE defaults
+	if c.SSE.ReplayBufferSize == 0 {
+		c.SSE.ReplayBufferSize = 1000
+	}
+	if c.SSE.PersistReplayBuffer == nil {
+		persist := false
+		c.SSE.PersistReplayBuffer = &persist
+	}
+	if c.SSE.HeartbeatInterval == 0 {
+		c.SSE.HeartbeatInterval = 15 * time.Second
+	}
+	if c.SSE.ClientBufferSize == 0 {
+		c.SSE.ClientBufferSize = 64
+	}
+	if c.SSE.SlowClientTimeout == 0 {
+		c.SSE.SlowClientTimeout = 30 * time.Second
+	}
+	if c.SSE.MaxConnectionsPerUser == 0 {
+		c.SSE.MaxConnectionsPerUser = 5
+	}
+	if c.SSE.TicketTTL == 0 {
+		c.SSE.TicketTTL = 30 * time.Second
+	}
+
+	// Outbound webhook defaults
+	if c.OutboundWebhooks.Timeout == 0 {
+		c.OutboundWebhooks.Timeout = 10 * time.Second
+	}
+	if c.OutboundWebhooks.MaxAttempts == 0 {
+		c.OutboundWebhooks.MaxAttempts = 5
+	}
+	if len(c.OutboundWebhooks.Delays) == 0 {
+		c.OutboundWebhooks.Delays = []time.Duration{
+			10 * time.Second,
+			1 * time.Minute,
+			5 * time.Minute,
+			30 * time.Minute,
+		}
+	}
+	if c.OutboundWebhooks.DisableAfterFailures == 0 {
+		c.OutboundWebhooks.DisableAfterFailures = 5
+	}
+	if c.OutboundWebhooks.DeliveryRetention == 0 {
+		c.OutboundWebhooks.DeliveryRetention = 30 * 24 * time.Hour
+	}
 }
 
 // LoadOrDefault attempts to load config from path, returns default config if file doesn't exist
@@ -112,12 +112,30 @@ func TestLoadOrDefault(t *testing.T) {
 	if cfg.GOWA.Timeout != 30*time.Second {
 		t.Errorf("Expected default GOWA timeout 30s, got %v", cfg.GOWA.Timeout)
 	}
+	if cfg.GOWA.HealthCheckInterval != 60*time.Second {
+		t.Errorf("Expected default health check interval 60s, got %v", cfg.GOWA.HealthCheckInterval)
+	}
+	if cfg.GOWA.HealthCheckTimeout != 5*time.Second {
+		t.Errorf("Expected default health check timeout 5s, got %v", cfg.GOWA.HealthCheckTimeout)
+	}
+	if cfg.GOWA.MediaMessages == nil || !*cfg.GOWA.MediaMessages {
+		t.Error("Expected media messages enabled by default")
+	}
 	if cfg.CircuitBreaker.FailureThreshold != 5 {
 		t.Errorf("Expected default failure threshold 5, got %d", cfg.CircuitBreaker.FailureThreshold)
 	}
 	if cfg.CircuitBreaker.CooldownDuration != 5*time.Minute {
 		t.Errorf("Expected default cooldown duration 5m, got %v", cfg.CircuitBreaker.CooldownDuration)
 	}
+	if cfg.CircuitBreaker.FailureWindow != 10*time.Minute {
+		t.Errorf("Expected default failure window 10m, got %v", cfg.CircuitBreaker.FailureWindow)
+	}
+	if cfg.CircuitBreaker.HalfOpenMaxRequests != 1 {
+		t.Errorf("Expected default half-open max requests 1, got %d", cfg.CircuitBreaker.HalfOpenMaxRequests)
+	}
+	if cfg.CircuitBreaker.SuccessThreshold != 2 {
+		t.Errorf("Expected default success threshold 2, got %d", cfg.CircuitBreaker.SuccessThreshold)
+	}
 	if cfg.Retry.MaxAttempts != 5 {
 		t.Errorf("Expected default max attempts 5, got %d", cfg.Retry.MaxAttempts)
 	}
@@ -388,3 +406,288 @@ func TestQuietHoursValidation_EmptyTimezoneIsValid(t *testing.T) {
 		t.Errorf("Expected empty timezone to be valid, got error: %v", err)
 	}
 }
+
+func TestCircuitBreakerValidation_HalfOpen(t *testing.T) {
+	cfg := &CircuitBreakerConfig{
+		FailureThreshold:    5,
+		CooldownDuration:    5 * time.Minute,
+		HalfOpenMaxRequests: 1,
+		SuccessThreshold:    0,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for success_threshold 0, got nil")
+	}
+
+	cfg.SuccessThreshold = 2
+	cfg.FailureWindow = -time.Minute
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for negative failure_window, got nil")
+	}
+
+	cfg.FailureWindow = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got error: %v", err)
+	}
+}
+
+func TestApplyDefaults_Links(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+
+	if cfg.Links.PublicBaseURL != "https://prima.app" {
+		t.Errorf("Expected default public base URL 'https://prima.app', got '%s'", cfg.Links.PublicBaseURL)
+	}
+	if cfg.Links.ShortLinkBaseURL != cfg.Links.PublicBaseURL {
+		t.Errorf("Expected short link base URL to default to public base URL, got '%s'", cfg.Links.ShortLinkBaseURL)
+	}
+	if !cfg.Links.ShortLinksEnabled() {
+		t.Error("Expected short links enabled by default")
+	}
+
+	staging := &Config{Links: LinksConfig{PublicBaseURL: "https://staging.prima.app/"}}
+	staging.applyDefaults()
+	if staging.Links.PublicBaseURL != "https://staging.prima.app" || staging.Links.ShortLinkBaseURL != "https://staging.prima.app" {
+		t.Errorf("Expected trailing slash trimmed and inherited, got %+v", staging.Links)
+	}
+}
+
+func TestLinksValidation(t *testing.T) {
+	cfg := &LinksConfig{PublicBaseURL: "https://prima.app", ShortLinkBaseURL: "https://go.prima.app"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got error: %v", err)
+	}
+
+	cfg.PublicBaseURL = "prima.app"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for base URL without scheme, got nil")
+	}
+
+	cfg.PublicBaseURL = "ftp://prima.app"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for non-http scheme, got nil")
+	}
+}
+
+func TestCampaignsConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.Campaigns.RatePerMinute != 20 {
+		t.Errorf("Expected default campaign rate 20, got %d", cfg.Campaigns.RatePerMinute)
+	}
+	if err := cfg.Campaigns.Validate(); err != nil {
+		t.Errorf("Expected default campaign config valid, got error: %v", err)
+	}
+
+	invalid := &CampaignsConfig{RatePerMinute: -1}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for negative rate, got nil")
+	}
+}
+
+func TestAppointmentsConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if len(cfg.Appointments.Notices) != 2 || cfg.Appointments.Notices[0].Name != "day_before" {
+		t.Errorf("Expected day_before and morning_of notices by default, got %+v", cfg.Appointments.Notices)
+	}
+	if err := cfg.Appointments.Validate(); err != nil {
+		t.Errorf("Expected default appointment config valid, got error: %v", err)
+	}
+
+	disabled := &Config{Appointments: AppointmentsConfig{Notices: []AppointmentNotice{}}}
+	disabled.applyDefaults()
+	if len(disabled.Appointments.Notices) != 0 {
+		t.Errorf("Expected empty notice list to stay empty, got %+v", disabled.Appointments.Notices)
+	}
+
+	tests := []struct {
+		name    string
+		notices []AppointmentNotice
+	}{
+		{"missing name", []AppointmentNotice{{DaysBefore: 1, Time: "18:00"}}},
+		{"duplicate name", []AppointmentNotice{{Name: "a", Time: "08:00"}, {Name: "a", Time: "09:00"}}},
+		{"negative days", []AppointmentNotice{{Name: "a", DaysBefore: -1, Time: "08:00"}}},
+		{"bad time", []AppointmentNotice{{Name: "a", Time: "8am"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := &AppointmentsConfig{Notices: tt.notices}
+			if err := invalid.Validate(); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestMedicationsConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.Medications.HorizonDays != 7 || cfg.Medications.ReplyWindowHours != 12 {
+		t.Errorf("Expected defaults 7 days and 12 hours, got %+v", cfg.Medications)
+	}
+	if err := cfg.Medications.Validate(); err != nil {
+		t.Errorf("Expected default medication config valid, got error: %v", err)
+	}
+
+	if err := (&MedicationsConfig{HorizonDays: 90, ReplyWindowHours: 12}).Validate(); err == nil {
+		t.Error("Expected error for horizon over 60 days, got nil")
+	}
+	if err := (&MedicationsConfig{HorizonDays: 7, ReplyWindowHours: -1}).Validate(); err == nil {
+		t.Error("Expected error for negative reply window, got nil")
+	}
+}
+
+func TestSSEConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.SSE.ReplayBufferSize != 1000 {
+		t.Errorf("Expected default replay buffer size 1000, got %d", cfg.SSE.ReplayBufferSize)
+	}
+	if cfg.SSE.PersistReplayBuffer == nil || *cfg.SSE.PersistReplayBuffer {
+		t.Error("Expected replay buffer persistence to default to false")
+	}
+	if cfg.SSE.HeartbeatInterval != 15*time.Second || cfg.SSE.ClientBufferSize != 64 ||
+		cfg.SSE.SlowClientTimeout != 30*time.Second || cfg.SSE.MaxConnectionsPerUser != 5 || cfg.SSE.TicketTTL != 30*time.Second {
+		t.Errorf("Expected defaults 15s heartbeat, 64 buffer, 30s timeout, 5 connections, 30s tickets, got %+v", cfg.SSE)
+	}
+	if err := cfg.SSE.Validate(); err != nil {
+		t.Errorf("Expected default SSE config valid, got error: %v", err)
+	}
+
+	if err := (&SSEConfig{ReplayBufferSize: -1}).Validate(); err == nil {
+		t.Error("Expected error for negative replay buffer size, got nil")
+	}
+	invalid := cfg.SSE
+	invalid.HeartbeatInterval = 10 * time.Minute
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for heartbeat interval over 5m, got nil")
+	}
+	invalid = cfg.SSE
+	invalid.TicketTTL = time.Hour
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for ticket TTL over 5m, got nil")
+	}
+	invalid = cfg.SSE
+	invalid.MaxConnectionsPerUser = -1
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for negative connection limit, got nil")
+	}
+}
+
+func TestOutboundWebhooksConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.OutboundWebhooks.Timeout != 10*time.Second || cfg.OutboundWebhooks.MaxAttempts != 5 ||
+		len(cfg.OutboundWebhooks.Delays) != 4 || cfg.OutboundWebhooks.DisableAfterFailures != 5 ||
+		cfg.OutboundWebhooks.DeliveryRetention != 30*24*time.Hour {
+		t.Errorf("Expected defaults 10s timeout, 5 attempts, 4 delays, disable after 5, kept 30 days, got %+v", cfg.OutboundWebhooks)
+	}
+	if err := cfg.OutboundWebhooks.Validate(); err != nil {
+		t.Errorf("Expected default outbound webhooks config valid, got error: %v", err)
+	}
+
+	invalid := cfg.OutboundWebhooks
+	invalid.Timeout = 5 * time.Minute
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for timeout over 1m, got nil")
+	}
+	invalid = cfg.OutboundWebhooks
+	invalid.MaxAttempts = 50
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for more than 20 attempts, got nil")
+	}
+	invalid = cfg.OutboundWebhooks
+	invalid.Delays = []time.Duration{-time.Second}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for negative delay, got nil")
+	}
+	invalid = cfg.OutboundWebhooks
+	invalid.DisableAfterFailures = -1
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for negative failure limit, got nil")
+	}
+	invalid = cfg.OutboundWebhooks
+	invalid.DeliveryRetention = time.Hour
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for retention under 24h, got nil")
+	}
+}
+
+func TestPrioritiesConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if err := cfg.Priorities.Validate(); err != nil {
+		t.Fatalf("Expected default priorities valid, got error: %v", err)
+	}
+	if got := cfg.Priorities.Names(); len(got) != 3 || got[0] != "high" || got[2] != "low" {
+		t.Errorf("Expected levels high, medium, low, got %v", got)
+	}
+	if cfg.MaxAttemptsFor("high") != 8 || cfg.MaxAttemptsFor("low") != cfg.Retry.MaxAttempts {
+		t.Errorf("Expected 8 attempts for high and %d for low, got %d and %d",
+			cfg.Retry.MaxAttempts, cfg.MaxAttemptsFor("high"), cfg.MaxAttemptsFor("low"))
+	}
+	if cfg.Priorities.Rank("high") != 0 || cfg.Priorities.Rank("") != 1 || cfg.Priorities.Rank("urgent") != 1 {
+		t.Errorf("Expected unknown and empty priorities to rank as the default level")
+	}
+	if cfg.Priorities.Level("").EscalationAfterHours != 0 || cfg.Priorities.Level("high").EscalationAfterHours != 0 {
+		t.Errorf("Expected priority escalation off by default")
+	}
+	if !cfg.Priorities.Valid("") || !cfg.Priorities.Valid("low") || cfg.Priorities.Valid("urgent") {
+		t.Errorf("Expected only configured priorities valid")
+	}
+
+	tests := []struct {
+		name string
+		cfg  PrioritiesConfig
+	}{
+		{"no levels", PrioritiesConfig{Default: "medium", Levels: []PriorityLevel{}}},
+		{"duplicate name", PrioritiesConfig{Default: "high", Levels: []PriorityLevel{{Name: "high"}, {Name: "high"}}}},
+		{"negative attempts", PrioritiesConfig{Default: "high", Levels: []PriorityLevel{{Name: "high", MaxAttempts: -1}}}},
+		{"escalation too late", PrioritiesConfig{Default: "high", Levels: []PriorityLevel{{Name: "high", EscalationAfterHours: 200}}}},
+		{"unknown default", PrioritiesConfig{Default: "medium", Levels: []PriorityLevel{{Name: "high"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+}
+
+func TestGOWAWebhookConfig(t *testing.T) {
+	cfg := &Config{}
+	cfg.applyDefaults()
+	if cfg.GOWA.WebhookTolerance != 5*time.Minute || !cfg.GOWA.WebhookTimestampRequired() {
+		t.Errorf("Expected 5m tolerance with timestamps required, got %+v", cfg.GOWA)
+	}
+	if err := cfg.GOWA.Validate(); err != nil {
+		t.Errorf("Expected default GOWA config valid, got error: %v", err)
+	}
+	if cfg.GOWA.WebhookMaxBodyBytes != 1024*1024 || cfg.GOWA.WebhookEventRetention != 30*24*time.Hour {
+		t.Errorf("Expected 1MB webhook bodies kept 30 days, got %d and %s", cfg.GOWA.WebhookMaxBodyBytes, cfg.GOWA.WebhookEventRetention)
+	}
+	invalid := cfg.GOWA
+	invalid.WebhookTolerance = 2 * time.Hour
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for tolerance over 1h, got nil")
+	}
+	invalid = cfg.GOWA
+	invalid.WebhookMaxBodyBytes = 100 * 1024 * 1024
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for body limit over 10MB, got nil")
+	}
+	invalid = cfg.GOWA
+	invalid.WebhookEventRetention = time.Hour
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for retention under 24h, got nil")
+	}
+
+	g := GOWAConfig{WebhookSecret: "current", WebhookPreviousSecrets: []string{"", "old"}}
+	if got := g.WebhookSecrets(); len(got) != 2 || got[0] != "current" || got[1] != "old" {
+		t.Errorf("Expected current then old secret, got %v", got)
+	}
+	if got := (&GOWAConfig{}).WebhookSecrets(); len(got) != 0 {
+		t.Errorf("Expected no secrets, got %v", got)
+	}
+}
@@ -0,0 +1,208 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// subscriberQueueSize bounds the events waiting for one subscriber. Events published
+// while a subscriber's queue is full are dropped for that subscriber only.
+const subscriberQueueSize = 1024
+
+// Handler consumes events delivered to a subscriber. A returned error or a panic is
+// logged and counted; it does not affect the publisher or other subscribers.
+type Handler func(event Event) error
+
+// Bus is an in-process publish/subscribe bus. Publish never blocks: each subscriber
+// receives its events in publish order on its own goroutine.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []*subscriber
+	logger      *slog.Logger
+	closed      bool
+}
+
+// subscriber is one consumer of the bus with its own queue and worker
+type subscriber struct {
+	name    string
+	types   map[string]bool // nil receives every event type
+	handler Handler
+	queue   chan Event
+	done    chan struct{} // Closed when the worker exits
+
+	mu      sync.Mutex
+	idle    *sync.Cond // Signalled when pending drops to zero
+	pending int        // Events queued but not yet handled
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// SubscriberStats reports how a subscriber is keeping up, since startup
+type SubscriberStats struct {
+	Name      string `json:"name"`
+	Queued    int    `json:"queued"`
+	Delivered uint64 `json:"delivered"`
+	Failed    uint64 `json:"failed"`  // Handler returned an error or panicked
+	Dropped   uint64 `json:"dropped"` // Queue was full
+}
+
+// NewBus creates an event bus
+func NewBus(logger *slog.Logger) *Bus {
+	return &Bus{logger: logger}
+}
+
+// Subscribe registers a handler for the given event types, or every type if none are
+// given. Subscribers should be registered before events are published.
+func (b *Bus) Subscribe(name string, handler Handler, types ...string) {
+	s := &subscriber{
+		name:    name,
+		handler: handler,
+		queue:   make(chan Event, subscriberQueueSize),
+		done:    make(chan struct{}),
+	}
+	s.idle = sync.NewCond(&s.mu)
+	if len(types) > 0 {
+		s.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.subscribers = append(b.subscribers, s)
+	go b.run(s)
+}
+
+// Publish queues an event for every subscriber of its type
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+
+	for _, s := range b.subscribers {
+		if s.types != nil && !s.types[event.Type()] {
+			continue
+		}
+		s.track(1)
+		select {
+		case s.queue <- event:
+		default:
+			s.track(-1)
+			s.dropped.Add(1)
+			if b.logger != nil {
+				b.logger.Warn("Event bus subscriber queue full, dropping event",
+					"subscriber", s.name,
+					"event", event.Type(),
+				)
+			}
+		}
+	}
+}
+
+// Flush waits until every event published so far has been handled
+func (b *Bus) Flush() {
+	b.mu.RLock()
+	subscribers := append([]*subscriber(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	for _, s := range subscribers {
+		s.mu.Lock()
+		for s.pending > 0 {
+			s.idle.Wait()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Close stops accepting events, lets subscribers handle what is queued and stops them
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, s := range b.subscribers {
+		close(s.queue)
+	}
+	subscribers := b.subscribers
+	b.mu.Unlock()
+
+	for _, s := range subscribers {
+		<-s.done
+	}
+}
+
+// Stats returns per-subscriber counters
+func (b *Bus) Stats() []SubscriberStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(b.subscribers))
+	for _, s := range b.subscribers {
+		stats = append(stats, SubscriberStats{
+			Name:      s.name,
+			Queued:    len(s.queue),
+			Delivered: s.delivered.Load(),
+			Failed:    s.failed.Load(),
+			Dropped:   s.dropped.Load(),
+		})
+	}
+	return stats
+}
+
+// run delivers a subscriber's events in order until its queue is closed
+func (b *Bus) run(s *subscriber) {
+	defer close(s.done)
+	for event := range s.queue {
+		b.deliver(s, event)
+		s.track(-1)
+	}
+}
+
+// track adjusts the subscriber's pending count, waking Flush when it reaches zero
+func (s *subscriber) track(delta int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending += delta
+	if s.pending == 0 {
+		s.idle.Broadcast()
+	}
+}
+
+// deliver calls the subscriber's handler, isolating its errors and panics
+func (b *Bus) deliver(s *subscriber, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.recordFailure(s, event, fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	if err := s.handler(event); err != nil {
+		b.recordFailure(s, event, err)
+		return
+	}
+	s.delivered.Add(1)
+}
+
+// recordFailure counts and logs a failed delivery
+func (b *Bus) recordFailure(s *subscriber, event Event, err error) {
+	s.failed.Add(1)
+	if b.logger != nil {
+		b.logger.Error("Event bus subscriber failed",
+			"subscriber", s.name,
+			"event", event.Type(),
+			"error", err.Error(),
+		)
+	}
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recorder collects the reminder IDs of delivered events
+type recorder struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *recorder) handle(event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch e := event.(type) {
+	case DeliveryStatusChanged:
+		r.ids = append(r.ids, e.ReminderID)
+	case DeliveryFailed:
+		r.ids = append(r.ids, e.ReminderID)
+	}
+	return nil
+}
+
+func TestBus_DeliversInOrderByType(t *testing.T) {
+	bus := NewBus(nil)
+	defer bus.Close()
+
+	all := &recorder{}
+	failures := &recorder{}
+	bus.Subscribe("all", all.handle)
+	bus.Subscribe("failures", failures.handle, TypeDeliveryFailed)
+
+	for _, id := range []string{"r1", "r2", "r3"} {
+		bus.Publish(DeliveryStatusChanged{ReminderID: id})
+	}
+	bus.Publish(DeliveryFailed{ReminderID: "r4"})
+	bus.Flush()
+
+	if len(all.ids) != 4 || all.ids[0] != "r1" || all.ids[2] != "r3" || all.ids[3] != "r4" {
+		t.Errorf("Expected every event in publish order, got %v", all.ids)
+	}
+	if len(failures.ids) != 1 || failures.ids[0] != "r4" {
+		t.Errorf("Expected only the failure, got %v", failures.ids)
+	}
+}
+
+func TestBus_IsolatesFailingSubscribers(t *testing.T) {
+	bus := NewBus(nil)
+	defer bus.Close()
+
+	healthy := &recorder{}
+	bus.Subscribe("erroring", func(Event) error { return errors.New("boom") })
+	bus.Subscribe("panicking", func(Event) error { panic("boom") })
+	bus.Subscribe("healthy", healthy.handle)
+
+	bus.Publish(DeliveryStatusChanged{ReminderID: "r1"})
+	bus.Publish(DeliveryStatusChanged{ReminderID: "r2"})
+	bus.Flush()
+
+	if len(healthy.ids) != 2 {
+		t.Errorf("Expected the healthy subscriber to get both events, got %v", healthy.ids)
+	}
+	for _, stats := range bus.Stats() {
+		switch stats.Name {
+		case "erroring", "panicking":
+			if stats.Failed != 2 || stats.Delivered != 0 {
+				t.Errorf("Expected 2 failures for %s, got %+v", stats.Name, stats)
+			}
+		case "healthy":
+			if stats.Delivered != 2 || stats.Failed != 0 {
+				t.Errorf("Expected 2 deliveries, got %+v", stats)
+			}
+		}
+	}
+}
+
+func TestBus_DropsWhenSubscriberFallsBehind(t *testing.T) {
+	bus := NewBus(nil)
+	defer bus.Close()
+
+	release := make(chan struct{})
+	bus.Subscribe("slow", func(Event) error {
+		<-release
+		return nil
+	})
+
+	// One event is held by the worker, the queue holds the next subscriberQueueSize
+	for i := 0; i < subscriberQueueSize+10; i++ {
+		bus.Publish(DeliveryStatusChanged{})
+	}
+	close(release)
+	bus.Flush()
+
+	stats := bus.Stats()[0]
+	if stats.Dropped == 0 || stats.Delivered+stats.Dropped != subscriberQueueSize+10 {
+		t.Errorf("Expected dropped events to be counted, got %+v", stats)
+	}
+}
+
+func TestBus_CloseDrainsQueues(t *testing.T) {
+	bus := NewBus(nil)
+	r := &recorder{}
+	bus.Subscribe("recorder", r.handle)
+
+	bus.Publish(DeliveryStatusChanged{ReminderID: "r1"})
+	bus.Close()
+	bus.Publish(DeliveryStatusChanged{ReminderID: "r2"})
+	bus.Close()
+
+	if len(r.ids) != 1 || r.ids[0] != "r1" {
+		t.Errorf("Expected only the event published before Close, got %v", r.ids)
+	}
+}
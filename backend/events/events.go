@@ -0,0 +1,122 @@
+// Package events defines the domain events published on the in-process event bus
+package events
+
+import "time"
+
+// Event types
+const (
+	TypeReminderCreated       = "reminder.created"
+	TypeDeliveryStatusChanged = "delivery.status_changed"
+	TypeDeliveryFailed        = "delivery.failed"
+	TypeReminderEscalated     = "reminder.escalated"
+	TypeCircuitStateChanged   = "circuit.state_changed"
+	TypeCampaignProgress      = "campaign.progress"
+)
+
+// Types lists every event type
+var Types = []string{
+	TypeReminderCreated,
+	TypeDeliveryStatusChanged,
+	TypeDeliveryFailed,
+	TypeReminderEscalated,
+	TypeCircuitStateChanged,
+	TypeCampaignProgress,
+}
+
+// IsType reports whether t is a known event type
+func IsType(t string) bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a domain event published on the bus
+type Event interface {
+	Type() string
+}
+
+// ReminderCreated is published when a reminder is added to a patient
+type ReminderCreated struct {
+	ReminderID  string    `json:"reminder_id"`
+	PatientID   string    `json:"patient_id"`
+	VolunteerID string    `json:"volunteer_id"` // Volunteer who owns the patient
+	Title       string    `json:"title"`
+	DueDate     string    `json:"due_date,omitempty"`
+	Priority    string    `json:"priority,omitempty"`
+	CreatedBy   string    `json:"created_by"`
+	At          time.Time `json:"at"`
+}
+
+// Type implements Event
+func (ReminderCreated) Type() string { return TypeReminderCreated }
+
+// DeliveryStatusChanged is published when a reminder's delivery status changes after a
+// send or a GOWA acknowledgement
+type DeliveryStatusChanged struct {
+	ReminderID  string    `json:"reminder_id"`
+	PatientID   string    `json:"patient_id"`
+	VolunteerID string    `json:"volunteer_id"`
+	From        string    `json:"from,omitempty"`
+	Status      string    `json:"status"`
+	At          time.Time `json:"at"`
+}
+
+// Type implements Event
+func (DeliveryStatusChanged) Type() string { return TypeDeliveryStatusChanged }
+
+// DeliveryFailed is published when a reminder could not be delivered
+type DeliveryFailed struct {
+	ReminderID  string    `json:"reminder_id"`
+	PatientID   string    `json:"patient_id"`
+	VolunteerID string    `json:"volunteer_id"`
+	PatientName string    `json:"patient_name"`
+	Error       string    `json:"error"`
+	FailureCode string    `json:"failure_code,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Type implements Event
+func (DeliveryFailed) Type() string { return TypeDeliveryFailed }
+
+// ReminderEscalated is published when a sent reminder is not read within its
+// escalation window
+type ReminderEscalated struct {
+	ReminderID  string    `json:"reminder_id"`
+	PatientID   string    `json:"patient_id"`
+	VolunteerID string    `json:"volunteer_id"`
+	PatientName string    `json:"patient_name"`
+	AfterHours  int       `json:"after_hours"`
+	At          time.Time `json:"at"`
+}
+
+// Type implements Event
+func (ReminderEscalated) Type() string { return TypeReminderEscalated }
+
+// CircuitStateChanged is published when the GOWA circuit breaker opens, half-opens or closes
+type CircuitStateChanged struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Reason   string    `json:"reason"`
+	Failures int       `json:"failures"`
+	At       time.Time `json:"at"`
+}
+
+// Type implements Event
+func (CircuitStateChanged) Type() string { return TypeCircuitStateChanged }
+
+// Opened reports whether the circuit breaker opened, holding back all sends
+func (e CircuitStateChanged) Opened() bool { return e.To == "open" }
+
+// CampaignProgress is published when a campaign's recipients or status change
+type CampaignProgress struct {
+	CampaignID string      `json:"campaign_id"`
+	Status     string      `json:"status"`
+	Report     interface{} `json:"report"` // services.CampaignReport
+	At         time.Time   `json:"at"`
+}
+
+// Type implements Event
+func (CampaignProgress) Type() string { return TypeCampaignProgress }
@@ -272,6 +272,7 @@ type FailedDeliveryPagination struct {
 type FailedDeliveryFilterCounts struct {
 	InvalidPhone    int `json:"invalid_phone"`
 	GOWATimeout     int `json:"gowa_timeout"`
+	GOWAUnavailable int `json:"gowa_unavailable"`
 	MessageRejected int `json:"message_rejected"`
 	Other           int `json:"other"`
 }
@@ -283,7 +284,26 @@ type FailedDeliveriesResponse struct {
 	FilterCounts FailedDeliveryFilterCounts `json:"filter_counts"`
 }
 
-// categorizeFailureReason categorizes an error message into a reason code
+// failureReasonTexts maps failure reason codes to display text
+var failureReasonTexts = map[string]string{
+	models.FailureCodeInvalidPhone:    "Nomor tidak valid",
+	models.FailureCodeGOWATimeout:     "GOWA timeout",
+	models.FailureCodeGOWAUnavailable: "GOWA tidak tersedia",
+	models.FailureCodeMessageRejected: "Pesan ditolak",
+	models.FailureCodeOther:           "Lainnya",
+}
+
+// failureReason returns the reason code for a failed reminder. It uses the
+// code persisted at send time and falls back to the error message for
+// reminders that failed before codes were recorded.
+func failureReason(reminder *models.Reminder) (reasonCode, displayText string) {
+	if text, ok := failureReasonTexts[reminder.DeliveryFailureCode]; ok {
+		return reminder.DeliveryFailureCode, text
+	}
+	return categorizeFailureReason(reminder.DeliveryErrorMessage)
+}
+
+// categorizeFailureReason categorizes a legacy error message into a reason code
 func categorizeFailureReason(errorMsg string) (reasonCode, displayText string) {
 	errorMsg = strings.ToLower(errorMsg)
 
@@ -337,7 +357,7 @@ func (h *AnalyticsHandler) GetFailedDeliveries(c *gin.Context) {
 			}
 
 			// Categorize the failure reason
-			reasonCode, _ := categorizeFailureReason(reminder.DeliveryErrorMessage)
+			reasonCode, _ := failureReason(reminder)
 
 			// Count for filter totals
 			switch reasonCode {
@@ -345,6 +365,8 @@ func (h *AnalyticsHandler) GetFailedDeliveries(c *gin.Context) {
 				filterCounts.InvalidPhone++
 			case "gowa_timeout":
 				filterCounts.GOWATimeout++
+			case "gowa_unavailable":
+				filterCounts.GOWAUnavailable++
 			case "message_rejected":
 				filterCounts.MessageRejected++
 			default:
@@ -438,7 +460,7 @@ func (h *AnalyticsHandler) ExportFailedDeliveries(c *gin.Context) {
 				continue
 			}
 
-			reasonCode, _ := categorizeFailureReason(reminder.DeliveryErrorMessage)
+			reasonCode, _ := failureReason(reminder)
 
 			if filterReason != "" && reasonCode != filterReason {
 				continue
@@ -538,6 +560,10 @@ func (h *AnalyticsHandler) GetFailedDeliveryDetail(c *gin.Context) {
 			break
 		}
 	}
+	retryAttempts := []models.DeliveryAttempt{}
+	if foundReminder != nil {
+		retryAttempts = append(retryAttempts, foundReminder.DeliveryAttempts...)
+	}
 	h.patientStore.RUnlock()
 
 	if foundReminder == nil {
@@ -545,9 +571,9 @@ func (h *AnalyticsHandler) GetFailedDeliveryDetail(c *gin.Context) {
 		return
 	}
 
-	reasonCode, _ := categorizeFailureReason(foundReminder.DeliveryErrorMessage)
+	reasonCode, _ := failureReason(foundReminder)
 
-	// Build response (note: retry_attempts is empty because model doesn't store individual retry attempts)
+	// Build response
 	response := gin.H{
 		"data": gin.H{
 			"reminder_id":           foundReminder.ID,
@@ -560,7 +586,7 @@ func (h *AnalyticsHandler) GetFailedDeliveryDetail(c *gin.Context) {
 			"failure_reason_code":   reasonCode,
 			"failure_timestamp":     foundReminder.MessageSentAt,
 			"retry_count":           foundReminder.RetryCount,
-			"retry_attempts":        []interface{}{}, // Empty - model doesn't store individual attempts
+			"retry_attempts":        retryAttempts,
 		},
 		"message": "Failed delivery details retrieved",
 	}
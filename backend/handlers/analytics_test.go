@@ -465,6 +465,48 @@ func TestCategorizeFailureReason(t *testing.T) {
 	}
 }
 
+// TestFailureReason tests that persisted failure codes take precedence over message matching
+func TestFailureReason(t *testing.T) {
+	tests := []struct {
+		name         string
+		reminder     *models.Reminder
+		expectedCode string
+	}{
+		{
+			name: "persisted code wins over message text",
+			reminder: &models.Reminder{
+				DeliveryErrorMessage: "GOWA returned status 400: invalid payload, retry after 500ms",
+				DeliveryFailureCode:  models.FailureCodeMessageRejected,
+			},
+			expectedCode: "message_rejected",
+		},
+		{
+			name: "unavailable code",
+			reminder: &models.Reminder{
+				DeliveryErrorMessage: "request failed: dial tcp: connection refused",
+				DeliveryFailureCode:  models.FailureCodeGOWAUnavailable,
+			},
+			expectedCode: "gowa_unavailable",
+		},
+		{
+			name: "legacy reminder without code",
+			reminder: &models.Reminder{
+				DeliveryErrorMessage: "nomor tidak valid",
+			},
+			expectedCode: "invalid_phone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasonCode, _ := failureReason(tt.reminder)
+			if reasonCode != tt.expectedCode {
+				t.Errorf("failureReason() = %s, expected %s", reasonCode, tt.expectedCode)
+			}
+		})
+	}
+}
+
 // Test GetFailedDeliveries tests the failed deliveries handler
 func TestGetFailedDeliveries(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -0,0 +1,603 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// AppointmentHandler handles patient appointments and their notice reminders
+type AppointmentHandler struct {
+	patientStore *models.PatientStore
+	config       *config.Config
+	logger       *slog.Logger
+	generateID   IDGenerator
+}
+
+// NewAppointmentHandler creates a new appointment handler
+func NewAppointmentHandler(patientStore *models.PatientStore, cfg *config.Config, logger *slog.Logger, idGen IDGenerator) *AppointmentHandler {
+	return &AppointmentHandler{
+		patientStore: patientStore,
+		config:       cfg,
+		logger:       logger,
+		generateID:   idGen,
+	}
+}
+
+// CreateAppointmentRequest represents the request body for creating an appointment
+type CreateAppointmentRequest struct {
+	ScheduledAt string `json:"scheduled_at" binding:"required"` // YYYY-MM-DDTHH:MM local time
+	Facility    string `json:"facility" binding:"required"`
+	Purpose     string `json:"purpose" binding:"required"`
+	Notes       string `json:"notes"`
+}
+
+// UpdateAppointmentRequest represents the request body for editing appointment details
+type UpdateAppointmentRequest struct {
+	Facility string `json:"facility" binding:"required"`
+	Purpose  string `json:"purpose" binding:"required"`
+	Notes    string `json:"notes"`
+}
+
+// RescheduleAppointmentRequest represents the request body for rescheduling an appointment.
+// Empty details are copied from the replaced appointment.
+type RescheduleAppointmentRequest struct {
+	ScheduledAt string `json:"scheduled_at" binding:"required"`
+	Facility    string `json:"facility"`
+	Purpose     string `json:"purpose"`
+	Notes       string `json:"notes"`
+}
+
+// AppointmentStatusRequest represents the request body for recording an appointment outcome
+type AppointmentStatusRequest struct {
+	Status string `json:"status" binding:"required"` // attended, no_show or cancelled
+}
+
+// appointmentNoticeSync counts notice reminder changes
+type appointmentNoticeSync struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+}
+
+// parseAppointmentTime parses an appointment time in local time
+func parseAppointmentTime(value string) (time.Time, error) {
+	at, err := time.ParseInLocation(models.AppointmentTimeLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("scheduled_at must be a local time in YYYY-MM-DDTHH:MM format")
+	}
+	return at, nil
+}
+
+// noticeDueDate returns when a notice should be sent for an appointment, and whether it
+// should be sent at all: notices must fall before the appointment and after now
+func noticeDueDate(notice config.AppointmentNotice, appointmentAt, now time.Time) (string, bool) {
+	clock, err := time.Parse("15:04", notice.Time)
+	if err != nil {
+		return "", false
+	}
+	due := time.Date(appointmentAt.Year(), appointmentAt.Month(), appointmentAt.Day()-notice.DaysBefore,
+		clock.Hour(), clock.Minute(), 0, 0, time.Local)
+	if !due.Before(appointmentAt) || !due.After(now) {
+		return "", false
+	}
+	return due.Format(models.AppointmentTimeLayout), true
+}
+
+// appointmentNoticeText returns the reminder title and description for an appointment
+// in the patient's language
+func appointmentNoticeText(language string, appointment *models.Appointment) (string, string) {
+	when := appointment.ScheduledAt
+	if at, err := time.ParseInLocation(models.AppointmentTimeLayout, appointment.ScheduledAt, time.Local); err == nil {
+		when = at.Format("02/01/2006 15:04")
+	}
+
+	var title, description string
+	if language == models.LanguageEnglish {
+		title = "Appointment: " + appointment.Purpose
+		description = fmt.Sprintf("Scheduled for %s at %s.", when, appointment.Facility)
+	} else {
+		title = "Janji temu: " + appointment.Purpose
+		description = fmt.Sprintf("Jadwal %s di %s.", when, appointment.Facility)
+	}
+	if appointment.Notes != "" {
+		description += "\n" + appointment.Notes
+	}
+	return title, description
+}
+
+// syncNotices brings the notice reminders of an appointment in line with its time and
+// details. Unsent notices linked to any of linkedIDs (the appointment and, when
+// rescheduling, the one it replaces) are moved to the appointment or removed if their
+// time has passed, and configured notices that are missing are created. Sent notices
+// are left untouched. The caller must hold the patient store write lock.
+func (h *AppointmentHandler) syncNotices(patient *models.Patient, appointment *models.Appointment, linkedIDs ...string) appointmentNoticeSync {
+	var result appointmentNoticeSync
+	appointmentAt, err := parseAppointmentTime(appointment.ScheduledAt)
+	if err != nil {
+		return result
+	}
+	now := time.Now()
+
+	notices := make(map[string]config.AppointmentNotice)
+	if h.config != nil {
+		for _, notice := range h.config.Appointments.Notices {
+			notices[notice.Name] = notice
+		}
+	}
+	linked := make(map[string]bool, len(linkedIDs)+1)
+	linked[appointment.ID] = true
+	for _, id := range linkedIDs {
+		linked[id] = true
+	}
+
+	title, description := appointmentNoticeText(patient.Language, appointment)
+	present := make(map[string]bool)
+	kept := patient.Reminders[:0]
+	for _, r := range patient.Reminders {
+		if r.AppointmentID == "" || !linked[r.AppointmentID] {
+			kept = append(kept, r)
+			continue
+		}
+		if !r.IsUnsent() {
+			if r.AppointmentID == appointment.ID {
+				present[r.AppointmentNotice] = true
+			}
+			kept = append(kept, r)
+			continue
+		}
+		notice, configured := notices[r.AppointmentNotice]
+		dueDate, send := noticeDueDate(notice, appointmentAt, now)
+		if !configured || !send || present[r.AppointmentNotice] {
+			result.Removed++
+			continue
+		}
+		r.AppointmentID = appointment.ID
+		r.DueDate = dueDate
+		r.Title = title
+		r.Description = description
+		if r.DeliveryStatus == models.DeliveryStatusScheduled {
+			transitionReminder(h.logger, r, models.DeliveryStatusPending, "", "appointment changed")
+		}
+		r.ScheduledDeliveryAt = ""
+		present[r.AppointmentNotice] = true
+		result.Updated++
+		kept = append(kept, r)
+	}
+	patient.Reminders = kept
+
+	if h.config == nil {
+		return result
+	}
+	for _, notice := range h.config.Appointments.Notices {
+		if present[notice.Name] {
+			continue
+		}
+		dueDate, send := noticeDueDate(notice, appointmentAt, now)
+		if !send {
+			continue
+		}
+		patient.Reminders = append(patient.Reminders, &models.Reminder{
+			ID:                h.generateID(),
+			Title:             title,
+			Description:       description,
+			DueDate:           dueDate,
+			Priority:          "high",
+			DeliveryStatus:    models.DeliveryStatusPending,
+			AppointmentID:     appointment.ID,
+			AppointmentNotice: notice.Name,
+		})
+		result.Created++
+	}
+	return result
+}
+
+// cancelNotices cancels the unsent notice reminders of an appointment.
+// The caller must hold the patient store write lock.
+func cancelNotices(patient *models.Patient, appointmentID, userID string) int {
+	timestamp := getCurrentTimestamp()
+	cancelled := 0
+	for _, r := range patient.Reminders {
+		if r.AppointmentID != appointmentID || !r.IsUnsent() {
+			continue
+		}
+		transitionReminder(nil, r, models.DeliveryStatusCancelled, userID, "appointment cancelled")
+		r.CancelledAt = timestamp
+		r.CancelledBy = userID
+		cancelled++
+	}
+	return cancelled
+}
+
+// findAppointment returns a patient's appointment by ID
+func findAppointment(patient *models.Patient, appointmentID string) *models.Appointment {
+	for _, appointment := range patient.Appointments {
+		if appointment.ID == appointmentID {
+			return appointment
+		}
+	}
+	return nil
+}
+
+// ListAppointments handles GET /api/patients/:id/appointments
+func (h *AppointmentHandler) ListAppointments(c *gin.Context) {
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	appointments := make([]models.Appointment, 0, len(patient.Appointments))
+	for _, appointment := range patient.Appointments {
+		appointments = append(appointments, *appointment)
+	}
+	h.patientStore.Unlock()
+
+	sort.SliceStable(appointments, func(i, j int) bool {
+		return appointments[i].ScheduledAt < appointments[j].ScheduledAt
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": appointments, "message": "Success"})
+}
+
+// CreateAppointment handles POST /api/patients/:id/appointments
+func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
+	var req CreateAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	at, err := parseAppointmentTime(req.ScheduledAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_APPOINTMENT"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	timestamp := getCurrentTimestamp()
+	appointment := &models.Appointment{
+		ID:          h.generateID(),
+		ScheduledAt: at.Format(models.AppointmentTimeLayout),
+		Facility:    strings.TrimSpace(req.Facility),
+		Purpose:     strings.TrimSpace(req.Purpose),
+		Notes:       req.Notes,
+		Status:      models.AppointmentStatusScheduled,
+		CreatedBy:   userID,
+		CreatedAt:   timestamp,
+		UpdatedAt:   timestamp,
+	}
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	patient.Appointments = append(patient.Appointments, appointment)
+	notices := h.syncNotices(patient, appointment)
+	patient.UpdatedAt = timestamp
+	created := *appointment
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Appointment created",
+			"appointment_id", created.ID,
+			"patient_id", patient.ID,
+			"scheduled_at", created.ScheduledAt,
+			"notices", notices.Created,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": created, "notices": notices, "message": "Appointment created"})
+}
+
+// UpdateAppointment handles PUT /api/patients/:id/appointments/:appointmentId
+// It edits the details of a scheduled appointment and refreshes its unsent notices.
+func (h *AppointmentHandler) UpdateAppointment(c *gin.Context) {
+	var req UpdateAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	appointment := findAppointment(patient, c.Param("appointmentId"))
+	if appointment == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "appointment not found", "code": "APPOINTMENT_NOT_FOUND"})
+		return
+	}
+	if appointment.Status != models.AppointmentStatusScheduled {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": "only scheduled appointments can be edited", "code": "INVALID_APPOINTMENT_STATE"})
+		return
+	}
+
+	timestamp := getCurrentTimestamp()
+	appointment.Facility = strings.TrimSpace(req.Facility)
+	appointment.Purpose = strings.TrimSpace(req.Purpose)
+	appointment.Notes = req.Notes
+	appointment.UpdatedAt = timestamp
+	notices := h.syncNotices(patient, appointment)
+	patient.UpdatedAt = timestamp
+	updated := *appointment
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	c.JSON(http.StatusOK, gin.H{"data": updated, "notices": notices, "message": "Appointment updated"})
+}
+
+// RescheduleAppointment handles POST /api/patients/:id/appointments/:appointmentId/reschedule
+// The appointment is marked rescheduled and replaced by a new one; its unsent notices
+// move to the new appointment.
+func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context) {
+	var req RescheduleAppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	at, err := parseAppointmentTime(req.ScheduledAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_APPOINTMENT"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	previous := findAppointment(patient, c.Param("appointmentId"))
+	if previous == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "appointment not found", "code": "APPOINTMENT_NOT_FOUND"})
+		return
+	}
+	if !previous.CanTransition(models.AppointmentStatusRescheduled) {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "appointment cannot be rescheduled",
+			"code":           "INVALID_APPOINTMENT_STATE",
+			"current_status": previous.Status,
+		})
+		return
+	}
+
+	timestamp := getCurrentTimestamp()
+	appointment := &models.Appointment{
+		ID:              h.generateID(),
+		ScheduledAt:     at.Format(models.AppointmentTimeLayout),
+		Facility:        previous.Facility,
+		Purpose:         previous.Purpose,
+		Notes:           previous.Notes,
+		Status:          models.AppointmentStatusScheduled,
+		RescheduledFrom: previous.ID,
+		CreatedBy:       userID,
+		CreatedAt:       timestamp,
+		UpdatedAt:       timestamp,
+	}
+	if facility := strings.TrimSpace(req.Facility); facility != "" {
+		appointment.Facility = facility
+	}
+	if purpose := strings.TrimSpace(req.Purpose); purpose != "" {
+		appointment.Purpose = purpose
+	}
+	if req.Notes != "" {
+		appointment.Notes = req.Notes
+	}
+
+	previous.Status = models.AppointmentStatusRescheduled
+	previous.RescheduledTo = appointment.ID
+	previous.StatusUpdatedAt = timestamp
+	previous.StatusUpdatedBy = userID
+	previous.UpdatedAt = timestamp
+	patient.Appointments = append(patient.Appointments, appointment)
+	notices := h.syncNotices(patient, appointment, previous.ID)
+	patient.UpdatedAt = timestamp
+	created := *appointment
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Appointment rescheduled",
+			"appointment_id", created.ID,
+			"rescheduled_from", created.RescheduledFrom,
+			"patient_id", patient.ID,
+			"scheduled_at", created.ScheduledAt,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": created, "notices": notices, "message": "Appointment rescheduled"})
+}
+
+// UpdateAppointmentStatus handles POST /api/patients/:id/appointments/:appointmentId/status
+// Recording attendance, a no-show or a cancellation cancels the unsent notices.
+func (h *AppointmentHandler) UpdateAppointmentStatus(c *gin.Context) {
+	var req AppointmentStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	switch req.Status {
+	case models.AppointmentStatusAttended, models.AppointmentStatusNoShow, models.AppointmentStatusCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be attended, no_show or cancelled", "code": "INVALID_APPOINTMENT"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	appointment := findAppointment(patient, c.Param("appointmentId"))
+	if appointment == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "appointment not found", "code": "APPOINTMENT_NOT_FOUND"})
+		return
+	}
+	if !appointment.CanTransition(req.Status) {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          fmt.Sprintf("appointment cannot change from %s to %s", appointment.Status, req.Status),
+			"code":           "INVALID_APPOINTMENT_STATE",
+			"current_status": appointment.Status,
+		})
+		return
+	}
+
+	timestamp := getCurrentTimestamp()
+	previousStatus := appointment.Status
+	appointment.Status = req.Status
+	appointment.StatusUpdatedAt = timestamp
+	appointment.StatusUpdatedBy = userID
+	appointment.UpdatedAt = timestamp
+	cancelled := cancelNotices(patient, appointment.ID, userID)
+	patient.UpdatedAt = timestamp
+	updated := *appointment
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Appointment status updated",
+			"appointment_id", updated.ID,
+			"patient_id", patient.ID,
+			"previous_status", previousStatus,
+			"status", updated.Status,
+			"cancelled_notices", cancelled,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": updated, "cancelled_notices": cancelled, "message": "Appointment updated"})
+}
+
+// AppointmentFacilityStats counts attendance at one facility
+type AppointmentFacilityStats struct {
+	Facility   string  `json:"facility"`
+	Attended   int     `json:"attended"`
+	NoShow     int     `json:"no_show"`
+	NoShowRate float64 `json:"no_show_rate"`
+}
+
+// NoShowEntry is one missed appointment in the report
+type NoShowEntry struct {
+	PatientID     string `json:"patient_id"`
+	PatientName   string `json:"patient_name"`
+	AppointmentID string `json:"appointment_id"`
+	ScheduledAt   string `json:"scheduled_at"`
+	Facility      string `json:"facility"`
+	Purpose       string `json:"purpose"`
+}
+
+// AppointmentReport summarises appointment outcomes
+type AppointmentReport struct {
+	Total           int                        `json:"total"`
+	Scheduled       int                        `json:"scheduled"`
+	AwaitingOutcome int                        `json:"awaiting_outcome"` // Scheduled, time passed, attendance not recorded
+	Attended        int                        `json:"attended"`
+	NoShow          int                        `json:"no_show"`
+	Rescheduled     int                        `json:"rescheduled"`
+	Cancelled       int                        `json:"cancelled"`
+	NoShowRate      float64                    `json:"no_show_rate"` // Percentage of recorded outcomes that were no-shows
+	ByFacility      []AppointmentFacilityStats `json:"by_facility"`
+	NoShows         []NoShowEntry              `json:"no_shows"`
+}
+
+// noShowRate returns no-shows as a percentage of recorded outcomes, rounded to one decimal
+func noShowRate(attended, noShow int) float64 {
+	if attended+noShow == 0 {
+		return 0
+	}
+	return math.Round(float64(noShow)/float64(attended+noShow)*1000) / 10
+}
+
+// GetAppointmentReport handles GET /api/analytics/appointments
+// Optional ?from= and ?to= (YYYY-MM-DD, inclusive) filter by appointment date.
+func (h *AppointmentHandler) GetAppointmentReport(c *gin.Context) {
+	from, to := c.Query("from"), c.Query("to")
+	for _, value := range []string{from, to} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be dates in YYYY-MM-DD format"})
+			return
+		}
+	}
+
+	now := time.Now()
+	report := AppointmentReport{ByFacility: []AppointmentFacilityStats{}, NoShows: []NoShowEntry{}}
+	facilities := make(map[string]*AppointmentFacilityStats)
+
+	h.patientStore.RLock()
+	for _, patient := range h.patientStore.Patients {
+		for _, appointment := range patient.Appointments {
+			day := appointment.ScheduledAt
+			if len(day) >= 10 {
+				day = day[:10]
+			}
+			if (from != "" && day < from) || (to != "" && day > to) {
+				continue
+			}
+
+			report.Total++
+			stats, ok := facilities[appointment.Facility]
+			if !ok {
+				stats = &AppointmentFacilityStats{Facility: appointment.Facility}
+				facilities[appointment.Facility] = stats
+			}
+			switch appointment.Status {
+			case models.AppointmentStatusScheduled:
+				report.Scheduled++
+				if at, err := parseAppointmentTime(appointment.ScheduledAt); err == nil && at.Before(now) {
+					report.AwaitingOutcome++
+				}
+			case models.AppointmentStatusAttended:
+				report.Attended++
+				stats.Attended++
+			case models.AppointmentStatusNoShow:
+				report.NoShow++
+				stats.NoShow++
+				report.NoShows = append(report.NoShows, NoShowEntry{
+					PatientID:     patient.ID,
+					PatientName:   patient.Name,
+					AppointmentID: appointment.ID,
+					ScheduledAt:   appointment.ScheduledAt,
+					Facility:      appointment.Facility,
+					Purpose:       appointment.Purpose,
+				})
+			case models.AppointmentStatusRescheduled:
+				report.Rescheduled++
+			case models.AppointmentStatusCancelled:
+				report.Cancelled++
+			}
+		}
+	}
+	h.patientStore.RUnlock()
+
+	report.NoShowRate = noShowRate(report.Attended, report.NoShow)
+	for _, stats := range facilities {
+		stats.NoShowRate = noShowRate(stats.Attended, stats.NoShow)
+		report.ByFacility = append(report.ByFacility, *stats)
+	}
+	sort.Slice(report.ByFacility, func(i, j int) bool {
+		return report.ByFacility[i].Facility < report.ByFacility[j].Facility
+	})
+	sort.Slice(report.NoShows, func(i, j int) bool {
+		return report.NoShows[i].ScheduledAt > report.NoShows[j].ScheduledAt
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
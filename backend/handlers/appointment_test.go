@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func setupAppointmentHandler(t *testing.T) (*AppointmentHandler, *models.PatientStore) {
+	t.Helper()
+	patients := models.NewPatientStore(func() {})
+	patients.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", CreatedBy: "volunteer-1"}
+
+	cfg := &config.Config{Appointments: config.AppointmentsConfig{Notices: []config.AppointmentNotice{
+		{Name: "day_before", DaysBefore: 1, Time: "18:00"},
+		{Name: "morning_of", DaysBefore: 0, Time: "07:00"},
+	}}}
+	next := 0
+	handler := NewAppointmentHandler(patients, cfg, nil, func() string {
+		next++
+		return fmt.Sprintf("id-%d", next)
+	})
+	return handler, patients
+}
+
+// noticesFor returns the reminders of an appointment keyed by notice name
+func noticesFor(patient *models.Patient, appointmentID string) map[string]*models.Reminder {
+	notices := make(map[string]*models.Reminder)
+	for _, r := range patient.Reminders {
+		if r.AppointmentID == appointmentID {
+			notices[r.AppointmentNotice] = r
+		}
+	}
+	return notices
+}
+
+func TestAppointmentHandler_CreateAppointment(t *testing.T) {
+	day := time.Now().AddDate(0, 0, 10)
+	params := gin.Params{{Key: "id", Value: "patient-1"}}
+
+	t.Run("creates day before and morning of notices", func(t *testing.T) {
+		handler, patients := setupAppointmentHandler(t)
+		w := roleRequest(handler.CreateAppointment, "POST", params, "volunteer", CreateAppointmentRequest{
+			ScheduledAt: day.Format("2006-01-02") + "T10:00",
+			Facility:    "Puskesmas Sukajadi",
+			Purpose:     "Kontrol TB",
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+
+		patient := patients.Patients["patient-1"]
+		if len(patient.Appointments) != 1 || patient.Appointments[0].Status != models.AppointmentStatusScheduled {
+			t.Fatalf("Expected one scheduled appointment, got %+v", patient.Appointments)
+		}
+		notices := noticesFor(patient, patient.Appointments[0].ID)
+		if len(notices) != 2 {
+			t.Fatalf("Expected 2 notices, got %d", len(notices))
+		}
+		if want := day.AddDate(0, 0, -1).Format("2006-01-02") + "T18:00"; notices["day_before"].DueDate != want {
+			t.Errorf("Expected day_before due %s, got %s", want, notices["day_before"].DueDate)
+		}
+		if want := day.Format("2006-01-02") + "T07:00"; notices["morning_of"].DueDate != want {
+			t.Errorf("Expected morning_of due %s, got %s", want, notices["morning_of"].DueDate)
+		}
+		if notices["morning_of"].Title != "Janji temu: Kontrol TB" {
+			t.Errorf("Unexpected notice title %q", notices["morning_of"].Title)
+		}
+	})
+
+	t.Run("skips notices not before the appointment", func(t *testing.T) {
+		handler, patients := setupAppointmentHandler(t)
+		w := roleRequest(handler.CreateAppointment, "POST", params, "volunteer", CreateAppointmentRequest{
+			ScheduledAt: day.Format("2006-01-02") + "T06:30",
+			Facility:    "RSUD",
+			Purpose:     "Cek darah",
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		notices := noticesFor(patients.Patients["patient-1"], "id-1")
+		if len(notices) != 1 || notices["day_before"] == nil {
+			t.Errorf("Expected only day_before notice, got %v", notices)
+		}
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		handler, _ := setupAppointmentHandler(t)
+		w := roleRequest(handler.CreateAppointment, "POST", params, "volunteer", CreateAppointmentRequest{
+			ScheduledAt: "besok pagi",
+			Facility:    "RSUD",
+			Purpose:     "Cek darah",
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("other volunteer's patient", func(t *testing.T) {
+		handler, patients := setupAppointmentHandler(t)
+		patients.Patients["patient-1"].CreatedBy = "volunteer-2"
+		w := roleRequest(handler.CreateAppointment, "POST", params, "volunteer", CreateAppointmentRequest{
+			ScheduledAt: day.Format("2006-01-02") + "T10:00",
+			Facility:    "RSUD",
+			Purpose:     "Cek darah",
+		})
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+}
+
+func TestAppointmentHandler_RescheduleAndStatus(t *testing.T) {
+	handler, patients := setupAppointmentHandler(t)
+	day := time.Now().AddDate(0, 0, 10)
+	patientParams := gin.Params{{Key: "id", Value: "patient-1"}}
+
+	roleRequest(handler.CreateAppointment, "POST", patientParams, "volunteer", CreateAppointmentRequest{
+		ScheduledAt: day.Format("2006-01-02") + "T10:00",
+		Facility:    "Puskesmas Sukajadi",
+		Purpose:     "Kontrol TB",
+	})
+	patient := patients.Patients["patient-1"]
+	original := patient.Appointments[0]
+	sent := noticesFor(patient, original.ID)["day_before"]
+	sent.DeliveryStatus = models.DeliveryStatusSent
+
+	newDay := day.AddDate(0, 0, 7)
+	params := gin.Params{{Key: "id", Value: "patient-1"}, {Key: "appointmentId", Value: original.ID}}
+	w := roleRequest(handler.RescheduleAppointment, "POST", params, "volunteer", RescheduleAppointmentRequest{
+		ScheduledAt: newDay.Format("2006-01-02") + "T13:00",
+		Facility:    "RSUD Kota",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var resp struct {
+		Data    models.Appointment    `json:"data"`
+		Notices appointmentNoticeSync `json:"notices"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Notices.Updated != 1 || resp.Notices.Created != 1 || resp.Notices.Removed != 0 {
+		t.Errorf("Unexpected notice changes: %+v", resp.Notices)
+	}
+	if original.Status != models.AppointmentStatusRescheduled || original.RescheduledTo != resp.Data.ID {
+		t.Errorf("Expected original marked rescheduled, got %+v", original)
+	}
+	if resp.Data.Facility != "RSUD Kota" || resp.Data.Purpose != "Kontrol TB" || resp.Data.RescheduledFrom != original.ID {
+		t.Errorf("Expected new appointment with copied details, got %+v", resp.Data)
+	}
+	if sent.AppointmentID != original.ID {
+		t.Error("Sent notice must stay with the original appointment")
+	}
+	notices := noticesFor(patient, resp.Data.ID)
+	if want := newDay.Format("2006-01-02") + "T07:00"; notices["morning_of"] == nil || notices["morning_of"].DueDate != want {
+		t.Errorf("Expected morning_of moved to %s, got %+v", want, notices["morning_of"])
+	}
+	if notices["day_before"] == nil || notices["day_before"].ID == sent.ID {
+		t.Errorf("Expected a new day_before notice, got %+v", notices["day_before"])
+	}
+
+	// A rescheduled appointment is final
+	w = roleRequest(handler.UpdateAppointmentStatus, "POST", params, "volunteer", AppointmentStatusRequest{Status: models.AppointmentStatusAttended})
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	params = gin.Params{{Key: "id", Value: "patient-1"}, {Key: "appointmentId", Value: resp.Data.ID}}
+	w = roleRequest(handler.UpdateAppointmentStatus, "POST", params, "volunteer", AppointmentStatusRequest{Status: models.AppointmentStatusNoShow})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	for name, r := range noticesFor(patient, resp.Data.ID) {
+		if r.DeliveryStatus != models.DeliveryStatusCancelled {
+			t.Errorf("Expected %s notice cancelled, got %s", name, r.DeliveryStatus)
+		}
+	}
+
+	// Attendance can be corrected, cancellation can't follow an outcome
+	if w = roleRequest(handler.UpdateAppointmentStatus, "POST", params, "volunteer", AppointmentStatusRequest{Status: models.AppointmentStatusAttended}); w.Code != http.StatusOK {
+		t.Errorf("Expected correction to attended, got %d", w.Code)
+	}
+	if w = roleRequest(handler.UpdateAppointmentStatus, "POST", params, "volunteer", AppointmentStatusRequest{Status: models.AppointmentStatusCancelled}); w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
+	}
+	if w = roleRequest(handler.UpdateAppointmentStatus, "POST", params, "volunteer", AppointmentStatusRequest{Status: "missed"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAppointmentHandler_GetAppointmentReport(t *testing.T) {
+	handler, patients := setupAppointmentHandler(t)
+	patients.Patients["patient-1"].Appointments = []*models.Appointment{
+		{ID: "a1", ScheduledAt: "2026-03-01T09:00", Facility: "RSUD", Status: models.AppointmentStatusAttended},
+		{ID: "a2", ScheduledAt: "2026-03-05T09:00", Facility: "RSUD", Status: models.AppointmentStatusNoShow, Purpose: "Kontrol"},
+		{ID: "a3", ScheduledAt: "2026-03-06T09:00", Facility: "Puskesmas", Status: models.AppointmentStatusAttended},
+		{ID: "a4", ScheduledAt: "2026-03-07T09:00", Facility: "Puskesmas", Status: models.AppointmentStatusScheduled},
+		{ID: "a5", ScheduledAt: "2026-04-01T09:00", Facility: "RSUD", Status: models.AppointmentStatusNoShow},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/analytics/appointments?from=2026-03-01&to=2026-03-31", nil)
+	handler.GetAppointmentReport(c)
+
+	var resp struct {
+		Data AppointmentReport `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	report := resp.Data
+	if report.Total != 4 || report.Attended != 2 || report.NoShow != 1 || report.AwaitingOutcome != 1 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if report.NoShowRate != 33.3 {
+		t.Errorf("Expected no-show rate 33.3, got %v", report.NoShowRate)
+	}
+	if len(report.ByFacility) != 2 || report.ByFacility[1].Facility != "RSUD" || report.ByFacility[1].NoShowRate != 50 {
+		t.Errorf("Unexpected facility stats: %+v", report.ByFacility)
+	}
+	if len(report.NoShows) != 1 || report.NoShows[0].PatientName != "Budi" || report.NoShows[0].AppointmentID != "a2" {
+		t.Errorf("Unexpected no-show list: %+v", report.NoShows)
+	}
+}
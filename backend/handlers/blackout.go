@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// blackoutRegions are the regions a blackout can be limited to
+var blackoutRegions = map[string]bool{"WIB": true, "WITA": true, "WIT": true}
+
+// BlackoutHandler handles the admin-managed blackout calendar
+type BlackoutHandler struct {
+	store      *models.BlackoutStore
+	priorities *config.PrioritiesConfig // Allowed exempt priorities, any if nil
+	logger     *slog.Logger
+	generateID IDGenerator
+}
+
+// NewBlackoutHandler creates a new blackout handler
+func NewBlackoutHandler(store *models.BlackoutStore, logger *slog.Logger, idGen IDGenerator) *BlackoutHandler {
+	return &BlackoutHandler{
+		store:      store,
+		logger:     logger,
+		generateID: idGen,
+	}
+}
+
+// SetPriorities sets the configured priority levels exemptions are validated against
+func (h *BlackoutHandler) SetPriorities(priorities *config.PrioritiesConfig) {
+	h.priorities = priorities
+}
+
+// BlackoutRequest represents the request body for creating or updating a blackout
+type BlackoutRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	Type             string   `json:"type" binding:"required"` // "dates" or "recurring"
+	StartDate        string   `json:"start_date"`
+	EndDate          string   `json:"end_date"` // Defaults to start_date
+	StartTime        string   `json:"start_time"`
+	EndTime          string   `json:"end_time"`
+	Weekdays         []int    `json:"weekdays"`
+	Region           string   `json:"region"`
+	ExemptPriorities []string `json:"exempt_priorities"`
+	Enabled          *bool    `json:"enabled"` // Defaults to true
+}
+
+// validateBlackout checks a blackout request and clears fields that do not apply to its type
+func validateBlackout(req *BlackoutRequest, priorities *config.PrioritiesConfig) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if req.Region != "" && !blackoutRegions[req.Region] {
+		return fmt.Errorf("region must be one of WIB, WITA, WIT")
+	}
+	for i, priority := range req.ExemptPriorities {
+		if strings.TrimSpace(priority) == "" {
+			return fmt.Errorf("exempt_priorities[%d] is empty", i)
+		}
+		if err := validatePriority(priorities, priority); err != nil {
+			return fmt.Errorf("exempt_priorities[%d]: %v", i, err)
+		}
+	}
+
+	switch req.Type {
+	case models.BlackoutTypeDates:
+		if req.EndDate == "" {
+			req.EndDate = req.StartDate
+		}
+		start, err := time.Parse(models.BlackoutDateLayout, req.StartDate)
+		if err != nil {
+			return fmt.Errorf("start_date must be a date in YYYY-MM-DD format")
+		}
+		end, err := time.Parse(models.BlackoutDateLayout, req.EndDate)
+		if err != nil {
+			return fmt.Errorf("end_date must be a date in YYYY-MM-DD format")
+		}
+		if end.Before(start) {
+			return fmt.Errorf("end_date must not be before start_date")
+		}
+		req.StartTime, req.EndTime, req.Weekdays = "", "", nil
+
+	case models.BlackoutTypeRecurring:
+		start, err := time.Parse("15:04", req.StartTime)
+		if err != nil {
+			return fmt.Errorf("start_time must be HH:MM")
+		}
+		end, err := time.Parse("15:04", req.EndTime)
+		if err != nil {
+			return fmt.Errorf("end_time must be HH:MM")
+		}
+		if start.Equal(end) {
+			return fmt.Errorf("start_time and end_time must differ")
+		}
+		req.StartTime, req.EndTime = start.Format("15:04"), end.Format("15:04")
+		seen := make(map[int]bool, len(req.Weekdays))
+		for _, day := range req.Weekdays {
+			if day < 0 || day > 6 {
+				return fmt.Errorf("weekdays must be between 0 (Sunday) and 6 (Saturday), got %d", day)
+			}
+			if seen[day] {
+				return fmt.Errorf("weekdays: duplicate day %d", day)
+			}
+			seen[day] = true
+		}
+		sort.Ints(req.Weekdays)
+		req.StartDate, req.EndDate = "", ""
+
+	default:
+		return fmt.Errorf("type must be %s or %s", models.BlackoutTypeDates, models.BlackoutTypeRecurring)
+	}
+	return nil
+}
+
+// applyBlackoutRequest copies a validated request onto a blackout
+func applyBlackoutRequest(b *models.Blackout, req *BlackoutRequest) {
+	b.Name = req.Name
+	b.Type = req.Type
+	b.StartDate = req.StartDate
+	b.EndDate = req.EndDate
+	b.StartTime = req.StartTime
+	b.EndTime = req.EndTime
+	b.Weekdays = req.Weekdays
+	b.Region = req.Region
+	b.ExemptPriorities = req.ExemptPriorities
+	b.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// ListBlackouts handles GET /api/blackouts
+func (h *BlackoutHandler) ListBlackouts(c *gin.Context) {
+	h.store.Mu.RLock()
+	blackouts := make([]models.Blackout, 0, len(h.store.Blackouts))
+	for _, b := range h.store.Blackouts {
+		blackouts = append(blackouts, *b)
+	}
+	h.store.Mu.RUnlock()
+
+	// Dated blackouts first in date order, then recurring ones by start time
+	sort.Slice(blackouts, func(i, j int) bool {
+		if blackouts[i].Type != blackouts[j].Type {
+			return blackouts[i].Type == models.BlackoutTypeDates
+		}
+		if blackouts[i].StartDate+blackouts[i].StartTime != blackouts[j].StartDate+blackouts[j].StartTime {
+			return blackouts[i].StartDate+blackouts[i].StartTime < blackouts[j].StartDate+blackouts[j].StartTime
+		}
+		return blackouts[i].Name < blackouts[j].Name
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": blackouts, "message": "Success"})
+}
+
+// CreateBlackout handles POST /api/blackouts
+func (h *BlackoutHandler) CreateBlackout(c *gin.Context) {
+	var req BlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateBlackout(&req, h.priorities); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_BLACKOUT"})
+		return
+	}
+
+	timestamp := getCurrentTimestamp()
+	blackout := &models.Blackout{
+		ID:        h.generateID(),
+		CreatedBy: c.GetString("userID"),
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	}
+	applyBlackoutRequest(blackout, &req)
+
+	h.store.Mu.Lock()
+	h.store.Blackouts[blackout.ID] = blackout
+	created := *blackout
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Blackout created",
+			"blackout_id", created.ID,
+			"type", created.Type,
+			"user_id", created.CreatedBy,
+		)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": created, "message": "Blackout created"})
+}
+
+// UpdateBlackout handles PUT /api/blackouts/:id
+func (h *BlackoutHandler) UpdateBlackout(c *gin.Context) {
+	var req BlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateBlackout(&req, h.priorities); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_BLACKOUT"})
+		return
+	}
+
+	h.store.Mu.Lock()
+	blackout, exists := h.store.Blackouts[c.Param("id")]
+	if !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "blackout not found", "code": "BLACKOUT_NOT_FOUND"})
+		return
+	}
+	applyBlackoutRequest(blackout, &req)
+	blackout.UpdatedAt = getCurrentTimestamp()
+	updated := *blackout
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	c.JSON(http.StatusOK, gin.H{"data": updated, "message": "Blackout updated"})
+}
+
+// DeleteBlackout handles DELETE /api/blackouts/:id
+// Reminders already held back keep their scheduled time.
+func (h *BlackoutHandler) DeleteBlackout(c *gin.Context) {
+	id := c.Param("id")
+	h.store.Mu.Lock()
+	if _, exists := h.store.Blackouts[id]; !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "blackout not found", "code": "BLACKOUT_NOT_FOUND"})
+		return
+	}
+	delete(h.store.Blackouts, id)
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Blackout deleted",
+			"blackout_id", id,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Blackout deleted"})
+}
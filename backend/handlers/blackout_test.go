@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func setupBlackoutHandler(t *testing.T) (*BlackoutHandler, *models.BlackoutStore) {
+	t.Helper()
+	store := models.NewBlackoutStore(func() {})
+	next := 0
+	return NewBlackoutHandler(store, nil, func() string {
+		next++
+		return fmt.Sprintf("blackout-%d", next)
+	}), store
+}
+
+func TestBlackoutHandler_CreateBlackout_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  BlackoutRequest
+	}{
+		{"unknown type", BlackoutRequest{Name: "Libur", Type: "weekly"}},
+		{"invalid start date", BlackoutRequest{Name: "Libur", Type: models.BlackoutTypeDates, StartDate: "20-03-2026"}},
+		{"end before start", BlackoutRequest{Name: "Libur", Type: models.BlackoutTypeDates, StartDate: "2026-03-20", EndDate: "2026-03-19"}},
+		{"invalid time", BlackoutRequest{Name: "Maghrib", Type: models.BlackoutTypeRecurring, StartTime: "18", EndTime: "18:30"}},
+		{"empty range", BlackoutRequest{Name: "Maghrib", Type: models.BlackoutTypeRecurring, StartTime: "18:00", EndTime: "18:00"}},
+		{"invalid weekday", BlackoutRequest{Name: "Jumat", Type: models.BlackoutTypeRecurring, StartTime: "11:30", EndTime: "13:00", Weekdays: []int{7}}},
+		{"invalid region", BlackoutRequest{Name: "Maghrib", Type: models.BlackoutTypeRecurring, StartTime: "18:00", EndTime: "18:30", Region: "UTC"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupBlackoutHandler(t)
+			w := roleRequest(handler.CreateBlackout, "POST", nil, "admin", tt.req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			var resp map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp["code"] != "INVALID_BLACKOUT" {
+				t.Errorf("Expected INVALID_BLACKOUT, got %v", resp["code"])
+			}
+		})
+	}
+}
+
+func TestBlackoutHandler_CRUD(t *testing.T) {
+	handler, store := setupBlackoutHandler(t)
+
+	w := roleRequest(handler.CreateBlackout, "POST", nil, "admin", BlackoutRequest{
+		Name:      "Idul Fitri",
+		Type:      models.BlackoutTypeDates,
+		StartDate: "2026-03-20",
+		StartTime: "08:00", // Ignored for whole days
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	lebaran := store.Blackouts["blackout-1"]
+	if lebaran.EndDate != "2026-03-20" || lebaran.StartTime != "" || !lebaran.Enabled || lebaran.CreatedBy != "admin-1" {
+		t.Errorf("Unexpected blackout %+v", lebaran)
+	}
+
+	w = roleRequest(handler.CreateBlackout, "POST", nil, "admin", BlackoutRequest{
+		Name:             "Sholat Jumat",
+		Type:             models.BlackoutTypeRecurring,
+		StartTime:        "11:30",
+		EndTime:          "13:00",
+		Weekdays:         []int{5},
+		Region:           "WITA",
+		ExemptPriorities: []string{"high"},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	w = roleRequest(handler.ListBlackouts, "GET", nil, "volunteer", nil)
+	var list struct {
+		Data []models.Blackout `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Data) != 2 || list.Data[0].ID != "blackout-1" || list.Data[1].Region != "WITA" {
+		t.Errorf("Expected dated blackout first, got %+v", list.Data)
+	}
+
+	disabled := false
+	params := gin.Params{{Key: "id", Value: "blackout-1"}}
+	w = roleRequest(handler.UpdateBlackout, "PUT", params, "admin", BlackoutRequest{
+		Name: "Idul Fitri", Type: models.BlackoutTypeDates, StartDate: "2026-03-20", EndDate: "2026-03-22", Enabled: &disabled,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if lebaran.EndDate != "2026-03-22" || lebaran.Enabled {
+		t.Errorf("Expected updated and disabled blackout, got %+v", lebaran)
+	}
+	if active := store.Active(); len(active) != 1 || active[0].ID != "blackout-2" {
+		t.Errorf("Expected only the enabled blackout active, got %+v", active)
+	}
+
+	if w := roleRequest(handler.DeleteBlackout, "DELETE", params, "admin", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := roleRequest(handler.DeleteBlackout, "DELETE", params, "admin", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d deleting twice, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,346 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/services"
+)
+
+// CampaignHandler handles bulk campaign administration
+type CampaignHandler struct {
+	store         *models.CampaignStore
+	patientStore  *models.PatientStore
+	contentStore  *ContentStore
+	templateStore *models.TemplateStore
+	config        *config.Config
+	logger        *slog.Logger
+	generateID    IDGenerator
+	bus           *events.Bus
+}
+
+// NewCampaignHandler creates a new campaign handler
+func NewCampaignHandler(store *models.CampaignStore, patientStore *models.PatientStore, contentStore *ContentStore, cfg *config.Config, logger *slog.Logger, idGen IDGenerator) *CampaignHandler {
+	return &CampaignHandler{
+		store:        store,
+		patientStore: patientStore,
+		contentStore: contentStore,
+		config:       cfg,
+		logger:       logger,
+		generateID:   idGen,
+	}
+}
+
+// SetTemplateStore sets the template store used to validate campaign message templates
+func (h *CampaignHandler) SetTemplateStore(templateStore *models.TemplateStore) {
+	h.templateStore = templateStore
+}
+
+// SetEventBus sets the bus that campaign status changes are published on
+func (h *CampaignHandler) SetEventBus(bus *events.Bus) {
+	h.bus = bus
+}
+
+// CreateCampaignRequest represents the request body for creating a campaign
+type CreateCampaignRequest struct {
+	Name        string                  `json:"name" binding:"required"`
+	Description string                  `json:"description"`
+	Audience    models.CampaignAudience `json:"audience"`
+	Reminder    models.CampaignReminder `json:"reminder"`
+	StartAt     string                  `json:"start_at"` // RFC3339, now if empty
+}
+
+// CampaignSummary is a campaign without its recipient list, with progress counts
+type CampaignSummary struct {
+	*models.Campaign
+	Report services.CampaignReport `json:"report"`
+}
+
+// snapshotCampaign copies a campaign so it can be used after the store lock is released
+func snapshotCampaign(campaign *models.Campaign) *models.Campaign {
+	snapshot := *campaign
+	snapshot.Recipients = append([]models.CampaignRecipient(nil), campaign.Recipients...)
+	snapshot.Reminder.Attachments = append([]models.Attachment(nil), campaign.Reminder.Attachments...)
+	return &snapshot
+}
+
+// ListCampaigns handles GET /api/campaigns
+func (h *CampaignHandler) ListCampaigns(c *gin.Context) {
+	h.store.Mu.RLock()
+	campaigns := make([]*models.Campaign, 0, len(h.store.Campaigns))
+	for _, campaign := range h.store.Campaigns {
+		campaigns = append(campaigns, snapshotCampaign(campaign))
+	}
+	h.store.Mu.RUnlock()
+
+	sort.Slice(campaigns, func(i, j int) bool {
+		return campaigns[i].CreatedAt > campaigns[j].CreatedAt
+	})
+
+	summaries := make([]CampaignSummary, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		report := services.BuildCampaignReport(campaign, h.patientStore)
+		campaign.Recipients = nil
+		summaries = append(summaries, CampaignSummary{Campaign: campaign, Report: report})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summaries, "message": "Success"})
+}
+
+// GetCampaign handles GET /api/campaigns/:id and includes per-recipient delivery status
+func (h *CampaignHandler) GetCampaign(c *gin.Context) {
+	campaign, ok := h.getSnapshot(c)
+	if !ok {
+		return
+	}
+
+	type recipientStatus struct {
+		models.CampaignRecipient
+		DeliveryStatus string `json:"delivery_status,omitempty"`
+	}
+	recipients := make([]recipientStatus, 0, len(campaign.Recipients))
+	h.patientStore.RLock()
+	for _, recipient := range campaign.Recipients {
+		item := recipientStatus{CampaignRecipient: recipient}
+		if patient, exists := h.patientStore.Patients[recipient.PatientID]; exists && recipient.ReminderID != "" {
+			for _, reminder := range patient.Reminders {
+				if reminder.ID == recipient.ReminderID {
+					item.DeliveryStatus = reminder.DeliveryStatus
+					break
+				}
+			}
+		}
+		recipients = append(recipients, item)
+	}
+	h.patientStore.RUnlock()
+
+	report := services.BuildCampaignReport(campaign, h.patientStore)
+	campaign.Recipients = nil
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"campaign":   campaign,
+			"recipients": recipients,
+			"report":     report,
+		},
+		"message": "Success",
+	})
+}
+
+// GetCampaignReport handles GET /api/campaigns/:id/report
+func (h *CampaignHandler) GetCampaignReport(c *gin.Context) {
+	campaign, ok := h.getSnapshot(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": services.BuildCampaignReport(campaign, h.patientStore), "message": "Success"})
+}
+
+// CreateCampaign handles POST /api/campaigns
+func (h *CampaignHandler) CreateCampaign(c *gin.Context) {
+	var req CreateCampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Reminder.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reminder title is required", "code": "INVALID_CAMPAIGN"})
+		return
+	}
+	if req.Audience.IsEmpty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "audience needs at least one filter, or all", "code": "INVALID_AUDIENCE"})
+		return
+	}
+	for _, lang := range req.Audience.Languages {
+		if !models.IsSupportedLanguage(lang) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported language '%s'", lang), "code": "INVALID_AUDIENCE"})
+			return
+		}
+	}
+	if len(req.Reminder.Attachments) > MaxAttachments {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     fmt.Sprintf("Maksimal %d konten yang dapat dilampirkan", MaxAttachments),
+			"code":      "MAX_ATTACHMENTS_EXCEEDED",
+			"max_count": MaxAttachments,
+			"actual":    len(req.Reminder.Attachments),
+		})
+		return
+	}
+	if err := validateAttachmentList(h.contentStore, req.Reminder.Attachments); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_ATTACHMENT"})
+		return
+	}
+	if err := validateTemplateName(h.templateStore, req.Reminder.MessageTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_TEMPLATE"})
+		return
+	}
+	if err := validatePriority(&h.config.Priorities, req.Reminder.Priority); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_PRIORITY"})
+		return
+	}
+
+	now := time.Now().UTC()
+	startAt := now
+	if req.StartAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.StartAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_at must be an RFC3339 timestamp", "code": "INVALID_CAMPAIGN"})
+			return
+		}
+		startAt = parsed.UTC()
+	}
+
+	userID := c.GetString("userID")
+	campaign := &models.Campaign{
+		ID:          h.generateID(),
+		Name:        req.Name,
+		Description: req.Description,
+		Audience:    req.Audience,
+		Reminder:    req.Reminder,
+		Status:      models.CampaignStatusScheduled,
+		StartAt:     startAt.Format(time.RFC3339),
+		CreatedBy:   userID,
+		CreatedAt:   now.Format(time.RFC3339),
+		UpdatedAt:   now.Format(time.RFC3339),
+	}
+
+	h.store.Mu.Lock()
+	h.store.Campaigns[campaign.ID] = campaign
+	snapshot := snapshotCampaign(campaign)
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Campaign created",
+			"campaign_id", campaign.ID,
+			"start_at", campaign.StartAt,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":          snapshot,
+		"audience_size": len(services.ResolveCampaignRecipients(req.Audience, h.patientStore)),
+		"message":       "Campaign created",
+	})
+}
+
+// PauseCampaign handles POST /api/campaigns/:id/pause
+func (h *CampaignHandler) PauseCampaign(c *gin.Context) {
+	h.transition(c, "Campaign paused", func(campaign *models.Campaign, userID string) error {
+		if campaign.Status != models.CampaignStatusScheduled && campaign.Status != models.CampaignStatusRunning {
+			return fmt.Errorf("only scheduled or running campaigns can be paused")
+		}
+		campaign.Status = models.CampaignStatusPaused
+		return nil
+	})
+}
+
+// ResumeCampaign handles POST /api/campaigns/:id/resume
+func (h *CampaignHandler) ResumeCampaign(c *gin.Context) {
+	h.transition(c, "Campaign resumed", func(campaign *models.Campaign, userID string) error {
+		if campaign.Status != models.CampaignStatusPaused {
+			return fmt.Errorf("only paused campaigns can be resumed")
+		}
+		// Campaigns paused before their start resume as scheduled and start at StartAt
+		if campaign.StartedAt == "" {
+			campaign.Status = models.CampaignStatusScheduled
+		} else {
+			campaign.Status = models.CampaignStatusRunning
+		}
+		return nil
+	})
+}
+
+// CancelCampaign handles POST /api/campaigns/:id/cancel
+// Recipients not yet sent are cancelled; messages already sent are unaffected.
+func (h *CampaignHandler) CancelCampaign(c *gin.Context) {
+	h.transition(c, "Campaign cancelled", func(campaign *models.Campaign, userID string) error {
+		if campaign.IsFinished() {
+			return fmt.Errorf("campaign is already %s", campaign.Status)
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		campaign.Status = models.CampaignStatusCancelled
+		campaign.CancelledAt = now
+		campaign.CancelledBy = userID
+		for i := range campaign.Recipients {
+			if campaign.Recipients[i].Status == models.RecipientStatusPending {
+				campaign.Recipients[i].Status = models.RecipientStatusCancelled
+				campaign.Recipients[i].ProcessedAt = now
+			}
+		}
+		return nil
+	})
+}
+
+// transition applies a status change to a campaign and broadcasts the new progress
+func (h *CampaignHandler) transition(c *gin.Context, message string, apply func(campaign *models.Campaign, userID string) error) {
+	userID := c.GetString("userID")
+
+	h.store.Mu.Lock()
+	campaign, exists := h.store.Campaigns[c.Param("id")]
+	if !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found", "code": "CAMPAIGN_NOT_FOUND"})
+		return
+	}
+	previousStatus := campaign.Status
+	if err := apply(campaign, userID); err != nil {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          err.Error(),
+			"code":           "INVALID_CAMPAIGN_STATE",
+			"current_status": campaign.Status,
+		})
+		return
+	}
+	campaign.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	snapshot := snapshotCampaign(campaign)
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info(message,
+			"campaign_id", snapshot.ID,
+			"previous_status", previousStatus,
+			"status", snapshot.Status,
+			"user_id", userID,
+		)
+	}
+
+	report := services.BuildCampaignReport(snapshot, h.patientStore)
+	if h.bus != nil {
+		h.bus.Publish(services.CampaignProgressEvent(report))
+	}
+
+	snapshot.Recipients = nil
+	c.JSON(http.StatusOK, gin.H{
+		"data":    CampaignSummary{Campaign: snapshot, Report: report},
+		"message": message,
+	})
+}
+
+// getSnapshot looks up the campaign in the :id param, writing a 404 if it does not exist
+func (h *CampaignHandler) getSnapshot(c *gin.Context) (*models.Campaign, bool) {
+	h.store.Mu.RLock()
+	campaign, exists := h.store.Campaigns[c.Param("id")]
+	var snapshot *models.Campaign
+	if exists {
+		snapshot = snapshotCampaign(campaign)
+	}
+	h.store.Mu.RUnlock()
+
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "campaign not found", "code": "CAMPAIGN_NOT_FOUND"})
+		return nil, false
+	}
+	return snapshot, true
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func setupCampaignHandler(t *testing.T) (*CampaignHandler, *models.CampaignStore, *models.PatientStore) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	patientStore := models.NewPatientStore(func() {})
+	patientStore.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Ani", Phone: "08123456789"}
+	patientStore.Patients["patient-2"] = &models.Patient{ID: "patient-2", Name: "Budi", Phone: "08123456780", Language: models.LanguageEnglish}
+
+	store := models.NewCampaignStore(func() {})
+	handler := NewCampaignHandler(store, patientStore, NewContentStore(), &config.Config{}, logger, func() string {
+		return "camp-1"
+	})
+	return handler, store, patientStore
+}
+
+func campaignRequest(handler gin.HandlerFunc, method, path string, params gin.Params, body interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	c.Request = httptest.NewRequest(method, path, reader)
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("userID", "admin-1")
+	c.Set("role", "admin")
+	handler(c)
+	return w
+}
+
+func TestCampaignHandler_CreateCampaign(t *testing.T) {
+	t.Run("creates scheduled campaign", func(t *testing.T) {
+		handler, store, _ := setupCampaignHandler(t)
+
+		w := campaignRequest(handler.CreateCampaign, "POST", "/api/campaigns", nil, CreateCampaignRequest{
+			Name:     "Pekan Imunisasi",
+			Audience: models.CampaignAudience{Languages: []string{models.LanguageEnglish}},
+			Reminder: models.CampaignReminder{Title: "Immunization week"},
+			StartAt:  "2030-01-01T08:00:00+07:00",
+		})
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		campaign := store.Campaigns["camp-1"]
+		if campaign == nil || campaign.Status != models.CampaignStatusScheduled {
+			t.Fatalf("Expected scheduled campaign, got %+v", campaign)
+		}
+		if campaign.StartAt != "2030-01-01T01:00:00Z" {
+			t.Errorf("Expected start time normalized to UTC, got %s", campaign.StartAt)
+		}
+		if campaign.Recipients != nil {
+			t.Error("Expected recipients to be resolved when the campaign starts")
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if response["audience_size"] != float64(1) {
+			t.Errorf("Expected audience size 1, got %v", response["audience_size"])
+		}
+	})
+
+	t.Run("rejects empty audience", func(t *testing.T) {
+		handler, store, _ := setupCampaignHandler(t)
+
+		w := campaignRequest(handler.CreateCampaign, "POST", "/api/campaigns", nil, CreateCampaignRequest{
+			Name:     "Semua?",
+			Reminder: models.CampaignReminder{Title: "Judul"},
+		})
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		if len(store.Campaigns) != 0 {
+			t.Error("Expected no campaign to be stored")
+		}
+	})
+
+	t.Run("rejects missing content", func(t *testing.T) {
+		handler, _, _ := setupCampaignHandler(t)
+
+		w := campaignRequest(handler.CreateCampaign, "POST", "/api/campaigns", nil, CreateCampaignRequest{
+			Name:     "Skrining TB",
+			Audience: models.CampaignAudience{All: true},
+			Reminder: models.CampaignReminder{
+				Title:       "Skrining TB",
+				Attachments: []models.Attachment{{Type: "article", ID: "missing", Title: "Artikel"}},
+			},
+		})
+
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if w.Code != http.StatusBadRequest || response["code"] != "INVALID_ATTACHMENT" {
+			t.Errorf("Expected INVALID_ATTACHMENT, got %d %v", w.Code, response["code"])
+		}
+	})
+}
+
+func TestCampaignHandler_Transitions(t *testing.T) {
+	handler, store, patientStore := setupCampaignHandler(t)
+	store.Campaigns["camp-1"] = &models.Campaign{
+		ID:        "camp-1",
+		Status:    models.CampaignStatusRunning,
+		StartedAt: "2025-01-01T00:00:00Z",
+		Recipients: []models.CampaignRecipient{
+			{PatientID: "patient-1", ReminderID: "rem-1", Status: models.RecipientStatusSent},
+			{PatientID: "patient-2", Status: models.RecipientStatusPending},
+		},
+	}
+	patientStore.Patients["patient-1"].Reminders = []*models.Reminder{{ID: "rem-1", DeliveryStatus: models.DeliveryStatusDelivered}}
+	params := gin.Params{{Key: "id", Value: "camp-1"}}
+
+	if w := campaignRequest(handler.ResumeCampaign, "POST", "/api/campaigns/camp-1/resume", params, nil); w.Code != http.StatusConflict {
+		t.Errorf("Expected resume of running campaign to conflict, got %d", w.Code)
+	}
+
+	if w := campaignRequest(handler.PauseCampaign, "POST", "/api/campaigns/camp-1/pause", params, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected pause to succeed, got %d", w.Code)
+	}
+	if store.Campaigns["camp-1"].Status != models.CampaignStatusPaused {
+		t.Errorf("Expected paused, got %s", store.Campaigns["camp-1"].Status)
+	}
+
+	if w := campaignRequest(handler.ResumeCampaign, "POST", "/api/campaigns/camp-1/resume", params, nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected resume to succeed, got %d", w.Code)
+	}
+	if store.Campaigns["camp-1"].Status != models.CampaignStatusRunning {
+		t.Errorf("Expected running after resume, got %s", store.Campaigns["camp-1"].Status)
+	}
+
+	w := campaignRequest(handler.CancelCampaign, "POST", "/api/campaigns/camp-1/cancel", params, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected cancel to succeed, got %d", w.Code)
+	}
+	campaign := store.Campaigns["camp-1"]
+	if campaign.Status != models.CampaignStatusCancelled || campaign.CancelledBy != "admin-1" {
+		t.Errorf("Expected cancelled by admin-1, got %s by %s", campaign.Status, campaign.CancelledBy)
+	}
+	if campaign.Recipients[0].Status != models.RecipientStatusSent || campaign.Recipients[1].Status != models.RecipientStatusCancelled {
+		t.Errorf("Expected only pending recipients cancelled, got %+v", campaign.Recipients)
+	}
+
+	var response struct {
+		Data CampaignSummary `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.Report.Delivered != 1 || response.Data.Report.Cancelled != 1 {
+		t.Errorf("Unexpected report: %+v", response.Data.Report)
+	}
+
+	if w := campaignRequest(handler.CancelCampaign, "POST", "/api/campaigns/camp-1/cancel", params, nil); w.Code != http.StatusConflict {
+		t.Errorf("Expected second cancel to conflict, got %d", w.Code)
+	}
+	if w := campaignRequest(handler.GetCampaign, "GET", "/api/campaigns/missing", gin.Params{{Key: "id", Value: "missing"}}, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown campaign, got %d", w.Code)
+	}
+}
@@ -0,0 +1,774 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// CarePlanHandler handles care plan definitions and patient enrollments
+type CarePlanHandler struct {
+	plans             *models.CarePlanStore
+	patientStore      *models.PatientStore
+	contentStore      *ContentStore
+	templateStore     *models.TemplateStore
+	reminderTemplates *models.ReminderTemplateStore
+	priorities        *config.PrioritiesConfig // Allowed step priorities, any if nil
+	logger            *slog.Logger
+	generateID        IDGenerator
+}
+
+// NewCarePlanHandler creates a new care plan handler
+func NewCarePlanHandler(plans *models.CarePlanStore, patientStore *models.PatientStore, contentStore *ContentStore, logger *slog.Logger, idGen IDGenerator) *CarePlanHandler {
+	return &CarePlanHandler{
+		plans:        plans,
+		patientStore: patientStore,
+		contentStore: contentStore,
+		logger:       logger,
+		generateID:   idGen,
+	}
+}
+
+// SetTemplateStore sets the template store used to validate step message templates
+func (h *CarePlanHandler) SetTemplateStore(templateStore *models.TemplateStore) {
+	h.templateStore = templateStore
+}
+
+// SetReminderTemplateStore sets the reminder template library steps can be based on
+func (h *CarePlanHandler) SetReminderTemplateStore(store *models.ReminderTemplateStore) {
+	h.reminderTemplates = store
+}
+
+// SetPriorities sets the configured priority levels steps are validated against
+func (h *CarePlanHandler) SetPriorities(priorities *config.PrioritiesConfig) {
+	h.priorities = priorities
+}
+
+// CarePlanRequest represents the request body for creating or updating a care plan
+type CarePlanRequest struct {
+	Name        string                `json:"name" binding:"required"`
+	Description string                `json:"description"`
+	Steps       []models.CarePlanStep `json:"steps"`
+}
+
+// EnrollRequest represents the request body for enrolling a patient in a care plan
+type EnrollRequest struct {
+	CarePlanID string `json:"care_plan_id" binding:"required"`
+	AnchorDate string `json:"anchor_date" binding:"required"` // YYYY-MM-DD
+}
+
+// RescheduleEnrollmentRequest represents the request body for changing an enrollment's anchor date
+type RescheduleEnrollmentRequest struct {
+	AnchorDate string `json:"anchor_date" binding:"required"` // YYYY-MM-DD
+}
+
+// EnrollmentProgress counts an enrollment's planned reminders by outcome
+type EnrollmentProgress struct {
+	Planned         int    `json:"planned"` // Reminders the plan schedules in total
+	Skipped         int    `json:"skipped"` // Planned reminders not generated because they were already due
+	Pending         int    `json:"pending"` // Generated and not yet sent
+	Sent            int    `json:"sent"`    // Sent, including delivered and read
+	Read            int    `json:"read"`
+	Failed          int    `json:"failed"`
+	Cancelled       int    `json:"cancelled"`
+	PercentComplete int    `json:"percent_complete"`
+	NextDueDate     string `json:"next_due_date,omitempty"`
+}
+
+// EnrollmentSummary is an enrollment with its progress
+type EnrollmentSummary struct {
+	*models.CarePlanEnrollment
+	Progress  EnrollmentProgress `json:"progress"`
+	Reminders []*models.Reminder `json:"reminders,omitempty"`
+}
+
+// carePlanStepKey identifies one planned occurrence of a step
+type carePlanStepKey struct {
+	stepID     string
+	occurrence int
+}
+
+// validateSteps checks care plan steps, filling in missing step IDs. It returns the
+// error code and error for the first invalid step.
+func (h *CarePlanHandler) validateSteps(steps []models.CarePlanStep) (string, error) {
+	if len(steps) == 0 || len(steps) > models.MaxCarePlanSteps {
+		return "INVALID_CARE_PLAN", fmt.Errorf("a care plan needs between 1 and %d steps", models.MaxCarePlanSteps)
+	}
+
+	seen := make(map[string]bool, len(steps))
+	occurrences := 0
+	for i := range steps {
+		step := &steps[i]
+		step.ID = strings.TrimSpace(step.ID)
+		if step.ID == "" {
+			step.ID = fmt.Sprintf("step-%d", i+1)
+		}
+		if seen[step.ID] {
+			return "INVALID_CARE_PLAN", fmt.Errorf("steps[%d]: duplicate step id '%s'", i, step.ID)
+		}
+		seen[step.ID] = true
+
+		if step.OffsetDays < 0 || step.OffsetDays > models.MaxCarePlanOffsetDays {
+			return "INVALID_CARE_PLAN", fmt.Errorf("steps[%d]: offset_days must be between 0 and %d", i, models.MaxCarePlanOffsetDays)
+		}
+		if step.Repeat != nil {
+			if step.Repeat.Count < 1 || step.Repeat.IntervalDays < 1 {
+				return "INVALID_CARE_PLAN", fmt.Errorf("steps[%d]: repeat needs a count and interval_days of at least 1", i)
+			}
+			if step.OffsetDays+(step.Repeat.Count-1)*step.Repeat.IntervalDays > models.MaxCarePlanOffsetDays {
+				return "INVALID_CARE_PLAN", fmt.Errorf("steps[%d]: repeats run past %d days", i, models.MaxCarePlanOffsetDays)
+			}
+		}
+		if _, err := step.DueDate(time.Now(), 0); err != nil {
+			return "INVALID_CARE_PLAN", fmt.Errorf("steps[%d]: %v", i, err)
+		}
+		occurrences += step.Occurrences()
+
+		if step.TemplateID != "" {
+			exists := false
+			if h.reminderTemplates != nil {
+				_, exists = h.reminderTemplates.Get(step.TemplateID)
+			}
+			if !exists {
+				return "REMINDER_TEMPLATE_NOT_FOUND", fmt.Errorf("steps[%d]: reminder template '%s' not found", i, step.TemplateID)
+			}
+		} else if strings.TrimSpace(step.Title) == "" {
+			return "INVALID_CARE_PLAN", fmt.Errorf("steps[%d]: title or template_id is required", i)
+		}
+
+		if err := validatePriority(h.priorities, step.Priority); err != nil {
+			return "INVALID_PRIORITY", fmt.Errorf("steps[%d]: %v", i, err)
+		}
+		if len(step.Attachments) > MaxAttachments {
+			return "MAX_ATTACHMENTS_EXCEEDED", fmt.Errorf("steps[%d]: Maksimal %d konten yang dapat dilampirkan", i, MaxAttachments)
+		}
+		if err := validateAttachmentList(h.contentStore, step.Attachments); err != nil {
+			return "INVALID_ATTACHMENT", fmt.Errorf("steps[%d]: %v", i, err)
+		}
+		if err := validateTemplateName(h.templateStore, step.MessageTemplate); err != nil {
+			return "INVALID_TEMPLATE", fmt.Errorf("steps[%d]: %v", i, err)
+		}
+		if err := validateEscalation(step.Escalation); err != nil {
+			return "INVALID_ESCALATION", fmt.Errorf("steps[%d]: %v", i, err)
+		}
+	}
+
+	if occurrences > models.MaxCarePlanOccurrences {
+		return "INVALID_CARE_PLAN", fmt.Errorf("a care plan may schedule at most %d reminders, got %d", models.MaxCarePlanOccurrences, occurrences)
+	}
+	return "", nil
+}
+
+// ListCarePlans handles GET /api/care-plans
+func (h *CarePlanHandler) ListCarePlans(c *gin.Context) {
+	h.plans.Mu.RLock()
+	plans := make([]models.CarePlan, 0, len(h.plans.Plans))
+	for _, plan := range h.plans.Plans {
+		plans = append(plans, *plan)
+	}
+	h.plans.Mu.RUnlock()
+
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].Name < plans[j].Name
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": plans, "message": "Success"})
+}
+
+// GetCarePlan handles GET /api/care-plans/:id
+func (h *CarePlanHandler) GetCarePlan(c *gin.Context) {
+	plan, exists := h.plans.Get(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "care plan not found", "code": "CARE_PLAN_NOT_FOUND"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": plan, "message": "Success"})
+}
+
+// CreateCarePlan handles POST /api/care-plans
+func (h *CarePlanHandler) CreateCarePlan(c *gin.Context) {
+	var req CarePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if code, err := h.validateSteps(req.Steps); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": code})
+		return
+	}
+
+	userID := c.GetString("userID")
+	now := getCurrentTimestamp()
+	plan := &models.CarePlan{
+		ID:          h.generateID(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Steps:       req.Steps,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	h.plans.Mu.Lock()
+	h.plans.Plans[plan.ID] = plan
+	h.plans.Mu.Unlock()
+	h.plans.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Care plan created",
+			"care_plan_id", plan.ID,
+			"steps", len(plan.Steps),
+			"user_id", userID,
+		)
+	}
+
+	created, _ := h.plans.Get(plan.ID)
+	c.JSON(http.StatusCreated, gin.H{"data": created, "message": "Care plan created"})
+}
+
+// UpdateCarePlan handles PUT /api/care-plans/:id
+// Existing enrollments keep the steps they were enrolled with.
+func (h *CarePlanHandler) UpdateCarePlan(c *gin.Context) {
+	var req CarePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if code, err := h.validateSteps(req.Steps); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": code})
+		return
+	}
+
+	id := c.Param("id")
+	h.plans.Mu.Lock()
+	plan, exists := h.plans.Plans[id]
+	if !exists {
+		h.plans.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "care plan not found", "code": "CARE_PLAN_NOT_FOUND"})
+		return
+	}
+	plan.Name = strings.TrimSpace(req.Name)
+	plan.Description = req.Description
+	plan.Steps = req.Steps
+	plan.UpdatedAt = getCurrentTimestamp()
+	h.plans.Mu.Unlock()
+	h.plans.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Care plan updated",
+			"care_plan_id", id,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	updated, _ := h.plans.Get(id)
+	c.JSON(http.StatusOK, gin.H{"data": updated, "message": "Care plan updated"})
+}
+
+// DeleteCarePlan handles DELETE /api/care-plans/:id
+// Existing enrollments and their reminders are kept.
+func (h *CarePlanHandler) DeleteCarePlan(c *gin.Context) {
+	id := c.Param("id")
+	h.plans.Mu.Lock()
+	if _, exists := h.plans.Plans[id]; !exists {
+		h.plans.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "care plan not found", "code": "CARE_PLAN_NOT_FOUND"})
+		return
+	}
+	delete(h.plans.Plans, id)
+	h.plans.Mu.Unlock()
+	h.plans.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Care plan deleted",
+			"care_plan_id", id,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Care plan deleted"})
+}
+
+// resolveSteps fills step fields left empty from their reminder templates
+func (h *CarePlanHandler) resolveSteps(steps []models.CarePlanStep) error {
+	for i := range steps {
+		step := &steps[i]
+		if step.TemplateID == "" {
+			continue
+		}
+		var tmpl models.ReminderTemplate
+		exists := false
+		if h.reminderTemplates != nil {
+			tmpl, exists = h.reminderTemplates.Get(step.TemplateID)
+		}
+		if !exists {
+			return fmt.Errorf("reminder template '%s' not found", step.TemplateID)
+		}
+		if step.Title == "" {
+			step.Title = tmpl.Title
+		}
+		if step.Description == "" {
+			step.Description = tmpl.Description
+		}
+		if step.Priority == "" {
+			step.Priority = tmpl.Priority
+		}
+		if step.Attachments == nil {
+			step.Attachments = tmpl.Attachments
+		}
+		if step.MessageTemplate == "" {
+			step.MessageTemplate = tmpl.MessageTemplate
+		}
+		if step.Escalation == nil {
+			step.Escalation = tmpl.Escalation
+		}
+	}
+	return nil
+}
+
+// parseAnchorDate parses an enrollment anchor date in local time
+func parseAnchorDate(value string) (time.Time, error) {
+	anchor, err := time.ParseInLocation(models.CarePlanAnchorLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New("anchor_date must be a date in YYYY-MM-DD format")
+	}
+	return anchor, nil
+}
+
+// isUpcoming reports whether a generated due date is still in the future
+func isUpcoming(dueDate string, now time.Time) bool {
+	due, err := time.ParseInLocation(models.CarePlanDueDateLayout, dueDate, time.Local)
+	return err == nil && due.After(now)
+}
+
+// newCarePlanReminder builds the reminder for one occurrence of an enrollment step
+func (h *CarePlanHandler) newCarePlanReminder(enrollmentID string, step models.CarePlanStep, occurrence int, dueDate string) *models.Reminder {
+	reminder := &models.Reminder{
+		ID:              h.generateID(),
+		Title:           step.Title,
+		Description:     step.Description,
+		DueDate:         dueDate,
+		Priority:        step.Priority,
+		Attachments:     append([]models.Attachment(nil), step.Attachments...),
+		DeliveryStatus:  models.DeliveryStatusPending,
+		MessageTemplate: step.MessageTemplate,
+		TemplateID:      step.TemplateID,
+
+		EnrollmentID:       enrollmentID,
+		CarePlanStepID:     step.ID,
+		CarePlanOccurrence: occurrence,
+	}
+	if step.Escalation != nil {
+		escalation := *step.Escalation
+		reminder.Escalation = &escalation
+	}
+	return reminder
+}
+
+// enrollmentProgress counts the enrollment's planned and generated reminders
+func enrollmentProgress(patient *models.Patient, enrollment *models.CarePlanEnrollment) EnrollmentProgress {
+	var progress EnrollmentProgress
+	for _, step := range enrollment.Steps {
+		progress.Planned += step.Occurrences()
+	}
+
+	generated := 0
+	for _, r := range patient.Reminders {
+		if r.EnrollmentID != enrollment.ID {
+			continue
+		}
+		generated++
+		switch r.DeliveryStatus {
+		case models.DeliveryStatusSent, models.DeliveryStatusDelivered:
+			progress.Sent++
+		case models.DeliveryStatusRead:
+			progress.Sent++
+			progress.Read++
+		case models.DeliveryStatusFailed:
+			progress.Failed++
+		case models.DeliveryStatusCancelled:
+			progress.Cancelled++
+		default:
+			if r.Completed || r.Notified {
+				progress.Sent++
+				continue
+			}
+			progress.Pending++
+			if progress.NextDueDate == "" || r.DueDate < progress.NextDueDate {
+				progress.NextDueDate = r.DueDate
+			}
+		}
+	}
+
+	progress.Skipped = progress.Planned - generated
+	if progress.Skipped < 0 {
+		progress.Skipped = 0
+	}
+	if progress.Planned > 0 {
+		progress.PercentComplete = (progress.Planned - progress.Pending) * 100 / progress.Planned
+	}
+	return progress
+}
+
+// refreshEnrollment marks an active enrollment completed once nothing is pending.
+// The caller must hold the patient store write lock. It reports whether anything changed.
+func refreshEnrollment(enrollment *models.CarePlanEnrollment, progress EnrollmentProgress) bool {
+	if enrollment.Status != models.EnrollmentStatusActive || progress.Pending > 0 {
+		return false
+	}
+	now := getCurrentTimestamp()
+	enrollment.Status = models.EnrollmentStatusCompleted
+	enrollment.CompletedAt = now
+	enrollment.UpdatedAt = now
+	return true
+}
+
+// snapshotEnrollment copies an enrollment so it can be used after the store lock is released
+func snapshotEnrollment(enrollment *models.CarePlanEnrollment) *models.CarePlanEnrollment {
+	snapshot := *enrollment
+	snapshot.Steps = models.CopyCarePlanSteps(enrollment.Steps)
+	return &snapshot
+}
+
+// findEnrollment returns a patient's enrollment by ID
+func findEnrollment(patient *models.Patient, enrollmentID string) *models.CarePlanEnrollment {
+	for _, enrollment := range patient.CarePlans {
+		if enrollment.ID == enrollmentID {
+			return enrollment
+		}
+	}
+	return nil
+}
+
+// lockManagedPatient takes the patient store write lock and returns the patient if it
+// exists and the user may manage it. On failure it writes the response and releases the lock.
+func lockManagedPatient(c *gin.Context, store *models.PatientStore) (*models.Patient, bool) {
+	store.Lock()
+	patient, exists := store.GetPatient(c.Param("id"))
+	if !exists {
+		store.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "patient not found"})
+		return nil, false
+	}
+	if c.GetString("role") == RoleVolunteer && patient.CreatedBy != c.GetString("userID") {
+		store.Unlock()
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		return nil, false
+	}
+	return patient, true
+}
+
+// Enroll handles POST /api/patients/:id/care-plans
+// It generates a reminder for every planned occurrence that is still in the future.
+func (h *CarePlanHandler) Enroll(c *gin.Context) {
+	var req EnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	anchor, err := parseAnchorDate(req.AnchorDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_ANCHOR_DATE"})
+		return
+	}
+
+	plan, exists := h.plans.Get(req.CarePlanID)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "care plan not found", "code": "CARE_PLAN_NOT_FOUND"})
+		return
+	}
+	if err := h.resolveSteps(plan.Steps); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "REMINDER_TEMPLATE_NOT_FOUND"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	now := time.Now()
+	timestamp := getCurrentTimestamp()
+	enrollment := &models.CarePlanEnrollment{
+		ID:           h.generateID(),
+		CarePlanID:   plan.ID,
+		CarePlanName: plan.Name,
+		AnchorDate:   anchor.Format(models.CarePlanAnchorLayout),
+		Status:       models.EnrollmentStatusActive,
+		Steps:        plan.Steps,
+		EnrolledBy:   userID,
+		EnrolledAt:   timestamp,
+		UpdatedAt:    timestamp,
+	}
+
+	var reminders []*models.Reminder
+	for _, step := range enrollment.Steps {
+		for occurrence := 0; occurrence < step.Occurrences(); occurrence++ {
+			dueDate, _ := step.DueDate(anchor, occurrence)
+			if !isUpcoming(dueDate, now) {
+				continue
+			}
+			reminders = append(reminders, h.newCarePlanReminder(enrollment.ID, step, occurrence, dueDate))
+		}
+	}
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	patient.CarePlans = append(patient.CarePlans, enrollment)
+	patient.Reminders = append(patient.Reminders, reminders...)
+	patient.UpdatedAt = timestamp
+	progress := enrollmentProgress(patient, enrollment)
+	refreshEnrollment(enrollment, progress)
+	summary := EnrollmentSummary{CarePlanEnrollment: snapshotEnrollment(enrollment), Progress: progress}
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.reminderTemplates != nil {
+		for _, step := range enrollment.Steps {
+			if step.TemplateID != "" {
+				h.reminderTemplates.IncrementUsage(step.TemplateID)
+			}
+		}
+	}
+
+	if h.logger != nil {
+		h.logger.Info("Patient enrolled in care plan",
+			"enrollment_id", enrollment.ID,
+			"care_plan_id", plan.ID,
+			"patient_id", patient.ID,
+			"reminders", len(reminders),
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": summary, "message": "Patient enrolled"})
+}
+
+// ListEnrollments handles GET /api/patients/:id/care-plans
+func (h *CarePlanHandler) ListEnrollments(c *gin.Context) {
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	changed := false
+	summaries := make([]EnrollmentSummary, 0, len(patient.CarePlans))
+	for _, enrollment := range patient.CarePlans {
+		progress := enrollmentProgress(patient, enrollment)
+		if refreshEnrollment(enrollment, progress) {
+			changed = true
+		}
+		summaries = append(summaries, EnrollmentSummary{CarePlanEnrollment: snapshotEnrollment(enrollment), Progress: progress})
+	}
+	h.patientStore.Unlock()
+	if changed {
+		h.patientStore.SaveData()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summaries, "message": "Success"})
+}
+
+// GetEnrollment handles GET /api/patients/:id/care-plans/:enrollmentId
+// The response includes the reminders generated for the enrollment in due date order.
+func (h *CarePlanHandler) GetEnrollment(c *gin.Context) {
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	enrollment := findEnrollment(patient, c.Param("enrollmentId"))
+	if enrollment == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "enrollment not found", "code": "ENROLLMENT_NOT_FOUND"})
+		return
+	}
+	progress := enrollmentProgress(patient, enrollment)
+	changed := refreshEnrollment(enrollment, progress)
+	summary := EnrollmentSummary{CarePlanEnrollment: snapshotEnrollment(enrollment), Progress: progress}
+	for _, r := range patient.Reminders {
+		if r.EnrollmentID == enrollment.ID {
+			reminder := *r
+			summary.Reminders = append(summary.Reminders, &reminder)
+		}
+	}
+	h.patientStore.Unlock()
+	if changed {
+		h.patientStore.SaveData()
+	}
+
+	sort.SliceStable(summary.Reminders, func(i, j int) bool {
+		return summary.Reminders[i].DueDate < summary.Reminders[j].DueDate
+	})
+
+	c.JSON(http.StatusOK, gin.H{"data": summary, "message": "Success"})
+}
+
+// RescheduleEnrollment handles PUT /api/patients/:id/care-plans/:enrollmentId
+// It moves the anchor date and recomputes every step that has not been sent yet:
+// pending reminders get their new due date, or are removed if it is already past,
+// and planned occurrences that were skipped are generated once they are upcoming.
+// Sent, failed and cancelled reminders are left untouched.
+func (h *CarePlanHandler) RescheduleEnrollment(c *gin.Context) {
+	var req RescheduleEnrollmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	anchor, err := parseAnchorDate(req.AnchorDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_ANCHOR_DATE"})
+		return
+	}
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	enrollment := findEnrollment(patient, c.Param("enrollmentId"))
+	if enrollment == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "enrollment not found", "code": "ENROLLMENT_NOT_FOUND"})
+		return
+	}
+	if enrollment.Status == models.EnrollmentStatusCancelled {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": "enrollment is cancelled", "code": "INVALID_ENROLLMENT_STATE"})
+		return
+	}
+
+	now := time.Now()
+	existing := make(map[carePlanStepKey]bool)
+	rescheduled, removed, created := 0, 0, 0
+	kept := patient.Reminders[:0]
+	for _, r := range patient.Reminders {
+		if r.EnrollmentID != enrollment.ID {
+			kept = append(kept, r)
+			continue
+		}
+		existing[carePlanStepKey{r.CarePlanStepID, r.CarePlanOccurrence}] = true
+		step, found := enrollment.Step(r.CarePlanStepID)
+		if !found || !r.IsUnsent() {
+			kept = append(kept, r)
+			continue
+		}
+		dueDate, _ := step.DueDate(anchor, r.CarePlanOccurrence)
+		if !isUpcoming(dueDate, now) {
+			removed++
+			continue
+		}
+		if dueDate != r.DueDate || r.DeliveryStatus == models.DeliveryStatusScheduled {
+			r.DueDate = dueDate
+			if r.DeliveryStatus == models.DeliveryStatusScheduled {
+				transitionReminder(h.logger, r, models.DeliveryStatusPending, c.GetString("userID"), "care plan rescheduled")
+			}
+			r.ScheduledDeliveryAt = ""
+			rescheduled++
+		}
+		kept = append(kept, r)
+	}
+	patient.Reminders = kept
+
+	for _, step := range enrollment.Steps {
+		for occurrence := 0; occurrence < step.Occurrences(); occurrence++ {
+			if existing[carePlanStepKey{step.ID, occurrence}] {
+				continue
+			}
+			dueDate, _ := step.DueDate(anchor, occurrence)
+			if !isUpcoming(dueDate, now) {
+				continue
+			}
+			patient.Reminders = append(patient.Reminders, h.newCarePlanReminder(enrollment.ID, step, occurrence, dueDate))
+			created++
+		}
+	}
+
+	timestamp := getCurrentTimestamp()
+	enrollment.AnchorDate = anchor.Format(models.CarePlanAnchorLayout)
+	enrollment.UpdatedAt = timestamp
+	if created > 0 || rescheduled > 0 {
+		enrollment.Status = models.EnrollmentStatusActive
+		enrollment.CompletedAt = ""
+	}
+	patient.UpdatedAt = timestamp
+	progress := enrollmentProgress(patient, enrollment)
+	refreshEnrollment(enrollment, progress)
+	summary := EnrollmentSummary{CarePlanEnrollment: snapshotEnrollment(enrollment), Progress: progress}
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Care plan enrollment rescheduled",
+			"enrollment_id", enrollment.ID,
+			"patient_id", patient.ID,
+			"anchor_date", enrollment.AnchorDate,
+			"rescheduled", rescheduled,
+			"created", created,
+			"removed", removed,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":        summary,
+		"rescheduled": rescheduled,
+		"created":     created,
+		"removed":     removed,
+		"message":     "Enrollment rescheduled",
+	})
+}
+
+// CancelEnrollment handles DELETE /api/patients/:id/care-plans/:enrollmentId
+// Pending reminders of the enrollment are cancelled; sent ones are kept for history.
+func (h *CarePlanHandler) CancelEnrollment(c *gin.Context) {
+	userID := c.GetString("userID")
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	enrollment := findEnrollment(patient, c.Param("enrollmentId"))
+	if enrollment == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "enrollment not found", "code": "ENROLLMENT_NOT_FOUND"})
+		return
+	}
+	if enrollment.Status == models.EnrollmentStatusCancelled {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": "enrollment is already cancelled", "code": "INVALID_ENROLLMENT_STATE"})
+		return
+	}
+
+	timestamp := getCurrentTimestamp()
+	cancelled := 0
+	for _, r := range patient.Reminders {
+		if r.EnrollmentID != enrollment.ID || !r.IsUnsent() {
+			continue
+		}
+		transitionReminder(h.logger, r, models.DeliveryStatusCancelled, userID, "care plan enrollment cancelled")
+		r.CancelledAt = timestamp
+		r.CancelledBy = userID
+		cancelled++
+	}
+	enrollment.Status = models.EnrollmentStatusCancelled
+	enrollment.CancelledAt = timestamp
+	enrollment.CancelledBy = userID
+	enrollment.UpdatedAt = timestamp
+	patient.UpdatedAt = timestamp
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Care plan enrollment cancelled",
+			"enrollment_id", enrollment.ID,
+			"patient_id", patient.ID,
+			"cancelled_reminders", cancelled,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cancelled": cancelled, "message": "Enrollment cancelled"})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func setupCarePlanHandler(t *testing.T) (*CarePlanHandler, *models.PatientStore, *models.ReminderTemplateStore) {
+	t.Helper()
+	patients := models.NewPatientStore(func() {})
+	patients.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", CreatedBy: "volunteer-1"}
+
+	templates := models.NewReminderTemplateStore(func() {})
+	templates.Templates["rt-1"] = &models.ReminderTemplate{
+		ID:         "rt-1",
+		Name:       "Kontrol TB",
+		Category:   "TB",
+		Title:      "Kontrol ke puskesmas",
+		Priority:   "high",
+		Escalation: &models.EscalationPolicy{AfterHours: 12},
+	}
+
+	next := 0
+	handler := NewCarePlanHandler(models.NewCarePlanStore(func() {}), patients, NewContentStore(), nil, func() string {
+		next++
+		return fmt.Sprintf("id-%d", next)
+	})
+	handler.SetReminderTemplateStore(templates)
+	return handler, patients, templates
+}
+
+// roleRequest calls handler with a JSON body as user "<role>-1"
+func roleRequest(handler gin.HandlerFunc, method string, params gin.Params, role string, body interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	data, _ := json.Marshal(body)
+	c.Request = httptest.NewRequest(method, "/", bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("userID", role+"-1")
+	c.Set("role", role)
+	handler(c)
+	return w
+}
+
+func TestCarePlanHandler_CreateCarePlan_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []models.CarePlanStep
+		code  string
+	}{
+		{"no steps", nil, "INVALID_CARE_PLAN"},
+		{"missing title", []models.CarePlanStep{{OffsetDays: 1}}, "INVALID_CARE_PLAN"},
+		{"negative offset", []models.CarePlanStep{{Title: "A", OffsetDays: -1}}, "INVALID_CARE_PLAN"},
+		{"bad time", []models.CarePlanStep{{Title: "A", Time: "25:00"}}, "INVALID_CARE_PLAN"},
+		{"zero interval", []models.CarePlanStep{{Title: "A", Repeat: &models.CarePlanRepeat{Count: 3}}}, "INVALID_CARE_PLAN"},
+		{"too many reminders", []models.CarePlanStep{{Title: "A", Repeat: &models.CarePlanRepeat{Count: 500, IntervalDays: 1}}}, "INVALID_CARE_PLAN"},
+		{"duplicate step id", []models.CarePlanStep{{ID: "a", Title: "A"}, {ID: "a", Title: "B"}}, "INVALID_CARE_PLAN"},
+		{"unknown template", []models.CarePlanStep{{TemplateID: "missing"}}, "REMINDER_TEMPLATE_NOT_FOUND"},
+		{"bad escalation", []models.CarePlanStep{{Title: "A", Escalation: &models.EscalationPolicy{}}}, "INVALID_ESCALATION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, _ := setupCarePlanHandler(t)
+			w := roleRequest(handler.CreateCarePlan, "POST", nil, "admin", CarePlanRequest{Name: "Plan", Steps: tt.steps})
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			var resp map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp["code"] != tt.code {
+				t.Errorf("Expected code %s, got %v", tt.code, resp["code"])
+			}
+		})
+	}
+
+	t.Run("assigns step ids", func(t *testing.T) {
+		handler, _, _ := setupCarePlanHandler(t)
+		w := roleRequest(handler.CreateCarePlan, "POST", nil, "admin", CarePlanRequest{
+			Name:  "Plan",
+			Steps: []models.CarePlanStep{{Title: "A"}, {TemplateID: "rt-1", OffsetDays: 30}},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		plan := handler.plans.Plans["id-1"]
+		if plan == nil || plan.Steps[0].ID != "step-1" || plan.Steps[1].ID != "step-2" {
+			t.Errorf("Expected generated step ids, got %+v", plan)
+		}
+	})
+}
+
+func TestCarePlanHandler_EnrollmentLifecycle(t *testing.T) {
+	handler, patients, templates := setupCarePlanHandler(t)
+	handler.plans.Plans["plan-1"] = &models.CarePlan{
+		ID:   "plan-1",
+		Name: "TB 6 bulan",
+		Steps: []models.CarePlanStep{
+			{ID: "start", Title: "Mulai pengobatan", OffsetDays: 0, Time: "00:00"},
+			{ID: "control", TemplateID: "rt-1", OffsetDays: 1, Repeat: &models.CarePlanRepeat{Count: 3, IntervalDays: 7}},
+		},
+	}
+	patientParams := gin.Params{{Key: "id", Value: "patient-1"}}
+	today := time.Now()
+	anchor := func(days int) string {
+		return today.AddDate(0, 0, days).Format(models.CarePlanAnchorLayout)
+	}
+
+	// Enroll today: the midnight start step is already due and is skipped
+	w := roleRequest(handler.Enroll, "POST", patientParams, "volunteer", EnrollRequest{CarePlanID: "plan-1", AnchorDate: anchor(0)})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var enrolled struct {
+		Data EnrollmentSummary `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &enrolled)
+	progress := enrolled.Data.Progress
+	if progress.Planned != 4 || progress.Skipped != 1 || progress.Pending != 3 {
+		t.Errorf("Unexpected progress after enrollment: %+v", progress)
+	}
+	enrollmentID := enrolled.Data.ID
+
+	patient := patients.Patients["patient-1"]
+	if len(patient.Reminders) != 3 || len(patient.CarePlans) != 1 {
+		t.Fatalf("Expected 3 reminders and 1 enrollment, got %d and %d", len(patient.Reminders), len(patient.CarePlans))
+	}
+	first := patient.Reminders[0]
+	wantDue := today.AddDate(0, 0, 1).Format("2006-01-02") + "T09:00"
+	if first.Title != "Kontrol ke puskesmas" || first.Priority != "high" || first.DueDate != wantDue {
+		t.Errorf("Expected template fields and due %s, got %+v", wantDue, first)
+	}
+	if first.Escalation == nil || first.EnrollmentID != enrollmentID || first.CarePlanStepID != "control" {
+		t.Errorf("Expected escalation and care plan links, got %+v", first)
+	}
+	if patient.Reminders[2].DueDate != today.AddDate(0, 0, 15).Format("2006-01-02")+"T09:00" {
+		t.Errorf("Expected third occurrence 15 days after anchor, got %s", patient.Reminders[2].DueDate)
+	}
+	if templates.Templates["rt-1"].UsageCount != 1 {
+		t.Errorf("Expected template usage 1, got %d", templates.Templates["rt-1"].UsageCount)
+	}
+
+	// First control reminder has been sent
+	first.DeliveryStatus = models.DeliveryStatusSent
+	sentDue := first.DueDate
+
+	enrollmentParams := gin.Params{{Key: "id", Value: "patient-1"}, {Key: "enrollmentId", Value: enrollmentID}}
+	w = roleRequest(handler.RescheduleEnrollment, "PUT", enrollmentParams, "volunteer", RescheduleEnrollmentRequest{AnchorDate: anchor(2)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var moved map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &moved)
+	if moved["rescheduled"] != float64(2) || moved["created"] != float64(1) || moved["removed"] != float64(0) {
+		t.Errorf("Unexpected reschedule counts: %v", moved)
+	}
+	if first.DueDate != sentDue {
+		t.Error("Sent reminder must not be rescheduled")
+	}
+	if len(patient.Reminders) != 4 || patient.CarePlans[0].AnchorDate != anchor(2) {
+		t.Errorf("Expected start step generated and anchor moved, got %d reminders", len(patient.Reminders))
+	}
+
+	// Moving the anchor far back removes pending reminders that are now past
+	w = roleRequest(handler.RescheduleEnrollment, "PUT", enrollmentParams, "volunteer", RescheduleEnrollmentRequest{AnchorDate: anchor(-100)})
+	json.Unmarshal(w.Body.Bytes(), &moved)
+	if moved["removed"] != float64(3) || len(patient.Reminders) != 1 {
+		t.Errorf("Expected 3 pending reminders removed, got %v with %d left", moved["removed"], len(patient.Reminders))
+	}
+	if patient.CarePlans[0].Status != models.EnrollmentStatusCompleted {
+		t.Errorf("Expected enrollment completed once nothing is pending, got %s", patient.CarePlans[0].Status)
+	}
+
+	// Moving it forward again reactivates the enrollment
+	roleRequest(handler.RescheduleEnrollment, "PUT", enrollmentParams, "volunteer", RescheduleEnrollmentRequest{AnchorDate: anchor(1)})
+	if patient.CarePlans[0].Status != models.EnrollmentStatusActive || len(patient.Reminders) != 4 {
+		t.Errorf("Expected active enrollment with 4 reminders, got %s with %d", patient.CarePlans[0].Status, len(patient.Reminders))
+	}
+
+	// Cancelling cancels pending reminders only
+	w = roleRequest(handler.CancelEnrollment, "DELETE", enrollmentParams, "volunteer", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	for _, r := range patient.Reminders {
+		if r.ID == first.ID {
+			if r.DeliveryStatus != models.DeliveryStatusSent {
+				t.Errorf("Sent reminder changed to %s", r.DeliveryStatus)
+			}
+		} else if r.DeliveryStatus != models.DeliveryStatusCancelled {
+			t.Errorf("Expected pending reminder cancelled, got %s", r.DeliveryStatus)
+		}
+	}
+
+	w = roleRequest(handler.GetEnrollment, "GET", enrollmentParams, "volunteer", nil)
+	var got struct {
+		Data EnrollmentSummary `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Data.Status != models.EnrollmentStatusCancelled || got.Data.Progress.Cancelled != 3 || got.Data.Progress.Sent != 1 || len(got.Data.Reminders) != 4 {
+		t.Errorf("Unexpected enrollment after cancel: %+v", got.Data)
+	}
+
+	w = roleRequest(handler.RescheduleEnrollment, "PUT", enrollmentParams, "volunteer", RescheduleEnrollmentRequest{AnchorDate: anchor(3)})
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected cancelled enrollment to reject reschedule, got %d", w.Code)
+	}
+}
+
+func TestCarePlanHandler_Enroll_Errors(t *testing.T) {
+	handler, _, _ := setupCarePlanHandler(t)
+	handler.plans.Plans["plan-1"] = &models.CarePlan{ID: "plan-1", Name: "Plan", Steps: []models.CarePlanStep{{ID: "a", Title: "A", OffsetDays: 1}}}
+	params := gin.Params{{Key: "id", Value: "patient-1"}}
+
+	tests := []struct {
+		name   string
+		role   string
+		req    EnrollRequest
+		status int
+	}{
+		{"invalid anchor", "admin", EnrollRequest{CarePlanID: "plan-1", AnchorDate: "01/02/2026"}, http.StatusBadRequest},
+		{"unknown plan", "admin", EnrollRequest{CarePlanID: "missing", AnchorDate: "2026-01-02"}, http.StatusNotFound},
+		{"own patient", "volunteer", EnrollRequest{CarePlanID: "plan-1", AnchorDate: "2026-01-02"}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := roleRequest(handler.Enroll, "POST", params, tt.role, tt.req); w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewReader([]byte(`{"care_plan_id":"plan-1","anchor_date":"2026-01-02"}`)))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("userID", "volunteer-2")
+	c.Set("role", RoleVolunteer)
+	handler.Enroll(c)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected another volunteer's patient to be forbidden, got %d", w.Code)
+	}
+}
@@ -37,6 +37,13 @@ type ContentStore struct {
 	Videos      *models.VideoStore
 	userStore   map[string]*UserInfo // For author name resolution (key: userID)
 	userStoreMu sync.RWMutex
+
+	shortLinks *models.ShortLinkStore // Tracked content links for engagement analytics
+}
+
+// SetShortLinkStore sets the short link store used for click analytics
+func (cs *ContentStore) SetShortLinkStore(store *models.ShortLinkStore) {
+	cs.shortLinks = store
 }
 
 // SetUserStore sets the user store for author name resolution
@@ -88,7 +95,7 @@ const (
 	categoriesDataFile = "data/categories.json"
 	articlesDataFile   = "data/articles.json"
 	videosDataFile     = "data/videos.json"
-	uploadsDir         = "uploads"
+	uploadsDir         = utils.UploadsDir
 )
 
 // LoadContentData loads all content data from JSON files
@@ -1145,6 +1152,52 @@ type ContentAnalyticsItem struct {
 	Title           string `json:"title"`
 	AttachmentCount int    `json:"attachmentCount"`
 	Type            string `json:"type"` // "article" or "video"
+
+	LinksSent        int     `json:"linksSent"`        // Tracked links sent to patients
+	Clicks           int     `json:"clicks"`           // Total clicks across all tracked links
+	ClickedLinks     int     `json:"clickedLinks"`     // Tracked links opened at least once
+	ClickThroughRate float64 `json:"clickThroughRate"` // ClickedLinks / LinksSent as a percentage
+}
+
+// linkEngagement aggregates tracked link statistics per content ID
+func (cs *ContentStore) linkEngagement() map[string]*ContentAnalyticsItem {
+	stats := make(map[string]*ContentAnalyticsItem)
+	if cs.shortLinks == nil {
+		return stats
+	}
+
+	cs.shortLinks.Mu.RLock()
+	defer cs.shortLinks.Mu.RUnlock()
+	for _, link := range cs.shortLinks.Links {
+		if link.ContentID == "" {
+			continue
+		}
+		item, ok := stats[link.ContentID]
+		if !ok {
+			item = &ContentAnalyticsItem{}
+			stats[link.ContentID] = item
+		}
+		item.LinksSent++
+		item.Clicks += link.ClickCount
+		if link.ClickCount > 0 {
+			item.ClickedLinks++
+		}
+	}
+	return stats
+}
+
+// applyEngagement copies tracked link statistics onto an analytics item
+func applyEngagement(item *ContentAnalyticsItem, stats map[string]*ContentAnalyticsItem) {
+	engagement, ok := stats[item.ID]
+	if !ok {
+		return
+	}
+	item.LinksSent = engagement.LinksSent
+	item.Clicks = engagement.Clicks
+	item.ClickedLinks = engagement.ClickedLinks
+	if engagement.LinksSent > 0 {
+		item.ClickThroughRate = float64(engagement.ClickedLinks) / float64(engagement.LinksSent) * 100
+	}
 }
 
 // GetContentAnalytics returns content with attachment statistics for admin analytics
@@ -1159,6 +1212,7 @@ func (cs *ContentStore) GetContentAnalytics(c *gin.Context) {
 	articles := make([]*ContentAnalyticsItem, 0)
 	videos := make([]*ContentAnalyticsItem, 0)
 	topContent := make([]*ContentAnalyticsItem, 0)
+	engagement := cs.linkEngagement()
 
 	// Collect articles with counts
 	cs.Articles.Mu.RLock()
@@ -1171,6 +1225,9 @@ func (cs *ContentStore) GetContentAnalytics(c *gin.Context) {
 		})
 	}
 	cs.Articles.Mu.RUnlock()
+	for _, item := range articles {
+		applyEngagement(item, engagement)
+	}
 
 	// Collect videos with counts
 	cs.Videos.Mu.RLock()
@@ -1183,6 +1240,9 @@ func (cs *ContentStore) GetContentAnalytics(c *gin.Context) {
 		})
 	}
 	cs.Videos.Mu.RUnlock()
+	for _, item := range videos {
+		applyEngagement(item, engagement)
+	}
 
 	// Combine and sort for top content
 	allContent := append(articles, videos...)
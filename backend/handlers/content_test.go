@@ -756,3 +756,52 @@ func TestGetContentAnalytics_EmptyStore(t *testing.T) {
 		t.Errorf("Expected 0 top content, got %d", len(topContent))
 	}
 }
+
+func TestGetContentAnalytics_LinkEngagement(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cs := NewContentStore()
+	cs.Articles.Mu.Lock()
+	cs.Articles.Articles["art-1"] = &models.Article{ID: "art-1", Title: "Article 1", AttachmentCount: 3}
+	cs.Articles.Mu.Unlock()
+	cs.Videos.Mu.Lock()
+	cs.Videos.Videos["vid-1"] = &models.Video{ID: "vid-1", Title: "Video 1", AttachmentCount: 1}
+	cs.Videos.Mu.Unlock()
+
+	links := models.NewShortLinkStore(nil)
+	links.Links["t1"] = &models.ShortLink{Token: "t1", ContentID: "art-1", ClickCount: 2}
+	links.Links["t2"] = &models.ShortLink{Token: "t2", ContentID: "art-1", ClickCount: 0}
+	links.Links["t3"] = &models.ShortLink{Token: "t3", ContentID: "art-1", ClickCount: 1}
+	links.Links["t4"] = &models.ShortLink{Token: "t4", ContentID: "art-1", ClickCount: 0}
+	cs.SetShortLinkStore(links)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/analytics/content", nil)
+	c.Set("role", "admin")
+
+	cs.GetContentAnalytics(c)
+
+	var response struct {
+		Data struct {
+			Articles []ContentAnalyticsItem `json:"articles"`
+			Videos   []ContentAnalyticsItem `json:"videos"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	article := response.Data.Articles[0]
+	if article.LinksSent != 4 || article.Clicks != 3 || article.ClickedLinks != 2 {
+		t.Errorf("unexpected article engagement: %+v", article)
+	}
+	if article.ClickThroughRate != 50 {
+		t.Errorf("Expected click-through rate 50, got %v", article.ClickThroughRate)
+	}
+
+	video := response.Data.Videos[0]
+	if video.LinksSent != 0 || video.Clicks != 0 || video.ClickThroughRate != 0 {
+		t.Errorf("Expected no engagement for video, got %+v", video)
+	}
+}
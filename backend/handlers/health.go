@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/davidyusaku-13/prima_v2/events"
 	"github.com/davidyusaku-13/prima_v2/models"
 	"github.com/davidyusaku-13/prima_v2/services"
 )
@@ -17,11 +18,23 @@ type HealthHandler struct {
 	gowaClient     *services.GOWAClient
 	lastGOWAPing   time.Time
 	gowaConnected  bool
+	lastProbe      services.GOWAProbeResult
+	lastGOWAOK     time.Time
+	circuitHistory []services.CircuitStateChange
+	webhookRejects map[string]int // Rejected GOWA webhooks by reason, since startup
+	lastRejectedAt time.Time
+	lastDevice     string // Last GOWA device event
+	lastDeviceAt   time.Time
+	sseHandler     *SSEHandler
+	eventBus       *events.Bus
 	mu             struct {
 		sync.RWMutex
 	}
 }
 
+// maxCircuitHistory bounds the number of circuit breaker transitions kept for the health endpoint
+const maxCircuitHistory = 50
+
 // NewHealthHandler creates a new health handler
 func NewHealthHandler(patientStore *models.PatientStore, gowaClient *services.GOWAClient) *HealthHandler {
 	h := &HealthHandler{
@@ -33,6 +46,16 @@ func NewHealthHandler(patientStore *models.PatientStore, gowaClient *services.GO
 	return h
 }
 
+// SetSSEHandler sets the SSE handler whose connection stats are reported
+func (h *HealthHandler) SetSSEHandler(sseHandler *SSEHandler) {
+	h.sseHandler = sseHandler
+}
+
+// SetEventBus sets the event bus whose subscriber stats are reported
+func (h *HealthHandler) SetEventBus(bus *events.Bus) {
+	h.eventBus = bus
+}
+
 // HealthStatus represents the basic health status response
 type HealthStatus struct {
 	Status     string `json:"status"`
@@ -46,20 +69,41 @@ type DetailedHealthStatus struct {
 	GOWA       GOWAHealthStatus        `json:"gowa"`
 	CircuitBreaker CircuitBreakerStatus `json:"circuit_breaker"`
 	Queue      QueueStatus             `json:"queue"`
+	Webhooks   WebhookHealthStatus     `json:"webhooks"`
+	SSE        *SSEStats               `json:"sse,omitempty"`
+	Events     []events.SubscriberStats `json:"events,omitempty"`
+}
+
+// WebhookHealthStatus represents rejected GOWA webhooks since startup
+type WebhookHealthStatus struct {
+	Rejected         int            `json:"rejected"`
+	RejectedByReason map[string]int `json:"rejected_by_reason"`
+	LastRejectedAt   string         `json:"last_rejected_at,omitempty"`
 }
 
 // GOWAHealthStatus represents GOWA connectivity status
 type GOWAHealthStatus struct {
-	Connected  bool   `json:"connected"`
-	LastPing   string `json:"last_ping,omitempty"`
-	Endpoint   string `json:"endpoint"`
+	Connected   bool   `json:"connected"`
+	LastPing    string `json:"last_ping,omitempty"`
+	Endpoint    string `json:"endpoint"`
+	Reachable   bool   `json:"reachable"`
+	LoggedIn    bool   `json:"logged_in"`
+	DeviceID    string `json:"device_id,omitempty"`
+	LatencyMs   int64  `json:"latency_ms"`
+	LastError   string `json:"last_error,omitempty"`
+	LastSuccess string `json:"last_success,omitempty"`
+
+	LastDeviceEvent   string `json:"last_device_event,omitempty"`
+	LastDeviceEventAt string `json:"last_device_event_at,omitempty"`
 }
 
 // CircuitBreakerStatus represents circuit breaker state
 type CircuitBreakerStatus struct {
-	State               string `json:"state"`
-	FailureCount        int    `json:"failure_count"`
-	CooldownRemaining   int    `json:"cooldown_remaining_seconds"`
+	State             string                        `json:"state"`
+	FailureCount      int                           `json:"failure_count"`
+	CooldownRemaining int                           `json:"cooldown_remaining_seconds"`
+	HeldReason        string                        `json:"held_reason,omitempty"` // Why sending is suspended, e.g. device_logged_out
+	History           []services.CircuitStateChange `json:"history"`
 }
 
 // QueueStatus represents reminder queue status
@@ -96,6 +140,23 @@ func (h *HealthHandler) GetHealthDetailed(c *gin.Context) {
 	h.mu.RLock()
 	lastPing := h.lastGOWAPing
 	gowaConnected := h.gowaConnected
+	lastProbe := h.lastProbe
+	lastGOWAOK := h.lastGOWAOK
+	circuitHistory := make([]services.CircuitStateChange, len(h.circuitHistory))
+	copy(circuitHistory, h.circuitHistory)
+	webhooks := WebhookHealthStatus{RejectedByReason: make(map[string]int, len(h.webhookRejects))}
+	for reason, count := range h.webhookRejects {
+		webhooks.RejectedByReason[reason] = count
+		webhooks.Rejected += count
+	}
+	if !h.lastRejectedAt.IsZero() {
+		webhooks.LastRejectedAt = h.lastRejectedAt.Format(time.RFC3339)
+	}
+	lastDevice := h.lastDevice
+	var lastDeviceAtStr string
+	if !h.lastDeviceAt.IsZero() {
+		lastDeviceAtStr = h.lastDeviceAt.Format(time.RFC3339)
+	}
 	h.mu.RUnlock()
 
 	gowaEndpoint := ""
@@ -107,12 +168,14 @@ func (h *HealthHandler) GetHealthDetailed(c *gin.Context) {
 	circuitState := "closed"
 	failureCount := 0
 	cooldownRemaining := int(0)
+	heldReason := ""
 
 	if h.gowaClient != nil {
 		details := h.gowaClient.GetCircuitBreakerDetails()
 		circuitState = details.State
 		failureCount = details.FailureCount
 		cooldownRemaining = int(details.CooldownRemaining.Seconds())
+		heldReason = details.HeldReason
 	}
 
 	// Get queue counts
@@ -123,19 +186,34 @@ func (h *HealthHandler) GetHealthDetailed(c *gin.Context) {
 	if !lastPing.IsZero() {
 		lastPingStr = lastPing.Format(time.RFC3339)
 	}
+	var lastSuccessStr string
+	if !lastGOWAOK.IsZero() {
+		lastSuccessStr = lastGOWAOK.Format(time.RFC3339)
+	}
 
 	response := DetailedHealthStatus{
 		Status:    "ok",
 		Timestamp: now.Format(time.RFC3339),
 		GOWA: GOWAHealthStatus{
-			Connected:  gowaConnected,
-			LastPing:   lastPingStr,
-			Endpoint:   gowaEndpoint,
+			Connected:   gowaConnected,
+			LastPing:    lastPingStr,
+			Endpoint:    gowaEndpoint,
+			Reachable:   lastProbe.Reachable,
+			LoggedIn:    lastProbe.LoggedIn,
+			DeviceID:    lastProbe.DeviceID,
+			LatencyMs:   lastProbe.Latency.Milliseconds(),
+			LastError:   lastProbe.Error,
+			LastSuccess: lastSuccessStr,
+
+			LastDeviceEvent:   lastDevice,
+			LastDeviceEventAt: lastDeviceAtStr,
 		},
 		CircuitBreaker: CircuitBreakerStatus{
 			State:             circuitState,
 			FailureCount:      failureCount,
 			CooldownRemaining: cooldownRemaining,
+			HeldReason:        heldReason,
+			History:           circuitHistory,
 		},
 		Queue: QueueStatus{
 			Total:      queueCounts.Total,
@@ -143,6 +221,14 @@ func (h *HealthHandler) GetHealthDetailed(c *gin.Context) {
 			Retrying:   queueCounts.Retrying,
 			QuietHours: queueCounts.QuietHours,
 		},
+		Webhooks: webhooks,
+	}
+	if h.sseHandler != nil {
+		sseStats := h.sseHandler.GetStats()
+		response.SSE = &sseStats
+	}
+	if h.eventBus != nil {
+		response.Events = h.eventBus.Stats()
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -214,10 +300,54 @@ func (h *HealthHandler) getQueueCounts() QueueCounts {
 	return counts
 }
 
-// UpdateGOWAPing updates the last GOWA ping time and connection status
-func (h *HealthHandler) UpdateGOWAPing(connected bool) {
+// UpdateGOWAPing records the result of an active GOWA probe.
+// GOWA counts as connected only when it is reachable and a device session is logged in.
+func (h *HealthHandler) UpdateGOWAPing(result services.GOWAProbeResult) {
+	checkedAt := result.CheckedAt
+	if checkedAt.IsZero() {
+		checkedAt = time.Now().UTC()
+	}
+
 	h.mu.Lock()
-	h.lastGOWAPing = time.Now().UTC()
-	h.gowaConnected = connected
+	h.lastGOWAPing = checkedAt
+	h.gowaConnected = result.Healthy()
+	h.lastProbe = result
+	if result.Healthy() {
+		h.lastGOWAOK = checkedAt
+	}
 	h.mu.Unlock()
 }
+
+// RecordCircuitStateChange appends a circuit breaker transition to the health history
+func (h *HealthHandler) RecordCircuitStateChange(change services.CircuitStateChange) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.circuitHistory = append(h.circuitHistory, change)
+	if len(h.circuitHistory) > maxCircuitHistory {
+		h.circuitHistory = h.circuitHistory[len(h.circuitHistory)-maxCircuitHistory:]
+	}
+}
+
+// RecordWebhookRejection counts a rejected GOWA webhook for the health endpoint
+func (h *HealthHandler) RecordWebhookRejection(reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.webhookRejects == nil {
+		h.webhookRejects = make(map[string]int)
+	}
+	h.webhookRejects[reason]++
+	h.lastRejectedAt = time.Now().UTC()
+}
+
+// RecordDeviceEvent records a GOWA device event reported by webhook as the latest
+// device state, without waiting for the next probe
+func (h *HealthHandler) RecordDeviceEvent(event string, result services.GOWAProbeResult) {
+	h.UpdateGOWAPing(result)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastDevice = event
+	h.lastDeviceAt = result.CheckedAt
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/davidyusaku-13/prima_v2/config"
 	"github.com/davidyusaku-13/prima_v2/handlers"
 	"github.com/davidyusaku-13/prima_v2/models"
 	"github.com/davidyusaku-13/prima_v2/services"
@@ -233,7 +234,13 @@ func TestUpdateGOWAPing(t *testing.T) {
 	healthHandler := handlers.NewHealthHandler(patientStore, gowaClient)
 
 	// Update GOWA ping status
-	healthHandler.UpdateGOWAPing(true)
+	healthHandler.UpdateGOWAPing(services.GOWAProbeResult{
+		Reachable: true,
+		Connected: true,
+		LoggedIn:  true,
+		DeviceID:  "628123456789@s.whatsapp.net",
+		Latency:   42 * time.Millisecond,
+	})
 
 	// Get health details to verify
 	c, w := createTestContext("GET", "/api/health/detailed")
@@ -249,6 +256,49 @@ func TestUpdateGOWAPing(t *testing.T) {
 	if gowa["connected"] != true {
 		t.Errorf("Expected GOWA connected to be true")
 	}
+	if gowa["logged_in"] != true {
+		t.Errorf("Expected GOWA logged_in to be true")
+	}
+	if gowa["latency_ms"] != float64(42) {
+		t.Errorf("Expected latency_ms 42, got %v", gowa["latency_ms"])
+	}
+	if gowa["last_success"] == nil {
+		t.Errorf("Expected last_success to be set")
+	}
+}
+
+func TestUpdateGOWAPing_LoggedOut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	patientStore := models.NewPatientStore(func() {})
+	healthHandler := handlers.NewHealthHandler(patientStore, nil)
+
+	healthHandler.UpdateGOWAPing(services.GOWAProbeResult{
+		Reachable: true,
+		Connected: true,
+		LoggedIn:  false,
+		Error:     "device not logged in",
+	})
+
+	c, w := createTestContext("GET", "/api/health/detailed")
+	c.Set("role", "admin")
+	healthHandler.GetHealthDetailed(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	data := response["data"].(map[string]interface{})
+	gowa := data["gowa"].(map[string]interface{})
+
+	if gowa["connected"] != false {
+		t.Errorf("Expected GOWA connected to be false for logged-out session")
+	}
+	if gowa["reachable"] != true {
+		t.Errorf("Expected GOWA reachable to be true")
+	}
+	if gowa["last_error"] != "device not logged in" {
+		t.Errorf("Expected last_error 'device not logged in', got %v", gowa["last_error"])
+	}
 }
 
 func TestGetQueueCounts_Empty(t *testing.T) {
@@ -527,3 +577,73 @@ func TestGetQueueCounts_RetryingExhausted(t *testing.T) {
 		t.Errorf("Expected retrying 1, got %v", queue["retrying"])
 	}
 }
+
+func TestGetHealthDetailed_CircuitBreakerHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	patientStore := models.NewPatientStore(func() {})
+	patientStore.Patients = make(map[string]*models.Patient)
+
+	gowaClient := services.NewGOWAClient(services.GOWAConfig{
+		Endpoint:         "http://localhost:3000",
+		User:             "test",
+		Password:         "test",
+		Timeout:          5 * time.Second,
+		FailureThreshold: 1,
+		CooldownDuration: 5 * time.Minute,
+	}, nil)
+
+	healthHandler := handlers.NewHealthHandler(patientStore, gowaClient)
+	gowaClient.OnCircuitStateChange(healthHandler.RecordCircuitStateChange)
+
+	// Unreachable endpoint trips the breaker after one failure
+	gowaClient.SendMessage("628123456789", "test")
+
+	c, w := createTestContext("GET", "/api/health/detailed")
+	c.Set("role", "admin")
+	healthHandler.GetHealthDetailed(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	data := response["data"].(map[string]interface{})
+	circuitBreaker := data["circuit_breaker"].(map[string]interface{})
+	history, ok := circuitBreaker["history"].([]interface{})
+	if !ok || len(history) != 1 {
+		t.Fatalf("Expected 1 history entry, got %v", circuitBreaker["history"])
+	}
+
+	entry := history[0].(map[string]interface{})
+	if entry["from"] != "closed" || entry["to"] != "open" {
+		t.Errorf("Expected closed -> open transition, got %v -> %v", entry["from"], entry["to"])
+	}
+}
+
+func TestGetHealthDetailed_SSEStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	patientStore := models.NewPatientStore(func() {})
+	patientStore.Patients = make(map[string]*models.Patient)
+
+	healthHandler := handlers.NewHealthHandler(patientStore, nil)
+	healthHandler.SetSSEHandler(handlers.NewSSEHandler(&config.Config{}, nil))
+
+	c, w := createTestContext("GET", "/api/health/detailed")
+	c.Set("role", "admin")
+	healthHandler.GetHealthDetailed(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	data := response["data"].(map[string]interface{})
+	sse, ok := data["sse"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected sse field in data, got %v", data)
+	}
+	if sse["connections"] != float64(0) || sse["dropped_events"] != float64(0) {
+		t.Errorf("Expected no connections or dropped events, got %v", sse)
+	}
+	if users, ok := sse["users"].([]interface{}); !ok || len(users) != 0 {
+		t.Errorf("Expected empty users list, got %v", sse["users"])
+	}
+}
@@ -0,0 +1,388 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/services"
+)
+
+// MedicationHandler handles patient medication schedules and dose confirmations
+type MedicationHandler struct {
+	patientStore *models.PatientStore
+	planner      *services.MedicationPlanner
+	logger       *slog.Logger
+	generateID   IDGenerator
+}
+
+// NewMedicationHandler creates a new medication handler
+func NewMedicationHandler(patientStore *models.PatientStore, planner *services.MedicationPlanner, logger *slog.Logger, idGen IDGenerator) *MedicationHandler {
+	return &MedicationHandler{
+		patientStore: patientStore,
+		planner:      planner,
+		logger:       logger,
+		generateID:   idGen,
+	}
+}
+
+// MedicationRequest represents the request body for creating or updating a medication.
+// Times may be omitted for up to four doses a day to use models.DefaultDoseTimes.
+type MedicationRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Dose         string   `json:"dose" binding:"required"`
+	TimesPerDay  int      `json:"times_per_day"`
+	Times        []string `json:"times"`
+	StartDate    string   `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate      string   `json:"end_date"`                      // YYYY-MM-DD, ongoing if empty
+	Instructions string   `json:"instructions"`
+}
+
+// ConfirmDoseRequest represents the request body for recording a dose on the patient's behalf
+type ConfirmDoseRequest struct {
+	Status string `json:"status" binding:"required"` // taken or skipped
+	Note   string `json:"note"`
+}
+
+// MedicationSummary is a medication with its adherence
+type MedicationSummary struct {
+	*models.Medication
+	Adherence services.MedicationAdherence `json:"adherence"`
+}
+
+// validateMedication normalises the dose times and dates of a request
+func validateMedication(req *MedicationRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Dose = strings.TrimSpace(req.Dose)
+	if len(req.Times) == 0 {
+		defaults, ok := models.DefaultDoseTimes[req.TimesPerDay]
+		if !ok {
+			return fmt.Errorf("times are required for %d doses a day", req.TimesPerDay)
+		}
+		req.Times = append([]string(nil), defaults...)
+	}
+	if req.TimesPerDay == 0 {
+		req.TimesPerDay = len(req.Times)
+	}
+	if req.TimesPerDay != len(req.Times) {
+		return fmt.Errorf("times_per_day is %d but %d times were given", req.TimesPerDay, len(req.Times))
+	}
+	if req.TimesPerDay > models.MaxDosesPerDay {
+		return fmt.Errorf("at most %d doses a day are supported", models.MaxDosesPerDay)
+	}
+
+	seen := make(map[string]bool, len(req.Times))
+	for i, clock := range req.Times {
+		at, err := time.Parse("15:04", strings.TrimSpace(clock))
+		if err != nil {
+			return fmt.Errorf("times[%d] must be HH:MM, got %q", i, clock)
+		}
+		req.Times[i] = at.Format("15:04")
+		if seen[req.Times[i]] {
+			return fmt.Errorf("times[%d]: duplicate time %s", i, req.Times[i])
+		}
+		seen[req.Times[i]] = true
+	}
+	sort.Strings(req.Times)
+
+	if _, err := time.Parse(models.MedicationDateLayout, req.StartDate); err != nil {
+		return fmt.Errorf("start_date must be a date in YYYY-MM-DD format")
+	}
+	if req.EndDate != "" {
+		if _, err := time.Parse(models.MedicationDateLayout, req.EndDate); err != nil {
+			return fmt.Errorf("end_date must be a date in YYYY-MM-DD format")
+		}
+		if req.EndDate < req.StartDate {
+			return fmt.Errorf("end_date must not be before start_date")
+		}
+	}
+	return nil
+}
+
+// findMedication returns a patient's medication by ID
+func findMedication(patient *models.Patient, medicationID string) *models.Medication {
+	for _, medication := range patient.Medications {
+		if medication.ID == medicationID {
+			return medication
+		}
+	}
+	return nil
+}
+
+// snapshotMedication copies a medication so it can be used after the store lock is released
+func snapshotMedication(medication *models.Medication) *models.Medication {
+	snapshot := *medication
+	snapshot.Times = append([]string(nil), medication.Times...)
+	return &snapshot
+}
+
+// ListMedications handles GET /api/patients/:id/medications
+// Adherence is computed since ?since= (YYYY-MM-DD) or over all doses.
+func (h *MedicationHandler) ListMedications(c *gin.Context) {
+	since := c.Query("since")
+	now := time.Now()
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	summaries := make([]MedicationSummary, 0, len(patient.Medications))
+	for _, medication := range patient.Medications {
+		summaries = append(summaries, MedicationSummary{
+			Medication: snapshotMedication(medication),
+			Adherence:  services.ComputeAdherence(patient, medication.ID, since, now),
+		})
+	}
+	h.patientStore.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{"data": summaries, "message": "Success"})
+}
+
+// CreateMedication handles POST /api/patients/:id/medications
+func (h *MedicationHandler) CreateMedication(c *gin.Context) {
+	var req MedicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateMedication(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_MEDICATION"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	timestamp := getCurrentTimestamp()
+	medication := &models.Medication{
+		ID:           h.generateID(),
+		Name:         req.Name,
+		Dose:         req.Dose,
+		TimesPerDay:  req.TimesPerDay,
+		Times:        req.Times,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		Instructions: req.Instructions,
+		Status:       models.MedicationStatusActive,
+		CreatedBy:    userID,
+		CreatedAt:    timestamp,
+		UpdatedAt:    timestamp,
+	}
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	patient.Medications = append(patient.Medications, medication)
+	doses := h.planner.SyncMedication(patient, medication, time.Now())
+	patient.UpdatedAt = timestamp
+	created := snapshotMedication(medication)
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Medication created",
+			"medication_id", created.ID,
+			"patient_id", patient.ID,
+			"dose_reminders", doses.Created,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": created, "doses": doses, "message": "Medication created"})
+}
+
+// UpdateMedication handles PUT /api/patients/:id/medications/:medicationId
+// Upcoming unsent dose reminders are regenerated for the new schedule.
+func (h *MedicationHandler) UpdateMedication(c *gin.Context) {
+	var req MedicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateMedication(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_MEDICATION"})
+		return
+	}
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	medication := findMedication(patient, c.Param("medicationId"))
+	if medication == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found", "code": "MEDICATION_NOT_FOUND"})
+		return
+	}
+	if medication.Status != models.MedicationStatusActive {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": "medication is stopped", "code": "INVALID_MEDICATION_STATE"})
+		return
+	}
+
+	timestamp := getCurrentTimestamp()
+	medication.Name = req.Name
+	medication.Dose = req.Dose
+	medication.TimesPerDay = req.TimesPerDay
+	medication.Times = req.Times
+	medication.StartDate = req.StartDate
+	medication.EndDate = req.EndDate
+	medication.Instructions = req.Instructions
+	medication.UpdatedAt = timestamp
+	doses := h.planner.SyncMedication(patient, medication, time.Now())
+	patient.UpdatedAt = timestamp
+	updated := snapshotMedication(medication)
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	c.JSON(http.StatusOK, gin.H{"data": updated, "doses": doses, "message": "Medication updated"})
+}
+
+// StopMedication handles POST /api/patients/:id/medications/:medicationId/stop
+// Upcoming unsent dose reminders are removed; past doses are kept for adherence.
+func (h *MedicationHandler) StopMedication(c *gin.Context) {
+	userID := c.GetString("userID")
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	medication := findMedication(patient, c.Param("medicationId"))
+	if medication == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found", "code": "MEDICATION_NOT_FOUND"})
+		return
+	}
+	if medication.Status != models.MedicationStatusActive {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": "medication is already stopped", "code": "INVALID_MEDICATION_STATE"})
+		return
+	}
+
+	timestamp := getCurrentTimestamp()
+	medication.Status = models.MedicationStatusStopped
+	medication.StoppedAt = timestamp
+	medication.UpdatedAt = timestamp
+	doses := h.planner.SyncMedication(patient, medication, time.Now())
+	patient.UpdatedAt = timestamp
+	stopped := snapshotMedication(medication)
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Medication stopped",
+			"medication_id", stopped.ID,
+			"patient_id", patient.ID,
+			"removed_doses", doses.Removed,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stopped, "doses": doses, "message": "Medication stopped"})
+}
+
+// GetAdherence handles GET /api/patients/:id/medications/:medicationId/adherence
+// The response lists the due doses, newest first, with their confirmations.
+func (h *MedicationHandler) GetAdherence(c *gin.Context) {
+	since := c.Query("since")
+	now := time.Now()
+
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	medication := findMedication(patient, c.Param("medicationId"))
+	if medication == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found", "code": "MEDICATION_NOT_FOUND"})
+		return
+	}
+	adherence := services.ComputeAdherence(patient, medication.ID, since, now)
+	doses := make([]models.Reminder, 0)
+	for _, r := range patient.Reminders {
+		if r.MedicationID != medication.ID || (since != "" && r.DueDate < since) {
+			continue
+		}
+		if due, err := time.ParseInLocation(models.DueDateLayout, r.DueDate, time.Local); err == nil && !due.After(now) {
+			doses = append(doses, *r)
+		}
+	}
+	h.patientStore.Unlock()
+
+	sort.Slice(doses, func(i, j int) bool {
+		return doses[i].DueDate > doses[j].DueDate
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"adherence": adherence,
+			"doses":     doses,
+		},
+		"message": "Success",
+	})
+}
+
+// ConfirmDose handles POST /api/patients/:id/medications/:medicationId/doses/:reminderId
+// A volunteer records whether a due dose was taken, replacing any earlier confirmation.
+func (h *MedicationHandler) ConfirmDose(c *gin.Context) {
+	var req ConfirmDoseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Status != models.DoseTaken && req.Status != models.DoseSkipped {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be taken or skipped", "code": "INVALID_DOSE_STATUS"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	patient, ok := lockManagedPatient(c, h.patientStore)
+	if !ok {
+		return
+	}
+	medicationID := c.Param("medicationId")
+	var dose *models.Reminder
+	for _, r := range patient.Reminders {
+		if r.ID == c.Param("reminderId") && r.MedicationID == medicationID {
+			dose = r
+			break
+		}
+	}
+	if dose == nil {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "dose not found", "code": "DOSE_NOT_FOUND"})
+		return
+	}
+	if due, err := time.ParseInLocation(models.DueDateLayout, dose.DueDate, time.Local); err != nil || due.After(time.Now()) {
+		h.patientStore.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": "dose is not due yet", "code": "DOSE_NOT_DUE"})
+		return
+	}
+
+	dose.Dose = &models.DoseConfirmation{
+		Status:      req.Status,
+		Source:      models.DoseSourceVolunteer,
+		ConfirmedBy: userID,
+		ConfirmedAt: getCurrentTimestamp(),
+		Note:        req.Note,
+	}
+	confirmed := *dose
+	adherence := services.ComputeAdherence(patient, medicationID, "", time.Now())
+	h.patientStore.Unlock()
+	h.patientStore.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Dose confirmed by volunteer",
+			"reminder_id", confirmed.ID,
+			"medication_id", medicationID,
+			"status", req.Status,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": confirmed, "adherence": adherence, "message": "Dose recorded"})
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/services"
+)
+
+func setupMedicationHandler(t *testing.T) (*MedicationHandler, *models.PatientStore) {
+	t.Helper()
+	patients := models.NewPatientStore(func() {})
+	patients.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", CreatedBy: "volunteer-1"}
+
+	cfg := &config.Config{Medications: config.MedicationsConfig{HorizonDays: 3, ReplyWindowHours: 12}}
+	next := 0
+	idGen := func() string {
+		next++
+		return fmt.Sprintf("id-%d", next)
+	}
+	planner := services.NewMedicationPlanner(patients, cfg, nil, idGen)
+	return NewMedicationHandler(patients, planner, nil, idGen), patients
+}
+
+func TestMedicationHandler_CreateMedication(t *testing.T) {
+	params := gin.Params{{Key: "id", Value: "patient-1"}}
+	today := time.Now().Format(models.MedicationDateLayout)
+
+	t.Run("uses default dose times and creates dose reminders", func(t *testing.T) {
+		handler, patients := setupMedicationHandler(t)
+		w := roleRequest(handler.CreateMedication, "POST", params, "volunteer", MedicationRequest{
+			Name:        "Metformin",
+			Dose:        "1 tablet 500 mg",
+			TimesPerDay: 2,
+			StartDate:   today,
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+
+		patient := patients.Patients["patient-1"]
+		if len(patient.Medications) != 1 {
+			t.Fatalf("Expected one medication, got %d", len(patient.Medications))
+		}
+		if got := fmt.Sprint(patient.Medications[0].Times); got != "[08:00 20:00]" {
+			t.Errorf("Expected default times, got %s", got)
+		}
+		doses := 0
+		for _, r := range patient.Reminders {
+			if r.MedicationID == patient.Medications[0].ID {
+				doses++
+			}
+		}
+		// Up to two doses on each of today and the next 3 days, minus those already past
+		if doses < 6 || doses > 8 {
+			t.Errorf("Expected 6-8 dose reminders, got %d", doses)
+		}
+	})
+
+	tests := []struct {
+		name string
+		req  MedicationRequest
+	}{
+		{"times mismatch", MedicationRequest{Name: "A", Dose: "1", TimesPerDay: 3, Times: []string{"08:00"}, StartDate: today}},
+		{"no default times", MedicationRequest{Name: "A", Dose: "1", TimesPerDay: 5, StartDate: today}},
+		{"invalid time", MedicationRequest{Name: "A", Dose: "1", Times: []string{"25:00"}, StartDate: today}},
+		{"duplicate time", MedicationRequest{Name: "A", Dose: "1", Times: []string{"08:00", "8:00"}, StartDate: today}},
+		{"end before start", MedicationRequest{Name: "A", Dose: "1", TimesPerDay: 1, StartDate: "2026-03-10", EndDate: "2026-03-01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupMedicationHandler(t)
+			w := roleRequest(handler.CreateMedication, "POST", params, "volunteer", tt.req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			var resp map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp["code"] != "INVALID_MEDICATION" {
+				t.Errorf("Expected INVALID_MEDICATION, got %v", resp["code"])
+			}
+		})
+	}
+
+	t.Run("other volunteer's patient is forbidden", func(t *testing.T) {
+		handler, patients := setupMedicationHandler(t)
+		patients.Patients["patient-1"].CreatedBy = "volunteer-2"
+		w := roleRequest(handler.CreateMedication, "POST", params, "volunteer", MedicationRequest{
+			Name: "A", Dose: "1", TimesPerDay: 1, StartDate: today,
+		})
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+}
+
+func TestMedicationHandler_StopMedication(t *testing.T) {
+	handler, patients := setupMedicationHandler(t)
+	patient := patients.Patients["patient-1"]
+	patient.Medications = []*models.Medication{{
+		ID: "med-1", Name: "Metformin", Dose: "1 tablet", TimesPerDay: 1, Times: []string{"08:00"},
+		StartDate: "2026-01-01", Status: models.MedicationStatusActive,
+	}}
+	past := time.Now().Add(-48 * time.Hour).Format(models.DueDateLayout)
+	future := time.Now().Add(48 * time.Hour).Format(models.DueDateLayout)
+	patient.Reminders = []*models.Reminder{
+		{ID: "dose-past", MedicationID: "med-1", DueDate: past, DeliveryStatus: models.DeliveryStatusSent},
+		{ID: "dose-future", MedicationID: "med-1", DueDate: future, DeliveryStatus: models.DeliveryStatusPending},
+	}
+
+	params := gin.Params{{Key: "id", Value: "patient-1"}, {Key: "medicationId", Value: "med-1"}}
+	w := roleRequest(handler.StopMedication, "POST", params, "volunteer", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if patient.Medications[0].Status != models.MedicationStatusStopped || patient.Medications[0].StoppedAt == "" {
+		t.Errorf("Expected medication stopped, got %+v", patient.Medications[0])
+	}
+	if len(patient.Reminders) != 1 || patient.Reminders[0].ID != "dose-past" {
+		t.Errorf("Expected only the past dose kept, got %d reminders", len(patient.Reminders))
+	}
+
+	w = roleRequest(handler.StopMedication, "POST", params, "volunteer", nil)
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d stopping twice, got %d", http.StatusConflict, w.Code)
+	}
+
+	missing := gin.Params{{Key: "id", Value: "patient-1"}, {Key: "medicationId", Value: "med-x"}}
+	if w := roleRequest(handler.StopMedication, "POST", missing, "volunteer", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for unknown medication, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestMedicationHandler_ConfirmDoseAndAdherence(t *testing.T) {
+	handler, patients := setupMedicationHandler(t)
+	patient := patients.Patients["patient-1"]
+	patient.Medications = []*models.Medication{{
+		ID: "med-1", Name: "Metformin", Dose: "1 tablet", TimesPerDay: 1, Times: []string{"08:00"},
+		StartDate: "2026-01-01", Status: models.MedicationStatusActive,
+	}}
+	patient.Reminders = []*models.Reminder{
+		{ID: "dose-1", MedicationID: "med-1", DueDate: time.Now().Add(-48 * time.Hour).Format(models.DueDateLayout)},
+		{ID: "dose-2", MedicationID: "med-1", DueDate: time.Now().Add(-24 * time.Hour).Format(models.DueDateLayout)},
+		{ID: "dose-3", MedicationID: "med-1", DueDate: time.Now().Add(24 * time.Hour).Format(models.DueDateLayout)},
+	}
+
+	confirm := func(reminderID, status string) int {
+		params := gin.Params{{Key: "id", Value: "patient-1"}, {Key: "medicationId", Value: "med-1"}, {Key: "reminderId", Value: reminderID}}
+		return roleRequest(handler.ConfirmDose, "POST", params, "volunteer", ConfirmDoseRequest{Status: status}).Code
+	}
+
+	if code := confirm("dose-1", models.DoseTaken); code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+	if dose := patient.Reminders[0].Dose; dose == nil || dose.Source != models.DoseSourceVolunteer || dose.ConfirmedBy != "volunteer-1" {
+		t.Errorf("Expected volunteer confirmation, got %+v", dose)
+	}
+	if code := confirm("dose-3", models.DoseTaken); code != http.StatusConflict {
+		t.Errorf("Expected status %d for a future dose, got %d", http.StatusConflict, code)
+	}
+	if code := confirm("dose-2", "maybe"); code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid status, got %d", http.StatusBadRequest, code)
+	}
+	if code := confirm("dose-x", models.DoseTaken); code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown dose, got %d", http.StatusNotFound, code)
+	}
+
+	params := gin.Params{{Key: "id", Value: "patient-1"}, {Key: "medicationId", Value: "med-1"}}
+	w := roleRequest(handler.GetAdherence, "GET", params, "volunteer", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var resp struct {
+		Data struct {
+			Adherence services.MedicationAdherence `json:"adherence"`
+			Doses     []models.Reminder            `json:"doses"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if resp.Data.Adherence.Due != 2 || resp.Data.Adherence.Taken != 1 || resp.Data.Adherence.Percent != 50 {
+		t.Errorf("Unexpected adherence %+v", resp.Data.Adherence)
+	}
+	if len(resp.Data.Doses) != 2 || resp.Data.Doses[0].ID != "dose-2" {
+		t.Errorf("Expected 2 due doses newest first, got %d", len(resp.Data.Doses))
+	}
+}
@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/services"
+)
+
+// minOutboundSecretLength is the shortest secret accepted for an outbound webhook
+const minOutboundSecretLength = 16
+
+// OutboundWebhookHandler handles admin-managed webhook subscriptions for partner systems
+type OutboundWebhookHandler struct {
+	store      *models.OutboundWebhookStore
+	deliveries *models.OutboundDeliveryStore
+	sender     *services.OutboundWebhookSender
+	logger     *slog.Logger
+	generateID IDGenerator
+}
+
+// NewOutboundWebhookHandler creates a new outbound webhook handler
+func NewOutboundWebhookHandler(store *models.OutboundWebhookStore, deliveries *models.OutboundDeliveryStore, sender *services.OutboundWebhookSender, logger *slog.Logger, idGen IDGenerator) *OutboundWebhookHandler {
+	return &OutboundWebhookHandler{
+		store:      store,
+		deliveries: deliveries,
+		sender:     sender,
+		logger:     logger,
+		generateID: idGen,
+	}
+}
+
+// OutboundWebhookRequest represents the request body for creating or updating an outbound webhook
+type OutboundWebhookRequest struct {
+	Name       string   `json:"name" binding:"required"`
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required"`
+	Secret     string   `json:"secret"`  // Generated on create and kept on update if empty
+	Enabled    *bool    `json:"enabled"` // Defaults to true on create, unchanged on update
+}
+
+// validateOutboundWebhook checks an outbound webhook request and removes duplicate event types
+func validateOutboundWebhook(req *OutboundWebhookRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	if len(req.EventTypes) == 0 {
+		return fmt.Errorf("event_types must not be empty")
+	}
+	seen := make(map[string]bool, len(req.EventTypes))
+	eventTypes := make([]string, 0, len(req.EventTypes))
+	for i, t := range req.EventTypes {
+		if !events.IsType(t) {
+			return fmt.Errorf("event_types[%d]: unknown event type %q", i, t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			eventTypes = append(eventTypes, t)
+		}
+	}
+	req.EventTypes = eventTypes
+	if req.Secret != "" && len(req.Secret) < minOutboundSecretLength {
+		return fmt.Errorf("secret must be at least %d characters", minOutboundSecretLength)
+	}
+	return nil
+}
+
+// generateOutboundSecret returns a random signing secret
+func generateOutboundSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// redactedWebhook returns a copy of the webhook without its secret
+func redactedWebhook(w *models.OutboundWebhook) models.OutboundWebhook {
+	snapshot := *w
+	snapshot.Secret = ""
+	return snapshot
+}
+
+// ListOutboundWebhooks handles GET /api/outbound-webhooks
+func (h *OutboundWebhookHandler) ListOutboundWebhooks(c *gin.Context) {
+	h.store.Mu.RLock()
+	webhooks := make([]models.OutboundWebhook, 0, len(h.store.Webhooks))
+	for _, w := range h.store.Webhooks {
+		webhooks = append(webhooks, redactedWebhook(w))
+	}
+	h.store.Mu.RUnlock()
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		if webhooks[i].Name != webhooks[j].Name {
+			return webhooks[i].Name < webhooks[j].Name
+		}
+		return webhooks[i].CreatedAt < webhooks[j].CreatedAt
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"webhooks":    webhooks,
+			"event_types": events.Types,
+		},
+		"message": "Success",
+	})
+}
+
+// CreateOutboundWebhook handles POST /api/outbound-webhooks
+// The secret is only returned in this response.
+func (h *OutboundWebhookHandler) CreateOutboundWebhook(c *gin.Context) {
+	var req OutboundWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateOutboundWebhook(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_OUTBOUND_WEBHOOK"})
+		return
+	}
+	if req.Secret == "" {
+		secret, err := generateOutboundSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret", "code": "INTERNAL_ERROR"})
+			return
+		}
+		req.Secret = secret
+	}
+
+	timestamp := getCurrentTimestamp()
+	webhook := &models.OutboundWebhook{
+		ID:         h.generateID(),
+		Name:       req.Name,
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Secret:     req.Secret,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		CreatedBy:  c.GetString("userID"),
+		CreatedAt:  timestamp,
+		UpdatedAt:  timestamp,
+	}
+
+	h.store.Mu.Lock()
+	h.store.Webhooks[webhook.ID] = webhook
+	created := *webhook
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Outbound webhook created",
+			"webhook_id", created.ID,
+			"event_types", strings.Join(created.EventTypes, ","),
+			"user_id", created.CreatedBy,
+		)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": created, "message": "Outbound webhook created"})
+}
+
+// UpdateOutboundWebhook handles PUT /api/outbound-webhooks/:id
+// Without "enabled" the webhook stays enabled or disabled. Re-enabling a disabled webhook,
+// including one disabled after repeated failures, resets its failure count.
+func (h *OutboundWebhookHandler) UpdateOutboundWebhook(c *gin.Context) {
+	var req OutboundWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateOutboundWebhook(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_OUTBOUND_WEBHOOK"})
+		return
+	}
+
+	h.store.Mu.Lock()
+	webhook, exists := h.store.Webhooks[c.Param("id")]
+	if !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "outbound webhook not found", "code": "OUTBOUND_WEBHOOK_NOT_FOUND"})
+		return
+	}
+	webhook.Name = req.Name
+	webhook.URL = req.URL
+	webhook.EventTypes = req.EventTypes
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.Enabled != nil {
+		if *req.Enabled && !webhook.Enabled {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledReason = ""
+			webhook.DisabledAt = ""
+		}
+		webhook.Enabled = *req.Enabled
+	}
+	webhook.UpdatedAt = getCurrentTimestamp()
+	updated := redactedWebhook(webhook)
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	c.JSON(http.StatusOK, gin.H{"data": updated, "message": "Outbound webhook updated"})
+}
+
+// DeleteOutboundWebhook handles DELETE /api/outbound-webhooks/:id
+// Pending retries are abandoned; the delivery log is kept.
+func (h *OutboundWebhookHandler) DeleteOutboundWebhook(c *gin.Context) {
+	id := c.Param("id")
+	h.store.Mu.Lock()
+	if _, exists := h.store.Webhooks[id]; !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "outbound webhook not found", "code": "OUTBOUND_WEBHOOK_NOT_FOUND"})
+		return
+	}
+	delete(h.store.Webhooks, id)
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Outbound webhook deleted",
+			"webhook_id", id,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Outbound webhook deleted"})
+}
+
+// TestOutboundWebhook sends a signed webhook.test event and returns the attempt.
+// POST /api/outbound-webhooks/:id/test
+func (h *OutboundWebhookHandler) TestOutboundWebhook(c *gin.Context) {
+	delivery, err := h.sender.Test(c.Param("id"))
+	if errors.Is(err, services.ErrOutboundWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "outbound webhook not found", "code": "OUTBOUND_WEBHOOK_NOT_FOUND"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "code": "INTERNAL_ERROR"})
+		return
+	}
+
+	message := "Test delivery succeeded"
+	if delivery.Outcome != models.OutboundDeliverySucceeded {
+		message = "Test delivery failed"
+	}
+	c.JSON(http.StatusOK, gin.H{"data": delivery, "message": message})
+}
+
+// ListOutboundDeliveries returns a webhook's delivery log, newest attempt first, without
+// payloads. Filters: outcome, event_type; limit defaults to 100 (max 500).
+// GET /api/outbound-webhooks/:id/deliveries
+func (h *OutboundWebhookHandler) ListOutboundDeliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit < 1 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	webhookID := c.Param("id")
+	outcome := c.Query("outcome")
+	eventType := c.Query("event_type")
+
+	deliveries := make([]models.OutboundDelivery, 0, limit)
+	h.deliveries.Mu.RLock()
+	for i := len(h.deliveries.Deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := h.deliveries.Deliveries[i]
+		if d.WebhookID != webhookID {
+			continue
+		}
+		if outcome != "" && d.Outcome != outcome {
+			continue
+		}
+		if eventType != "" && d.EventType != eventType {
+			continue
+		}
+		summary := *d
+		summary.Payload = ""
+		deliveries = append(deliveries, summary)
+	}
+	h.deliveries.Mu.RUnlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    deliveries,
+		"message": "Outbound webhook deliveries retrieved successfully",
+	})
+}
+
+// GetOutboundDelivery returns one delivery attempt including its payload
+// GET /api/outbound-webhooks/:id/deliveries/:deliveryId
+func (h *OutboundWebhookHandler) GetOutboundDelivery(c *gin.Context) {
+	delivery, ok := h.deliveries.Get(c.Param("deliveryId"))
+	if !ok || delivery.WebhookID != c.Param("id") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found", "code": "OUTBOUND_DELIVERY_NOT_FOUND"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    delivery,
+		"message": "Outbound webhook delivery retrieved successfully",
+	})
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/services"
+)
+
+func setupOutboundWebhookHandler(t *testing.T) (*OutboundWebhookHandler, *models.OutboundWebhookStore) {
+	t.Helper()
+	store := models.NewOutboundWebhookStore(func() {})
+	deliveries := models.NewOutboundDeliveryStore(nil)
+	cfg := &config.Config{OutboundWebhooks: config.OutboundWebhooksConfig{Timeout: time.Second, MaxAttempts: 1, DisableAfterFailures: 5}}
+	next := 0
+	generateID := func() string {
+		next++
+		return fmt.Sprintf("id-%d", next)
+	}
+	sender := services.NewOutboundWebhookSender(store, deliveries, cfg, nil, generateID)
+	t.Cleanup(sender.Stop)
+	return NewOutboundWebhookHandler(store, deliveries, sender, nil, generateID), store
+}
+
+func TestOutboundWebhookHandler_Create_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		req  OutboundWebhookRequest
+	}{
+		{"relative url", OutboundWebhookRequest{Name: "SIMRS", URL: "/hooks", EventTypes: []string{events.TypeDeliveryFailed}}},
+		{"unsupported scheme", OutboundWebhookRequest{Name: "SIMRS", URL: "ftp://simrs.example", EventTypes: []string{events.TypeDeliveryFailed}}},
+		{"no event types", OutboundWebhookRequest{Name: "SIMRS", URL: "https://simrs.example/hooks", EventTypes: []string{}}},
+		{"unknown event type", OutboundWebhookRequest{Name: "SIMRS", URL: "https://simrs.example/hooks", EventTypes: []string{"patient.deleted"}}},
+		{"short secret", OutboundWebhookRequest{Name: "SIMRS", URL: "https://simrs.example/hooks", EventTypes: []string{events.TypeDeliveryFailed}, Secret: "short"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupOutboundWebhookHandler(t)
+			w := roleRequest(handler.CreateOutboundWebhook, "POST", nil, "admin", tt.req)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			var resp map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp["code"] != "INVALID_OUTBOUND_WEBHOOK" {
+				t.Errorf("Expected INVALID_OUTBOUND_WEBHOOK, got %v", resp["code"])
+			}
+		})
+	}
+}
+
+func TestOutboundWebhookHandler_CRUD(t *testing.T) {
+	handler, store := setupOutboundWebhookHandler(t)
+
+	w := roleRequest(handler.CreateOutboundWebhook, "POST", nil, "admin", OutboundWebhookRequest{
+		Name:       "SIMRS",
+		URL:        "https://simrs.example/hooks",
+		EventTypes: []string{events.TypeDeliveryFailed, events.TypeDeliveryStatusChanged, events.TypeDeliveryFailed},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	var created struct {
+		Data models.OutboundWebhook `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created.Data.Secret) != 64 || !created.Data.Enabled || len(created.Data.EventTypes) != 2 {
+		t.Errorf("Expected an enabled webhook with a generated secret and deduplicated types, got %+v", created.Data)
+	}
+
+	w = roleRequest(handler.ListOutboundWebhooks, "GET", nil, "admin", nil)
+	var list struct {
+		Data struct {
+			Webhooks []models.OutboundWebhook `json:"webhooks"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Data.Webhooks) != 1 || list.Data.Webhooks[0].Secret != "" {
+		t.Errorf("Expected the webhook listed without its secret, got %+v", list.Data.Webhooks)
+	}
+
+	// Editing a webhook disabled after failures leaves it disabled
+	webhook := store.Webhooks[created.Data.ID]
+	webhook.Enabled = false
+	webhook.ConsecutiveFailures = 5
+	webhook.DisabledReason = "5 consecutive failed deliveries"
+	params := gin.Params{{Key: "id", Value: created.Data.ID}}
+	w = roleRequest(handler.UpdateOutboundWebhook, "PUT", params, "admin", OutboundWebhookRequest{
+		Name:       "SIMRS v2",
+		URL:        "https://simrs.example/v2/hooks",
+		EventTypes: []string{events.TypeDeliveryFailed},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if webhook.Enabled || webhook.ConsecutiveFailures != 5 || webhook.DisabledReason == "" || webhook.Name != "SIMRS v2" {
+		t.Errorf("Expected the edit to keep the webhook disabled with its failure state, got %+v", webhook)
+	}
+
+	// Re-enabling it resets the failure state and keeps the secret
+	enabled := true
+	w = roleRequest(handler.UpdateOutboundWebhook, "PUT", params, "admin", OutboundWebhookRequest{
+		Name:       "SIMRS",
+		URL:        "https://simrs.example/v2/hooks",
+		EventTypes: []string{events.TypeDeliveryFailed},
+		Enabled:    &enabled,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if !webhook.Enabled || webhook.ConsecutiveFailures != 0 || webhook.DisabledReason != "" || webhook.Secret != created.Data.Secret {
+		t.Errorf("Expected the webhook re-enabled with its secret, got %+v", webhook)
+	}
+	if contains(w.Body.String(), created.Data.Secret) {
+		t.Error("Expected the secret withheld from the update response")
+	}
+
+	if w := roleRequest(handler.DeleteOutboundWebhook, "DELETE", params, "admin", nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w := roleRequest(handler.DeleteOutboundWebhook, "DELETE", params, "admin", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d deleting twice, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestOutboundWebhookHandler_TestAndDeliveries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	handler, store := setupOutboundWebhookHandler(t)
+	store.Webhooks["hook-1"] = &models.OutboundWebhook{
+		ID:         "hook-1",
+		URL:        server.URL,
+		EventTypes: []string{events.TypeDeliveryFailed},
+		Secret:     "partner-secret-0123456789",
+	}
+
+	if w := roleRequest(handler.TestOutboundWebhook, "POST", gin.Params{{Key: "id", Value: "missing"}}, "admin", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown webhook, got %d", http.StatusNotFound, w.Code)
+	}
+
+	params := gin.Params{{Key: "id", Value: "hook-1"}}
+	w := roleRequest(handler.TestOutboundWebhook, "POST", params, "admin", nil)
+	var tested struct {
+		Data    models.OutboundDelivery `json:"data"`
+		Message string                  `json:"message"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &tested)
+	if w.Code != http.StatusOK || tested.Data.Outcome != models.OutboundDeliverySucceeded || tested.Data.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected a successful test delivery, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = roleRequest(handler.ListOutboundDeliveries, "GET", params, "admin", nil)
+	var list struct {
+		Data []models.OutboundDelivery `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Data) != 1 || !list.Data[0].Test || list.Data[0].Payload != "" {
+		t.Errorf("Expected the test delivery listed without its payload, got %+v", list.Data)
+	}
+
+	w = roleRequest(handler.GetOutboundDelivery, "GET", gin.Params{{Key: "id", Value: "hook-1"}, {Key: "deliveryId", Value: tested.Data.ID}}, "admin", nil)
+	if w.Code != http.StatusOK || !contains(w.Body.String(), services.OutboundTestEventType) {
+		t.Errorf("Expected the delivery with its payload, got %d %s", w.Code, w.Body.String())
+	}
+	w = roleRequest(handler.GetOutboundDelivery, "GET", gin.Params{{Key: "id", Value: "other"}, {Key: "deliveryId", Value: tested.Data.ID}}, "admin", nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for another webhook's delivery, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -3,15 +3,18 @@ package handlers
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"sort"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
 	"github.com/davidyusaku-13/prima_v2/models"
 	"github.com/davidyusaku-13/prima_v2/services"
 	"github.com/davidyusaku-13/prima_v2/utils"
@@ -22,6 +25,18 @@ func getCurrentTimestamp() string {
 	return time.Now().UTC().Format(time.RFC3339)
 }
 
+// transitionReminder moves a reminder to a new delivery status on behalf of a user,
+// logging moves the state machine rejects. The caller must hold the patient store write lock.
+func transitionReminder(logger *slog.Logger, reminder *models.Reminder, to, userID, reason string) {
+	if err := reminder.TransitionTo(to, models.StatusActorUser, userID, reason, time.Now()); err != nil && logger != nil {
+		logger.Warn("Delivery status transition rejected",
+			"reminder_id", reminder.ID,
+			"user_id", userID,
+			"error", err.Error(),
+		)
+	}
+}
+
 // Role constants
 const (
 	RoleVolunteer = "volunteer"
@@ -38,7 +53,17 @@ type ReminderHandler struct {
 	logger       *slog.Logger
 	generateID   IDGenerator
 	contentStore *ContentStore // Added for attachment validation and content lookup
-	sseHandler   *SSEHandler   // SSE handler for broadcasting delivery status updates
+	bus          *events.Bus   // Domain events, none published if nil
+
+	templateStore     *models.TemplateStore         // Message templates, built-in layout if nil
+	shortLinkStore    *models.ShortLinkStore        // Tracked content links, raw links if nil
+	reminderTemplates *models.ReminderTemplateStore // Reminder presets for Create, template_id rejected if nil
+	blackouts         *models.BlackoutStore         // Blackout calendar, none if nil
+}
+
+// SetBlackoutStore sets the blackout calendar that defers non-exempt sends
+func (h *ReminderHandler) SetBlackoutStore(blackouts *models.BlackoutStore) {
+	h.blackouts = blackouts
 }
 
 // NewReminderHandler creates a new reminder handler
@@ -50,23 +75,70 @@ func NewReminderHandler(store *models.PatientStore, cfg *config.Config, gowaClie
 		logger:       logger,
 		generateID:   idGen,
 		contentStore: contentStore,
-		sseHandler:   nil, // Will be set via SetSSEHandler
+		bus:          nil, // Will be set via SetEventBus
 	}
 }
 
-// SetSSEHandler sets the SSE handler for broadcasting delivery status updates
-func (h *ReminderHandler) SetSSEHandler(sseHandler *SSEHandler) {
-	h.sseHandler = sseHandler
+// SetEventBus sets the bus that reminder and delivery events are published on
+func (h *ReminderHandler) SetEventBus(bus *events.Bus) {
+	h.bus = bus
+}
+
+// SetTemplateStore sets the message template store used to format reminders
+func (h *ReminderHandler) SetTemplateStore(templateStore *models.TemplateStore) {
+	h.templateStore = templateStore
+}
+
+// SetShortLinkStore sets the store used to track content links in sent messages
+func (h *ReminderHandler) SetShortLinkStore(shortLinkStore *models.ShortLinkStore) {
+	h.shortLinkStore = shortLinkStore
+}
+
+// SetReminderTemplateStore sets the reminder template library used by Create
+func (h *ReminderHandler) SetReminderTemplateStore(store *models.ReminderTemplateStore) {
+	h.reminderTemplates = store
+}
+
+// applyReminderTemplate fills the request fields the caller left empty from a reminder template.
+// An explicit empty attachments array overrides the template's attachments.
+func applyReminderTemplate(req *CreateReminderRequest, tmpl models.ReminderTemplate) {
+	if req.Title == "" {
+		req.Title = tmpl.Title
+	}
+	if req.Description == "" {
+		req.Description = tmpl.Description
+	}
+	if req.Priority == "" {
+		req.Priority = tmpl.Priority
+	}
+	if req.Recurrence.Frequency == "" {
+		req.Recurrence = tmpl.Recurrence
+	}
+	if req.Attachments == nil {
+		req.Attachments = tmpl.Attachments
+	}
+	if req.MessageTemplate == "" {
+		req.MessageTemplate = tmpl.MessageTemplate
+	}
+	if req.Escalation == nil {
+		req.Escalation = tmpl.Escalation
+	}
 }
 
 // CreateReminderRequest represents the request body for creating a reminder
+// Fields left empty are taken from the reminder template when TemplateID is set.
 type CreateReminderRequest struct {
-	Title       string              `json:"title" binding:"required"`
+	Title       string              `json:"title"` // Required unless provided by the template
 	Description string              `json:"description"`
 	DueDate     string              `json:"dueDate"`
 	Priority    string              `json:"priority"`
 	Recurrence  models.Recurrence   `json:"recurrence"`
 	Attachments []models.Attachment `json:"attachments"`
+
+	MessageTemplate string `json:"message_template"` // Template name, default template if empty
+
+	TemplateID string                   `json:"template_id"` // Reminder template to instantiate
+	Escalation *models.EscalationPolicy `json:"escalation"`
 }
 
 // UpdateReminderRequest represents the request body for updating a reminder
@@ -77,6 +149,10 @@ type UpdateReminderRequest struct {
 	Priority    string              `json:"priority"`
 	Recurrence  models.Recurrence   `json:"recurrence"`
 	Attachments []models.Attachment `json:"attachments"`
+
+	MessageTemplate string `json:"message_template"` // Template name, default template if empty
+
+	Escalation *models.EscalationPolicy `json:"escalation"`
 }
 
 // MaxAttachments is the maximum number of content attachments per reminder
@@ -84,6 +160,11 @@ const MaxAttachments = 3
 
 // validateAttachments validates the attachments array
 func (h *ReminderHandler) validateAttachments(attachments []models.Attachment) error {
+	return validateAttachmentList(h.contentStore, attachments)
+}
+
+// validateAttachmentList validates attachments, checking that content exists when a content store is given
+func validateAttachmentList(contentStore *ContentStore, attachments []models.Attachment) error {
 	for i, att := range attachments {
 		if att.Type != "article" && att.Type != "video" {
 			return fmt.Errorf("attachment[%d]: type must be 'article' or 'video'", i)
@@ -99,27 +180,46 @@ func (h *ReminderHandler) validateAttachments(attachments []models.Attachment) e
 		}
 
 		// Validate that attachment ID exists in content store
-		if h.contentStore != nil {
+		if contentStore != nil {
 			if att.Type == "article" {
-				h.contentStore.Articles.Mu.RLock()
-				if _, exists := h.contentStore.Articles.Articles[att.ID]; !exists {
-					h.contentStore.Articles.Mu.RUnlock()
+				contentStore.Articles.Mu.RLock()
+				if _, exists := contentStore.Articles.Articles[att.ID]; !exists {
+					contentStore.Articles.Mu.RUnlock()
 					return fmt.Errorf("attachment[%d]: article with id '%s' not found", i, att.ID)
 				}
-				h.contentStore.Articles.Mu.RUnlock()
+				contentStore.Articles.Mu.RUnlock()
 			} else if att.Type == "video" {
-				h.contentStore.Videos.Mu.RLock()
-				if _, exists := h.contentStore.Videos.Videos[att.ID]; !exists {
-					h.contentStore.Videos.Mu.RUnlock()
+				contentStore.Videos.Mu.RLock()
+				if _, exists := contentStore.Videos.Videos[att.ID]; !exists {
+					contentStore.Videos.Mu.RUnlock()
 					return fmt.Errorf("attachment[%d]: video with id '%s' not found", i, att.ID)
 				}
-				h.contentStore.Videos.Mu.RUnlock()
+				contentStore.Videos.Mu.RUnlock()
 			}
 		}
 	}
 	return nil
 }
 
+// validateMessageTemplate checks that a named message template exists
+func (h *ReminderHandler) validateMessageTemplate(name string) error {
+	return validateTemplateName(h.templateStore, name)
+}
+
+// validateTemplateName checks that a named message template exists in the store, if any
+func validateTemplateName(templateStore *models.TemplateStore, name string) error {
+	if name == "" || templateStore == nil {
+		return nil
+	}
+	templateStore.Mu.RLock()
+	_, exists := templateStore.ByName[name]
+	templateStore.Mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("message template '%s' not found", name)
+	}
+	return nil
+}
+
 // Create handles POST /patients/:id/reminders
 func (h *ReminderHandler) Create(c *gin.Context) {
 	patientID := c.Param("id")
@@ -132,6 +232,38 @@ func (h *ReminderHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// Instantiate from a reminder template, request fields override it
+	if req.TemplateID != "" {
+		var tmpl models.ReminderTemplate
+		exists := false
+		if h.reminderTemplates != nil {
+			tmpl, exists = h.reminderTemplates.Get(req.TemplateID)
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("reminder template '%s' not found", req.TemplateID),
+				"code":  "REMINDER_TEMPLATE_NOT_FOUND",
+			})
+			return
+		}
+		applyReminderTemplate(&req, tmpl)
+	}
+	if req.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+	if err := validateEscalation(req.Escalation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_ESCALATION"})
+		return
+	}
+	if err := validatePriority(&h.config.Priorities, req.Priority); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_PRIORITY"})
+		return
+	}
+	if req.Priority == "" {
+		req.Priority = h.config.Priorities.Default
+	}
+
 	// Validate attachments count
 	if len(req.Attachments) > MaxAttachments {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -152,6 +284,14 @@ func (h *ReminderHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateMessageTemplate(req.MessageTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  "INVALID_TEMPLATE",
+		})
+		return
+	}
+
 	h.store.Lock()
 	patient, exists := h.store.GetPatient(patientID)
 	if !exists {
@@ -178,6 +318,10 @@ func (h *ReminderHandler) Create(c *gin.Context) {
 		Notified:       false,
 		Attachments:    req.Attachments,
 		DeliveryStatus: models.DeliveryStatusPending,
+
+		MessageTemplate: req.MessageTemplate,
+		TemplateID:      req.TemplateID,
+		Escalation:      req.Escalation,
 	}
 	patient.Reminders = append(patient.Reminders, reminder)
 	patient.UpdatedAt = getCurrentTimestamp()
@@ -185,6 +329,23 @@ func (h *ReminderHandler) Create(c *gin.Context) {
 
 	h.store.SaveData()
 
+	if req.TemplateID != "" && h.reminderTemplates != nil {
+		h.reminderTemplates.IncrementUsage(req.TemplateID)
+	}
+
+	if h.bus != nil {
+		h.bus.Publish(events.ReminderCreated{
+			ReminderID:  reminder.ID,
+			PatientID:   patientID,
+			VolunteerID: patient.CreatedBy,
+			Title:       reminder.Title,
+			DueDate:     reminder.DueDate,
+			Priority:    reminder.Priority,
+			CreatedBy:   userID,
+			At:          time.Now().UTC(),
+		})
+	}
+
 	if h.logger != nil {
 		h.logger.Info("Reminder created",
 			"reminder_id", reminder.ID,
@@ -208,6 +369,10 @@ func (h *ReminderHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validatePriority(&h.config.Priorities, req.Priority); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_PRIORITY"})
+		return
+	}
 
 	// Validate attachments count
 	if len(req.Attachments) > MaxAttachments {
@@ -229,6 +394,19 @@ func (h *ReminderHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateMessageTemplate(req.MessageTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  "INVALID_TEMPLATE",
+		})
+		return
+	}
+
+	if err := validateEscalation(req.Escalation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_ESCALATION"})
+		return
+	}
+
 	h.store.Lock()
 	patient, exists := h.store.GetPatient(patientID)
 	if !exists {
@@ -256,6 +434,13 @@ func (h *ReminderHandler) Update(c *gin.Context) {
 			if req.Attachments != nil {
 				r.Attachments = req.Attachments
 			}
+			if req.MessageTemplate != "" {
+				r.MessageTemplate = req.MessageTemplate
+			}
+			if req.Escalation != nil {
+				r.Escalation = req.Escalation
+				r.EscalatedAt = ""
+			}
 			if req.DueDate != "" && req.DueDate != r.DueDate {
 				r.Notified = false
 			}
@@ -428,54 +613,92 @@ func (h *ReminderHandler) Send(c *gin.Context) {
 		})
 		return
 	}
+	// A resend of a sent reminder keeps its status, the idempotency outbox suppresses the
+	// duplicate; cancelled and expired reminders cannot be sent
+	resend := models.IsSentStatus(reminder.DeliveryStatus)
+	setStatus := func(to, reason string) {
+		if !resend {
+			transitionReminder(h.logger, reminder, to, userID, reason)
+		}
+	}
+	if !resend && !models.CanTransition(reminder.DeliveryStatus, models.DeliveryStatusSending) {
+		h.store.Unlock()
+		c.JSON(http.StatusConflict, gin.H{
+			"error":          "Reminder tidak dapat dikirim dengan status saat ini",
+			"code":           "INVALID_STATUS_TRANSITION",
+			"current_status": reminder.DeliveryStatus,
+		})
+		return
+	}
 
-	// 5. Check quiet hours - schedule for later if in quiet hours
+	// 5. Check quiet hours and blackouts - schedule for later if sending is held back
 	now := time.Now()
-	if utils.IsQuietHours(now, &h.config.QuietHours) {
-		scheduledTime := utils.GetNextActiveTime(now, &h.config.QuietHours)
-		reminder.DeliveryStatus = models.DeliveryStatusScheduled
-		reminder.ScheduledDeliveryAt = scheduledTime.Format(time.RFC3339)
+	if deferral := utils.ReminderSendTime(now, h.config, h.blackouts.Active(), patient, reminder.Priority); !resend && deferral.Deferred() {
+		reason := "quiet hours"
+		if deferral.Blackout != nil {
+			reason = "held back by blackout " + deferral.Blackout.Name
+		}
+		setStatus(models.DeliveryStatusScheduled, reason)
+		reminder.ScheduledDeliveryAt = deferral.SendAt.UTC().Format(time.RFC3339)
 		h.store.Unlock()
 		h.store.SaveData()
 
+		message := "Reminder dijadwalkan untuk dikirim jam 06:00"
+		blackoutID := ""
+		if deferral.Blackout != nil {
+			message = fmt.Sprintf("Reminder ditahan selama %s dan dijadwalkan untuk dikirim setelahnya", deferral.Blackout.Name)
+			blackoutID = deferral.Blackout.ID
+		}
+
 		if h.logger != nil {
-			h.logger.Info("Reminder scheduled for quiet hours",
+			h.logger.Info("Reminder scheduled for quiet hours or blackout",
 				"reminder_id", reminderID,
 				"patient_id", patientID,
+				"blackout_id", blackoutID,
 				"scheduled_at", reminder.ScheduledDeliveryAt,
 			)
 		}
 
-		c.JSON(http.StatusOK, gin.H{
+		response := gin.H{
 			"data":         reminder,
-			"message":      "Reminder dijadwalkan untuk dikirim jam 06:00",
+			"message":      message,
 			"scheduled":    true,
 			"scheduled_at": reminder.ScheduledDeliveryAt,
-		})
+		}
+		if blackoutID != "" {
+			response["blackout_id"] = blackoutID
+		}
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
 	// 6. Update status to sending (optimistic) - active hours
-	reminder.DeliveryStatus = models.DeliveryStatusSending
+	setStatus(models.DeliveryStatusSending, "manual send")
+	idempotencyKey := services.ReminderIdempotencyKey(reminder)
+	previousAttempt := services.PreviousDeliveryAttempt(reminder, idempotencyKey)
 	// Capture sentAt timestamp before GOWA call for accuracy
 	sentAt := time.Now().UTC()
 	h.store.Unlock()
 	h.store.SaveData()
 
-	// 7. Format message
-	message := h.formatReminderMessage(reminder, patient)
-
-	// 8. Send via GOWA (outside lock)
+	// 7. Format message, only once it is known to be sent
 	whatsappPhone := utils.FormatWhatsAppNumber(patient.Phone)
-	response, err := h.gowaClient.SendMessage(whatsappPhone, message)
+	buildMessage := func() services.OutboundMessage {
+		return h.buildOutboundMessage(reminder, patient, whatsappPhone, idempotencyKey)
+	}
+
+	// 8. Send via GOWA (outside lock), at most once per reminder occurrence
+	response, duplicate, err := h.gowaClient.SendIdempotent(idempotencyKey, buildMessage, previousAttempt)
 
 	// 9. Update status based on result
 	h.store.Lock()
+	services.RecordDeliveryAttempt(reminder, idempotencyKey, response, duplicate, err)
 	if err != nil {
 		// Check if circuit breaker is open - queue for retry (NFR-I2)
-		if h.gowaClient.GetCircuitBreakerState() == "open" {
-			reminder.DeliveryStatus = models.DeliveryStatusQueued
+		if errors.Is(err, services.ErrCircuitOpen) || h.gowaClient.GetCircuitBreakerState() == services.CircuitStateOpen {
+			setStatus(models.DeliveryStatusQueued, "GOWA unavailable")
 			reminder.DeliveryErrorMessage = "GOWA sedang tidak tersedia. Coba lagi nanti."
+			reminder.DeliveryFailureCode = models.FailureCodeGOWAUnavailable
 			reminder.RetryCount++
 
 			h.store.Unlock()
@@ -499,13 +722,14 @@ func (h *ReminderHandler) Send(c *gin.Context) {
 		}
 
 		// Check if error is retryable and we haven't exceeded max attempts
-		if services.ShouldRetry(err) && reminder.RetryCount < h.config.Retry.MaxAttempts {
+		if services.ShouldRetry(err) && reminder.RetryCount < h.config.MaxAttemptsFor(reminder.Priority) {
 			// Schedule retry with exponential backoff
 			retryDelay := services.GetRetryDelay(reminder.RetryCount, h.config.Retry.Delays)
 			nextRetryTime := time.Now().UTC().Add(retryDelay)
-			reminder.DeliveryStatus = models.DeliveryStatusRetrying
+			setStatus(models.DeliveryStatusRetrying, err.Error())
 			reminder.ScheduledDeliveryAt = nextRetryTime.Format(time.RFC3339)
 			reminder.DeliveryErrorMessage = err.Error()
+			reminder.DeliveryFailureCode = services.FailureReasonCode(err)
 			reminder.RetryCount++
 
 			h.store.Unlock()
@@ -532,8 +756,9 @@ func (h *ReminderHandler) Send(c *gin.Context) {
 		}
 
 		// Max retries exceeded or non-retryable error
-		reminder.DeliveryStatus = models.DeliveryStatusFailed
+		setStatus(models.DeliveryStatusFailed, err.Error())
 		reminder.DeliveryErrorMessage = err.Error()
+		reminder.DeliveryFailureCode = services.FailureReasonCode(err)
 
 		h.store.Unlock()
 		h.store.SaveData()
@@ -556,21 +781,26 @@ func (h *ReminderHandler) Send(c *gin.Context) {
 	}
 
 	// Success - use captured timestamp for accuracy
-	reminder.DeliveryStatus = models.DeliveryStatusSent
+	setStatus(models.DeliveryStatusSent, services.SentReason(duplicate))
 	reminder.GOWAMessageID = response.MessageID
-	reminder.MessageSentAt = sentAt.Format(time.RFC3339)
+	if !duplicate || reminder.MessageSentAt == "" {
+		reminder.MessageSentAt = sentAt.Format(time.RFC3339)
+	}
 	reminder.DeliveryErrorMessage = ""
+	reminder.DeliveryFailureCode = ""
 	reminder.Completed = true // Mark as completed when successfully sent
 	h.store.Unlock()
 	h.store.SaveData()
 
-	// Broadcast SSE event for real-time UI updates
-	if h.sseHandler != nil {
-		h.sseHandler.BroadcastDeliveryStatusUpdate(
-			reminderID,
-			string(models.DeliveryStatusSent),
-			sentAt.Format(time.RFC3339),
-		)
+	// Publish the status change for real-time UI updates
+	if h.bus != nil && !resend {
+		h.bus.Publish(events.DeliveryStatusChanged{
+			ReminderID:  reminderID,
+			PatientID:   patientID,
+			VolunteerID: patient.CreatedBy,
+			Status:      models.DeliveryStatusSent,
+			At:          sentAt,
+		})
 	}
 
 	if h.logger != nil {
@@ -579,24 +809,26 @@ func (h *ReminderHandler) Send(c *gin.Context) {
 			"patient_id", patientID,
 			"phone", utils.MaskPhone(whatsappPhone),
 			"gowa_message_id", response.MessageID,
+			"duplicate_suppressed", duplicate,
 		)
 	}
 
-	// Increment attachment counts for analytics
-	if h.contentStore != nil && len(reminder.Attachments) > 0 {
+	// Increment attachment counts for analytics, once per delivered message
+	if h.contentStore != nil && len(reminder.Attachments) > 0 && !duplicate {
 		for _, att := range reminder.Attachments {
 			h.contentStore.IncrementAttachmentCountInternal(att.Type, att.ID)
 		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":    reminder,
-		"message": "Reminder berhasil dikirim",
+		"data":                 reminder,
+		"message":              "Reminder berhasil dikirim",
+		"duplicate_suppressed": duplicate,
 	})
 }
 
 // buildContentAttachments builds ContentAttachment slice from reminder attachments
-// Uses the shared utils.BuildContentAttachments function
+// Uses the shared utils.BuildContentAttachmentsWithBaseURL function
 func (h *ReminderHandler) buildContentAttachments(reminder *models.Reminder) []utils.ContentAttachment {
 	var articleStore *models.ArticleStore
 	var videoStore *models.VideoStore
@@ -606,17 +838,20 @@ func (h *ReminderHandler) buildContentAttachments(reminder *models.Reminder) []u
 		videoStore = h.contentStore.Videos
 	}
 
-	return utils.BuildContentAttachments(reminder.Attachments, articleStore, videoStore)
+	return utils.BuildContentAttachmentsWithBaseURL(reminder.Attachments, articleStore, videoStore, h.config.Links.PublicBaseURL)
 }
 
 // formatReminderMessage creates the WhatsApp message content with excerpts
+// from the reminder's message template in the patient's language
 func (h *ReminderHandler) formatReminderMessage(reminder *models.Reminder, patient *models.Patient) string {
-	disclaimerEnabled := h.config.Disclaimer.Enabled != nil && *h.config.Disclaimer.Enabled
+	return h.renderReminderMessage(reminder, patient, h.buildContentAttachments(reminder))
+}
 
-	// Build content attachments with excerpts
-	contentAttachments := h.buildContentAttachments(reminder)
+// renderReminderMessage renders the reminder's message template with the given attachments
+func (h *ReminderHandler) renderReminderMessage(reminder *models.Reminder, patient *models.Patient, contentAttachments []utils.ContentAttachment) string {
+	disclaimerEnabled := h.config.Disclaimer.Enabled != nil && *h.config.Disclaimer.Enabled
 
-	return utils.FormatReminderMessageWithExcerpts(utils.ReminderMessageParams{
+	return utils.FormatReminderMessageFromTemplate(h.templateStore, reminder.MessageTemplate, patient.Language, utils.ReminderMessageParams{
 		PatientName:         patient.Name,
 		ReminderTitle:       reminder.Title,
 		ReminderDescription: reminder.Description,
@@ -625,6 +860,43 @@ func (h *ReminderHandler) formatReminderMessage(reminder *models.Reminder, patie
 	}, contentAttachments)
 }
 
+// buildOutboundMessage builds the message to send for a reminder. Content links are
+// replaced with tracked short links for the occurrence's idempotency key when enabled.
+// When media messages are enabled and an attached article has a hero image, the message
+// goes out as the image caption.
+func (h *ReminderHandler) buildOutboundMessage(reminder *models.Reminder, patient *models.Patient, whatsappPhone, idempotencyKey string) services.OutboundMessage {
+	message, _ := h.composeOutboundMessage(reminder, patient, whatsappPhone, idempotencyKey)
+	return message
+}
+
+// composeOutboundMessage builds the outbound message and returns the resolved content
+// attachments. Without an idempotency key no short links are created; placeholders of
+// the same length stand in for them so previews match the sent message.
+func (h *ReminderHandler) composeOutboundMessage(reminder *models.Reminder, patient *models.Patient, whatsappPhone, idempotencyKey string) (services.OutboundMessage, []utils.ContentAttachment) {
+	resolved := h.buildContentAttachments(reminder)
+	contentAttachments := resolved
+
+	message := services.OutboundMessage{Phone: whatsappPhone}
+	if h.config.GOWA.MediaMessages != nil && *h.config.GOWA.MediaMessages {
+		message.ImagePath = utils.HeroImagePath(contentAttachments)
+	}
+	if h.config.Links.ShortLinksEnabled() {
+		if idempotencyKey != "" {
+			contentAttachments = utils.TrackContentLinks(h.shortLinkStore, h.config.Links.ShortLinkBaseURL, patient.ID, reminder.ID, idempotencyKey, contentAttachments)
+		} else if h.shortLinkStore != nil {
+			contentAttachments = make([]utils.ContentAttachment, len(resolved))
+			copy(contentAttachments, resolved)
+			for i := range contentAttachments {
+				if contentAttachments[i].URL != "" {
+					contentAttachments[i].URL = utils.ShortLinkPlaceholder(h.config.Links.ShortLinkBaseURL)
+				}
+			}
+		}
+	}
+	message.Text = h.renderReminderMessage(reminder, patient, contentAttachments)
+	return message, resolved
+}
+
 // DefaultIDGenerator generates a unique ID using timestamp and random string
 func DefaultIDGenerator() string {
 	return time.Now().Format("20060102150405") + "-" + reminderRandomString(8)
@@ -691,7 +963,7 @@ func (h *ReminderHandler) GetReminderStatus(c *gin.Context) {
 			"title":           foundReminder.Title,
 			"delivery_status": foundReminder.DeliveryStatus,
 			"retry_count":     foundReminder.RetryCount,
-			"max_attempts":    h.config.Retry.MaxAttempts,
+			"max_attempts":    h.config.MaxAttemptsFor(foundReminder.Priority),
 			"error_message":   foundReminder.DeliveryErrorMessage,
 			"scheduled_at":    foundReminder.ScheduledDeliveryAt,
 			"sent_at":         foundReminder.MessageSentAt,
@@ -701,11 +973,77 @@ func (h *ReminderHandler) GetReminderStatus(c *gin.Context) {
 	})
 }
 
+// GetReminderTimeline handles GET /api/reminders/:id/timeline and returns every delivery
+// status change of the reminder, oldest first, with its send attempts
+func (h *ReminderHandler) GetReminderTimeline(c *gin.Context) {
+	reminderID := c.Param("id")
+	userID := c.GetString("userID")
+	role := c.GetString("role")
+
+	h.store.RLock()
+	defer h.store.RUnlock()
+
+	var patient *models.Patient
+	var reminder *models.Reminder
+	for _, p := range h.store.Patients {
+		for _, r := range p.Reminders {
+			if r.ID == reminderID {
+				patient = p
+				reminder = r
+				break
+			}
+		}
+		if reminder != nil {
+			break
+		}
+	}
+
+	if reminder == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reminder not found", "code": "REMINDER_NOT_FOUND"})
+		return
+	}
+
+	// Volunteers can only see the timeline of their own patients' reminders
+	if role == RoleVolunteer && patient.CreatedBy != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "code": "FORBIDDEN"})
+		return
+	}
+
+	timeline := reminder.StatusTimeline
+	if timeline == nil {
+		timeline = []models.StatusTransition{}
+	}
+	attempts := reminder.DeliveryAttempts
+	if attempts == nil {
+		attempts = []models.DeliveryAttempt{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"reminder_id":     reminder.ID,
+			"patient_id":      patient.ID,
+			"delivery_status": reminder.DeliveryStatus,
+			"gowa_message_id": reminder.GOWAMessageID,
+			"timeline":        timeline,
+			"attempts":        attempts,
+		},
+		"message": "Reminder timeline retrieved successfully",
+	})
+}
+
 // RetryReminder handles POST /api/reminders/:id/retry
+// With ?force=true (admins only) a send that could not be verified is resent anyway,
+// which may deliver the message twice.
 func (h *ReminderHandler) RetryReminder(c *gin.Context) {
 	reminderID := c.Param("id")
 	userID := c.GetString("userID")
 	role := c.GetString("role")
+	force := c.Query("force") == "true"
+
+	if force && role != "admin" && role != "superadmin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can force a resend", "code": "FORBIDDEN"})
+		return
+	}
 
 	// 1. Find reminder across all patients
 	h.store.Lock()
@@ -762,8 +1100,9 @@ func (h *ReminderHandler) RetryReminder(c *gin.Context) {
 	// 5. Check circuit breaker state
 	if !h.gowaClient.IsAvailable() {
 		// Queue reminder for retry when circuit breaker resets
-		reminder.DeliveryStatus = models.DeliveryStatusQueued
+		transitionReminder(h.logger, reminder, models.DeliveryStatusQueued, userID, "manual retry, GOWA unavailable")
 		reminder.DeliveryErrorMessage = "GOWA sedang tidak tersedia. Akan dicoba lagi."
+		reminder.DeliveryFailureCode = models.FailureCodeGOWAUnavailable
 		h.store.Unlock()
 		h.store.SaveData()
 
@@ -785,25 +1124,42 @@ func (h *ReminderHandler) RetryReminder(c *gin.Context) {
 	}
 
 	// 6. Update status to sending (optimistic)
-	reminder.DeliveryStatus = models.DeliveryStatusSending
+	transitionReminder(h.logger, reminder, models.DeliveryStatusSending, userID, "manual retry")
 	reminder.DeliveryErrorMessage = ""
+	reminder.DeliveryFailureCode = ""
+	idempotencyKey := services.ReminderIdempotencyKey(reminder)
+	previousAttempt := services.PreviousDeliveryAttempt(reminder, idempotencyKey)
+	if force && previousAttempt != nil && previousAttempt.Outcome == models.AttemptOutcomeAmbiguous {
+		// The operator accepts a possible duplicate; resend under the same key
+		previousAttempt = nil
+		if h.logger != nil {
+			h.logger.Warn("Forcing resend of unverified reminder",
+				"reminder_id", reminderID,
+				"user_id", userID,
+			)
+		}
+	}
 	sentAt := time.Now().UTC()
 	h.store.Unlock()
 	h.store.SaveData()
 
-	// 7. Format message
-	message := h.formatReminderMessage(reminder, patient)
-
-	// 8. Send via GOWA (outside lock)
+	// 7. Format message, only once it is known to be sent
 	whatsappPhone := utils.FormatWhatsAppNumber(patient.Phone)
-	response, err := h.gowaClient.SendMessage(whatsappPhone, message)
+	buildMessage := func() services.OutboundMessage {
+		return h.buildOutboundMessage(reminder, patient, whatsappPhone, idempotencyKey)
+	}
+
+	// 8. Send via GOWA (outside lock), at most once per reminder occurrence
+	response, duplicate, err := h.gowaClient.SendIdempotent(idempotencyKey, buildMessage, previousAttempt)
 
 	// 9. Update status based on result
 	h.store.Lock()
+	services.RecordDeliveryAttempt(reminder, idempotencyKey, response, duplicate, err)
 	if err != nil {
 		// Retry failed
-		reminder.DeliveryStatus = models.DeliveryStatusFailed
+		transitionReminder(h.logger, reminder, models.DeliveryStatusFailed, userID, err.Error())
 		reminder.DeliveryErrorMessage = err.Error()
+		reminder.DeliveryFailureCode = services.FailureReasonCode(err)
 		h.store.Unlock()
 		h.store.SaveData()
 
@@ -815,6 +1171,14 @@ func (h *ReminderHandler) RetryReminder(c *gin.Context) {
 			)
 		}
 
+		var gowaErr *services.GOWAError
+		if errors.As(err, &gowaErr) && gowaErr.Exhausted {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": reminder.DeliveryErrorMessage,
+				"code":  "SEND_UNVERIFIED",
+			})
+			return
+		}
 		c.JSON(http.StatusServiceUnavailable, gin.H{
 			"error": reminder.DeliveryErrorMessage,
 			"code":  "GOWA_ERROR",
@@ -823,22 +1187,27 @@ func (h *ReminderHandler) RetryReminder(c *gin.Context) {
 	}
 
 	// Success - reset retry count
-	reminder.DeliveryStatus = models.DeliveryStatusSent
+	transitionReminder(h.logger, reminder, models.DeliveryStatusSent, userID, services.SentReason(duplicate))
 	reminder.GOWAMessageID = response.MessageID
-	reminder.MessageSentAt = sentAt.Format(time.RFC3339)
+	if !duplicate || reminder.MessageSentAt == "" {
+		reminder.MessageSentAt = sentAt.Format(time.RFC3339)
+	}
 	reminder.DeliveryErrorMessage = ""
+	reminder.DeliveryFailureCode = ""
 	reminder.RetryCount = 0 // Reset retry count on manual retry
 	reminder.Completed = true // Mark as completed when successfully sent
 	h.store.Unlock()
 	h.store.SaveData()
 
-	// Broadcast SSE event for real-time UI updates
-	if h.sseHandler != nil {
-		h.sseHandler.BroadcastDeliveryStatusUpdate(
-			reminderID,
-			string(models.DeliveryStatusSent),
-			sentAt.Format(time.RFC3339),
-		)
+	// Publish the status change for real-time UI updates
+	if h.bus != nil {
+		h.bus.Publish(events.DeliveryStatusChanged{
+			ReminderID:  reminderID,
+			PatientID:   patient.ID,
+			VolunteerID: patient.CreatedBy,
+			Status:      models.DeliveryStatusSent,
+			At:          sentAt,
+		})
 	}
 
 	if h.logger != nil {
@@ -852,8 +1221,9 @@ func (h *ReminderHandler) RetryReminder(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"data": gin.H{
 			"reminder_id": reminderID,
-			"status":      "sent",
-			"message_id":  response.MessageID,
+			"status":               "sent",
+			"message_id":           response.MessageID,
+			"duplicate_suppressed": duplicate,
 		},
 		"message": "Reminder berhasil dikirim ulang",
 	})
@@ -874,6 +1244,8 @@ type ReminderHistoryResponse struct {
 	CancelledAt     string              `json:"cancelled_at,omitempty"`
 	Attachments     []models.Attachment `json:"attachments"`
 	AttachmentCount int                 `json:"attachment_count"`
+
+	DeliveryAttempts []models.DeliveryAttempt `json:"delivery_attempts,omitempty"`
 }
 
 // PaginationResponse represents pagination info
@@ -958,6 +1330,8 @@ func (h *ReminderHandler) GetPatientReminders(c *gin.Context) {
 			CancelledAt:     r.CancelledAt,
 			Attachments:     r.Attachments,
 			AttachmentCount: len(r.Attachments),
+
+			DeliveryAttempts: append([]models.DeliveryAttempt(nil), r.DeliveryAttempts...),
 		}
 		reminders = append(reminders, reminderResp)
 	}
@@ -1048,7 +1422,7 @@ func (h *ReminderHandler) CancelReminder(c *gin.Context) {
 
 	// Cancel the reminder
 	previousStatus := reminder.DeliveryStatus
-	reminder.DeliveryStatus = models.DeliveryStatusCancelled
+	transitionReminder(h.logger, reminder, models.DeliveryStatusCancelled, userID, "cancelled")
 	reminder.CancelledAt = time.Now().UTC().Format(time.RFC3339)
 	reminder.CancelledBy = userID
 
@@ -1075,3 +1449,290 @@ func (h *ReminderHandler) CancelReminder(c *gin.Context) {
 		"message": "Reminder berhasil dibatalkan",
 	})
 }
+
+// PreviewReminderRequest is an unsaved reminder payload to preview
+type PreviewReminderRequest struct {
+	Title       string              `json:"title" binding:"required"`
+	Description string              `json:"description"`
+	DueDate     string              `json:"dueDate"`
+	Priority    string              `json:"priority"`
+	Attachments []models.Attachment `json:"attachments"`
+
+	MessageTemplate string `json:"message_template"` // Template name, default template if empty
+}
+
+// PreviewWarning describes something that would make the sent message differ from what was intended
+type PreviewWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Index   *int   `json:"index,omitempty"` // Attachment index, for attachment warnings
+}
+
+// PreviewAttachment is an attachment as it will appear in the message
+type PreviewAttachment struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	URL   string `json:"url,omitempty"` // Resolved destination; sent as a tracked short link when enabled
+}
+
+// PreviewBlackout identifies the blackout a previewed send is held back by
+type PreviewBlackout struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ReminderPreview is the rendered message for a reminder without sending it
+type ReminderPreview struct {
+	Message         string              `json:"message"`
+	CharacterCount  int                 `json:"character_count"`
+	DeliveryType    string              `json:"delivery_type"` // "text" or "image" (message sent as image caption)
+	Phone           string              `json:"phone,omitempty"`
+	Language        string              `json:"language"`
+	MessageTemplate string              `json:"message_template"`
+	Attachments     []PreviewAttachment `json:"attachments"`
+	TrackedLinks    bool                `json:"tracked_links"`
+	EffectiveSendAt string              `json:"effective_send_at"`
+	QuietHours      bool                `json:"quiet_hours"`        // Sending at the due date (or now) would be deferred to EffectiveSendAt
+	Blackout        *PreviewBlackout    `json:"blackout,omitempty"` // Blackout deferring the send, if any
+	Warnings        []PreviewWarning    `json:"warnings"`
+}
+
+// PreviewSavedReminder handles GET /patients/:id/reminders/:reminderId/preview
+func (h *ReminderHandler) PreviewSavedReminder(c *gin.Context) {
+	patientID := c.Param("id")
+	reminderID := c.Param("reminderId")
+
+	h.store.RLock()
+	patient, exists := h.store.GetPatient(patientID)
+	if !exists {
+		h.store.RUnlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "patient not found", "code": "PATIENT_NOT_FOUND"})
+		return
+	}
+	if c.GetString("role") == RoleVolunteer && patient.CreatedBy != c.GetString("userID") {
+		h.store.RUnlock()
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "code": "FORBIDDEN"})
+		return
+	}
+	var reminder *models.Reminder
+	for _, r := range patient.Reminders {
+		if r.ID == reminderID {
+			reminder = r
+			break
+		}
+	}
+	if reminder == nil {
+		h.store.RUnlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "reminder not found", "code": "REMINDER_NOT_FOUND"})
+		return
+	}
+	patientCopy := *patient
+	reminderCopy := *reminder
+	reminderCopy.Attachments = append([]models.Attachment(nil), reminder.Attachments...)
+	h.store.RUnlock()
+
+	preview := h.previewReminder(&reminderCopy, &patientCopy, time.Now())
+	if reminderCopy.DeliveryStatus == models.DeliveryStatusSending {
+		preview.Warnings = append(preview.Warnings, PreviewWarning{
+			Code:    "ALREADY_SENDING",
+			Message: "Reminder sedang dalam proses pengiriman",
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": preview})
+}
+
+// PreviewReminder handles POST /patients/:id/reminders/preview for an unsaved reminder
+func (h *ReminderHandler) PreviewReminder(c *gin.Context) {
+	patientID := c.Param("id")
+
+	var req PreviewReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_REQUEST"})
+		return
+	}
+	if err := validatePriority(&h.config.Priorities, req.Priority); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_PRIORITY"})
+		return
+	}
+
+	h.store.RLock()
+	patient, exists := h.store.GetPatient(patientID)
+	if !exists {
+		h.store.RUnlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "patient not found", "code": "PATIENT_NOT_FOUND"})
+		return
+	}
+	if c.GetString("role") == RoleVolunteer && patient.CreatedBy != c.GetString("userID") {
+		h.store.RUnlock()
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions", "code": "FORBIDDEN"})
+		return
+	}
+	patientCopy := *patient
+	h.store.RUnlock()
+
+	reminder := &models.Reminder{
+		Title:           req.Title,
+		Description:     req.Description,
+		DueDate:         req.DueDate,
+		Priority:        req.Priority,
+		Attachments:     req.Attachments,
+		DeliveryStatus:  models.DeliveryStatusPending,
+		MessageTemplate: req.MessageTemplate,
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": h.previewReminder(reminder, &patientCopy, time.Now())})
+}
+
+// previewReminder renders the message exactly as Send would, without creating short links
+// or contacting GOWA, and collects warnings about the patient, content and template
+func (h *ReminderHandler) previewReminder(reminder *models.Reminder, patient *models.Patient, now time.Time) ReminderPreview {
+	preview := ReminderPreview{
+		Language:        patient.Language,
+		MessageTemplate: reminder.MessageTemplate,
+		Attachments:     make([]PreviewAttachment, 0, len(reminder.Attachments)),
+		Warnings:        make([]PreviewWarning, 0),
+	}
+	if preview.Language == "" {
+		preview.Language = models.DefaultLanguage
+	}
+	if preview.MessageTemplate == "" {
+		preview.MessageTemplate = models.DefaultMessageTemplateName
+	}
+
+	phoneResult := utils.ValidatePhoneNumber(patient.Phone)
+	if phoneResult.Valid {
+		preview.Phone = phoneResult.Normalized
+	} else {
+		preview.Warnings = append(preview.Warnings, PreviewWarning{
+			Code:    "INVALID_PHONE",
+			Message: "Nomor WhatsApp tidak valid: " + phoneResult.ErrorMessage,
+		})
+	}
+
+	preview.Warnings = append(preview.Warnings, h.templateWarnings(reminder.MessageTemplate, patient.Language)...)
+	preview.Warnings = append(preview.Warnings, h.attachmentWarnings(reminder.Attachments)...)
+
+	message, resolved := h.composeOutboundMessage(reminder, patient, phoneResult.WhatsAppFormat, "")
+	preview.Message = message.Text
+	preview.CharacterCount = utf8.RuneCountInString(message.Text)
+	preview.TrackedLinks = h.config.Links.ShortLinksEnabled() && h.shortLinkStore != nil
+	for _, att := range resolved {
+		preview.Attachments = append(preview.Attachments, PreviewAttachment{
+			Type:  att.Type,
+			ID:    att.ID,
+			Title: att.Title,
+			URL:   att.URL,
+		})
+	}
+
+	preview.DeliveryType = "text"
+	if message.ImagePath != "" {
+		if preview.CharacterCount <= services.MaxImageCaptionLength {
+			preview.DeliveryType = "image"
+		} else {
+			preview.Warnings = append(preview.Warnings, PreviewWarning{
+				Code:    "CAPTION_TOO_LONG",
+				Message: fmt.Sprintf("Pesan melebihi %d karakter, gambar artikel tidak dikirim", services.MaxImageCaptionLength),
+			})
+		}
+	}
+
+	// The scheduler sends at the due date, or now if it has passed
+	sendAt := now
+	if dueTime, err := services.ParseDueDate(reminder.DueDate); err == nil && dueTime.After(now) {
+		sendAt = dueTime
+	}
+	deferral := utils.ReminderSendTime(sendAt, h.config, h.blackouts.Active(), patient, reminder.Priority)
+	if deferral.QuietHours {
+		preview.QuietHours = true
+		preview.Warnings = append(preview.Warnings, PreviewWarning{
+			Code:    "QUIET_HOURS",
+			Message: "Waktu kirim jatuh pada jam tenang, pesan akan dijadwalkan",
+		})
+	}
+	if deferral.Blackout != nil {
+		preview.Blackout = &PreviewBlackout{ID: deferral.Blackout.ID, Name: deferral.Blackout.Name}
+		preview.Warnings = append(preview.Warnings, PreviewWarning{
+			Code:    "BLACKOUT",
+			Message: fmt.Sprintf("Pengiriman ditahan selama %s, pesan akan dijadwalkan", deferral.Blackout.Name),
+		})
+	}
+	preview.EffectiveSendAt = deferral.SendAt.UTC().Format(time.RFC3339)
+
+	return preview
+}
+
+// templateWarnings reports when the reminder's template would fall back to the built-in template
+func (h *ReminderHandler) templateWarnings(name, language string) []PreviewWarning {
+	if name == "" || h.templateStore == nil {
+		return nil
+	}
+	body, ok := h.templateStore.Resolve(name, language)
+	if !ok {
+		return []PreviewWarning{{
+			Code:    "TEMPLATE_NOT_FOUND",
+			Message: fmt.Sprintf("Template '%s' tidak ditemukan, template bawaan digunakan", name),
+		}}
+	}
+	if err := utils.ValidateMessageTemplate(body); err != nil {
+		return []PreviewWarning{{
+			Code:    "TEMPLATE_INVALID",
+			Message: fmt.Sprintf("Template '%s' tidak valid, template bawaan digunakan: %s", name, err.Error()),
+		}}
+	}
+	return nil
+}
+
+// attachmentWarnings reports attachments that are missing, unpublished or have no link
+func (h *ReminderHandler) attachmentWarnings(attachments []models.Attachment) []PreviewWarning {
+	var warnings []PreviewWarning
+	if len(attachments) > MaxAttachments {
+		warnings = append(warnings, PreviewWarning{
+			Code:    "MAX_ATTACHMENTS_EXCEEDED",
+			Message: fmt.Sprintf("Maksimal %d konten yang dapat dilampirkan", MaxAttachments),
+		})
+	}
+	if h.contentStore == nil {
+		return warnings
+	}
+
+	for i, att := range attachments {
+		index := i
+		switch att.Type {
+		case "article":
+			h.contentStore.Articles.Mu.RLock()
+			article, exists := h.contentStore.Articles.Articles[att.ID]
+			var status models.ArticleStatus
+			var slug string
+			if exists {
+				status, slug = article.Status, article.Slug
+			}
+			h.contentStore.Articles.Mu.RUnlock()
+			if !exists {
+				warnings = append(warnings, PreviewWarning{Code: "CONTENT_NOT_FOUND", Message: fmt.Sprintf("Artikel '%s' tidak ditemukan", att.Title), Index: &index})
+			} else if status != models.ArticleStatusPublished {
+				warnings = append(warnings, PreviewWarning{Code: "CONTENT_UNPUBLISHED", Message: fmt.Sprintf("Artikel '%s' belum dipublikasikan", att.Title), Index: &index})
+			} else if slug == "" && att.URL == "" {
+				warnings = append(warnings, PreviewWarning{Code: "CONTENT_NO_LINK", Message: fmt.Sprintf("Artikel '%s' tidak memiliki tautan", att.Title), Index: &index})
+			}
+		case "video":
+			h.contentStore.Videos.Mu.RLock()
+			video, exists := h.contentStore.Videos.Videos[att.ID]
+			var youtubeID string
+			if exists {
+				youtubeID = video.YouTubeID
+			}
+			h.contentStore.Videos.Mu.RUnlock()
+			if !exists {
+				warnings = append(warnings, PreviewWarning{Code: "CONTENT_NOT_FOUND", Message: fmt.Sprintf("Video '%s' tidak ditemukan", att.Title), Index: &index})
+			} else if youtubeID == "" && att.URL == "" {
+				warnings = append(warnings, PreviewWarning{Code: "CONTENT_NO_LINK", Message: fmt.Sprintf("Video '%s' tidak memiliki tautan", att.Title), Index: &index})
+			}
+		default:
+			warnings = append(warnings, PreviewWarning{Code: "INVALID_ATTACHMENT", Message: fmt.Sprintf("attachment[%d]: type must be 'article' or 'video'", i), Index: &index})
+		}
+	}
+	return warnings
+}
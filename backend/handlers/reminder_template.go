@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// ReminderTemplateHandler handles the reusable reminder template library
+type ReminderTemplateHandler struct {
+	store         *models.ReminderTemplateStore
+	contentStore  *ContentStore
+	templateStore *models.TemplateStore
+	priorities    *config.PrioritiesConfig // Allowed priorities, any if nil
+	logger        *slog.Logger
+	generateID    IDGenerator
+}
+
+// NewReminderTemplateHandler creates a new reminder template handler
+func NewReminderTemplateHandler(store *models.ReminderTemplateStore, contentStore *ContentStore, templateStore *models.TemplateStore, logger *slog.Logger, idGen IDGenerator) *ReminderTemplateHandler {
+	return &ReminderTemplateHandler{
+		store:         store,
+		contentStore:  contentStore,
+		templateStore: templateStore,
+		logger:        logger,
+		generateID:    idGen,
+	}
+}
+
+// SetPriorities sets the configured priority levels templates are validated against
+func (h *ReminderTemplateHandler) SetPriorities(priorities *config.PrioritiesConfig) {
+	h.priorities = priorities
+}
+
+// ReminderTemplateRequest represents the request body for creating or updating a reminder template
+type ReminderTemplateRequest struct {
+	Name            string                   `json:"name" binding:"required"`
+	Category        string                   `json:"category" binding:"required"`
+	Title           string                   `json:"title" binding:"required"`
+	Description     string                   `json:"description"`
+	Priority        string                   `json:"priority"`
+	Recurrence      models.Recurrence        `json:"recurrence"`
+	Attachments     []models.Attachment      `json:"attachments"`
+	Escalation      *models.EscalationPolicy `json:"escalation"`
+	MessageTemplate string                   `json:"message_template"`
+}
+
+// ReminderTemplateCategory summarises templates and usage in one category
+type ReminderTemplateCategory struct {
+	Category      string `json:"category"`
+	TemplateCount int    `json:"template_count"`
+	UsageCount    int    `json:"usage_count"`
+}
+
+// validateEscalation checks an escalation policy, nil means no escalation
+func validateEscalation(escalation *models.EscalationPolicy) error {
+	if escalation == nil {
+		return nil
+	}
+	if escalation.AfterHours <= 0 || escalation.AfterHours > models.MaxEscalationHours {
+		return fmt.Errorf("escalation.after_hours must be between 1 and %d", models.MaxEscalationHours)
+	}
+	return nil
+}
+
+// validatePriority checks a reminder priority against the configured levels, any
+// priority is accepted if priorities is nil
+func validatePriority(priorities *config.PrioritiesConfig, priority string) error {
+	if priorities == nil || priorities.Valid(priority) {
+		return nil
+	}
+	return fmt.Errorf("priority must be one of %s, got '%s'", strings.Join(priorities.Names(), ", "), priority)
+}
+
+// validate checks the request, writing a 400 response and returning false if invalid
+func (h *ReminderTemplateHandler) validate(c *gin.Context, req *ReminderTemplateRequest) bool {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Category = strings.TrimSpace(req.Category)
+	if req.Name == "" || req.Category == "" || strings.TrimSpace(req.Title) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name, category and title are required", "code": "INVALID_REMINDER_TEMPLATE"})
+		return false
+	}
+	if len(req.Attachments) > MaxAttachments {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     fmt.Sprintf("Maksimal %d konten yang dapat dilampirkan", MaxAttachments),
+			"code":      "MAX_ATTACHMENTS_EXCEEDED",
+			"max_count": MaxAttachments,
+			"actual":    len(req.Attachments),
+		})
+		return false
+	}
+	if err := validateAttachmentList(h.contentStore, req.Attachments); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_ATTACHMENT"})
+		return false
+	}
+	if err := validateTemplateName(h.templateStore, req.MessageTemplate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_TEMPLATE"})
+		return false
+	}
+	if err := validateEscalation(req.Escalation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_ESCALATION"})
+		return false
+	}
+	if err := validatePriority(h.priorities, req.Priority); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_PRIORITY"})
+		return false
+	}
+	return true
+}
+
+// ListReminderTemplates handles GET /api/reminder-templates, optionally filtered by ?category=
+func (h *ReminderTemplateHandler) ListReminderTemplates(c *gin.Context) {
+	category := c.Query("category")
+
+	h.store.Mu.RLock()
+	templates := make([]models.ReminderTemplate, 0, len(h.store.Templates))
+	categorySet := make(map[string]bool)
+	for _, tmpl := range h.store.Templates {
+		categorySet[tmpl.Category] = true
+		if category != "" && tmpl.Category != category {
+			continue
+		}
+		templates = append(templates, *tmpl)
+	}
+	h.store.Mu.RUnlock()
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Category == templates[j].Category {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].Category < templates[j].Category
+	})
+	categories := make([]string, 0, len(categorySet))
+	for name := range categorySet {
+		categories = append(categories, name)
+	}
+	sort.Strings(categories)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":       templates,
+		"categories": categories,
+		"message":    "Success",
+	})
+}
+
+// GetReminderTemplate handles GET /api/reminder-templates/:id
+func (h *ReminderTemplateHandler) GetReminderTemplate(c *gin.Context) {
+	tmpl, exists := h.store.Get(c.Param("id"))
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reminder template not found", "code": "REMINDER_TEMPLATE_NOT_FOUND"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": tmpl, "message": "Success"})
+}
+
+// CreateReminderTemplate handles POST /api/reminder-templates
+func (h *ReminderTemplateHandler) CreateReminderTemplate(c *gin.Context) {
+	var req ReminderTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.validate(c, &req) {
+		return
+	}
+
+	userID := c.GetString("userID")
+	now := getCurrentTimestamp()
+	tmpl := &models.ReminderTemplate{
+		ID:              h.generateID(),
+		Name:            req.Name,
+		Category:        req.Category,
+		Title:           req.Title,
+		Description:     req.Description,
+		Priority:        req.Priority,
+		Recurrence:      req.Recurrence,
+		Attachments:     req.Attachments,
+		Escalation:      req.Escalation,
+		MessageTemplate: req.MessageTemplate,
+		CreatedBy:       userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	h.store.Mu.Lock()
+	h.store.Templates[tmpl.ID] = tmpl
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Reminder template created",
+			"template_id", tmpl.ID,
+			"category", tmpl.Category,
+			"user_id", userID,
+		)
+	}
+
+	created, _ := h.store.Get(tmpl.ID)
+	c.JSON(http.StatusCreated, gin.H{"data": created, "message": "Reminder template created"})
+}
+
+// UpdateReminderTemplate handles PUT /api/reminder-templates/:id
+// Reminders already created from the template are not changed.
+func (h *ReminderTemplateHandler) UpdateReminderTemplate(c *gin.Context) {
+	var req ReminderTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.validate(c, &req) {
+		return
+	}
+
+	id := c.Param("id")
+	h.store.Mu.Lock()
+	tmpl, exists := h.store.Templates[id]
+	if !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "reminder template not found", "code": "REMINDER_TEMPLATE_NOT_FOUND"})
+		return
+	}
+	tmpl.Name = req.Name
+	tmpl.Category = req.Category
+	tmpl.Title = req.Title
+	tmpl.Description = req.Description
+	tmpl.Priority = req.Priority
+	tmpl.Recurrence = req.Recurrence
+	tmpl.Attachments = req.Attachments
+	tmpl.Escalation = req.Escalation
+	tmpl.MessageTemplate = req.MessageTemplate
+	tmpl.UpdatedAt = getCurrentTimestamp()
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Reminder template updated",
+			"template_id", id,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	updated, _ := h.store.Get(id)
+	c.JSON(http.StatusOK, gin.H{"data": updated, "message": "Reminder template updated"})
+}
+
+// DeleteReminderTemplate handles DELETE /api/reminder-templates/:id
+func (h *ReminderTemplateHandler) DeleteReminderTemplate(c *gin.Context) {
+	id := c.Param("id")
+	h.store.Mu.Lock()
+	if _, exists := h.store.Templates[id]; !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "reminder template not found", "code": "REMINDER_TEMPLATE_NOT_FOUND"})
+		return
+	}
+	delete(h.store.Templates, id)
+	h.store.Mu.Unlock()
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Reminder template deleted",
+			"template_id", id,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reminder template deleted"})
+}
+
+// GetReminderTemplateAnalytics handles GET /api/analytics/reminder-templates
+// and returns templates by usage, with per-category totals
+func (h *ReminderTemplateHandler) GetReminderTemplateAnalytics(c *gin.Context) {
+	h.store.Mu.RLock()
+	templates := make([]models.ReminderTemplate, 0, len(h.store.Templates))
+	byCategory := make(map[string]*ReminderTemplateCategory)
+	for _, tmpl := range h.store.Templates {
+		templates = append(templates, *tmpl)
+		category, ok := byCategory[tmpl.Category]
+		if !ok {
+			category = &ReminderTemplateCategory{Category: tmpl.Category}
+			byCategory[tmpl.Category] = category
+		}
+		category.TemplateCount++
+		category.UsageCount += tmpl.UsageCount
+	}
+	h.store.Mu.RUnlock()
+
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].UsageCount == templates[j].UsageCount {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].UsageCount > templates[j].UsageCount
+	})
+	categories := make([]ReminderTemplateCategory, 0, len(byCategory))
+	for _, category := range byCategory {
+		categories = append(categories, *category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].UsageCount == categories[j].UsageCount {
+			return categories[i].Category < categories[j].Category
+		}
+		return categories[i].UsageCount > categories[j].UsageCount
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"templates":  templates,
+			"categories": categories,
+		},
+	})
+}
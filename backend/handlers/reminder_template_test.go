@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func setupReminderTemplateHandler(t *testing.T) (*ReminderTemplateHandler, *models.ReminderTemplateStore) {
+	t.Helper()
+	store := models.NewReminderTemplateStore(func() {})
+	handler := NewReminderTemplateHandler(store, NewContentStore(), nil, nil, func() string { return "rt-1" })
+	return handler, store
+}
+
+func reminderTemplateRequest(handler gin.HandlerFunc, method, path string, params gin.Params, body interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	data, _ := json.Marshal(body)
+	c.Request = httptest.NewRequest(method, path, bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("userID", "admin-1")
+	c.Set("role", "admin")
+	handler(c)
+	return w
+}
+
+func TestReminderTemplateHandler_CRUD(t *testing.T) {
+	handler, store := setupReminderTemplateHandler(t)
+
+	w := reminderTemplateRequest(handler.CreateReminderTemplate, "POST", "/api/reminder-templates", nil, ReminderTemplateRequest{
+		Name:       "TB harian",
+		Category:   "TB",
+		Title:      "Minum obat TB",
+		Priority:   "high",
+		Recurrence: models.Recurrence{Frequency: "daily", Interval: 1},
+		Escalation: &models.EscalationPolicy{AfterHours: 6},
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if store.Templates["rt-1"] == nil || store.Templates["rt-1"].Escalation.AfterHours != 6 {
+		t.Fatalf("Expected template stored with escalation, got %+v", store.Templates["rt-1"])
+	}
+
+	w = reminderTemplateRequest(handler.CreateReminderTemplate, "POST", "/api/reminder-templates", nil, ReminderTemplateRequest{
+		Name:       "Salah",
+		Category:   "TB",
+		Title:      "Judul",
+		Escalation: &models.EscalationPolicy{AfterHours: 0},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected invalid escalation to be rejected, got %d", w.Code)
+	}
+
+	w = reminderTemplateRequest(handler.CreateReminderTemplate, "POST", "/api/reminder-templates", nil, ReminderTemplateRequest{
+		Name:        "Artikel hilang",
+		Category:    "TB",
+		Title:       "Judul",
+		Attachments: []models.Attachment{{Type: "article", ID: "missing", Title: "Artikel"}},
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected missing content to be rejected, got %d", w.Code)
+	}
+
+	params := gin.Params{{Key: "id", Value: "rt-1"}}
+	w = reminderTemplateRequest(handler.UpdateReminderTemplate, "PUT", "/api/reminder-templates/rt-1", params, ReminderTemplateRequest{
+		Name:     "TB harian",
+		Category: "Tuberkulosis",
+		Title:    "Minum obat TB pagi",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected update to succeed, got %d", w.Code)
+	}
+	if store.Templates["rt-1"].Category != "Tuberkulosis" || store.Templates["rt-1"].Escalation != nil {
+		t.Errorf("Expected template replaced, got %+v", store.Templates["rt-1"])
+	}
+
+	w = reminderTemplateRequest(handler.DeleteReminderTemplate, "DELETE", "/api/reminder-templates/rt-1", params, nil)
+	if w.Code != http.StatusOK || len(store.Templates) != 0 {
+		t.Errorf("Expected template deleted, got %d with %d templates", w.Code, len(store.Templates))
+	}
+	w = reminderTemplateRequest(handler.GetReminderTemplate, "GET", "/api/reminder-templates/rt-1", params, nil)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestReminderTemplateHandler_ListAndAnalytics(t *testing.T) {
+	handler, store := setupReminderTemplateHandler(t)
+	store.Templates["a"] = &models.ReminderTemplate{ID: "a", Name: "Obat TB", Category: "TB", UsageCount: 2}
+	store.Templates["b"] = &models.ReminderTemplate{ID: "b", Name: "Kontrol tensi", Category: "Hipertensi", UsageCount: 7}
+	store.Templates["c"] = &models.ReminderTemplate{ID: "c", Name: "Dahak TB", Category: "TB", UsageCount: 1}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/reminder-templates?category=TB", nil)
+	handler.ListReminderTemplates(c)
+
+	var list struct {
+		Data       []models.ReminderTemplate `json:"data"`
+		Categories []string                  `json:"categories"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Data) != 2 || list.Data[0].Name != "Dahak TB" {
+		t.Errorf("Expected 2 TB templates sorted by name, got %+v", list.Data)
+	}
+	if len(list.Categories) != 2 {
+		t.Errorf("Expected all categories listed, got %v", list.Categories)
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/analytics/reminder-templates", nil)
+	handler.GetReminderTemplateAnalytics(c)
+
+	var analytics struct {
+		Data struct {
+			Templates  []models.ReminderTemplate  `json:"templates"`
+			Categories []ReminderTemplateCategory `json:"categories"`
+		} `json:"data"`
+	}
+	body, _ := io.ReadAll(w.Body)
+	json.Unmarshal(body, &analytics)
+	if len(analytics.Data.Templates) != 3 || analytics.Data.Templates[0].ID != "b" {
+		t.Errorf("Expected templates sorted by usage, got %+v", analytics.Data.Templates)
+	}
+	if len(analytics.Data.Categories) != 2 || analytics.Data.Categories[0].Category != "Hipertensi" ||
+		analytics.Data.Categories[1].UsageCount != 3 || analytics.Data.Categories[1].TemplateCount != 2 {
+		t.Errorf("Unexpected category totals: %+v", analytics.Data.Categories)
+	}
+}
@@ -3,11 +3,13 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +19,7 @@ import (
 	"github.com/davidyusaku-13/prima_v2/config"
 	"github.com/davidyusaku-13/prima_v2/models"
 	"github.com/davidyusaku-13/prima_v2/services"
+	"github.com/davidyusaku-13/prima_v2/utils"
 )
 
 func init() {
@@ -467,6 +470,180 @@ func TestReminderHandler_Send(t *testing.T) {
 		}
 		// Note: Circuit breaker behavior may vary based on timing, so we accept both outcomes
 	})
+
+	t.Run("records attempt and suppresses duplicate resend", func(t *testing.T) {
+		sends := 0
+		gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sends++
+			if r.Header.Get("Idempotency-Key") == "" {
+				t.Error("Expected Idempotency-Key header")
+			}
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(services.SendMessageResponse{
+				Success:   true,
+				MessageID: "msg-123",
+			})
+		}))
+		defer gowaServer.Close()
+
+		handler, store := setupTestHandler(t, gowaServer)
+
+		store.Patients["patient-1"] = &models.Patient{
+			ID:        "patient-1",
+			Name:      "Test Patient",
+			Phone:     "08123456789",
+			CreatedBy: "user-1",
+			Reminders: []*models.Reminder{
+				{
+					ID:             "reminder-1",
+					Title:          "Test Reminder",
+					DeliveryStatus: models.DeliveryStatusPending,
+				},
+			},
+		}
+
+		for i := 0; i < 2; i++ {
+			c, w := setupTestContext("POST", "/api/patients/patient-1/reminders/reminder-1/send", map[string]string{
+				"id":         "patient-1",
+				"reminderId": "reminder-1",
+			})
+			c.Set("userID", "user-1")
+			c.Set("role", "volunteer")
+
+			handler.Send(c)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Send %d: expected status %d, got %d", i+1, http.StatusOK, w.Code)
+			}
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if response["duplicate_suppressed"] != (i == 1) {
+				t.Errorf("Send %d: expected duplicate_suppressed=%v, got %v", i+1, i == 1, response["duplicate_suppressed"])
+			}
+		}
+
+		if sends != 1 {
+			t.Errorf("Expected GOWA to be called once, got %d", sends)
+		}
+
+		attempts := store.Patients["patient-1"].Reminders[0].DeliveryAttempts
+		if len(attempts) != 2 {
+			t.Fatalf("Expected 2 delivery attempts, got %d", len(attempts))
+		}
+		if attempts[0].Outcome != models.AttemptOutcomeSent {
+			t.Errorf("Expected first attempt 'sent', got '%s'", attempts[0].Outcome)
+		}
+		if attempts[1].Outcome != models.AttemptOutcomeDuplicateSuppressed || attempts[1].MessageID != "msg-123" {
+			t.Errorf("Expected duplicate_suppressed with original message ID, got %+v", attempts[1])
+		}
+	})
+}
+
+func TestReminderHandler_StatusTimeline(t *testing.T) {
+	gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(services.SendMessageResponse{Success: true, MessageID: "msg-123"})
+	}))
+	defer gowaServer.Close()
+
+	handler, store := setupTestHandler(t, gowaServer)
+	store.Patients["patient-1"] = &models.Patient{
+		ID:        "patient-1",
+		Name:      "Test Patient",
+		Phone:     "08123456789",
+		CreatedBy: "user-1",
+		Reminders: []*models.Reminder{
+			{ID: "reminder-1", Title: "Test Reminder", DeliveryStatus: models.DeliveryStatusPending},
+			{ID: "reminder-2", Title: "Cancelled Reminder", DeliveryStatus: models.DeliveryStatusCancelled},
+		},
+	}
+	reminder := store.Patients["patient-1"].Reminders[0]
+
+	send := func(reminderID string) *httptest.ResponseRecorder {
+		c, w := setupTestContext("POST", "/api/patients/patient-1/reminders/"+reminderID+"/send", map[string]string{
+			"id":         "patient-1",
+			"reminderId": reminderID,
+		})
+		c.Set("userID", "user-1")
+		c.Set("role", "volunteer")
+		handler.Send(c)
+		return w
+	}
+
+	if w := send("reminder-1"); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if err := reminder.TransitionTo(models.DeliveryStatusRead, models.StatusActorWebhook, "", "GOWA ack msg-123", time.Now()); err != nil {
+		t.Fatalf("Expected sent to read to be legal: %v", err)
+	}
+	var illegal *models.IllegalTransitionError
+	if err := reminder.TransitionTo(models.DeliveryStatusSent, models.StatusActorScheduler, "", "", time.Now()); !errors.As(err, &illegal) {
+		t.Errorf("Expected read to sent to be rejected, got %v", err)
+	}
+
+	t.Run("resend keeps a read reminder read", func(t *testing.T) {
+		if w := send("reminder-1"); w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if reminder.DeliveryStatus != models.DeliveryStatusRead {
+			t.Errorf("Expected status to stay read, got %s", reminder.DeliveryStatus)
+		}
+	})
+
+	t.Run("cancelled reminders cannot be sent", func(t *testing.T) {
+		w := send("reminder-2")
+		if w.Code != http.StatusConflict || !contains(w.Body.String(), "INVALID_STATUS_TRANSITION") {
+			t.Errorf("Expected INVALID_STATUS_TRANSITION, got %d %s", w.Code, w.Body.String())
+		}
+		if models.CanTransition(models.DeliveryStatusCancelled, models.DeliveryStatusDelivered) {
+			t.Error("Expected cancelled to delivered to be illegal")
+		}
+	})
+
+	t.Run("timeline lists every transition with its actor", func(t *testing.T) {
+		c, w := setupTestContext("GET", "/api/reminders/reminder-1/timeline", map[string]string{"id": "reminder-1"})
+		c.Set("userID", "user-1")
+		c.Set("role", "volunteer")
+		handler.GetReminderTimeline(c)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		var resp struct {
+			Data struct {
+				Timeline []models.StatusTransition `json:"timeline"`
+				Attempts []models.DeliveryAttempt  `json:"attempts"`
+			} `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+
+		want := []struct{ from, to, actor string }{
+			{models.DeliveryStatusPending, models.DeliveryStatusSending, models.StatusActorUser},
+			{models.DeliveryStatusSending, models.DeliveryStatusSent, models.StatusActorUser},
+			{models.DeliveryStatusSent, models.DeliveryStatusRead, models.StatusActorWebhook},
+		}
+		if len(resp.Data.Timeline) != len(want) {
+			t.Fatalf("Expected %d transitions, got %+v", len(want), resp.Data.Timeline)
+		}
+		for i, w := range want {
+			got := resp.Data.Timeline[i]
+			if got.From != w.from || got.To != w.to || got.Actor != w.actor || got.At == "" {
+				t.Errorf("Transition %d: expected %s -> %s by %s, got %+v", i, w.from, w.to, w.actor, got)
+			}
+		}
+		if resp.Data.Timeline[0].ActorID != "user-1" || len(resp.Data.Attempts) != 2 {
+			t.Errorf("Expected user ID and both send attempts, got %+v %+v", resp.Data.Timeline[0], resp.Data.Attempts)
+		}
+	})
+
+	t.Run("forbidden for volunteer of another patient", func(t *testing.T) {
+		c, w := setupTestContext("GET", "/api/reminders/reminder-1/timeline", map[string]string{"id": "reminder-1"})
+		c.Set("userID", "user-2")
+		c.Set("role", "volunteer")
+		handler.GetReminderTimeline(c)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
 }
 
 func TestReminderHandler_FormatReminderMessage(t *testing.T) {
@@ -1552,6 +1729,119 @@ func TestReminderHandler_RetryReminder(t *testing.T) {
 			t.Errorf("Expected code 'FORBIDDEN', got '%v'", response["code"])
 		}
 	})
+	t.Run("unverified send fails until an admin forces it", func(t *testing.T) {
+		sends := 0
+		gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/chat/") {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			sends++
+			json.NewEncoder(w).Encode(services.SendMessageResponse{Success: true, MessageID: "msg-forced"})
+		}))
+		defer gowaServer.Close()
+
+		handler, store := setupTestHandler(t, gowaServer)
+		reminder := &models.Reminder{ID: "reminder-1", Title: "Test Reminder", DeliveryStatus: models.DeliveryStatusFailed}
+		reminder.DeliveryAttempts = []models.DeliveryAttempt{{
+			Attempt:        1,
+			IdempotencyKey: services.ReminderIdempotencyKey(reminder),
+			Outcome:        models.AttemptOutcomeAmbiguous,
+			Unconfirmed:    services.MaxUnconfirmedAttempts - 1,
+		}}
+		store.Patients["patient-1"] = &models.Patient{
+			ID:        "patient-1",
+			Name:      "Test Patient",
+			Phone:     "08123456789",
+			CreatedBy: "user-1",
+			Reminders: []*models.Reminder{reminder},
+		}
+
+		retry := func(path, role string) *httptest.ResponseRecorder {
+			c, w := setupTestContext("POST", path, map[string]string{"id": "reminder-1"})
+			c.Set("userID", "user-1")
+			c.Set("role", role)
+			handler.RetryReminder(c)
+			return w
+		}
+
+		w := retry("/api/reminders/reminder-1/retry", "volunteer")
+		if w.Code != http.StatusConflict || !contains(w.Body.String(), "SEND_UNVERIFIED") {
+			t.Fatalf("Expected SEND_UNVERIFIED, got %d %s", w.Code, w.Body.String())
+		}
+		if reminder.DeliveryStatus != models.DeliveryStatusFailed || sends != 0 {
+			t.Errorf("Expected the reminder failed without a send, got %s after %d sends", reminder.DeliveryStatus, sends)
+		}
+
+		if w := retry("/api/reminders/reminder-1/retry?force=true", "volunteer"); w.Code != http.StatusForbidden {
+			t.Errorf("Expected volunteers not to force a resend, got %d", w.Code)
+		}
+
+		w = retry("/api/reminders/reminder-1/retry?force=true", "admin")
+		if w.Code != http.StatusOK || sends != 1 || reminder.GOWAMessageID != "msg-forced" {
+			t.Errorf("Expected the forced resend sent, got %d %s after %d sends", w.Code, w.Body.String(), sends)
+		}
+	})
+
+	t.Run("ambiguous send with tracked links is found in chat history", func(t *testing.T) {
+		var sent []string
+		gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasPrefix(r.URL.Path, "/chat/") {
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"code": "SUCCESS",
+					"results": map[string]interface{}{
+						"data": []map[string]interface{}{{"id": "msg-1", "content": sent[0], "is_from_me": true}},
+					},
+				})
+				return
+			}
+			var req services.SendMessageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			sent = append(sent, req.Message)
+			// Response cut off: the send is ambiguous
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte(`{"su`))
+		}))
+		defer gowaServer.Close()
+
+		handler, store := setupTestHandler(t, gowaServer)
+		enabled := true
+		handler.config.Links = config.LinksConfig{PublicBaseURL: "https://staging.prima.app", ShortLinkBaseURL: "https://go.prima.app", ShortLinks: &enabled}
+		links := models.NewShortLinkStore(nil)
+		handler.SetShortLinkStore(links)
+		handler.contentStore.Videos.Videos["vid-1"] = &models.Video{ID: "vid-1", Title: "Video", YouTubeID: "abc123"}
+		reminder := &models.Reminder{
+			ID:             "reminder-1",
+			Title:          "Olahraga",
+			DeliveryStatus: models.DeliveryStatusFailed,
+			Attachments:    []models.Attachment{{Type: "video", ID: "vid-1", Title: "Video"}},
+		}
+		store.Patients["patient-1"] = &models.Patient{
+			ID:        "patient-1",
+			Name:      "Test Patient",
+			Phone:     "08123456789",
+			CreatedBy: "user-1",
+			Reminders: []*models.Reminder{reminder},
+		}
+
+		for i := 0; i < 2; i++ {
+			reminder.DeliveryStatus = models.DeliveryStatusFailed
+			c, _ := setupTestContext("POST", "/api/reminders/reminder-1/retry", map[string]string{"id": "reminder-1"})
+			c.Set("userID", "user-1")
+			c.Set("role", "volunteer")
+			handler.RetryReminder(c)
+		}
+
+		if len(sent) != 1 || !contains(sent[0], "https://go.prima.app/l/") {
+			t.Fatalf("Expected one send with a tracked link, got %q", sent)
+		}
+		if previous := services.PreviousDeliveryAttempt(reminder, services.ReminderIdempotencyKey(reminder)); previous == nil || previous.Outcome != models.AttemptOutcomeDuplicateSuppressed {
+			t.Errorf("Expected the retry matched in chat history, got %+v", previous)
+		}
+		if len(links.Links) != 1 {
+			t.Errorf("Expected the retry to reuse the tracked link, got %d links", len(links.Links))
+		}
+	})
 }
 
 func TestReminderHandler_Send_QuietHours(t *testing.T) {
@@ -2515,3 +2805,503 @@ func TestReminderHandler_CancelReminder(t *testing.T) {
 		}
 	})
 }
+
+func TestReminderHandler_PreviewReminder(t *testing.T) {
+	decodePreview := func(t *testing.T, w *httptest.ResponseRecorder) ReminderPreview {
+		t.Helper()
+		var response struct {
+			Data ReminderPreview `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return response.Data
+	}
+	hasWarning := func(preview ReminderPreview, code string) bool {
+		for _, w := range preview.Warnings {
+			if w.Code == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("renders saved reminder without sending", func(t *testing.T) {
+		gowaCalled := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gowaCalled = true
+		}))
+		defer server.Close()
+
+		handler, store := setupTestHandler(t, server)
+		handler.contentStore.Articles.Articles["art-1"] = &models.Article{
+			ID:      "art-1",
+			Title:   "Artikel Diabetes",
+			Slug:    "artikel-diabetes",
+			Excerpt: "Ringkasan artikel",
+			Status:  models.ArticleStatusPublished,
+		}
+		store.Patients["patient-1"] = &models.Patient{
+			ID:        "patient-1",
+			Name:      "Budi",
+			Phone:     "08123456789",
+			CreatedBy: "user-1",
+			Reminders: []*models.Reminder{{
+				ID:             "reminder-1",
+				Title:          "Minum obat",
+				DeliveryStatus: models.DeliveryStatusPending,
+				Attachments:    []models.Attachment{{Type: "article", ID: "art-1", Title: "Artikel Diabetes"}},
+			}},
+		}
+
+		c, w := setupTestContext("GET", "/api/patients/patient-1/reminders/reminder-1/preview", map[string]string{
+			"id":         "patient-1",
+			"reminderId": "reminder-1",
+		})
+		c.Set("userID", "user-1")
+		c.Set("role", "volunteer")
+
+		handler.PreviewSavedReminder(c)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		preview := decodePreview(t, w)
+		expected := handler.formatReminderMessage(store.Patients["patient-1"].Reminders[0], store.Patients["patient-1"])
+		if preview.Message != expected {
+			t.Errorf("Preview message differs from sent message:\n%s\n---\n%s", preview.Message, expected)
+		}
+		if preview.CharacterCount != len([]rune(expected)) {
+			t.Errorf("Expected character count %d, got %d", len([]rune(expected)), preview.CharacterCount)
+		}
+		if preview.Phone != "628123456789" {
+			t.Errorf("Expected phone 628123456789, got %s", preview.Phone)
+		}
+		if len(preview.Attachments) != 1 || preview.Attachments[0].URL != "https://prima.app/artikel/artikel-diabetes" {
+			t.Errorf("Unexpected attachments: %+v", preview.Attachments)
+		}
+		if preview.EffectiveSendAt == "" || preview.QuietHours {
+			t.Errorf("Expected immediate send, got %s (quiet hours %v)", preview.EffectiveSendAt, preview.QuietHours)
+		}
+		if len(preview.Warnings) != 0 {
+			t.Errorf("Expected no warnings, got %+v", preview.Warnings)
+		}
+		if gowaCalled {
+			t.Error("Preview must not call GOWA")
+		}
+		if store.Patients["patient-1"].Reminders[0].DeliveryStatus != models.DeliveryStatusPending {
+			t.Error("Preview must not change delivery status")
+		}
+	})
+
+	t.Run("unsaved payload reports warnings", func(t *testing.T) {
+		handler, store := setupTestHandler(t, nil)
+		handler.contentStore.Articles.Articles["art-draft"] = &models.Article{
+			ID:     "art-draft",
+			Title:  "Draft",
+			Slug:   "draft",
+			Status: models.ArticleStatusDraft,
+		}
+		store.Patients["patient-1"] = &models.Patient{
+			ID:        "patient-1",
+			Name:      "Budi",
+			Phone:     "12345",
+			CreatedBy: "user-1",
+		}
+
+		body, _ := json.Marshal(PreviewReminderRequest{
+			Title: "Kontrol",
+			Attachments: []models.Attachment{
+				{Type: "article", ID: "art-draft", Title: "Draft"},
+				{Type: "video", ID: "vid-missing", Title: "Hilang"},
+			},
+		})
+		c, w := setupTestContext("POST", "/api/patients/patient-1/reminders/preview", map[string]string{"id": "patient-1"})
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Set("userID", "user-1")
+		c.Set("role", "volunteer")
+
+		handler.PreviewReminder(c)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		preview := decodePreview(t, w)
+		for _, code := range []string{"INVALID_PHONE", "CONTENT_UNPUBLISHED", "CONTENT_NOT_FOUND"} {
+			if !hasWarning(preview, code) {
+				t.Errorf("Expected warning %s, got %+v", code, preview.Warnings)
+			}
+		}
+		if !contains(preview.Message, "Kontrol") {
+			t.Errorf("Expected title in message, got %s", preview.Message)
+		}
+		if len(store.Patients["patient-1"].Reminders) != 0 {
+			t.Error("Preview must not save the reminder")
+		}
+	})
+
+	t.Run("tracked links use placeholders without creating tokens", func(t *testing.T) {
+		handler, store := setupTestHandler(t, nil)
+		enabled := true
+		handler.config.Links = config.LinksConfig{PublicBaseURL: "https://staging.prima.app", ShortLinkBaseURL: "https://go.prima.app", ShortLinks: &enabled}
+		links := models.NewShortLinkStore(nil)
+		handler.SetShortLinkStore(links)
+		handler.contentStore.Videos.Videos["vid-1"] = &models.Video{ID: "vid-1", Title: "Video", YouTubeID: "abc123"}
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789"}
+
+		reminder := &models.Reminder{Title: "Olahraga", Attachments: []models.Attachment{{Type: "video", ID: "vid-1", Title: "Video"}}}
+		preview := handler.previewReminder(reminder, store.Patients["patient-1"], time.Now())
+
+		if len(links.Links) != 0 {
+			t.Errorf("Preview created %d short links", len(links.Links))
+		}
+		if !preview.TrackedLinks || !contains(preview.Message, utils.ShortLinkPlaceholder("https://go.prima.app")) {
+			t.Errorf("Expected placeholder short link in message, got %s", preview.Message)
+		}
+		if preview.Attachments[0].URL != "https://youtube.com/watch?v=abc123" {
+			t.Errorf("Expected resolved destination URL, got %s", preview.Attachments[0].URL)
+		}
+	})
+
+	t.Run("caption limit counts characters", func(t *testing.T) {
+		handler, store := setupTestHandler(t, nil)
+		enabled := true
+		handler.config.GOWA.MediaMessages = &enabled
+		wd, _ := os.Getwd()
+		if err := os.Chdir(t.TempDir()); err != nil {
+			t.Fatalf("Failed to chdir: %v", err)
+		}
+		defer os.Chdir(wd)
+		os.MkdirAll(utils.UploadsDir, 0755)
+		os.WriteFile(filepath.Join(utils.UploadsDir, "hero_1x1.jpg"), []byte("jpg"), 0644)
+		handler.contentStore.Articles.Articles["art-1"] = &models.Article{
+			ID:         "art-1",
+			Title:      "Artikel",
+			Slug:       "artikel",
+			HeroImages: models.HeroImages{Hero1x1: "/uploads/hero_1x1.jpg"},
+			Status:     models.ArticleStatusPublished,
+		}
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789"}
+		attachments := []models.Attachment{{Type: "article", ID: "art-1", Title: "Artikel"}}
+
+		// Under the limit in characters, over it in bytes
+		preview := handler.previewReminder(&models.Reminder{Title: strings.Repeat("💊", 300), Attachments: attachments}, store.Patients["patient-1"], time.Now())
+		if len(preview.Message) <= services.MaxImageCaptionLength || preview.CharacterCount > services.MaxImageCaptionLength {
+			t.Fatalf("Expected a message under the limit only in characters, got %d bytes, %d characters", len(preview.Message), preview.CharacterCount)
+		}
+		if preview.DeliveryType != "image" || hasWarning(preview, "CAPTION_TOO_LONG") {
+			t.Errorf("Expected an image send without warning, got %s %+v", preview.DeliveryType, preview.Warnings)
+		}
+
+		preview = handler.previewReminder(&models.Reminder{Title: strings.Repeat("a", services.MaxImageCaptionLength), Attachments: attachments}, store.Patients["patient-1"], time.Now())
+		if preview.DeliveryType != "text" || !hasWarning(preview, "CAPTION_TOO_LONG") {
+			t.Errorf("Expected a text send with CAPTION_TOO_LONG, got %s %+v", preview.DeliveryType, preview.Warnings)
+		}
+	})
+
+	t.Run("quiet hours defer effective send time", func(t *testing.T) {
+		startHour, endHour := 21, 6
+		handler, store := setupTestHandlerWithQuietHours(t, nil, config.QuietHoursConfig{StartHour: &startHour, EndHour: &endHour, Timezone: "WIB"})
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789"}
+
+		// 23:00 WIB
+		now := time.Date(2025, 1, 10, 16, 0, 0, 0, time.UTC)
+		preview := handler.previewReminder(&models.Reminder{Title: "Minum obat"}, store.Patients["patient-1"], now)
+
+		if !preview.QuietHours || !hasWarning(preview, "QUIET_HOURS") {
+			t.Errorf("Expected quiet hours warning, got %+v", preview.Warnings)
+		}
+		if preview.EffectiveSendAt != "2025-01-10T23:00:00Z" {
+			t.Errorf("Expected effective send at 06:00 WIB, got %s", preview.EffectiveSendAt)
+		}
+	})
+
+	t.Run("blackouts defer effective send time in the patient region", func(t *testing.T) {
+		startHour, endHour := 21, 6
+		handler, store := setupTestHandlerWithQuietHours(t, nil, config.QuietHoursConfig{StartHour: &startHour, EndHour: &endHour, Timezone: "WIB"})
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", Timezone: "WITA"}
+		blackouts := models.NewBlackoutStore(func() {})
+		blackouts.Blackouts["maghrib"] = &models.Blackout{
+			ID: "maghrib", Name: "Maghrib", Type: models.BlackoutTypeRecurring, StartTime: "18:00", EndTime: "18:30",
+			Region: "WITA", ExemptPriorities: []string{"high"}, Enabled: true,
+		}
+		handler.SetBlackoutStore(blackouts)
+
+		// 18:10 WITA
+		now := time.Date(2025, 1, 10, 10, 10, 0, 0, time.UTC)
+		preview := handler.previewReminder(&models.Reminder{Title: "Minum obat"}, store.Patients["patient-1"], now)
+		if preview.Blackout == nil || preview.Blackout.ID != "maghrib" || !hasWarning(preview, "BLACKOUT") {
+			t.Errorf("Expected Maghrib blackout warning, got %+v", preview)
+		}
+		if preview.QuietHours || preview.EffectiveSendAt != "2025-01-10T10:30:00Z" {
+			t.Errorf("Expected effective send at 18:30 WITA, got %s", preview.EffectiveSendAt)
+		}
+
+		urgent := handler.previewReminder(&models.Reminder{Title: "Minum obat", Priority: "high"}, store.Patients["patient-1"], now)
+		if urgent.Blackout != nil || urgent.EffectiveSendAt != "2025-01-10T10:10:00Z" {
+			t.Errorf("Expected exempt priority to send now, got %+v", urgent)
+		}
+
+		store.Patients["patient-1"].Timezone = "WIB"
+		if other := handler.previewReminder(&models.Reminder{Title: "Minum obat"}, store.Patients["patient-1"], now); other.Blackout != nil {
+			t.Errorf("Expected WITA blackout not to apply to a WIB patient")
+		}
+	})
+
+	t.Run("effective send time starts at a future due date", func(t *testing.T) {
+		handler, store := setupTestHandler(t, nil)
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", Timezone: "WITA"}
+		blackouts := models.NewBlackoutStore(func() {})
+		blackouts.Blackouts["maghrib"] = &models.Blackout{
+			ID: "maghrib", Name: "Maghrib", Type: models.BlackoutTypeRecurring, StartTime: "18:00", EndTime: "18:30",
+			Region: "WITA", Enabled: true,
+		}
+		handler.SetBlackoutStore(blackouts)
+
+		// 10:00 WITA, due at 18:15 WITA
+		now := time.Date(2025, 1, 10, 2, 0, 0, 0, time.UTC)
+		reminder := &models.Reminder{Title: "Minum obat", DueDate: "2025-01-10T18:15:00+08:00"}
+		preview := handler.previewReminder(reminder, store.Patients["patient-1"], now)
+		if preview.Blackout == nil || preview.Blackout.ID != "maghrib" || preview.EffectiveSendAt != "2025-01-10T10:30:00Z" {
+			t.Errorf("Expected the due date held back until 18:30 WITA, got %s (blackout %+v)", preview.EffectiveSendAt, preview.Blackout)
+		}
+
+		// A due date that has passed is sent now
+		reminder.DueDate = "2025-01-09T18:15:00+08:00"
+		if overdue := handler.previewReminder(reminder, store.Patients["patient-1"], now); overdue.Blackout != nil || overdue.EffectiveSendAt != "2025-01-10T02:00:00Z" {
+			t.Errorf("Expected an overdue reminder sent now, got %s", overdue.EffectiveSendAt)
+		}
+	})
+
+	t.Run("quiet hours deferral matches the scheduled send", func(t *testing.T) {
+		// Quiet hours covering the current hour
+		hour := time.Now().In(utils.WIBLocation).Hour()
+		startHour, endHour := hour, (hour+1)%24
+		handler, store := setupTestHandlerWithQuietHours(t, nil, config.QuietHoursConfig{StartHour: &startHour, EndHour: &endHour, Timezone: "WIB"})
+		reminder := &models.Reminder{
+			ID:             "reminder-1",
+			Title:          "Minum obat",
+			DueDate:        time.Now().Add(-time.Minute).Format(models.DueDateLayout),
+			DeliveryStatus: models.DeliveryStatusPending,
+		}
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", Reminders: []*models.Reminder{reminder}}
+
+		preview := handler.previewReminder(reminder, store.Patients["patient-1"], time.Now())
+		if !preview.QuietHours {
+			t.Fatalf("Expected the preview deferred by quiet hours, got %+v", preview)
+		}
+
+		scheduler := services.NewReminderScheduler(store, nil, handler.config, nil)
+		scheduler.SetInterval(10 * time.Millisecond)
+		scheduler.Start()
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			store.RLock()
+			status := reminder.DeliveryStatus
+			store.RUnlock()
+			if status != models.DeliveryStatusPending || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		scheduler.Stop()
+
+		if reminder.DeliveryStatus != models.DeliveryStatusScheduled || reminder.ScheduledDeliveryAt != preview.EffectiveSendAt {
+			t.Errorf("Expected the reminder scheduled for %s as previewed, got %s at %q", preview.EffectiveSendAt, reminder.DeliveryStatus, reminder.ScheduledDeliveryAt)
+		}
+	})
+
+	t.Run("forbidden for volunteer accessing other user patient", func(t *testing.T) {
+		handler, store := setupTestHandler(t, nil)
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", CreatedBy: "user-2"}
+
+		body, _ := json.Marshal(PreviewReminderRequest{Title: "Kontrol"})
+		c, w := setupTestContext("POST", "/api/patients/patient-1/reminders/preview", map[string]string{"id": "patient-1"})
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Set("userID", "user-1")
+		c.Set("role", "volunteer")
+
+		handler.PreviewReminder(c)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+}
+
+func TestReminderHandler_Create_FromTemplate(t *testing.T) {
+	setup := func(t *testing.T) (*ReminderHandler, *models.PatientStore, *models.ReminderTemplateStore) {
+		handler, store := setupTestHandler(t, nil)
+		handler.contentStore.Articles.Articles["art-1"] = &models.Article{ID: "art-1", Title: "Artikel TB", Slug: "artikel-tb"}
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", CreatedBy: "user-1"}
+
+		templates := models.NewReminderTemplateStore(func() {})
+		templates.Templates["rt-1"] = &models.ReminderTemplate{
+			ID:          "rt-1",
+			Name:        "TB harian",
+			Category:    "TB",
+			Title:       "Minum obat TB",
+			Description: "Setelah sarapan",
+			Priority:    "high",
+			Recurrence:  models.Recurrence{Frequency: "daily", Interval: 1},
+			Attachments: []models.Attachment{{Type: "article", ID: "art-1", Title: "Artikel TB"}},
+			Escalation:  &models.EscalationPolicy{AfterHours: 6},
+		}
+		handler.SetReminderTemplateStore(templates)
+		return handler, store, templates
+	}
+	create := func(handler *ReminderHandler, body string) *httptest.ResponseRecorder {
+		c, w := setupTestContext("POST", "/api/patients/patient-1/reminders", map[string]string{"id": "patient-1"})
+		c.Request.Body = io.NopCloser(strings.NewReader(body))
+		c.Set("userID", "user-1")
+		c.Set("role", "volunteer")
+		handler.Create(c)
+		return w
+	}
+
+	t.Run("instantiates template with overrides", func(t *testing.T) {
+		handler, store, templates := setup(t)
+
+		w := create(handler, `{"template_id":"rt-1","description":"Setelah makan malam","dueDate":"2025-01-10T19:00"}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+
+		reminder := store.Patients["patient-1"].Reminders[0]
+		if reminder.Title != "Minum obat TB" || reminder.Description != "Setelah makan malam" || reminder.Priority != "high" {
+			t.Errorf("Unexpected fields: %+v", reminder)
+		}
+		if reminder.Recurrence.Frequency != "daily" || len(reminder.Attachments) != 1 || reminder.TemplateID != "rt-1" {
+			t.Errorf("Expected template recurrence, attachments and id, got %+v", reminder)
+		}
+		if reminder.Escalation == nil || reminder.Escalation.AfterHours != 6 {
+			t.Errorf("Expected default escalation from template, got %+v", reminder.Escalation)
+		}
+		if templates.Templates["rt-1"].UsageCount != 1 {
+			t.Errorf("Expected usage count 1, got %d", templates.Templates["rt-1"].UsageCount)
+		}
+
+		// Changing the reminder must not change the template
+		reminder.Escalation.AfterHours = 1
+		if templates.Templates["rt-1"].Escalation.AfterHours != 6 {
+			t.Error("Reminder shares escalation with its template")
+		}
+	})
+
+	t.Run("empty attachments override template", func(t *testing.T) {
+		handler, store, _ := setup(t)
+
+		w := create(handler, `{"template_id":"rt-1","attachments":[]}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if n := len(store.Patients["patient-1"].Reminders[0].Attachments); n != 0 {
+			t.Errorf("Expected no attachments, got %d", n)
+		}
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		handler, store, _ := setup(t)
+
+		w := create(handler, `{"template_id":"missing"}`)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "REMINDER_TEMPLATE_NOT_FOUND") {
+			t.Errorf("Expected REMINDER_TEMPLATE_NOT_FOUND, got %d %s", w.Code, w.Body.String())
+		}
+		if len(store.Patients["patient-1"].Reminders) != 0 {
+			t.Error("Expected no reminder created")
+		}
+	})
+
+	t.Run("title still required without template", func(t *testing.T) {
+		handler, _, _ := setup(t)
+
+		if w := create(handler, `{"description":"tanpa judul"}`); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
+
+func TestReminderHandler_Send_Blackout(t *testing.T) {
+	gowaCalled := false
+	gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gowaCalled = true
+		json.NewEncoder(w).Encode(services.SendMessageResponse{Success: true, MessageID: "msg-123"})
+	}))
+	defer gowaServer.Close()
+
+	// Quiet hours disabled, so only the blackout holds the reminder back
+	noQuietHours := 0
+	handler, store := setupTestHandlerWithQuietHours(t, gowaServer, config.QuietHoursConfig{StartHour: &noQuietHours, EndHour: &noQuietHours, Timezone: "WIB"})
+	today := time.Now().In(utils.WIBLocation).Format(models.BlackoutDateLayout)
+	blackouts := models.NewBlackoutStore(func() {})
+	blackouts.Blackouts["lebaran"] = &models.Blackout{ID: "lebaran", Name: "Idul Fitri", Type: models.BlackoutTypeDates, StartDate: today, EndDate: today, Enabled: true}
+	handler.SetBlackoutStore(blackouts)
+
+	store.Patients["patient-1"] = &models.Patient{
+		ID:        "patient-1",
+		Name:      "Test Patient",
+		Phone:     "08123456789",
+		CreatedBy: "user-1",
+		Reminders: []*models.Reminder{{ID: "reminder-1", Title: "Kontrol", DeliveryStatus: models.DeliveryStatusPending}},
+	}
+
+	c, w := setupTestContext("POST", "/api/patients/patient-1/reminders/reminder-1/send", map[string]string{
+		"id":         "patient-1",
+		"reminderId": "reminder-1",
+	})
+	c.Set("userID", "user-1")
+	c.Set("role", "volunteer")
+	handler.Send(c)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if w.Code != http.StatusOK || response["scheduled"] != true || response["blackout_id"] != "lebaran" {
+		t.Fatalf("Expected reminder held back by the blackout, got %d: %s", w.Code, w.Body.String())
+	}
+	if gowaCalled {
+		t.Error("Expected GOWA not to be called during a blackout")
+	}
+	reminder := store.Patients["patient-1"].Reminders[0]
+	tomorrow := time.Now().In(utils.WIBLocation).AddDate(0, 0, 1).Format(models.BlackoutDateLayout)
+	want, _ := time.ParseInLocation(models.BlackoutDateLayout, tomorrow, utils.WIBLocation)
+	if reminder.DeliveryStatus != models.DeliveryStatusScheduled || reminder.ScheduledDeliveryAt != want.UTC().Format(time.RFC3339) {
+		t.Errorf("Expected reminder scheduled at %s, got %s (%s)", want.UTC().Format(time.RFC3339), reminder.ScheduledDeliveryAt, reminder.DeliveryStatus)
+	}
+}
+
+func TestReminderHandler_Create_Priority(t *testing.T) {
+	create := func(t *testing.T, body string) (*httptest.ResponseRecorder, *models.PatientStore) {
+		handler, store := setupTestHandler(t, nil)
+		handler.config.Priorities = config.PrioritiesConfig{Default: "medium", Levels: []config.PriorityLevel{
+			{Name: "high"}, {Name: "medium"}, {Name: "low"},
+		}}
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Name: "Budi", Phone: "08123456789", CreatedBy: "user-1"}
+
+		c, w := setupTestContext("POST", "/api/patients/patient-1/reminders", map[string]string{"id": "patient-1"})
+		c.Request.Body = io.NopCloser(strings.NewReader(body))
+		c.Set("userID", "user-1")
+		c.Set("role", "volunteer")
+		handler.Create(c)
+		return w, store
+	}
+
+	t.Run("defaults to the configured priority", func(t *testing.T) {
+		w, store := create(t, `{"title":"Minum obat"}`)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if p := store.Patients["patient-1"].Reminders[0].Priority; p != "medium" {
+			t.Errorf("Expected default priority medium, got %q", p)
+		}
+	})
+
+	t.Run("rejects unknown priority", func(t *testing.T) {
+		w, store := create(t, `{"title":"Minum obat","priority":"urgent"}`)
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "INVALID_PRIORITY") {
+			t.Errorf("Expected INVALID_PRIORITY, got %d %s", w.Code, w.Body.String())
+		}
+		if len(store.Patients["patient-1"].Reminders) != 0 {
+			t.Error("Expected no reminder created")
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// maxClickUserAgentLength bounds the stored user agent per click
+const maxClickUserAgentLength = 200
+
+// clickFlushInterval is how often recorded clicks are written to disk. Saving rewrites
+// every short link, so clicks are batched instead of saved per request.
+const clickFlushInterval = 10 * time.Second
+
+// linkPreviewAgents are user agent fragments of crawlers that fetch links to build previews.
+// WhatsApp fetches every link in a message when it is delivered, so these must not count as clicks.
+var linkPreviewAgents = []string{
+	"whatsapp",
+	"facebookexternalhit",
+	"facebot",
+	"telegrambot",
+	"slackbot",
+	"twitterbot",
+	"bot",
+	"crawler",
+	"spider",
+}
+
+// ShortLinkHandler serves the public redirect endpoint for tracked content links
+type ShortLinkHandler struct {
+	store         *models.ShortLinkStore
+	logger        *slog.Logger
+	clicksPending atomic.Bool // Clicks recorded since the last save
+	stopCh        chan struct{}
+	wg            sync.WaitGroup
+}
+
+// NewShortLinkHandler creates a new short link handler
+func NewShortLinkHandler(store *models.ShortLinkStore, logger *slog.Logger) *ShortLinkHandler {
+	return &ShortLinkHandler{
+		store:  store,
+		logger: logger,
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins saving recorded clicks every clickFlushInterval
+func (h *ShortLinkHandler) Start() {
+	h.wg.Add(1)
+	go h.run()
+}
+
+// Stop saves any pending clicks and stops the background saves
+func (h *ShortLinkHandler) Stop() {
+	close(h.stopCh)
+	h.wg.Wait()
+}
+
+// run flushes recorded clicks on every tick and once more when stopped
+func (h *ShortLinkHandler) run() {
+	defer h.wg.Done()
+
+	ticker := time.NewTicker(clickFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.FlushClicks()
+		case <-h.stopCh:
+			h.FlushClicks()
+			return
+		}
+	}
+}
+
+// FlushClicks saves the short links if clicks were recorded since the last save
+func (h *ShortLinkHandler) FlushClicks() {
+	if h.clicksPending.Swap(false) {
+		h.store.SaveData()
+	}
+}
+
+// isLinkPreviewAgent reports whether the user agent belongs to a link preview crawler
+func isLinkPreviewAgent(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, agent := range linkPreviewAgents {
+		if strings.Contains(ua, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redirect handles GET /l/:token - records the click and redirects to the content
+func (h *ShortLinkHandler) Redirect(c *gin.Context) {
+	token := c.Param("token")
+	userAgent := c.Request.UserAgent()
+	preview := isLinkPreviewAgent(userAgent)
+
+	h.store.Mu.Lock()
+	link, exists := h.store.Links[token]
+	if !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "link not found", "code": "LINK_NOT_FOUND"})
+		return
+	}
+	target := link.TargetURL
+	if !preview {
+		now := time.Now().UTC().Format(time.RFC3339)
+		if len(userAgent) > maxClickUserAgentLength {
+			userAgent = userAgent[:maxClickUserAgentLength]
+		}
+		link.ClickCount++
+		if link.FirstClickedAt == "" {
+			link.FirstClickedAt = now
+		}
+		link.LastClickedAt = now
+		link.Clicks = append(link.Clicks, models.LinkClick{At: now, UserAgent: userAgent})
+		if len(link.Clicks) > models.MaxLinkClicks {
+			link.Clicks = link.Clicks[len(link.Clicks)-models.MaxLinkClicks:]
+		}
+	}
+	patientID, reminderID, contentID := link.PatientID, link.ReminderID, link.ContentID
+	h.store.Mu.Unlock()
+
+	if !preview {
+		h.clicksPending.Store(true)
+		if h.logger != nil {
+			h.logger.Info("Content link clicked",
+				"token", token,
+				"patient_id", patientID,
+				"reminder_id", reminderID,
+				"content_id", contentID,
+			)
+		}
+	}
+
+	c.Redirect(http.StatusFound, target)
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func setupShortLinkRouter(t *testing.T) (*gin.Engine, *models.ShortLinkStore) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := models.NewShortLinkStore(func() {})
+	store.Links["abc123"] = &models.ShortLink{
+		Token:       "abc123",
+		TargetURL:   "https://prima.app/artikel/art-1",
+		PatientID:   "patient-1",
+		ReminderID:  "reminder-1",
+		ContentType: "article",
+		ContentID:   "art-1",
+	}
+
+	handler := NewShortLinkHandler(store, nil)
+	router := gin.New()
+	router.GET("/l/:token", handler.Redirect)
+	return router, store
+}
+
+func TestShortLinkHandler_Redirect(t *testing.T) {
+	t.Run("redirects and records click", func(t *testing.T) {
+		router, store := setupShortLinkRouter(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/l/abc123", nil)
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 13)")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusFound)
+		}
+		if loc := w.Header().Get("Location"); loc != "https://prima.app/artikel/art-1" {
+			t.Errorf("Location = %q", loc)
+		}
+
+		link := store.Links["abc123"]
+		if link.ClickCount != 1 {
+			t.Errorf("ClickCount = %d, want 1", link.ClickCount)
+		}
+		if link.FirstClickedAt == "" || link.LastClickedAt == "" {
+			t.Error("expected click timestamps to be set")
+		}
+		if len(link.Clicks) != 1 || link.Clicks[0].UserAgent != "Mozilla/5.0 (Linux; Android 13)" {
+			t.Errorf("unexpected clicks: %+v", link.Clicks)
+		}
+	})
+
+	t.Run("link preview crawler is not counted", func(t *testing.T) {
+		router, store := setupShortLinkRouter(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/l/abc123", nil)
+		req.Header.Set("User-Agent", "WhatsApp/2.23.20.0 A")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusFound {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusFound)
+		}
+		if store.Links["abc123"].ClickCount != 0 {
+			t.Errorf("ClickCount = %d, want 0", store.Links["abc123"].ClickCount)
+		}
+	})
+
+	t.Run("click history is capped", func(t *testing.T) {
+		router, store := setupShortLinkRouter(t)
+
+		for i := 0; i < models.MaxLinkClicks+5; i++ {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/l/abc123", nil))
+		}
+
+		link := store.Links["abc123"]
+		if link.ClickCount != models.MaxLinkClicks+5 {
+			t.Errorf("ClickCount = %d, want %d", link.ClickCount, models.MaxLinkClicks+5)
+		}
+		if len(link.Clicks) != models.MaxLinkClicks {
+			t.Errorf("len(Clicks) = %d, want %d", len(link.Clicks), models.MaxLinkClicks)
+		}
+	})
+
+	t.Run("clicks are saved in batches", func(t *testing.T) {
+		router, store := setupShortLinkRouter(t)
+		saves := 0
+		store.SaveFunc = func() { saves++ }
+		handler := NewShortLinkHandler(store, nil)
+		router.GET("/batched/:token", handler.Redirect)
+
+		for i := 0; i < 3; i++ {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/batched/abc123", nil))
+		}
+		if saves != 0 {
+			t.Fatalf("saves = %d before flush, want 0", saves)
+		}
+
+		handler.FlushClicks()
+		handler.FlushClicks()
+		if saves != 1 {
+			t.Errorf("saves = %d, want 1 for all pending clicks", saves)
+		}
+	})
+
+	t.Run("unknown token returns 404", func(t *testing.T) {
+		router, _ := setupShortLinkRouter(t)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/l/missing", nil))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNotFound)
+		}
+	})
+}
@@ -2,152 +2,548 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/services"
 	"github.com/gin-gonic/gin"
 )
 
 // SSEHandler handles Server-Sent Events for real-time delivery status updates
 type SSEHandler struct {
-	clients  map[chan SSEEvent]string // channel -> user_id
+	clients  map[chan SSEEvent]*sseClient
 	mu       sync.RWMutex
 	logger   *slog.Logger
 	stopCh   chan struct{} // Signal to stop all SSE connections
 	isClosed bool          // Track if handler is closed
+
+	// Recent events kept for Last-Event-ID replay, oldest first. IDs are consecutive.
+	lastID     uint64
+	buffer     []SSEEvent
+	bufferSize int
+	saveFunc   func() // Persists the buffer; nil keeps it in memory only
+
+	// Per-connection limits
+	heartbeatInterval time.Duration
+	clientBufferSize  int
+	slowClientTimeout time.Duration
+	maxConnsPerUser   int
+
+	// Single-use connection tickets, by ticket
+	tickets    map[string]sseTicket
+	ticketTTL  time.Duration
+	userLookup func(userID string) (role string, ok bool)
+
+	// Counters since startup, for the health endpoint
+	droppedEvents  uint64
+	evictedClients uint64
+	rejectedConns  uint64
+}
+
+// SSE topics clients can subscribe to with ?topics=a,b (all topics if omitted)
+const (
+	SSETopicDelivery       = "delivery"        // delivery.status.updated, delivery.failed
+	SSETopicReminder       = "reminder"        // reminder.escalated
+	SSETopicCircuitBreaker = "circuit_breaker" // circuit_breaker.state_changed (admins)
+	SSETopicCampaign       = "campaign"        // campaign.progress (admins)
+)
+
+// sseTopics lists the valid SSE topics
+var sseTopics = map[string]bool{
+	SSETopicDelivery:       true,
+	SSETopicReminder:       true,
+	SSETopicCircuitBreaker: true,
+	SSETopicCampaign:       true,
+}
+
+// sseClient identifies the user behind an SSE connection and the topics it subscribed to
+type sseClient struct {
+	userID string
+	role   string
+	topics map[string]bool // nil subscribes to every topic
+
+	connectedAt time.Time
+	dropped     int           // Events dropped because the client's queue was full
+	fullSince   time.Time     // When the queue filled up; zero while it has room
+	closed      chan struct{} // Closed when the server disconnects the client
 }
 
-// SSEEvent represents an event to be sent via SSE
+// isAdmin reports whether the client may receive admin-only events
+func (c sseClient) isAdmin() bool {
+	return c.role == "admin" || c.role == "superadmin"
+}
+
+// wants reports whether the event is for this client: it must match the client's topics,
+// admin-only events go to admins, and patient events go to admins and the patient's volunteer
+func (c sseClient) wants(event SSEEvent) bool {
+	if c.topics != nil && !c.topics[event.Topic] {
+		return false
+	}
+	if event.AdminOnly || event.PatientID != "" {
+		return c.isAdmin() || (!event.AdminOnly && c.userID == event.VolunteerID)
+	}
+	return true
+}
+
+// SSEEvent represents an event to be sent via SSE. Clients only receive ID, Event and
+// Data; the remaining fields route the event and are kept for replay.
 type SSEEvent struct {
-	Event string      `json:"-"`
-	Data  interface{} `json:"data"`
+	ID    uint64      `json:"id"` // Assigned on broadcast
+	Event string      `json:"event"`
+	Data  interface{} `json:"data"` // Marshaled to json.RawMessage on broadcast
+
+	Topic       string `json:"topic"`                  // SSETopic*
+	PatientID   string `json:"patient_id,omitempty"`   // Owning patient, empty for events not about a patient
+	VolunteerID string `json:"volunteer_id,omitempty"` // Volunteer who owns the patient
+	AdminOnly   bool   `json:"admin_only,omitempty"`
+}
+
+// sseEventReset tells a client it missed more events than the replay buffer holds and
+// must refetch its state
+const sseEventReset = "reset"
+
+// SSEStats reports SSE connections for the health endpoint
+type SSEStats struct {
+	Connections         int            `json:"connections"`
+	Users               []SSEUserStats `json:"users"`
+	DroppedEvents       uint64         `json:"dropped_events"`       // Since startup
+	EvictedClients      uint64         `json:"evicted_clients"`      // Slow clients disconnected since startup
+	RejectedConnections uint64         `json:"rejected_connections"` // Over the per-user limit since startup
+	LastEventID         uint64         `json:"last_event_id"`
+}
+
+// SSEUserStats reports one user's open SSE connections
+type SSEUserStats struct {
+	UserID         string `json:"user_id"`
+	Role           string `json:"role"`
+	Connections    int    `json:"connections"`
+	QueuedEvents   int    `json:"queued_events"`   // Waiting to be written, across the user's connections
+	DroppedEvents  int    `json:"dropped_events"`  // Dropped on the user's open connections
+	ConnectedSince string `json:"connected_since"` // Oldest open connection
+}
+
+// parseSSETopics parses a comma-separated topic list; empty means every topic
+func parseSSETopics(value string) (map[string]bool, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	topics := make(map[string]bool)
+	for _, topic := range strings.Split(value, ",") {
+		topic = strings.TrimSpace(topic)
+		if !sseTopics[topic] {
+			return nil, fmt.Errorf("unknown SSE topic %q", topic)
+		}
+		topics[topic] = true
+	}
+	return topics, nil
+}
+
+// parseLastEventID reads the ID of the last event a client saw, from the Last-Event-ID
+// header browsers send on reconnect or the last_event_id query parameter
+func parseLastEventID(c *gin.Context) (uint64, bool) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		// An unreadable ID cannot be resumed from; treat it as out of range
+		return 0, true
+	}
+	return id, true
+}
+
+// writeSSEEvent writes one event with its ID and flushes it
+func writeSSEEvent(w gin.ResponseWriter, id uint64, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, payload); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
+
+// setSSEHeaders sets the response headers for an event stream
+func setSSEHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
 }
 
 // NewSSEHandler creates a new SSE handler
 func NewSSEHandler(cfg *config.Config, logger *slog.Logger) *SSEHandler {
-	return &SSEHandler{
-		clients:  make(map[chan SSEEvent]string),
+	h := &SSEHandler{
+		clients:  make(map[chan SSEEvent]*sseClient),
 		logger:   logger,
 		stopCh:   make(chan struct{}),
 		isClosed: false,
+		// Start IDs from the clock so IDs from before a restart never look resumable
+		lastID: uint64(time.Now().UnixMicro()),
+
+		bufferSize:        cfg.SSE.ReplayBufferSize,
+		heartbeatInterval: cfg.SSE.HeartbeatInterval,
+		clientBufferSize:  cfg.SSE.ClientBufferSize,
+		slowClientTimeout: cfg.SSE.SlowClientTimeout,
+		maxConnsPerUser:   cfg.SSE.MaxConnectionsPerUser,
+		tickets:           make(map[string]sseTicket),
+		ticketTTL:         cfg.SSE.TicketTTL,
+	}
+	// Configs built without applyDefaults, e.g. in tests
+	if h.bufferSize <= 0 {
+		h.bufferSize = 1000
 	}
+	if h.heartbeatInterval <= 0 {
+		h.heartbeatInterval = 15 * time.Second
+	}
+	if h.clientBufferSize <= 0 {
+		h.clientBufferSize = 64
+	}
+	if h.slowClientTimeout <= 0 {
+		h.slowClientTimeout = 30 * time.Second
+	}
+	if h.maxConnsPerUser <= 0 {
+		h.maxConnsPerUser = 5
+	}
+	if h.ticketTTL <= 0 {
+		h.ticketTTL = 30 * time.Second
+	}
+	return h
+}
+
+// SetPersistence sets the function that saves the replay buffer after each event
+func (h *SSEHandler) SetPersistence(saveFunc func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.saveFunc = saveFunc
+}
+
+// Events returns a copy of the replay buffer, oldest first
+func (h *SSEHandler) Events() []SSEEvent {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	events := make([]SSEEvent, len(h.buffer))
+	copy(events, h.buffer)
+	return events
+}
+
+// RestoreEvents loads a saved replay buffer so clients can resume across a restart.
+// Events must be oldest first with consecutive IDs.
+func (h *SSEHandler) RestoreEvents(events []SSEEvent) {
+	if len(events) == 0 {
+		return
+	}
+	if len(events) > h.bufferSize {
+		events = events[len(events)-h.bufferSize:]
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.buffer = append([]SSEEvent(nil), events...)
+	h.lastID = events[len(events)-1].ID
+}
+
+// LastEventID returns the ID of the most recent event
+func (h *SSEHandler) LastEventID() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lastID
 }
 
-// HandleDeliveryStatusSSE handles SSE connections for delivery status updates
+// eventsSince returns the buffered events after lastID that the client wants. ok is
+// false when the client missed events no longer in the buffer, or sent an ID this
+// server never issued. The caller must hold h.mu.
+func (h *SSEHandler) eventsSince(lastID uint64, client *sseClient) (events []SSEEvent, ok bool) {
+	oldest := h.lastID - uint64(len(h.buffer)) + 1
+	if lastID+1 < oldest || lastID > h.lastID {
+		return nil, false
+	}
+	for _, event := range h.buffer {
+		if event.ID > lastID && client.wants(event) {
+			events = append(events, event)
+		}
+	}
+	return events, true
+}
+
+// HandleDeliveryStatusSSE handles SSE connections for delivery status updates.
+// Volunteers only receive events about their own patients; ?topics= limits the stream
+// to the given topics. A client reconnecting with Last-Event-ID (or ?last_event_id=)
+// first receives the events it missed, or a "reset" event if they are no longer buffered.
+// Idle streams get a heartbeat comment; a client that falls too far behind is disconnected.
 // GET /api/sse/delivery-status
 func (h *SSEHandler) HandleDeliveryStatusSSE(c *gin.Context) {
-	// Set SSE headers
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
-	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering
+	topics, err := parseSSETopics(c.Query("topics"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "INVALID_TOPIC"})
+		return
+	}
 
 	// Get user from JWT (already authenticated by middleware)
 	userID, exists := c.Get("user_id")
 	if !exists {
 		// Send error event and close
+		setSSEHeaders(c)
 		c.SSEvent("error", `{"error": "Unauthorized"}`)
 		c.Writer.Flush()
 		return
 	}
 
 	// Create client channel with buffer to prevent blocking
-	clientChan := make(chan SSEEvent, 10)
+	clientChan := make(chan SSEEvent, h.clientBufferSize)
+	client := &sseClient{
+		userID:      userID.(string),
+		role:        c.GetString("role"),
+		topics:      topics,
+		connectedAt: time.Now().UTC(),
+		closed:      make(chan struct{}),
+	}
+	lastEventID, resuming := parseLastEventID(c)
 
-	// Register client
+	// Register client and take the missed events under one lock, so every event is
+	// either replayed or delivered on the channel, never both or neither
 	h.mu.Lock()
-	h.clients[clientChan] = userID.(string)
+	if h.userConnections(client.userID) >= h.maxConnsPerUser {
+		h.rejectedConns++
+		h.mu.Unlock()
+		if h.logger != nil {
+			h.logger.Warn("SSE connection limit reached",
+				"user_id", client.userID,
+				"limit", h.maxConnsPerUser,
+			)
+		}
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many open event streams for this user", "code": "TOO_MANY_CONNECTIONS"})
+		return
+	}
+	h.clients[clientChan] = client
+	currentID := h.lastID
+	missed, replayable := h.eventsSince(lastEventID, client)
 	h.mu.Unlock()
+	defer h.removeClient(clientChan)
 
-	// Send initial connection event
-	connectionData := map[string]string{
+	// A client that stops reading blocks writes; give up on it after the slow client timeout
+	rc := http.NewResponseController(c.Writer)
+	defer rc.SetWriteDeadline(time.Time{})
+	send := func(id uint64, event string, data interface{}) error {
+		rc.SetWriteDeadline(time.Now().Add(h.slowClientTimeout))
+		return writeSSEEvent(c.Writer, id, event, data)
+	}
+
+	setSSEHeaders(c)
+
+	// Send initial connection event; its ID lets a client resume before any event arrives.
+	// A resuming client keeps its own ID until the missed events are replayed, so a stream
+	// dropped during the replay resumes where it stopped.
+	establishedID := currentID
+	if resuming {
+		establishedID = lastEventID
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	send(establishedID, "connection.established", map[string]string{
 		"message":   "Connected to delivery status updates",
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"timestamp": now,
+	})
+
+	if resuming {
+		if !replayable {
+			if h.logger != nil {
+				h.logger.Info("SSE client gap exceeds replay buffer, sending reset",
+					"user_id", client.userID,
+					"last_event_id", lastEventID,
+					"current_event_id", currentID,
+				)
+			}
+			send(currentID, sseEventReset, map[string]interface{}{
+				"reason":        "events_unavailable",
+				"last_event_id": currentID,
+				"timestamp":     now,
+			})
+		}
+		for _, event := range missed {
+			if err := send(event.ID, event.Event, event.Data); err != nil {
+				return
+			}
+		}
 	}
-	dataJSON, _ := json.Marshal(connectionData)
-	c.SSEvent("connection.established", string(dataJSON))
-	c.Writer.Flush()
 
 	// Use request context for disconnect detection (replaces deprecated CloseNotifier)
 	ctx := c.Request.Context()
 
+	// Keep-alive comments stop proxies from closing idle streams and surface dead connections
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
 	// Listen for events or client disconnect
 	for {
 		select {
 		case event := <-clientChan:
 			// Send event to client
-			data, err := json.Marshal(event.Data)
-			if err != nil {
+			if err := send(event.ID, event.Event, event.Data); err != nil {
 				if h.logger != nil {
-					h.logger.Error("Failed to marshal SSE event data",
+					h.logger.Warn("Failed to write SSE event, closing connection",
 						"error", err.Error(),
 						"event", event.Event,
+						"user_id", client.userID,
 					)
 				}
-				continue
+				return
+			}
+
+		case <-heartbeat.C:
+			rc.SetWriteDeadline(time.Now().Add(h.slowClientTimeout))
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
 			}
-			c.SSEvent(event.Event, string(data))
 			c.Writer.Flush()
 
+		case <-client.closed:
+			// Evicted or revoked; a client still allowed in resumes from Last-Event-ID
+			return
+
 		case <-ctx.Done():
 			// Client disconnected
-			h.mu.Lock()
-			delete(h.clients, clientChan)
-			h.mu.Unlock()
-			close(clientChan)
 			return
 
 		case <-h.stopCh:
 			// Server is shutting down, close connection gracefully
-			h.mu.Lock()
-			delete(h.clients, clientChan)
-			h.mu.Unlock()
-			close(clientChan)
 			return
 		}
 	}
 }
 
-// BroadcastDeliveryStatusUpdate broadcasts delivery status update to all connected clients
-func (h *SSEHandler) BroadcastDeliveryStatusUpdate(reminderID, status, timestamp string) {
-	event := SSEEvent{
-		Event: "delivery.status.updated",
-		Data: map[string]string{
-			"reminder_id": reminderID,
-			"status":      status,
-			"timestamp":   timestamp,
-		},
+// userConnections counts the user's open connections. The caller must hold h.mu.
+func (h *SSEHandler) userConnections(userID string) int {
+	count := 0
+	for _, client := range h.clients {
+		if client.userID == userID {
+			count++
+		}
 	}
+	return count
+}
 
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// removeClient unregisters a connection and closes its channel
+func (h *SSEHandler) removeClient(clientChan chan SSEEvent) {
+	h.mu.Lock()
+	delete(h.clients, clientChan)
+	h.mu.Unlock()
+	close(clientChan)
+}
+
+// disconnect unregisters a client and ends its stream. The caller must hold h.mu.
+func (h *SSEHandler) disconnect(clientChan chan SSEEvent, client *sseClient) {
+	delete(h.clients, clientChan)
+	if client.closed != nil {
+		close(client.closed)
+	}
+}
+
+// evict disconnects a client whose queue stayed full for the slow client timeout.
+// The caller must hold h.mu.
+func (h *SSEHandler) evict(clientChan chan SSEEvent, client *sseClient) {
+	h.disconnect(clientChan, client)
+	h.evictedClients++
+}
 
-	if len(h.clients) == 0 {
-		// No clients connected, skip broadcasting
+// broadcast assigns the event the next ID, adds it to the replay buffer and sends it
+// to every connected client it is meant for
+func (h *SSEHandler) broadcast(event SSEEvent) {
+	// Marshal once for every client and for replay
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.Error("Failed to marshal SSE event data",
+				"error", err.Error(),
+				"event", event.Event,
+			)
+		}
 		return
 	}
+	event.Data = json.RawMessage(data)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	event.ID = h.lastID
+	h.buffer = append(h.buffer, event)
+	if len(h.buffer) > h.bufferSize {
+		h.buffer = h.buffer[len(h.buffer)-h.bufferSize:]
+	}
+	if h.saveFunc != nil {
+		h.saveFunc()
+	}
 
-	// Broadcast to all connected clients
-	for clientChan := range h.clients {
+	now := time.Now()
+	for clientChan, client := range h.clients {
+		if !client.wants(event) {
+			continue
+		}
 		select {
 		case clientChan <- event:
 			// Event sent successfully
+			client.fullSince = time.Time{}
 		default:
 			// Channel full, skip this client (client is slow)
+			client.dropped++
+			h.droppedEvents++
+			if client.fullSince.IsZero() {
+				client.fullSince = now
+			}
+			if now.Sub(client.fullSince) >= h.slowClientTimeout {
+				h.evict(clientChan, client)
+				if h.logger != nil {
+					h.logger.Warn("SSE client queue stayed full, disconnecting",
+						"user_id", client.userID,
+						"dropped", client.dropped,
+					)
+				}
+				continue
+			}
 			if h.logger != nil {
 				h.logger.Warn("SSE client channel full, skipping event",
-					"reminder_id", reminderID,
+					"event", event.Event,
+					"user_id", client.userID,
 				)
 			}
 		}
 	}
 }
 
-// BroadcastDeliveryFailed broadcasts delivery failure to all connected clients
-func (h *SSEHandler) BroadcastDeliveryFailed(reminderID, patientID, patientName, errorMsg string) {
-	event := SSEEvent{
+// BroadcastDeliveryStatusUpdate broadcasts a delivery status update to admins and the
+// patient's volunteer
+func (h *SSEHandler) BroadcastDeliveryStatusUpdate(reminderID, patientID, volunteerID, status, timestamp string) {
+	h.broadcast(SSEEvent{
+		Event: "delivery.status.updated",
+		Data: map[string]string{
+			"reminder_id": reminderID,
+			"patient_id":  patientID,
+			"status":      status,
+			"timestamp":   timestamp,
+		},
+		Topic:       SSETopicDelivery,
+		PatientID:   patientID,
+		VolunteerID: volunteerID,
+	})
+}
+
+// BroadcastDeliveryFailed broadcasts a delivery failure to admins and the patient's volunteer
+func (h *SSEHandler) BroadcastDeliveryFailed(reminderID, patientID, volunteerID, patientName, errorMsg string) {
+	h.broadcast(SSEEvent{
 		Event: "delivery.failed",
 		Data: map[string]string{
 			"reminder_id":  reminderID,
@@ -156,30 +552,97 @@ func (h *SSEHandler) BroadcastDeliveryFailed(reminderID, patientID, patientName,
 			"error":        errorMsg,
 			"timestamp":    time.Now().UTC().Format(time.RFC3339),
 		},
-	}
+		Topic:       SSETopicDelivery,
+		PatientID:   patientID,
+		VolunteerID: volunteerID,
+	})
+}
 
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// BroadcastCircuitBreakerStateChange broadcasts a circuit breaker transition to connected admins
+func (h *SSEHandler) BroadcastCircuitBreakerStateChange(change services.CircuitStateChange) {
+	h.broadcast(SSEEvent{
+		Event: "circuit_breaker.state_changed",
+		Data: map[string]interface{}{
+			"from":      change.From,
+			"to":        change.To,
+			"reason":    change.Reason,
+			"failures":  change.Failures,
+			"timestamp": change.Timestamp.Format(time.RFC3339),
+		},
+		Topic:     SSETopicCircuitBreaker,
+		AdminOnly: true,
+	})
+}
 
-	if len(h.clients) == 0 {
-		// No clients connected, skip broadcasting
-		return
-	}
+// BroadcastReminderEscalated notifies admins and the patient's volunteer that a sent
+// reminder has not been read within its escalation window
+func (h *SSEHandler) BroadcastReminderEscalated(reminderID, patientID, patientName, volunteerID string, afterHours int) {
+	h.broadcast(SSEEvent{
+		Event: "reminder.escalated",
+		Data: map[string]interface{}{
+			"reminder_id":  reminderID,
+			"patient_id":   patientID,
+			"patient_name": patientName,
+			"after_hours":  afterHours,
+			"timestamp":    time.Now().UTC().Format(time.RFC3339),
+		},
+		Topic:       SSETopicReminder,
+		PatientID:   patientID,
+		VolunteerID: volunteerID,
+	})
+}
 
-	// Broadcast to all connected clients
-	for clientChan := range h.clients {
-		select {
-		case clientChan <- event:
-			// Event sent successfully
-		default:
-			// Channel full, skip this client (client is slow)
-			if h.logger != nil {
-				h.logger.Warn("SSE client channel full, skipping delivery.failed event",
-					"reminder_id", reminderID,
-				)
-			}
+// BroadcastCampaignProgress broadcasts campaign progress counts to connected admins
+func (h *SSEHandler) BroadcastCampaignProgress(report services.CampaignReport) {
+	h.broadcast(SSEEvent{
+		Event: "campaign.progress",
+		Data: map[string]interface{}{
+			"campaign_id": report.CampaignID,
+			"status":      report.Status,
+			"report":      report,
+			"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		},
+		Topic:     SSETopicCampaign,
+		AdminOnly: true,
+	})
+}
+
+// SSEEventTypes lists the bus event types HandleEvent streams to clients
+var SSEEventTypes = []string{
+	events.TypeDeliveryStatusChanged,
+	events.TypeDeliveryFailed,
+	events.TypeReminderEscalated,
+	events.TypeCircuitStateChanged,
+	events.TypeCampaignProgress,
+}
+
+// HandleEvent is the event bus subscriber that streams domain events to SSE clients
+func (h *SSEHandler) HandleEvent(event events.Event) error {
+	switch e := event.(type) {
+	case events.DeliveryStatusChanged:
+		h.BroadcastDeliveryStatusUpdate(e.ReminderID, e.PatientID, e.VolunteerID, e.Status, e.At.Format(time.RFC3339))
+	case events.DeliveryFailed:
+		h.BroadcastDeliveryFailed(e.ReminderID, e.PatientID, e.VolunteerID, e.PatientName, e.Error)
+	case events.ReminderEscalated:
+		h.BroadcastReminderEscalated(e.ReminderID, e.PatientID, e.PatientName, e.VolunteerID, e.AfterHours)
+	case events.CircuitStateChanged:
+		h.BroadcastCircuitBreakerStateChange(services.CircuitStateChange{
+			From:      e.From,
+			To:        e.To,
+			Reason:    e.Reason,
+			Failures:  e.Failures,
+			Timestamp: e.At,
+		})
+	case events.CampaignProgress:
+		report, ok := e.Report.(services.CampaignReport)
+		if !ok {
+			return fmt.Errorf("unexpected campaign report %T", e.Report)
 		}
+		h.BroadcastCampaignProgress(report)
+	default:
+		return fmt.Errorf("unsupported event %s", event.Type())
 	}
+	return nil
 }
 
 // GetClientCount returns the number of connected SSE clients
@@ -189,6 +652,48 @@ func (h *SSEHandler) GetClientCount() int {
 	return len(h.clients)
 }
 
+// GetStats returns connection counts per user and the event counters since startup
+func (h *SSEHandler) GetStats() SSEStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := SSEStats{
+		Connections:         len(h.clients),
+		Users:               []SSEUserStats{},
+		DroppedEvents:       h.droppedEvents,
+		EvictedClients:      h.evictedClients,
+		RejectedConnections: h.rejectedConns,
+		LastEventID:         h.lastID,
+	}
+
+	byUser := make(map[string]*SSEUserStats)
+	oldest := make(map[string]time.Time)
+	for clientChan, client := range h.clients {
+		user, ok := byUser[client.userID]
+		if !ok {
+			user = &SSEUserStats{UserID: client.userID, Role: client.role}
+			byUser[client.userID] = user
+		}
+		user.Connections++
+		user.QueuedEvents += len(clientChan)
+		user.DroppedEvents += client.dropped
+		if since, ok := oldest[client.userID]; !ok || client.connectedAt.Before(since) {
+			oldest[client.userID] = client.connectedAt
+		}
+	}
+	for userID, user := range byUser {
+		if since := oldest[userID]; !since.IsZero() {
+			user.ConnectedSince = since.Format(time.RFC3339)
+		}
+		stats.Users = append(stats.Users, *user)
+	}
+	sort.Slice(stats.Users, func(i, j int) bool {
+		return stats.Users[i].UserID < stats.Users[j].UserID
+	})
+
+	return stats
+}
+
 // Shutdown gracefully closes all SSE connections
 func (h *SSEHandler) Shutdown() {
 	h.mu.Lock()
@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,7 +24,7 @@ func TestSSEHandler_BroadcastDeliveryStatusUpdate(t *testing.T) {
 	handler := NewSSEHandler(cfg, nil)
 
 	// Test broadcasting with no clients connected
-	handler.BroadcastDeliveryStatusUpdate("reminder-123", "delivered", time.Now().UTC().Format(time.RFC3339))
+	handler.BroadcastDeliveryStatusUpdate("reminder-123", "patient-1", "volunteer-1", "delivered", time.Now().UTC().Format(time.RFC3339))
 
 	// Verify no panic occurs when no clients are connected
 	if handler.GetClientCount() != 0 {
@@ -99,7 +105,7 @@ func TestSSEHandler_GetClientCount(t *testing.T) {
 	// Manually add a client for testing
 	handler.mu.Lock()
 	testChan := make(chan SSEEvent, 10)
-	handler.clients[testChan] = "test-user"
+	handler.clients[testChan] = &sseClient{userID: "test-user"}
 	handler.mu.Unlock()
 
 	if count := handler.GetClientCount(); count != 1 {
@@ -112,3 +118,385 @@ func TestSSEHandler_GetClientCount(t *testing.T) {
 	close(testChan)
 	handler.mu.Unlock()
 }
+
+func TestSSEHandler_BroadcastCircuitBreakerStateChange(t *testing.T) {
+	cfg := &config.Config{}
+	handler := NewSSEHandler(cfg, nil)
+
+	adminChan := make(chan SSEEvent, 10)
+	volunteerChan := make(chan SSEEvent, 10)
+	handler.mu.Lock()
+	handler.clients[adminChan] = &sseClient{userID: "admin-1", role: "admin"}
+	handler.clients[volunteerChan] = &sseClient{userID: "volunteer-1", role: "volunteer"}
+	handler.mu.Unlock()
+
+	handler.BroadcastCircuitBreakerStateChange(services.CircuitStateChange{
+		From:      "closed",
+		To:        "open",
+		Reason:    "failure_threshold_reached",
+		Failures:  5,
+		Timestamp: time.Now().UTC(),
+	})
+
+	select {
+	case event := <-adminChan:
+		if event.Event != "circuit_breaker.state_changed" {
+			t.Errorf("Expected circuit_breaker.state_changed event, got %s", event.Event)
+		}
+	default:
+		t.Error("Expected admin client to receive circuit breaker event")
+	}
+
+	select {
+	case <-volunteerChan:
+		t.Error("Expected volunteer client not to receive circuit breaker event")
+	default:
+	}
+}
+
+func TestSSEHandler_HandleEvent(t *testing.T) {
+	handler := NewSSEHandler(&config.Config{}, nil)
+	bus := events.NewBus(nil)
+	defer bus.Close()
+	bus.Subscribe("sse", handler.HandleEvent, SSEEventTypes...)
+
+	adminChan := make(chan SSEEvent, 10)
+	handler.mu.Lock()
+	handler.clients[adminChan] = &sseClient{userID: "admin-1", role: "admin"}
+	handler.mu.Unlock()
+
+	now := time.Now().UTC()
+	bus.Publish(events.ReminderCreated{ReminderID: "reminder-1"}) // Not streamed
+	bus.Publish(events.DeliveryStatusChanged{ReminderID: "reminder-1", PatientID: "patient-1", Status: "sent", At: now})
+	bus.Publish(events.DeliveryFailed{ReminderID: "reminder-2", PatientID: "patient-1", Error: "failed", At: now})
+	bus.Publish(events.ReminderEscalated{ReminderID: "reminder-3", PatientID: "patient-1", AfterHours: 4, At: now})
+	bus.Publish(events.CircuitStateChanged{From: "closed", To: "open", At: now})
+	bus.Publish(events.CampaignProgress{CampaignID: "campaign-1", Report: services.CampaignReport{CampaignID: "campaign-1"}, At: now})
+	bus.Publish(events.CampaignProgress{CampaignID: "campaign-2", Report: "not a report"})
+	bus.Flush()
+
+	var got []string
+	for len(adminChan) > 0 {
+		got = append(got, (<-adminChan).Event)
+	}
+	want := []string{"delivery.status.updated", "delivery.failed", "reminder.escalated", "circuit_breaker.state_changed", "campaign.progress"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected events %v, got %v", want, got)
+	}
+	if stats := bus.Stats()[0]; stats.Delivered != 5 || stats.Failed != 1 {
+		t.Errorf("Expected 5 delivered and 1 failed, got %+v", stats)
+	}
+}
+
+func TestSSEHandler_OwnershipAndTopics(t *testing.T) {
+	handler := NewSSEHandler(&config.Config{}, nil)
+
+	clients := map[string]*sseClient{
+		"admin":          {userID: "admin-1", role: "admin"},
+		"owner":          {userID: "volunteer-1", role: "volunteer"},
+		"other":          {userID: "volunteer-2", role: "volunteer"},
+		"admin-campaign": {userID: "admin-2", role: "admin", topics: map[string]bool{SSETopicCampaign: true}},
+	}
+	chans := make(map[string]chan SSEEvent)
+	handler.mu.Lock()
+	for name, client := range clients {
+		chans[name] = make(chan SSEEvent, 10)
+		handler.clients[chans[name]] = client
+	}
+	handler.mu.Unlock()
+
+	received := func() map[string]bool {
+		got := make(map[string]bool)
+		for name, ch := range chans {
+			select {
+			case <-ch:
+				got[name] = true
+			default:
+			}
+		}
+		return got
+	}
+
+	handler.BroadcastDeliveryStatusUpdate("reminder-1", "patient-1", "volunteer-1", "delivered", time.Now().UTC().Format(time.RFC3339))
+	if got := received(); !got["admin"] || !got["owner"] || got["other"] || got["admin-campaign"] {
+		t.Errorf("Expected delivery update for admin and owner only, got %v", got)
+	}
+
+	handler.BroadcastDeliveryFailed("reminder-1", "patient-1", "volunteer-1", "Budi", "failed")
+	if got := received(); got["other"] {
+		t.Errorf("Expected patient name withheld from other volunteers, got %v", got)
+	}
+
+	handler.BroadcastCampaignProgress(services.CampaignReport{CampaignID: "campaign-1"})
+	if got := received(); !got["admin"] || !got["admin-campaign"] || got["owner"] || got["other"] {
+		t.Errorf("Expected campaign progress for admins only, got %v", got)
+	}
+
+	t.Run("unknown topics are rejected", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/sse", func(c *gin.Context) {
+			c.Set("user_id", "volunteer-1")
+			handler.HandleDeliveryStatusSSE(c)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/sse?topics=delivery,unknown", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
+
+func TestSSEHandler_ResumeFromLastEventID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.SSE.ReplayBufferSize = 3
+	handler := NewSSEHandler(cfg, nil)
+
+	for i := 1; i <= 5; i++ {
+		handler.BroadcastDeliveryStatusUpdate(fmt.Sprintf("reminder-%d", i), "patient-1", "volunteer-1", "sent", time.Now().UTC().Format(time.RFC3339))
+	}
+	last := handler.LastEventID()
+	if events := handler.Events(); len(events) != 3 || events[0].ID != last-2 {
+		t.Fatalf("Expected the 3 most recent events buffered, got %+v", events)
+	}
+
+	// connect opens a stream that ends as soon as the replay is written
+	connect := func(userID, role, lastEventID string) string {
+		router := gin.New()
+		router.GET("/sse", func(c *gin.Context) {
+			c.Set("user_id", userID)
+			c.Set("role", role)
+			handler.HandleDeliveryStatusSSE(c)
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest("GET", "/sse", nil).WithContext(ctx)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	t.Run("replays events after the last ID", func(t *testing.T) {
+		body := connect("volunteer-1", "volunteer", fmt.Sprint(last-2))
+		if !strings.Contains(body, fmt.Sprintf("id: %d\nevent: delivery.status.updated", last-1)) ||
+			!strings.Contains(body, fmt.Sprintf("id: %d\nevent: delivery.status.updated", last)) {
+			t.Errorf("Expected events %d and %d replayed, got %q", last-1, last, body)
+		}
+		if strings.Contains(body, "reminder-3") || strings.Contains(body, "event: reset") {
+			t.Errorf("Expected only missed events replayed, got %q", body)
+		}
+	})
+
+	t.Run("stream dropped during replay resumes where it stopped", func(t *testing.T) {
+		clientID := fmt.Sprint(last - 3)
+		var replayed []string
+		for cut := 0; cut < 3; cut++ {
+			router := gin.New()
+			router.GET("/sse", func(c *gin.Context) {
+				c.Set("user_id", "volunteer-1")
+				c.Set("role", "volunteer")
+				handler.HandleDeliveryStatusSSE(c)
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			req := httptest.NewRequest("GET", "/sse", nil).WithContext(ctx)
+			req.Header.Set("Last-Event-ID", clientID)
+			w := &cutSSEWriter{ResponseRecorder: httptest.NewRecorder(), limit: cut}
+			router.ServeHTTP(w, req)
+
+			// Track IDs the way the frontend does: the last ID received wins
+			for _, line := range strings.Split(w.Body.String(), "\n") {
+				if id, ok := strings.CutPrefix(line, "id: "); ok {
+					clientID = id
+				}
+				if strings.HasPrefix(line, "event: delivery.status.updated") {
+					replayed = append(replayed, clientID)
+				}
+				if line == "event: reset" {
+					t.Fatalf("Expected no reset while the events are buffered, got %q", w.Body.String())
+				}
+			}
+		}
+		want := []string{fmt.Sprint(last - 2), fmt.Sprint(last - 1), fmt.Sprint(last)}
+		if strings.Join(replayed, ",") != strings.Join(want, ",") {
+			t.Errorf("Expected events %v replayed once each across reconnects, got %v", want, replayed)
+		}
+		if clientID != fmt.Sprint(last) {
+			t.Errorf("Expected the client to resume after event %d, got %s", last, clientID)
+		}
+	})
+
+	t.Run("replay respects ownership", func(t *testing.T) {
+		body := connect("volunteer-2", "volunteer", fmt.Sprint(last-2))
+		if strings.Contains(body, "delivery.status.updated") {
+			t.Errorf("Expected no events for another volunteer, got %q", body)
+		}
+	})
+
+	t.Run("gap beyond buffer sends reset", func(t *testing.T) {
+		body := connect("volunteer-1", "volunteer", fmt.Sprint(last-4))
+		if !strings.Contains(body, "event: reset") || strings.Contains(body, "delivery.status.updated") {
+			t.Errorf("Expected a reset without replay, got %q", body)
+		}
+	})
+
+	t.Run("ID from before a restart sends reset", func(t *testing.T) {
+		restarted := NewSSEHandler(cfg, nil)
+		restarted.BroadcastDeliveryStatusUpdate("reminder-6", "patient-1", "volunteer-1", "sent", time.Now().UTC().Format(time.RFC3339))
+		if _, ok := restarted.eventsSince(last, &sseClient{userID: "volunteer-1"}); ok {
+			t.Error("Expected an ID issued before the restart not to be resumable")
+		}
+		if _, ok := handler.eventsSince(last+10, &sseClient{userID: "volunteer-1"}); ok {
+			t.Error("Expected an ID never issued not to be resumable")
+		}
+	})
+
+	t.Run("restored buffer resumes across restart", func(t *testing.T) {
+		data, err := json.Marshal(handler.Events())
+		if err != nil {
+			t.Fatalf("Failed to marshal events: %v", err)
+		}
+		var saved []SSEEvent
+		if err := json.Unmarshal(data, &saved); err != nil {
+			t.Fatalf("Failed to unmarshal events: %v", err)
+		}
+
+		restored := NewSSEHandler(cfg, nil)
+		restored.RestoreEvents(saved)
+		events, ok := restored.eventsSince(last-1, &sseClient{userID: "volunteer-1", role: "volunteer"})
+		if !ok || len(events) != 1 || events[0].ID != last {
+			t.Errorf("Expected event %d replayed from the restored buffer, got %+v (ok=%v)", last, events, ok)
+		}
+		restored.BroadcastDeliveryStatusUpdate("reminder-6", "patient-1", "volunteer-1", "read", time.Now().UTC().Format(time.RFC3339))
+		if restored.LastEventID() != last+1 {
+			t.Errorf("Expected IDs to continue from %d, got %d", last, restored.LastEventID())
+		}
+	})
+}
+
+// cutSSEWriter drops the connection before the event after the first limit delivery
+// status events
+type cutSSEWriter struct {
+	*httptest.ResponseRecorder
+	limit   int
+	written int
+}
+
+func (w *cutSSEWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), "event: delivery.status.updated") {
+		if w.written == w.limit {
+			return 0, errors.New("connection reset")
+		}
+		w.written++
+	}
+	return w.ResponseRecorder.Write(p)
+}
+
+func TestSSEHandler_SlowClientsAndLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.SSE.ClientBufferSize = 1
+	cfg.SSE.MaxConnectionsPerUser = 2
+	handler := NewSSEHandler(cfg, nil)
+
+	slowChan := make(chan SSEEvent, 1)
+	slow := &sseClient{userID: "admin-1", role: "admin", connectedAt: time.Now().UTC(), closed: make(chan struct{})}
+	fastChan := make(chan SSEEvent, 1)
+	handler.mu.Lock()
+	handler.clients[slowChan] = slow
+	handler.clients[fastChan] = &sseClient{userID: "admin-1", role: "admin", connectedAt: time.Now().UTC()}
+	handler.mu.Unlock()
+
+	broadcast := func() {
+		handler.BroadcastCampaignProgress(services.CampaignReport{CampaignID: "campaign-1"})
+	}
+
+	broadcast()
+	<-fastChan
+	broadcast() // slow client's queue is full, the event is dropped
+	<-fastChan
+
+	stats := handler.GetStats()
+	if stats.DroppedEvents != 1 || stats.EvictedClients != 0 {
+		t.Fatalf("Expected 1 dropped event and no eviction yet, got %+v", stats)
+	}
+	if len(stats.Users) != 1 || stats.Users[0].Connections != 2 || stats.Users[0].QueuedEvents != 1 || stats.Users[0].DroppedEvents != 1 {
+		t.Errorf("Expected 2 connections for admin-1 with 1 queued and 1 dropped event, got %+v", stats.Users)
+	}
+
+	t.Run("connection limit per user", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/sse", func(c *gin.Context) {
+			c.Set("user_id", "admin-1")
+			c.Set("role", "admin")
+			handler.HandleDeliveryStatusSSE(c)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/sse", nil))
+		if w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "TOO_MANY_CONNECTIONS") {
+			t.Errorf("Expected 429 TOO_MANY_CONNECTIONS, got %d %s", w.Code, w.Body.String())
+		}
+		if handler.GetStats().RejectedConnections != 1 {
+			t.Error("Expected the rejected connection to be counted")
+		}
+	})
+
+	t.Run("queue full past the timeout evicts", func(t *testing.T) {
+		handler.mu.Lock()
+		slow.fullSince = time.Now().Add(-handler.slowClientTimeout)
+		handler.mu.Unlock()
+
+		broadcast()
+		<-fastChan
+
+		select {
+		case <-slow.closed:
+		default:
+			t.Fatal("Expected slow client to be evicted")
+		}
+		stats := handler.GetStats()
+		if stats.Connections != 1 || stats.EvictedClients != 1 || stats.DroppedEvents != 2 {
+			t.Errorf("Expected 1 connection left, 1 eviction and 2 dropped events, got %+v", stats)
+		}
+	})
+}
+
+func TestSSEHandler_Heartbeat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{}
+	cfg.SSE.HeartbeatInterval = 10 * time.Millisecond
+	handler := NewSSEHandler(cfg, nil)
+
+	router := gin.New()
+	router.GET("/sse", func(c *gin.Context) {
+		c.Set("user_id", "volunteer-1")
+		handler.HandleDeliveryStatusSSE(c)
+	})
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/sse", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	buf := make([]byte, 4096)
+	var body strings.Builder
+	for !strings.Contains(body.String(), ": heartbeat\n\n") {
+		n, err := resp.Body.Read(buf)
+		if err != nil {
+			t.Fatalf("Expected a heartbeat comment, got %q (%v)", body.String(), err)
+		}
+		body.Write(buf[:n])
+	}
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseTicket authorizes one SSE connection for a user from one client IP
+type sseTicket struct {
+	userID    string
+	role      string // Role when issued, used when there is no user lookup
+	clientIP  string
+	expiresAt time.Time
+}
+
+// SetUserLookup sets the function that returns a user's current role, or false if the
+// user no longer exists. Tickets are only issued and redeemed for existing users, with
+// their current role rather than the one in their JWT.
+func (h *SSEHandler) SetUserLookup(lookup func(userID string) (role string, ok bool)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.userLookup = lookup
+}
+
+// currentRole returns the user's current role. Without a lookup the given role is kept.
+func (h *SSEHandler) currentRole(userID, role string) (string, bool) {
+	h.mu.RLock()
+	lookup := h.userLookup
+	h.mu.RUnlock()
+	if lookup == nil {
+		return role, true
+	}
+	return lookup(userID)
+}
+
+// IssueTicket issues a single-use ticket for opening an SSE stream, so the long-lived
+// JWT never appears in a URL. The ticket is bound to the caller's user and IP.
+// POST /api/sse/ticket
+func (h *SSEHandler) IssueTicket(c *gin.Context) {
+	userID := c.GetString("userID")
+	role, ok := h.currentRole(userID, c.GetString("role"))
+	if userID == "" || !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not found", "code": "UNAUTHORIZED"})
+		return
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		if h.logger != nil {
+			h.logger.Error("Failed to generate SSE ticket", "error", err.Error())
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to issue ticket", "code": "INTERNAL_ERROR"})
+		return
+	}
+	ticket := hex.EncodeToString(raw)
+	now := time.Now().UTC()
+	expiresAt := now.Add(h.ticketTTL)
+
+	h.mu.Lock()
+	// Drop expired tickets that were never redeemed
+	for key, t := range h.tickets {
+		if now.After(t.expiresAt) {
+			delete(h.tickets, key)
+		}
+	}
+	h.tickets[ticket] = sseTicket{userID: userID, role: role, clientIP: c.ClientIP(), expiresAt: expiresAt}
+	h.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"ticket":     ticket,
+			"expires_at": expiresAt.Format(time.RFC3339),
+		},
+		"message": "SSE ticket issued",
+	})
+}
+
+// redeemTicket consumes a ticket. A ticket is valid once, before it expires, and only
+// from the IP it was issued to.
+func (h *SSEHandler) redeemTicket(ticket, clientIP string) (sseTicket, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.tickets[ticket]
+	if !ok {
+		return sseTicket{}, false
+	}
+	delete(h.tickets, ticket)
+	if time.Now().After(t.expiresAt) || t.clientIP != clientIP {
+		return sseTicket{}, false
+	}
+	return t, true
+}
+
+// TicketAuth authenticates SSE connections with a ticket from IssueTicket, passed as
+// ?ticket= because EventSource cannot send headers. JWTs are not accepted.
+func (h *SSEHandler) TicketAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ticket, ok := h.redeemTicket(c.Query("ticket"), c.ClientIP())
+		var role string
+		if ok {
+			role, ok = h.currentRole(ticket.userID, ticket.role)
+		}
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired SSE ticket", "code": "INVALID_SSE_TICKET"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", ticket.userID)
+		c.Set("role", role)
+		c.Next()
+	}
+}
+
+// DisconnectUser closes the user's open SSE connections and revokes their unused
+// tickets, e.g. when the user is deleted or their role changes. A client that is still
+// allowed in reconnects with a new ticket and its current role.
+func (h *SSEHandler) DisconnectUser(userID, reason string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for key, t := range h.tickets {
+		if t.userID == userID {
+			delete(h.tickets, key)
+		}
+	}
+
+	closed := 0
+	for clientChan, client := range h.clients {
+		if client.userID == userID {
+			h.disconnect(clientChan, client)
+			closed++
+		}
+	}
+
+	if closed > 0 && h.logger != nil {
+		h.logger.Info("Closed SSE connections for user",
+			"user_id", userID,
+			"reason", reason,
+			"connections", closed,
+		)
+	}
+	return closed
+}
@@ -0,0 +1,452 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+// templateNamePattern restricts template names to stable, URL-safe identifiers
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+// TemplateHandler handles message template administration and preview
+type TemplateHandler struct {
+	store        *models.TemplateStore
+	patientStore *models.PatientStore
+	contentStore *ContentStore
+	config       *config.Config
+	logger       *slog.Logger
+	generateID   IDGenerator
+}
+
+// NewTemplateHandler creates a new template handler
+func NewTemplateHandler(store *models.TemplateStore, patientStore *models.PatientStore, contentStore *ContentStore, cfg *config.Config, logger *slog.Logger, idGen IDGenerator) *TemplateHandler {
+	return &TemplateHandler{
+		store:        store,
+		patientStore: patientStore,
+		contentStore: contentStore,
+		config:       cfg,
+		logger:       logger,
+		generateID:   idGen,
+	}
+}
+
+// CreateTemplateRequest represents the request body for creating a message template
+type CreateTemplateRequest struct {
+	Name        string            `json:"name" binding:"required"`
+	Description string            `json:"description"`
+	Variants    map[string]string `json:"variants" binding:"required"`
+}
+
+// UpdateTemplateRequest represents the request body for updating a message template
+type UpdateTemplateRequest struct {
+	Description string            `json:"description"`
+	Variants    map[string]string `json:"variants" binding:"required"`
+}
+
+// RestoreTemplateRequest represents the request body for restoring a template version
+type RestoreTemplateRequest struct {
+	Version int `json:"version" binding:"required"`
+}
+
+// PreviewTemplateRequest represents the request body for rendering a template preview.
+// Body previews unsaved edits; otherwise TemplateID or the reminder's template is used.
+type PreviewTemplateRequest struct {
+	PatientID  string `json:"patient_id" binding:"required"`
+	ReminderID string `json:"reminder_id" binding:"required"`
+	TemplateID string `json:"template_id"`
+	Body       string `json:"body"`
+	Language   string `json:"language"`
+}
+
+// EnsureDefaultTemplate seeds the default reminder template from the built-in layouts
+// so it can be edited through the API
+func (h *TemplateHandler) EnsureDefaultTemplate() {
+	h.store.Mu.Lock()
+	if _, exists := h.store.ByName[models.DefaultMessageTemplateName]; exists {
+		h.store.Mu.Unlock()
+		return
+	}
+
+	now := getCurrentTimestamp()
+	variants := make(map[string]string, len(utils.DefaultReminderTemplates))
+	for lang, body := range utils.DefaultReminderTemplates {
+		variants[lang] = body
+	}
+	tmpl := &models.MessageTemplate{
+		ID:          h.generateID(),
+		Name:        models.DefaultMessageTemplateName,
+		Description: "Default reminder message",
+		Variants:    variants,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	h.store.Templates[tmpl.ID] = tmpl
+	h.store.ByName[tmpl.Name] = tmpl.ID
+	h.store.Mu.Unlock()
+
+	h.store.SaveData()
+}
+
+// validateVariants checks language codes and that every variant parses and renders
+func validateVariants(variants map[string]string) (string, error) {
+	if len(variants) == 0 {
+		return "", fmt.Errorf("at least one language variant is required")
+	}
+	for lang, body := range variants {
+		if !models.IsSupportedLanguage(lang) {
+			return lang, fmt.Errorf("unsupported language '%s'", lang)
+		}
+		if err := utils.ValidateMessageTemplate(body); err != nil {
+			return lang, fmt.Errorf("variant '%s': %v", lang, err)
+		}
+	}
+	return "", nil
+}
+
+// invalidTemplateResponse writes a 400 for a template that failed validation
+func invalidTemplateResponse(c *gin.Context, language string, err error) {
+	resp := gin.H{
+		"error": err.Error(),
+		"code":  "INVALID_TEMPLATE",
+	}
+	if language != "" {
+		resp["language"] = language
+	}
+	c.JSON(http.StatusBadRequest, resp)
+}
+
+// ListTemplates handles GET /api/message-templates
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	h.store.Mu.RLock()
+	templates := make([]*models.MessageTemplate, 0, len(h.store.Templates))
+	for _, tmpl := range h.store.Templates {
+		templates = append(templates, tmpl)
+	}
+	h.store.Mu.RUnlock()
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":      templates,
+		"languages": models.SupportedLanguages,
+		"message":   "Success",
+	})
+}
+
+// GetTemplate handles GET /api/message-templates/:id
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	h.store.Mu.RLock()
+	tmpl, exists := h.store.Templates[c.Param("id")]
+	h.store.Mu.RUnlock()
+
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found", "code": "TEMPLATE_NOT_FOUND"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tmpl, "message": "Success"})
+}
+
+// CreateTemplate handles POST /api/message-templates
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !templateNamePattern.MatchString(req.Name) {
+		invalidTemplateResponse(c, "", fmt.Errorf("name must be 1-50 lowercase letters, digits, '-' or '_'"))
+		return
+	}
+	if lang, err := validateVariants(req.Variants); err != nil {
+		invalidTemplateResponse(c, lang, err)
+		return
+	}
+
+	userID := c.GetString("userID")
+	now := getCurrentTimestamp()
+
+	h.store.Mu.Lock()
+	if _, exists := h.store.ByName[req.Name]; exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": "template name already exists", "code": "TEMPLATE_EXISTS"})
+		return
+	}
+	tmpl := &models.MessageTemplate{
+		ID:          h.generateID(),
+		Name:        req.Name,
+		Description: req.Description,
+		Variants:    req.Variants,
+		Version:     1,
+		CreatedBy:   userID,
+		UpdatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	h.store.Templates[tmpl.ID] = tmpl
+	h.store.ByName[tmpl.Name] = tmpl.ID
+	h.store.Mu.Unlock()
+
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Message template created",
+			"template_id", tmpl.ID,
+			"name", tmpl.Name,
+			"user_id", userID,
+		)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": tmpl, "message": "Template created"})
+}
+
+// UpdateTemplate handles PUT /api/message-templates/:id
+// Every update creates a new version; the previous one is kept in the history.
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	var req UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if lang, err := validateVariants(req.Variants); err != nil {
+		invalidTemplateResponse(c, lang, err)
+		return
+	}
+
+	h.saveVersion(c, c.Param("id"), req.Description, req.Variants, "Template updated")
+}
+
+// RestoreTemplate handles POST /api/message-templates/:id/restore
+// Restoring creates a new version with the variants of an earlier one.
+func (h *TemplateHandler) RestoreTemplate(c *gin.Context) {
+	var req RestoreTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.store.Mu.RLock()
+	tmpl, exists := h.store.Templates[c.Param("id")]
+	var variants map[string]string
+	var description string
+	if exists {
+		description = tmpl.Description
+		for _, v := range tmpl.History {
+			if v.Version == req.Version {
+				variants = v.Variants
+				break
+			}
+		}
+	}
+	h.store.Mu.RUnlock()
+
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found", "code": "TEMPLATE_NOT_FOUND"})
+		return
+	}
+	if variants == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template version not found", "code": "VERSION_NOT_FOUND"})
+		return
+	}
+
+	h.saveVersion(c, c.Param("id"), description, variants, fmt.Sprintf("Template restored from version %d", req.Version))
+}
+
+// saveVersion replaces a template's variants, archiving the current version
+func (h *TemplateHandler) saveVersion(c *gin.Context, id, description string, variants map[string]string, message string) {
+	userID := c.GetString("userID")
+	now := getCurrentTimestamp()
+
+	h.store.Mu.Lock()
+	tmpl, exists := h.store.Templates[id]
+	if !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found", "code": "TEMPLATE_NOT_FOUND"})
+		return
+	}
+
+	tmpl.History = append(tmpl.History, models.TemplateVersion{
+		Version:   tmpl.Version,
+		Variants:  tmpl.Variants,
+		UpdatedBy: tmpl.UpdatedBy,
+		UpdatedAt: tmpl.UpdatedAt,
+	})
+	if len(tmpl.History) > models.MaxTemplateVersions {
+		tmpl.History = tmpl.History[len(tmpl.History)-models.MaxTemplateVersions:]
+	}
+	tmpl.Description = description
+	tmpl.Variants = variants
+	tmpl.Version++
+	tmpl.UpdatedBy = userID
+	tmpl.UpdatedAt = now
+	version := tmpl.Version
+	h.store.Mu.Unlock()
+
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Message template saved",
+			"template_id", id,
+			"version", version,
+			"user_id", userID,
+		)
+	}
+
+	h.store.Mu.RLock()
+	c.JSON(http.StatusOK, gin.H{"data": tmpl, "message": message})
+	h.store.Mu.RUnlock()
+}
+
+// DeleteTemplate handles DELETE /api/message-templates/:id
+// The default template can't be deleted. Reminders that name a deleted template
+// fall back to the default.
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	id := c.Param("id")
+
+	h.store.Mu.Lock()
+	tmpl, exists := h.store.Templates[id]
+	if !exists {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found", "code": "TEMPLATE_NOT_FOUND"})
+		return
+	}
+	if tmpl.Name == models.DefaultMessageTemplateName {
+		h.store.Mu.Unlock()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the default template cannot be deleted", "code": "DEFAULT_TEMPLATE"})
+		return
+	}
+	delete(h.store.Templates, id)
+	delete(h.store.ByName, tmpl.Name)
+	h.store.Mu.Unlock()
+
+	h.store.SaveData()
+
+	if h.logger != nil {
+		h.logger.Info("Message template deleted",
+			"template_id", id,
+			"name", tmpl.Name,
+			"user_id", c.GetString("userID"),
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// PreviewTemplate handles POST /api/message-templates/preview
+// Renders a template against a real patient and reminder without sending anything.
+func (h *TemplateHandler) PreviewTemplate(c *gin.Context) {
+	var req PreviewTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Language != "" && !models.IsSupportedLanguage(req.Language) {
+		invalidTemplateResponse(c, req.Language, fmt.Errorf("unsupported language '%s'", req.Language))
+		return
+	}
+
+	// Snapshot the patient and reminder fields used for rendering
+	h.patientStore.RLock()
+	patient, exists := h.patientStore.GetPatient(req.PatientID)
+	if !exists {
+		h.patientStore.RUnlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "patient not found", "code": "PATIENT_NOT_FOUND"})
+		return
+	}
+	var reminder models.Reminder
+	found := false
+	for _, r := range patient.Reminders {
+		if r.ID == req.ReminderID {
+			reminder = *r
+			found = true
+			break
+		}
+	}
+	patientName := patient.Name
+	language := patient.Language
+	h.patientStore.RUnlock()
+
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reminder not found", "code": "REMINDER_NOT_FOUND"})
+		return
+	}
+	if req.Language != "" {
+		language = req.Language
+	}
+	if language == "" {
+		language = models.DefaultLanguage
+	}
+
+	var articleStore *models.ArticleStore
+	var videoStore *models.VideoStore
+	if h.contentStore != nil {
+		articleStore = h.contentStore.Articles
+		videoStore = h.contentStore.Videos
+	}
+	disclaimerEnabled := h.config.Disclaimer.Enabled != nil && *h.config.Disclaimer.Enabled
+	params := utils.ReminderMessageParams{
+		PatientName:         patientName,
+		ReminderTitle:       reminder.Title,
+		ReminderDescription: reminder.Description,
+		DisclaimerText:      h.config.Disclaimer.Text,
+		DisclaimerEnabled:   disclaimerEnabled,
+	}
+	attachments := utils.BuildContentAttachmentsWithBaseURL(reminder.Attachments, articleStore, videoStore, h.config.Links.PublicBaseURL)
+
+	// Inline body or a specific stored template
+	body := req.Body
+	templateName := reminder.MessageTemplate
+	usedLanguage := language
+	if body == "" && req.TemplateID != "" {
+		h.store.Mu.RLock()
+		tmpl, exists := h.store.Templates[req.TemplateID]
+		if exists {
+			templateName = tmpl.Name
+			body, usedLanguage, _ = tmpl.Variant(language)
+		}
+		h.store.Mu.RUnlock()
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "template not found", "code": "TEMPLATE_NOT_FOUND"})
+			return
+		}
+	}
+
+	var message string
+	if body != "" {
+		rendered, err := utils.RenderMessageTemplate(body, utils.NewMessageTemplateData(params, attachments))
+		if err != nil {
+			invalidTemplateResponse(c, usedLanguage, err)
+			return
+		}
+		message = rendered
+	} else {
+		message = utils.FormatReminderMessageFromTemplate(h.store, templateName, language, params, attachments)
+	}
+
+	if templateName == "" {
+		templateName = models.DefaultMessageTemplateName
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"message":  message,
+			"language": usedLanguage,
+			"template": templateName,
+		},
+		"message": "Preview rendered",
+	})
+}
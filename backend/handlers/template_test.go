@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func setupTemplateHandler(t *testing.T) (*TemplateHandler, *models.TemplateStore, *models.PatientStore) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	enabled := false
+	cfg := &config.Config{
+		Disclaimer: config.DisclaimerConfig{Enabled: &enabled},
+	}
+
+	patientStore := models.NewPatientStore(func() {})
+	patientStore.Patients["patient-1"] = &models.Patient{
+		ID:       "patient-1",
+		Name:     "Budi",
+		Phone:    "08123456789",
+		Language: models.LanguageEnglish,
+		Reminders: []*models.Reminder{
+			{ID: "reminder-1", Title: "Minum obat", Description: "Setelah makan"},
+		},
+	}
+
+	templateStore := models.NewTemplateStore(func() {})
+	counter := 0
+	handler := NewTemplateHandler(templateStore, patientStore, NewContentStore(), cfg, logger, func() string {
+		counter++
+		return fmt.Sprintf("tmpl-%d", counter)
+	})
+	handler.EnsureDefaultTemplate()
+
+	return handler, templateStore, patientStore
+}
+
+func templateRequest(method, path string, body interface{}, params gin.Params) (*gin.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	data, _ := json.Marshal(body)
+	c.Request = httptest.NewRequest(method, path, bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("userID", "admin-1")
+	c.Set("role", "admin")
+	return c, w
+}
+
+func TestTemplateHandler_EnsureDefaultTemplate(t *testing.T) {
+	handler, store, _ := setupTemplateHandler(t)
+	handler.EnsureDefaultTemplate()
+
+	if len(store.Templates) != 1 {
+		t.Fatalf("Expected exactly one seeded template, got %d", len(store.Templates))
+	}
+	tmpl := store.Templates[store.ByName[models.DefaultMessageTemplateName]]
+	if tmpl == nil || tmpl.Version != 1 || len(tmpl.Variants) != len(models.SupportedLanguages) {
+		t.Errorf("Unexpected default template: %+v", tmpl)
+	}
+}
+
+func TestTemplateHandler_CreateTemplate(t *testing.T) {
+	t.Run("creates valid template", func(t *testing.T) {
+		handler, store, _ := setupTemplateHandler(t)
+		c, w := templateRequest("POST", "/api/message-templates", gin.H{
+			"name":     "short",
+			"variants": gin.H{"id": "Hai {{.PatientName}}", "en": "Hi {{.PatientName}}"},
+		}, nil)
+
+		handler.CreateTemplate(c)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+		}
+		if _, exists := store.ByName["short"]; !exists {
+			t.Error("Expected template to be indexed by name")
+		}
+	})
+
+	tests := []struct {
+		name     string
+		body     gin.H
+		wantCode int
+		wantLang string
+	}{
+		{"invalid name", gin.H{"name": "Bad Name", "variants": gin.H{"id": "x"}}, http.StatusBadRequest, ""},
+		{"unsupported language", gin.H{"name": "x", "variants": gin.H{"fr": "Bonjour"}}, http.StatusBadRequest, "fr"},
+		{"syntax error", gin.H{"name": "x", "variants": gin.H{"id": "{{.PatientName"}}, http.StatusBadRequest, "id"},
+		{"unknown field", gin.H{"name": "x", "variants": gin.H{"en": "{{.Secret}}"}}, http.StatusBadRequest, "en"},
+		{"duplicate name", gin.H{"name": models.DefaultMessageTemplateName, "variants": gin.H{"id": "x"}}, http.StatusConflict, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _, _ := setupTemplateHandler(t)
+			c, w := templateRequest("POST", "/api/message-templates", tt.body, nil)
+
+			handler.CreateTemplate(c)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			if tt.wantLang != "" && response["language"] != tt.wantLang {
+				t.Errorf("Expected failing language %q, got %v", tt.wantLang, response["language"])
+			}
+		})
+	}
+}
+
+func TestTemplateHandler_UpdateAndRestore(t *testing.T) {
+	handler, store, _ := setupTemplateHandler(t)
+	id := store.ByName[models.DefaultMessageTemplateName]
+	original := store.Templates[id].Variants[models.LanguageIndonesian]
+	params := gin.Params{{Key: "id", Value: id}}
+
+	c, w := templateRequest("PUT", "/api/message-templates/"+id, gin.H{
+		"variants": gin.H{"id": "Halo {{.PatientName}}"},
+	}, params)
+	handler.UpdateTemplate(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	tmpl := store.Templates[id]
+	if tmpl.Version != 2 || len(tmpl.History) != 1 || tmpl.History[0].Version != 1 {
+		t.Fatalf("Expected version 2 with one archived version, got v%d history=%d", tmpl.Version, len(tmpl.History))
+	}
+	if tmpl.UpdatedBy != "admin-1" {
+		t.Errorf("Expected updated_by admin-1, got %q", tmpl.UpdatedBy)
+	}
+
+	// Invalid update is rejected and leaves the template untouched
+	c, w = templateRequest("PUT", "/api/message-templates/"+id, gin.H{
+		"variants": gin.H{"id": "{{end}}"},
+	}, params)
+	handler.UpdateTemplate(c)
+	if w.Code != http.StatusBadRequest || tmpl.Version != 2 {
+		t.Errorf("Expected rejected update, got status %d version %d", w.Code, tmpl.Version)
+	}
+
+	c, w = templateRequest("POST", "/api/message-templates/"+id+"/restore", gin.H{"version": 1}, params)
+	handler.RestoreTemplate(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	if tmpl.Version != 3 || tmpl.Variants[models.LanguageIndonesian] != original {
+		t.Errorf("Expected version 3 with original variants, got v%d", tmpl.Version)
+	}
+}
+
+func TestTemplateHandler_DeleteTemplate(t *testing.T) {
+	handler, store, _ := setupTemplateHandler(t)
+	id := store.ByName[models.DefaultMessageTemplateName]
+
+	c, w := templateRequest("DELETE", "/api/message-templates/"+id, nil, gin.Params{{Key: "id", Value: id}})
+	handler.DeleteTemplate(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected default template delete to be refused, got %d", w.Code)
+	}
+	if _, exists := store.Templates[id]; !exists {
+		t.Error("Expected default template to remain")
+	}
+}
+
+func TestTemplateHandler_PreviewTemplate(t *testing.T) {
+	t.Run("uses patient language", func(t *testing.T) {
+		handler, _, _ := setupTemplateHandler(t)
+		c, w := templateRequest("POST", "/api/message-templates/preview", gin.H{
+			"patient_id":  "patient-1",
+			"reminder_id": "reminder-1",
+		}, nil)
+
+		handler.PreviewTemplate(c)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var response struct {
+			Data struct {
+				Message  string `json:"message"`
+				Language string `json:"language"`
+			} `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		if !strings.HasPrefix(response.Data.Message, "Hello Budi,") || !strings.Contains(response.Data.Message, "*Minum obat*") {
+			t.Errorf("Expected English message for patient, got %q", response.Data.Message)
+		}
+		if response.Data.Language != models.LanguageEnglish {
+			t.Errorf("Expected language en, got %q", response.Data.Language)
+		}
+	})
+
+	t.Run("renders unsaved body", func(t *testing.T) {
+		handler, _, _ := setupTemplateHandler(t)
+		c, w := templateRequest("POST", "/api/message-templates/preview", gin.H{
+			"patient_id":  "patient-1",
+			"reminder_id": "reminder-1",
+			"body":        "{{upper .PatientName}} - {{.Description}}",
+		}, nil)
+
+		handler.PreviewTemplate(c)
+
+		if !strings.Contains(w.Body.String(), "BUDI - Setelah makan") {
+			t.Errorf("Expected rendered body, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("reports render errors", func(t *testing.T) {
+		handler, _, _ := setupTemplateHandler(t)
+		c, w := templateRequest("POST", "/api/message-templates/preview", gin.H{
+			"patient_id":  "patient-1",
+			"reminder_id": "reminder-1",
+			"body":        "{{.Nope}}",
+		}, nil)
+
+		handler.PreviewTemplate(c)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("unknown reminder", func(t *testing.T) {
+		handler, _, _ := setupTemplateHandler(t)
+		c, w := templateRequest("POST", "/api/message-templates/preview", gin.H{
+			"patient_id":  "patient-1",
+			"reminder_id": "missing",
+		}, nil)
+
+		handler.PreviewTemplate(c)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestReminderHandler_FormatReminderMessage_Template(t *testing.T) {
+	handler, _ := setupTestHandler(t, nil)
+	templateStore := models.NewTemplateStore(nil)
+	templateStore.Templates["t1"] = &models.MessageTemplate{
+		ID:   "t1",
+		Name: "brief",
+		Variants: map[string]string{
+			models.LanguageIndonesian: "Halo {{.PatientName}}: {{.Title}}",
+			models.LanguageEnglish:    "Hi {{.PatientName}}: {{.Title}}",
+		},
+	}
+	templateStore.ByName["brief"] = "t1"
+	handler.SetTemplateStore(templateStore)
+
+	patient := &models.Patient{Name: "Budi", Language: models.LanguageEnglish}
+	reminder := &models.Reminder{Title: "Kontrol", MessageTemplate: "brief"}
+
+	if got := handler.formatReminderMessage(reminder, patient); got != "Hi Budi: Kontrol" {
+		t.Errorf("Expected English template variant, got %q", got)
+	}
+
+	// Unknown template name falls back to the built-in layout
+	reminder.MessageTemplate = "missing"
+	if got := handler.formatReminderMessage(reminder, patient); !strings.HasPrefix(got, "Hello Budi,") {
+		t.Errorf("Expected built-in English layout, got %q", got)
+	}
+}
@@ -1,16 +1,21 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
 	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/services"
 	"github.com/davidyusaku-13/prima_v2/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -18,54 +23,108 @@ import (
 // WebhookHandler handles GOWA webhook callbacks for delivery status updates
 type WebhookHandler struct {
 	patientStore *models.PatientStore
+	eventStore   *models.WebhookEventStore // Event log, also backs idempotency
 	config       *config.Config
 	logger       *slog.Logger
-	sseHandler   *SSEHandler // SSE handler for broadcasting updates
+	bus          *events.Bus // Domain events, none published if nil
+	medications  *services.MedicationPlanner
+	generateID   func() string
+	nonces       *utils.NonceCache   // Nonces of timestamped webhooks, to reject replays
+	onRejected   func(reason string) // Reports rejected webhooks, e.g. to the health endpoint
+
+	gowaClient    *services.GOWAClient                                // Suspended while the device is logged out
+	onDeviceEvent func(event string, result services.GOWAProbeResult) // Reports device events, e.g. to the health endpoint
 }
 
-// processedWebhook tracks webhooks that have been processed for idempotency
-type processedWebhook struct {
-	messageID   string
-	status      string
-	processedAt time.Time
-}
+// GOWA webhook event types
+const (
+	GOWAEventMessageAck         = "message.ack"
+	GOWAEventMessage            = "message"         // Incoming message
+	GOWAEventMessageRevoked     = "message.revoked" // Deleted for everyone
+	GOWAEventMessageDeleted     = "message.deleted" // Deleted on the device only
+	GOWAEventMessageEdited      = "message.edited"
+	GOWAEventDeviceConnected    = "device.connected"
+	GOWAEventDeviceDisconnected = "device.disconnected"
+	GOWAEventDeviceLoggedOut    = "device.logged_out"
+)
 
-var (
-	// webhookProcessor tracks processed webhooks for idempotency
-	webhookProcessor = struct {
-		mu       sync.RWMutex
-		webhooks map[string]processedWebhook
-	}{
-		webhooks: make(map[string]processedWebhook),
-	}
-	webhookTTL = 24 * time.Hour
+// Webhook rejection reasons
+const (
+	WebhookRejectMissingSignature = "missing_signature"
+	WebhookRejectInvalidSignature = "invalid_signature"
+	WebhookRejectMissingTimestamp = "missing_timestamp"
+	WebhookRejectStaleTimestamp   = "stale_timestamp"
+	WebhookRejectReplayedNonce    = "replayed_nonce"
+	WebhookRejectInvalidPayload   = "invalid_payload"
 )
 
+// webhookRejection describes why a webhook failed verification
+type webhookRejection struct {
+	reason    string // WebhookReject*
+	signature string // Event log signature result
+	message   string
+	code      string
+}
+
 // NewWebhookHandler creates a new webhook handler
-func NewWebhookHandler(patientStore *models.PatientStore, cfg *config.Config, logger *slog.Logger) *WebhookHandler {
+func NewWebhookHandler(patientStore *models.PatientStore, eventStore *models.WebhookEventStore, cfg *config.Config, logger *slog.Logger, generateID func() string) *WebhookHandler {
 	return &WebhookHandler{
 		patientStore: patientStore,
+		eventStore:   eventStore,
 		config:       cfg,
 		logger:       logger,
-		sseHandler:   nil, // Will be set via SetSSEHandler
+		bus:          nil, // Will be set via SetEventBus
+		generateID:   generateID,
+		// A timestamp is accepted up to the tolerance either side of now
+		nonces: utils.NewNonceCache(2 * cfg.GOWA.WebhookTolerance),
 	}
 }
 
-// SetSSEHandler sets the SSE handler for broadcasting delivery status updates
-func (h *WebhookHandler) SetSSEHandler(sseHandler *SSEHandler) {
-	h.sseHandler = sseHandler
+// SetEventBus sets the bus that delivery status changes are published on
+func (h *WebhookHandler) SetEventBus(bus *events.Bus) {
+	h.bus = bus
+}
+
+// SetRejectionHandler sets the callback notified with the reason of every rejected webhook
+func (h *WebhookHandler) SetRejectionHandler(handler func(reason string)) {
+	h.onRejected = handler
+}
+
+// SetGOWAClient sets the client taken out of the send rotation while its device is logged out
+func (h *WebhookHandler) SetGOWAClient(client *services.GOWAClient) {
+	h.gowaClient = client
+}
+
+// SetDeviceEventHandler sets the callback notified of device connection and logout events
+func (h *WebhookHandler) SetDeviceEventHandler(handler func(event string, result services.GOWAProbeResult)) {
+	h.onDeviceEvent = handler
+}
+
+// SetMedicationPlanner sets the planner that records dose confirmations from patient replies
+func (h *WebhookHandler) SetMedicationPlanner(planner *services.MedicationPlanner) {
+	h.medications = planner
 }
 
 // GOWAPayload represents the webhook payload from GOWA
 type GOWAPayload struct {
 	Event   string      `json:"event"`
+	From    string      `json:"from,omitempty"` // Sender JID of incoming messages
 	Message MessageAck  `json:"message"`
+	Device  *GOWADevice `json:"device,omitempty"` // Device events only
 }
 
-// MessageAck represents the message acknowledgment data
-type MessageAck struct {
+// GOWADevice identifies the WhatsApp device session of a device event
+type GOWADevice struct {
 	ID     string `json:"id"`
-	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// MessageAck represents the message acknowledgment data, or an incoming message
+type MessageAck struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Text      string `json:"text,omitempty"`       // Incoming and edited messages only
+	RepliedID string `json:"replied_id,omitempty"` // ID of the quoted message, if the message is a reply
 }
 
 // WebhookResponse represents the response from webhook processing
@@ -76,11 +135,115 @@ type WebhookResponse struct {
 	Code    string      `json:"code,omitempty"`
 }
 
+// webhookIdempotencyKey identifies a webhook so redeliveries are only processed once.
+// Edits and device events have no key: a message may be edited several times, and
+// applying the same edit or device state again is harmless.
+func webhookIdempotencyKey(payload *GOWAPayload) string {
+	switch payload.Event {
+	case GOWAEventMessageAck, GOWAEventMessage:
+		return payload.Message.ID + ":" + payload.Message.Status
+	case GOWAEventMessageRevoked, GOWAEventMessageDeleted:
+		return payload.Event + ":" + payload.Message.ID
+	}
+	return ""
+}
+
+// recordEvent appends a received webhook to the event log
+func (h *WebhookHandler) recordEvent(event *models.WebhookEvent, payload *GOWAPayload, outcome string, result string) {
+	event.ID = h.generateID()
+	event.ReceivedAt = time.Now().UTC().Format(time.RFC3339)
+	if payload != nil {
+		event.Event = payload.Event
+		event.MessageID = payload.Message.ID
+		event.Status = payload.Message.Status
+		event.IdempotencyKey = webhookIdempotencyKey(payload)
+	}
+	// Requests that failed the checks are unauthenticated or unusable; keep only their
+	// size and hash so they cannot fill the permanent log
+	if outcome == models.WebhookOutcomeRejected || outcome == models.WebhookOutcomeInvalid {
+		sum := sha256.Sum256([]byte(event.Payload))
+		event.PayloadSize = len(event.Payload)
+		event.PayloadSHA256 = hex.EncodeToString(sum[:])
+		event.Payload = ""
+	}
+	event.Outcome = outcome
+	event.Result = result
+	h.eventStore.Append(event)
+}
+
+// reportRejection notifies the rejection handler, if set
+func (h *WebhookHandler) reportRejection(reason string) {
+	if h.onRejected != nil {
+		h.onRejected(reason)
+	}
+}
+
+// verifyWebhook checks the X-Webhook-Signature against every accepted secret. A timestamped
+// webhook signs "<timestamp>.<nonce>.<body>" (X-Webhook-Timestamp in Unix seconds and
+// X-Webhook-Nonce); it must be within the configured tolerance and its nonce unused. Unless
+// timestamps are required, a webhook without them may sign just the body.
+// Returns nil if the webhook is authentic.
+func (h *WebhookHandler) verifyWebhook(c *gin.Context, body []byte, now time.Time) *webhookRejection {
+	signature := c.GetHeader("X-Webhook-Signature")
+	if signature == "" {
+		return &webhookRejection{WebhookRejectMissingSignature, models.WebhookSignatureMissing, "Missing webhook signature", "MISSING_SIGNATURE"}
+	}
+	invalid := &webhookRejection{WebhookRejectInvalidSignature, models.WebhookSignatureInvalid, "Invalid webhook signature", "INVALID_SIGNATURE"}
+	secrets := h.config.GOWA.WebhookSecrets()
+
+	timestamp := c.GetHeader("X-Webhook-Timestamp")
+	nonce := c.GetHeader("X-Webhook-Nonce")
+	if timestamp == "" && nonce == "" && !h.config.GOWA.WebhookTimestampRequired() {
+		if !utils.ValidateWebhookSignatureAny(body, signature, secrets) {
+			return invalid
+		}
+		return nil
+	}
+	if timestamp == "" || nonce == "" {
+		return &webhookRejection{WebhookRejectMissingTimestamp, models.WebhookSignatureNoTimestamp, "Missing webhook timestamp or nonce", "MISSING_TIMESTAMP"}
+	}
+	if !utils.ValidateWebhookSignatureAny(utils.TimestampedWebhookPayload(timestamp, nonce, body), signature, secrets) {
+		return invalid
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return &webhookRejection{WebhookRejectStaleTimestamp, models.WebhookSignatureExpired, "Invalid webhook timestamp", "STALE_TIMESTAMP"}
+	}
+	skew := now.Sub(time.Unix(unix, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > h.config.GOWA.WebhookTolerance {
+		return &webhookRejection{WebhookRejectStaleTimestamp, models.WebhookSignatureExpired, "Webhook timestamp outside the accepted window", "STALE_TIMESTAMP"}
+	}
+	if h.nonces.Seen(nonce, now) {
+		return &webhookRejection{WebhookRejectReplayedNonce, models.WebhookSignatureReplayed, "Webhook nonce already used", "REPLAYED_NONCE"}
+	}
+	return nil
+}
+
 // HandleGOWAWebhook processes incoming GOWA webhook callbacks
 // POST /api/webhook/gowa
 func (h *WebhookHandler) HandleGOWAWebhook(c *gin.Context) {
-	// Read request body
+	// Read request body, up to the configured limit
+	if limit := h.config.GOWA.WebhookMaxBodyBytes; limit > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	}
 	body, err := io.ReadAll(c.Request.Body)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		if h.logger != nil {
+			h.logger.Warn("Webhook body too large",
+				"limit", tooLarge.Limit,
+			)
+		}
+		c.JSON(http.StatusRequestEntityTooLarge, WebhookResponse{
+			Error: "Request body too large",
+			Code:  "PAYLOAD_TOO_LARGE",
+		})
+		return
+	}
 	if err != nil {
 		if h.logger != nil {
 			h.logger.Error("Failed to read webhook body",
@@ -93,33 +256,25 @@ func (h *WebhookHandler) HandleGOWAWebhook(c *gin.Context) {
 		})
 		return
 	}
+	event := &models.WebhookEvent{Payload: string(body)}
 
-	// Validate HMAC signature
-	signature := c.GetHeader("X-Webhook-Signature")
-	if signature == "" {
+	// Validate HMAC signature, timestamp and nonce
+	if rejection := h.verifyWebhook(c, body, time.Now()); rejection != nil {
 		if h.logger != nil {
-			h.logger.Warn("Webhook received without signature header")
-		}
-		c.JSON(http.StatusUnauthorized, WebhookResponse{
-			Error: "Missing webhook signature",
-			Code:  "MISSING_SIGNATURE",
-		})
-		return
-	}
-
-	// Validate signature using HMAC-SHA256 (uses utils for secure validation)
-	if !utils.ValidateWebhookSignature(body, signature, h.config.GOWA.WebhookSecret) {
-		if h.logger != nil {
-			h.logger.Warn("Webhook received with invalid signature",
-				"signature_length", len(signature),
+			h.logger.Warn("Webhook rejected",
+				"reason", rejection.reason,
 			)
 		}
+		event.Signature = rejection.signature
+		h.recordEvent(event, nil, models.WebhookOutcomeRejected, rejection.message)
+		h.reportRejection(rejection.reason)
 		c.JSON(http.StatusUnauthorized, WebhookResponse{
-			Error: "Invalid webhook signature",
-			Code:  "INVALID_SIGNATURE",
+			Error: rejection.message,
+			Code:  rejection.code,
 		})
 		return
 	}
+	event.Signature = models.WebhookSignatureValid
 
 	// Parse webhook payload
 	var payload GOWAPayload
@@ -129,6 +284,8 @@ func (h *WebhookHandler) HandleGOWAWebhook(c *gin.Context) {
 				"error", err.Error(),
 			)
 		}
+		h.recordEvent(event, nil, models.WebhookOutcomeInvalid, "Invalid webhook payload")
+		h.reportRejection(WebhookRejectInvalidPayload)
 		c.JSON(http.StatusBadRequest, WebhookResponse{
 			Error: "Invalid webhook payload",
 			Code:  "INVALID_PAYLOAD",
@@ -136,15 +293,23 @@ func (h *WebhookHandler) HandleGOWAWebhook(c *gin.Context) {
 		return
 	}
 
-	// Check for idempotency - skip if already processed
-	idempotencyKey := payload.Message.ID + ":" + payload.Message.Status
-	if isWebhookProcessed(idempotencyKey) {
+	// Check for idempotency - skip if already processed or being processed by a concurrent
+	// delivery of the same event
+	key := webhookIdempotencyKey(&payload)
+	processedBy, claimed := h.eventStore.Claim(key)
+	if !claimed {
 		if h.logger != nil {
 			h.logger.Debug("Webhook already processed (idempotent skip)",
 				"message_id", payload.Message.ID,
 				"status", payload.Message.Status,
+				"processed_by", processedBy,
 			)
 		}
+		result := "Already processed by event " + processedBy
+		if processedBy == "" {
+			result = "Already being processed by another delivery"
+		}
+		h.recordEvent(event, &payload, models.WebhookOutcomeDuplicate, result)
 		c.JSON(http.StatusOK, WebhookResponse{
 			Data:    map[string]string{"message_id": payload.Message.ID},
 			Message: "Webhook already processed",
@@ -152,10 +317,27 @@ func (h *WebhookHandler) HandleGOWAWebhook(c *gin.Context) {
 		return
 	}
 
-	// Process based on event type
+	outcome, response := h.processPayload(&payload)
+	h.recordEvent(event, &payload, outcome, response.Message)
+	h.eventStore.Release(key)
+	c.JSON(http.StatusOK, response)
+}
+
+// processPayload applies a verified webhook and returns its outcome and response
+func (h *WebhookHandler) processPayload(payload *GOWAPayload) (string, WebhookResponse) {
 	switch payload.Event {
-	case "message.ack":
-		h.processMessageAck(c, &payload)
+	case GOWAEventMessageAck:
+		return h.processMessageAck(payload)
+	case GOWAEventMessage:
+		return h.processIncomingMessage(payload)
+	case GOWAEventMessageRevoked:
+		return h.processMessageRevoked(payload)
+	case GOWAEventMessageDeleted:
+		return h.processMessageDeleted(payload)
+	case GOWAEventMessageEdited:
+		return h.processMessageEdited(payload)
+	case GOWAEventDeviceConnected, GOWAEventDeviceDisconnected, GOWAEventDeviceLoggedOut:
+		return h.processDeviceEvent(payload)
 	default:
 		if h.logger != nil {
 			h.logger.Warn("Unknown webhook event type",
@@ -163,15 +345,44 @@ func (h *WebhookHandler) HandleGOWAWebhook(c *gin.Context) {
 				"message_id", payload.Message.ID,
 			)
 		}
-		c.JSON(http.StatusOK, WebhookResponse{
+		return models.WebhookOutcomeIgnored, WebhookResponse{
 			Data:    map[string]string{"message_id": payload.Message.ID},
 			Message: fmt.Sprintf("Event type '%s' acknowledged but not processed", payload.Event),
-		})
+		}
+	}
+}
+
+// processIncomingMessage records patient replies to dose reminders as dose confirmations
+func (h *WebhookHandler) processIncomingMessage(payload *GOWAPayload) (string, WebhookResponse) {
+	messageID := payload.Message.ID
+	if h.medications == nil {
+		return models.WebhookOutcomeIgnored, WebhookResponse{
+			Data:    map[string]string{"message_id": messageID},
+			Message: "Incoming message acknowledged but not processed",
+		}
+	}
+
+	result, ok := h.medications.ConfirmDoseReply(payload.From, payload.Message.ID, payload.Message.Text, payload.Message.RepliedID, time.Now())
+	if !ok {
+		return models.WebhookOutcomeProcessed, WebhookResponse{
+			Data:    map[string]string{"message_id": messageID},
+			Message: "Incoming message is not a dose confirmation",
+		}
+	}
+
+	return models.WebhookOutcomeProcessed, WebhookResponse{
+		Data: map[string]interface{}{
+			"message_id":    messageID,
+			"reminder_id":   result.ReminderID,
+			"medication_id": result.MedicationID,
+			"dose_status":   result.Status,
+		},
+		Message: fmt.Sprintf("Dose recorded as '%s'", result.Status),
 	}
 }
 
 // processMessageAck processes message acknowledgment events
-func (h *WebhookHandler) processMessageAck(c *gin.Context, payload *GOWAPayload) {
+func (h *WebhookHandler) processMessageAck(payload *GOWAPayload) (string, WebhookResponse) {
 	messageID := payload.Message.ID
 	newStatus := payload.Message.Status
 
@@ -188,6 +399,7 @@ func (h *WebhookHandler) processMessageAck(c *gin.Context, payload *GOWAPayload)
 
 	var updatedReminder *models.Reminder
 	var patientID string
+	var volunteerID string
 	var patientName string
 	var patientPhone string
 	var previousStatus string
@@ -199,17 +411,15 @@ func (h *WebhookHandler) processMessageAck(c *gin.Context, payload *GOWAPayload)
 				// Capture previous status BEFORE updating
 				previousStatus = string(reminder.DeliveryStatus)
 
-				// Update delivery status based on acknowledgment status
+				// Map the acknowledgment status to a delivery status
+				var target string
 				switch newStatus {
 				case "delivered":
-					reminder.DeliveryStatus = models.DeliveryStatusDelivered
-					reminder.DeliveredAt = time.Now().UTC().Format(time.RFC3339)
+					target = models.DeliveryStatusDelivered
 				case "read":
-					reminder.DeliveryStatus = models.DeliveryStatusRead
-					reminder.ReadAt = time.Now().UTC().Format(time.RFC3339)
+					target = models.DeliveryStatusRead
 				case "failed":
-					reminder.DeliveryStatus = models.DeliveryStatusFailed
-					reminder.DeliveryErrorMessage = "Delivery failed according to GOWA webhook"
+					target = models.DeliveryStatusFailed
 				default:
 					// Log unknown status but don't update
 					if h.logger != nil {
@@ -218,15 +428,41 @@ func (h *WebhookHandler) processMessageAck(c *gin.Context, payload *GOWAPayload)
 							"status", newStatus,
 						)
 					}
-					c.JSON(http.StatusOK, WebhookResponse{
+					return models.WebhookOutcomeIgnored, WebhookResponse{
 						Data:    map[string]string{"message_id": messageID},
 						Message: fmt.Sprintf("Status '%s' acknowledged but not processed", newStatus),
-					})
-					return
+					}
+				}
+
+				// Acks for a recipient may arrive out of order; the delivery state machine
+				// keeps a late "delivered" from moving a read reminder back
+				now := time.Now().UTC()
+				if err := reminder.TransitionTo(target, models.StatusActorWebhook, "", "GOWA ack "+messageID, now); err != nil {
+					if h.logger != nil {
+						h.logger.Info("Message acknowledgment not applied",
+							"message_id", messageID,
+							"current_status", previousStatus,
+							"status", newStatus,
+						)
+					}
+					return models.WebhookOutcomeIgnored, WebhookResponse{
+						Data:    map[string]string{"message_id": messageID, "delivery_status": previousStatus},
+						Message: fmt.Sprintf("Status '%s' cannot follow current status '%s', not applied", newStatus, previousStatus),
+					}
+				}
+				switch target {
+				case models.DeliveryStatusDelivered:
+					reminder.DeliveredAt = now.Format(time.RFC3339)
+				case models.DeliveryStatusRead:
+					reminder.ReadAt = now.Format(time.RFC3339)
+				case models.DeliveryStatusFailed:
+					reminder.DeliveryErrorMessage = "Delivery failed according to GOWA webhook"
+					reminder.DeliveryFailureCode = models.FailureCodeMessageRejected
 				}
 
 				updatedReminder = reminder
 				patientID = patient.ID
+				volunteerID = patient.CreatedBy
 				patientName = patient.Name
 				patientPhone = utils.MaskPhone(patient.Phone)
 				break
@@ -243,16 +479,12 @@ func (h *WebhookHandler) processMessageAck(c *gin.Context, payload *GOWAPayload)
 				"message_id", messageID,
 			)
 		}
-		c.JSON(http.StatusOK, WebhookResponse{
+		return models.WebhookOutcomeIgnored, WebhookResponse{
 			Data:    map[string]string{"message_id": messageID},
 			Message: "Message ID not found, may have been deleted",
-		})
-		return
+		}
 	}
 
-	// Mark webhook as processed (idempotency)
-	markWebhookProcessed(messageID, newStatus)
-
 	// Log the status update for audit purposes (FR36)
 	if h.logger != nil {
 		h.logger.Info("Reminder delivery status updated",
@@ -268,84 +500,45 @@ func (h *WebhookHandler) processMessageAck(c *gin.Context, payload *GOWAPayload)
 	// Save data
 	h.patientStore.SaveData()
 
-	// Broadcast SSE event for real-time updates (if SSE handler is configured)
-	if h.sseHandler != nil {
-		h.sseHandler.BroadcastDeliveryStatusUpdate(
-			updatedReminder.ID,
-			string(updatedReminder.DeliveryStatus),
-			time.Now().UTC().Format(time.RFC3339),
-		)
+	// Publish the status change for real-time updates (if the event bus is configured)
+	if h.bus != nil {
+		now := time.Now().UTC()
+		h.bus.Publish(events.DeliveryStatusChanged{
+			ReminderID:  updatedReminder.ID,
+			PatientID:   patientID,
+			VolunteerID: volunteerID,
+			From:        previousStatus,
+			Status:      string(updatedReminder.DeliveryStatus),
+			At:          now,
+		})
 
-		// Broadcast delivery.failed event if status is failed
+		// Publish a delivery failure if status is failed
 		if newStatus == "failed" {
-			h.sseHandler.BroadcastDeliveryFailed(
-				updatedReminder.ID,
-				patientID,
-				patientName,
-				updatedReminder.DeliveryErrorMessage,
-			)
+			h.bus.Publish(events.DeliveryFailed{
+				ReminderID:  updatedReminder.ID,
+				PatientID:   patientID,
+				VolunteerID: volunteerID,
+				PatientName: patientName,
+				Error:       updatedReminder.DeliveryErrorMessage,
+				FailureCode: updatedReminder.DeliveryFailureCode,
+				At:          now,
+			})
 		}
 	} else {
-		// Log warning if SSE handler not configured (should be set via SetSSEHandler)
+		// Log warning if the event bus is not configured (should be set via SetEventBus)
 		if h.logger != nil {
-			h.logger.Warn("SSE handler not configured, real-time updates unavailable",
+			h.logger.Warn("Event bus not configured, real-time updates unavailable",
 				"reminder_id", updatedReminder.ID,
 			)
 		}
 	}
 
-	c.JSON(http.StatusOK, WebhookResponse{
+	return models.WebhookOutcomeProcessed, WebhookResponse{
 		Data: map[string]interface{}{
 			"message_id":      messageID,
 			"reminder_id":     updatedReminder.ID,
 			"delivery_status": newStatus,
 		},
 		Message: fmt.Sprintf("Reminder status updated to '%s'", newStatus),
-	})
-}
-
-// isWebhookProcessed checks if a webhook has already been processed
-func isWebhookProcessed(key string) bool {
-	webhookProcessor.mu.RLock()
-	defer webhookProcessor.mu.RUnlock()
-
-	entry, exists := webhookProcessor.webhooks[key]
-	if !exists {
-		return false
-	}
-
-	// Check if entry has expired
-	if time.Since(entry.processedAt) > webhookTTL {
-		return false
-	}
-
-	return true
-}
-
-// markWebhookProcessed records a webhook as processed
-func markWebhookProcessed(messageID, status string) {
-	webhookProcessor.mu.Lock()
-	defer webhookProcessor.mu.Unlock()
-
-	key := messageID + ":" + status
-	webhookProcessor.webhooks[key] = processedWebhook{
-		messageID:   messageID,
-		status:      status,
-		processedAt: time.Now(),
-	}
-
-	// Clean up old entries periodically
-	if len(webhookProcessor.webhooks) > 1000 {
-		cleanupExpiredWebhooks()
-	}
-}
-
-// cleanupExpiredWebhooks removes expired webhook entries
-func cleanupExpiredWebhooks() {
-	cutoff := time.Now().Add(-webhookTTL)
-	for key, entry := range webhookProcessor.webhooks {
-		if entry.processedAt.Before(cutoff) {
-			delete(webhookProcessor.webhooks, key)
-		}
 	}
 }
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/services"
+)
+
+// processDeviceEvent records a device connection change. A logged-out device takes the
+// GOWA client out of the send rotation until the device connects again.
+func (h *WebhookHandler) processDeviceEvent(payload *GOWAPayload) (string, WebhookResponse) {
+	result := services.GOWAProbeResult{Reachable: true, CheckedAt: time.Now().UTC()}
+	var reason string
+	if payload.Device != nil {
+		result.DeviceID = payload.Device.ID
+		reason = payload.Device.Reason
+	}
+
+	message := "Device connection recorded"
+	switch payload.Event {
+	case GOWAEventDeviceConnected:
+		result.Connected = true
+		result.LoggedIn = true
+		if h.gowaClient != nil {
+			h.gowaClient.ResumeSending("device_connected")
+		}
+	case GOWAEventDeviceDisconnected:
+		// The session stays logged in; GOWA reconnects on its own
+		result.LoggedIn = true
+		result.Error = "device disconnected"
+		message = "Device disconnect recorded"
+	case GOWAEventDeviceLoggedOut:
+		result.Error = "device logged out"
+		if h.gowaClient != nil {
+			h.gowaClient.SuspendSending("device_logged_out")
+		}
+		message = "Device logout recorded, sending suspended"
+	}
+	if reason != "" && result.Error != "" {
+		result.Error += ": " + reason
+	}
+
+	if h.onDeviceEvent != nil {
+		h.onDeviceEvent(payload.Event, result)
+	}
+
+	if h.logger != nil {
+		h.logger.Warn("GOWA device event",
+			"event", payload.Event,
+			"device_id", result.DeviceID,
+			"reason", reason,
+		)
+	}
+
+	return models.WebhookOutcomeProcessed, WebhookResponse{
+		Data:    map[string]string{"event": payload.Event, "device_id": result.DeviceID},
+		Message: message,
+	}
+}
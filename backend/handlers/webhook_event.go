@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ListWebhookEvents returns the webhook event log, newest first, without payloads.
+// Filters: event, outcome, message_id; limit defaults to 100 (max 500).
+// GET /api/webhook-events
+func (h *WebhookHandler) ListWebhookEvents(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if limit < 1 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	eventType := c.Query("event")
+	outcome := c.Query("outcome")
+	messageID := c.Query("message_id")
+
+	events := make([]models.WebhookEvent, 0, limit)
+	h.eventStore.Mu.RLock()
+	for i := len(h.eventStore.Events) - 1; i >= 0 && len(events) < limit; i-- {
+		e := h.eventStore.Events[i]
+		if eventType != "" && e.Event != eventType {
+			continue
+		}
+		if outcome != "" && e.Outcome != outcome {
+			continue
+		}
+		if messageID != "" && e.MessageID != messageID {
+			continue
+		}
+		summary := *e
+		summary.Payload = ""
+		events = append(events, summary)
+	}
+	h.eventStore.Mu.RUnlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    events,
+		"message": "Webhook events retrieved successfully",
+	})
+}
+
+// GetWebhookEvent returns one logged webhook event including its raw payload
+// GET /api/webhook-events/:id
+func (h *WebhookHandler) GetWebhookEvent(c *gin.Context) {
+	event, ok := h.eventStore.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook event not found", "code": "WEBHOOK_EVENT_NOT_FOUND"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    event,
+		"message": "Webhook event retrieved successfully",
+	})
+}
+
+// ReplayWebhookEvent processes a logged webhook again, ignoring idempotency, and logs
+// the replay as a new event. Only events that passed the signature check can be replayed.
+// POST /api/webhook-events/:id/replay
+func (h *WebhookHandler) ReplayWebhookEvent(c *gin.Context) {
+	original, ok := h.eventStore.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook event not found", "code": "WEBHOOK_EVENT_NOT_FOUND"})
+		return
+	}
+	if original.Signature != models.WebhookSignatureValid {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only webhooks with a valid signature can be replayed", "code": "WEBHOOK_EVENT_NOT_REPLAYABLE"})
+		return
+	}
+	var payload GOWAPayload
+	if err := json.Unmarshal([]byte(original.Payload), &payload); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Webhook payload cannot be parsed", "code": "WEBHOOK_EVENT_NOT_REPLAYABLE"})
+		return
+	}
+
+	outcome, response := h.processPayload(&payload)
+	replay := &models.WebhookEvent{
+		Payload:    original.Payload,
+		Signature:  original.Signature,
+		ReplayOf:   original.ID,
+		ReplayedBy: c.GetString("userID"),
+	}
+	h.recordEvent(replay, &payload, outcome, response.Message)
+
+	if h.logger != nil {
+		h.logger.Info("Webhook event replayed",
+			"event_id", original.ID,
+			"replay_id", replay.ID,
+			"outcome", outcome,
+			"replayed_by", replay.ReplayedBy,
+		)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"event":  replay,
+			"result": response.Data,
+		},
+		"message": response.Message,
+	})
+}
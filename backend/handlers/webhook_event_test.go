@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func TestWebhookEventLog(t *testing.T) {
+	handler, patientStore := setupWebhookTestHandler()
+	patientStore.Patients["patient-1"] = &models.Patient{
+		ID:    "patient-1",
+		Name:  "Test Patient",
+		Phone: "628123456789",
+		Reminders: []*models.Reminder{
+			{ID: "reminder-1", GOWAMessageID: "gowa-msg-1", DeliveryStatus: models.DeliveryStatusSent},
+		},
+	}
+
+	router := gin.New()
+	router.POST("/api/webhook/gowa", handler.HandleGOWAWebhook)
+	post := func(body []byte, signature string) int {
+		req, _ := http.NewRequest("POST", "/api/webhook/gowa", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		if signature != "" {
+			req.Header.Set("X-Webhook-Signature", signature)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	ack := []byte(`{"event":"message.ack","message":{"id":"gowa-msg-1","status":"delivered"}}`)
+	post(ack, "forged")                                                                    // event-1
+	post(ack, generateTestSignature(ack, "test-secret-key"))                               // event-2
+	post(ack, generateTestSignature(ack, "test-secret-key"))                               // event-3
+	post([]byte(`not json`), generateTestSignature([]byte(`not json`), "test-secret-key")) // event-4
+
+	want := []struct {
+		signature, outcome string
+	}{
+		{models.WebhookSignatureInvalid, models.WebhookOutcomeRejected},
+		{models.WebhookSignatureValid, models.WebhookOutcomeProcessed},
+		{models.WebhookSignatureValid, models.WebhookOutcomeDuplicate},
+		{models.WebhookSignatureValid, models.WebhookOutcomeInvalid},
+	}
+	events := handler.eventStore.Events
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d logged events, got %d", len(want), len(events))
+	}
+	for i, w := range want {
+		if events[i].Signature != w.signature || events[i].Outcome != w.outcome {
+			t.Errorf("Event %d: expected %s/%s, got %s/%s", i+1, w.signature, w.outcome, events[i].Signature, events[i].Outcome)
+		}
+		// Only events that passed the checks keep their raw payload
+		kept := w.outcome == models.WebhookOutcomeProcessed || w.outcome == models.WebhookOutcomeDuplicate
+		if kept && events[i].Payload == "" {
+			t.Errorf("Event %d: expected raw payload stored", i+1)
+		}
+		if !kept && (events[i].Payload != "" || events[i].PayloadSize == 0 || events[i].PayloadSHA256 == "") {
+			t.Errorf("Event %d: expected only payload size and hash stored, got %+v", i+1, events[i])
+		}
+	}
+	if events[3].PayloadSize != len("not json") {
+		t.Errorf("Expected invalid payload size recorded, got %d", events[3].PayloadSize)
+	}
+	if events[1].IdempotencyKey != "gowa-msg-1:delivered" || events[2].Result != "Already processed by event event-2" {
+		t.Errorf("Unexpected idempotency bookkeeping: %+v %+v", events[1], events[2])
+	}
+
+	t.Run("list newest first without payloads", func(t *testing.T) {
+		w := roleRequest(handler.ListWebhookEvents, "GET", nil, "superadmin", nil)
+		var resp struct {
+			Data []models.WebhookEvent `json:"data"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Data) != 4 || resp.Data[0].ID != "event-4" || resp.Data[0].Payload != "" {
+			t.Errorf("Unexpected list %+v", resp.Data)
+		}
+	})
+
+	t.Run("get includes payload", func(t *testing.T) {
+		w := roleRequest(handler.GetWebhookEvent, "GET", gin.Params{{Key: "id", Value: "event-2"}}, "superadmin", nil)
+		if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`gowa-msg-1`)) {
+			t.Errorf("Expected event with payload, got %d %s", w.Code, w.Body.String())
+		}
+		w = roleRequest(handler.GetWebhookEvent, "GET", gin.Params{{Key: "id", Value: "missing"}}, "superadmin", nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("replay reprocesses despite idempotency", func(t *testing.T) {
+		reminder := patientStore.Patients["patient-1"].Reminders[0]
+		reminder.DeliveryStatus = models.DeliveryStatusSent
+
+		w := roleRequest(handler.ReplayWebhookEvent, "POST", gin.Params{{Key: "id", Value: "event-2"}}, "superadmin", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d. Body: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if reminder.DeliveryStatus != models.DeliveryStatusDelivered {
+			t.Errorf("Expected reminder delivered again, got %s", reminder.DeliveryStatus)
+		}
+		replay, ok := handler.eventStore.Get("event-5")
+		if !ok || replay.ReplayOf != "event-2" || replay.ReplayedBy != "superadmin-1" || replay.Outcome != models.WebhookOutcomeProcessed {
+			t.Errorf("Expected replay logged as a new event, got %+v", replay)
+		}
+	})
+
+	t.Run("oversized body rejected unread", func(t *testing.T) {
+		handler.config.GOWA.WebhookMaxBodyBytes = 1024
+		defer func() { handler.config.GOWA.WebhookMaxBodyBytes = 0 }()
+
+		count := len(handler.eventStore.Events)
+		large := bytes.Repeat([]byte("x"), 2048)
+		if code := post(large, generateTestSignature(large, "test-secret-key")); code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, code)
+		}
+		if len(handler.eventStore.Events) != count {
+			t.Error("Expected no event logged for an oversized body")
+		}
+	})
+
+	t.Run("only verified payloads can be replayed", func(t *testing.T) {
+		for _, id := range []string{"event-1", "event-4"} {
+			w := roleRequest(handler.ReplayWebhookEvent, "POST", gin.Params{{Key: "id", Value: id}}, "superadmin", nil)
+			if w.Code != http.StatusConflict {
+				t.Errorf("%s: expected status %d, got %d", id, http.StatusConflict, w.Code)
+			}
+		}
+	})
+}
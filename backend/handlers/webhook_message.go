@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// markReminderMessage applies mark to the reminder sent as the given GOWA message and
+// saves it. It returns the reminder ID, or "" if no reminder was sent as that message.
+func (h *WebhookHandler) markReminderMessage(messageID string, mark func(reminder *models.Reminder)) string {
+	if messageID == "" {
+		return ""
+	}
+
+	h.patientStore.Lock()
+	defer h.patientStore.Unlock()
+
+	for _, patient := range h.patientStore.Patients {
+		for _, reminder := range patient.Reminders {
+			if reminder.GOWAMessageID == messageID {
+				mark(reminder)
+				h.patientStore.SaveData()
+				return reminder.ID
+			}
+		}
+	}
+	return ""
+}
+
+// processMessageRevoked handles a message deleted for everyone. A revoked reminder is
+// marked so it is no longer escalated; a revoked patient reply withdraws its dose confirmation.
+func (h *WebhookHandler) processMessageRevoked(payload *GOWAPayload) (string, WebhookResponse) {
+	messageID := payload.Message.ID
+	now := time.Now().UTC()
+
+	reminderID := h.markReminderMessage(messageID, func(reminder *models.Reminder) {
+		reminder.MessageRevokedAt = now.Format(time.RFC3339)
+	})
+	if reminderID != "" {
+		if h.logger != nil {
+			h.logger.Info("Reminder message revoked",
+				"reminder_id", reminderID,
+				"message_id", messageID,
+			)
+		}
+		return models.WebhookOutcomeProcessed, WebhookResponse{
+			Data:    map[string]string{"message_id": messageID, "reminder_id": reminderID},
+			Message: "Reminder message marked as revoked",
+		}
+	}
+
+	if h.medications != nil {
+		if result, ok := h.medications.ReviseDoseReply(messageID, "", true, now); ok {
+			return models.WebhookOutcomeProcessed, WebhookResponse{
+				Data: map[string]string{
+					"message_id":    messageID,
+					"reminder_id":   result.ReminderID,
+					"medication_id": result.MedicationID,
+				},
+				Message: "Dose confirmation withdrawn",
+			}
+		}
+	}
+
+	return models.WebhookOutcomeIgnored, WebhookResponse{
+		Data:    map[string]string{"message_id": messageID},
+		Message: "Revoked message not found, nothing changed",
+	}
+}
+
+// processMessageEdited handles an edited message. An edited reminder is marked; an
+// edited patient reply revises the dose it confirmed, or confirms a dose if it now
+// reads as a dose answer.
+func (h *WebhookHandler) processMessageEdited(payload *GOWAPayload) (string, WebhookResponse) {
+	messageID := payload.Message.ID
+	now := time.Now().UTC()
+
+	reminderID := h.markReminderMessage(messageID, func(reminder *models.Reminder) {
+		reminder.MessageEditedAt = now.Format(time.RFC3339)
+	})
+	if reminderID != "" {
+		return models.WebhookOutcomeProcessed, WebhookResponse{
+			Data:    map[string]string{"message_id": messageID, "reminder_id": reminderID},
+			Message: "Reminder message marked as edited",
+		}
+	}
+
+	if h.medications == nil {
+		return models.WebhookOutcomeIgnored, WebhookResponse{
+			Data:    map[string]string{"message_id": messageID},
+			Message: "Edited message acknowledged but not processed",
+		}
+	}
+
+	result, ok := h.medications.ReviseDoseReply(messageID, payload.Message.Text, false, now)
+	if !ok {
+		result, ok = h.medications.ConfirmDoseReply(payload.From, messageID, payload.Message.Text, payload.Message.RepliedID, now)
+	}
+	if !ok {
+		return models.WebhookOutcomeProcessed, WebhookResponse{
+			Data:    map[string]string{"message_id": messageID},
+			Message: "Edited message is not a dose confirmation",
+		}
+	}
+
+	message := "Dose confirmation withdrawn"
+	if result.Status != "" {
+		message = fmt.Sprintf("Dose recorded as '%s'", result.Status)
+	}
+	return models.WebhookOutcomeProcessed, WebhookResponse{
+		Data: map[string]interface{}{
+			"message_id":    messageID,
+			"reminder_id":   result.ReminderID,
+			"medication_id": result.MedicationID,
+			"dose_status":   result.Status,
+		},
+		Message: message,
+	}
+}
+
+// processMessageDeleted handles a message deleted on the linked device only. The
+// recipient still has the message, so nothing changes.
+func (h *WebhookHandler) processMessageDeleted(payload *GOWAPayload) (string, WebhookResponse) {
+	return models.WebhookOutcomeProcessed, WebhookResponse{
+		Data:    map[string]string{"message_id": payload.Message.ID},
+		Message: "Message deleted on the device only, nothing changed",
+	}
+}
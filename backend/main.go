@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
@@ -22,6 +24,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
 	"github.com/davidyusaku-13/prima_v2/handlers"
 	"github.com/davidyusaku-13/prima_v2/models"
 	"github.com/davidyusaku-13/prima_v2/services"
@@ -35,6 +38,18 @@ const (
 	categoriesDataFile = "data/categories.json"
 	articlesDataFile   = "data/articles.json"
 	videosDataFile     = "data/videos.json"
+	templatesDataFile  = "data/message_templates.json"
+	shortLinksDataFile = "data/short_links.json"
+	campaignsDataFile  = "data/campaigns.json"
+
+	reminderTemplatesDataFile = "data/reminder_templates.json"
+	carePlansDataFile         = "data/care_plans.json"
+	blackoutsDataFile         = "data/blackouts.json"
+	webhookEventsDataFile     = "data/webhook_events.jsonl" // Append-only, one JSON event per line
+	sseEventsDataFile         = "data/sse_events.json"      // SSE replay buffer, when sse.persist_replay_buffer is set
+
+	outboundWebhooksDataFile   = "data/outbound_webhooks.json"
+	outboundDeliveriesDataFile = "data/outbound_webhook_deliveries.jsonl" // Append-only, one JSON attempt per line
 )
 
 const tokenExpiry = 24 * 7 * time.Hour // 1 week
@@ -152,7 +167,6 @@ func verifyToken(tokenString string) (*Claims, error) {
 	return nil, fmt.Errorf("invalid token")
 }
 
-
 // Patient and Reminder types are now in models/patient.go
 
 // User is stored in memory and file (includes password)
@@ -198,19 +212,44 @@ type UserStore struct {
 }
 
 var (
-	store            = PatientStore{patients: make(map[string]*models.Patient)}
-	userStore        = UserStore{users: make(map[string]*User), byName: make(map[string]string)}
-	contentStore     *handlers.ContentStore
-	appConfig        *config.Config
-	appLogger        *slog.Logger
-	gowaClient       *services.GOWAClient
-	reminderHandler  *handlers.ReminderHandler
-	patientStore     *models.PatientStore
-	scheduler        *services.ReminderScheduler
-	webhookHandler   *handlers.WebhookHandler
-	sseHandler       *handlers.SSEHandler
-	analyticsHandler *handlers.AnalyticsHandler
-	healthHandler    *handlers.HealthHandler
+	store             = PatientStore{patients: make(map[string]*models.Patient)}
+	userStore         = UserStore{users: make(map[string]*User), byName: make(map[string]string)}
+	contentStore      *handlers.ContentStore
+	appConfig         *config.Config
+	appLogger         *slog.Logger
+	gowaClient        *services.GOWAClient
+	reminderHandler   *handlers.ReminderHandler
+	patientStore      *models.PatientStore
+	scheduler         *services.ReminderScheduler
+	webhookHandler    *handlers.WebhookHandler
+	sseHandler        *handlers.SSEHandler
+	eventBus          *events.Bus
+	analyticsHandler  *handlers.AnalyticsHandler
+	healthHandler     *handlers.HealthHandler
+	gowaHealthChecker *services.GOWAHealthChecker
+	templateStore     *models.TemplateStore
+	templateHandler   *handlers.TemplateHandler
+	shortLinkStore    *models.ShortLinkStore
+	shortLinkHandler  *handlers.ShortLinkHandler
+	campaignStore     *models.CampaignStore
+	campaignHandler   *handlers.CampaignHandler
+	campaignRunner    *services.CampaignRunner
+
+	reminderTemplateStore   *models.ReminderTemplateStore
+	reminderTemplateHandler *handlers.ReminderTemplateHandler
+	carePlanStore           *models.CarePlanStore
+	carePlanHandler         *handlers.CarePlanHandler
+	appointmentHandler      *handlers.AppointmentHandler
+	medicationPlanner       *services.MedicationPlanner
+	medicationHandler       *handlers.MedicationHandler
+	blackoutStore           *models.BlackoutStore
+	blackoutHandler         *handlers.BlackoutHandler
+	webhookEventStore       *models.WebhookEventStore
+
+	outboundWebhookStore   *models.OutboundWebhookStore
+	outboundDeliveryStore  *models.OutboundDeliveryStore
+	outboundWebhookSender  *services.OutboundWebhookSender
+	outboundWebhookHandler *handlers.OutboundWebhookHandler
 )
 
 func main() {
@@ -267,59 +306,169 @@ func main() {
 		contentStore, // Pass contentStore for attachment validation
 	)
 
+	// Load message templates and seed the default reminder template
+	templateStore = models.NewTemplateStore(saveTemplates)
+	loadTemplates()
+	templateHandler = handlers.NewTemplateHandler(templateStore, patientStore, contentStore, appConfig, appLogger, generateID)
+	templateHandler.EnsureDefaultTemplate()
+	reminderHandler.SetTemplateStore(templateStore)
+
+	// Load the reminder template library used when creating reminders
+	reminderTemplateStore = models.NewReminderTemplateStore(saveReminderTemplates)
+	loadReminderTemplates()
+	reminderTemplateHandler = handlers.NewReminderTemplateHandler(reminderTemplateStore, contentStore, templateStore, appLogger, generateID)
+	reminderHandler.SetReminderTemplateStore(reminderTemplateStore)
+	reminderTemplateHandler.SetPriorities(&appConfig.Priorities)
+
+	// Load care plan definitions; enrollments are stored on the patient
+	carePlanStore = models.NewCarePlanStore(saveCarePlans)
+	loadCarePlans()
+	carePlanHandler = handlers.NewCarePlanHandler(carePlanStore, patientStore, contentStore, appLogger, generateID)
+	carePlanHandler.SetTemplateStore(templateStore)
+	carePlanHandler.SetReminderTemplateStore(reminderTemplateStore)
+	carePlanHandler.SetPriorities(&appConfig.Priorities)
+
+	// Appointments are stored on the patient; notices are generated as reminders
+	appointmentHandler = handlers.NewAppointmentHandler(patientStore, appConfig, appLogger, generateID)
+
+	// Load tracked content links and connect them to sends and content analytics
+	shortLinkStore = models.NewShortLinkStore(saveShortLinks)
+	loadShortLinks()
+	shortLinkHandler = handlers.NewShortLinkHandler(shortLinkStore, appLogger)
+	shortLinkHandler.Start()
+	reminderHandler.SetShortLinkStore(shortLinkStore)
+	contentStore.SetShortLinkStore(shortLinkStore)
+
+	// Load the blackout calendar that holds back non-exempt reminders
+	blackoutStore = models.NewBlackoutStore(saveBlackouts)
+	loadBlackouts()
+	blackoutHandler = handlers.NewBlackoutHandler(blackoutStore, appLogger, generateID)
+	blackoutHandler.SetPriorities(&appConfig.Priorities)
+	reminderHandler.SetBlackoutStore(blackoutStore)
+
 	// Create default superadmin if not exists
 	createDefaultSuperadmin()
 
 	// Initialize reminder scheduler for quiet hours (Start() called after all setters)
 	scheduler = services.NewReminderScheduler(patientStore, gowaClient, appConfig, appLogger)
 
-	// Initialize webhook handler for GOWA delivery status updates
-	webhookHandler = handlers.NewWebhookHandler(patientStore, appConfig, appLogger)
+	// Initialize webhook handler for GOWA delivery status updates, with its event log
+	webhookEventStore = models.NewWebhookEventStore(appendWebhookEvent)
+	webhookEventStore.RewriteFunc = rewriteWebhookEvents
+	loadWebhookEvents()
+	webhookHandler = handlers.NewWebhookHandler(patientStore, webhookEventStore, appConfig, appLogger, generateID)
 
 	// Initialize SSE handler for real-time delivery status updates
 	sseHandler = handlers.NewSSEHandler(appConfig, appLogger)
+	if appConfig.SSE.PersistReplayBuffer != nil && *appConfig.SSE.PersistReplayBuffer {
+		loadSSEEvents()
+		sseHandler.SetPersistence(saveSSEEvents)
+	}
+	sseHandler.SetUserLookup(func(userID string) (string, bool) {
+		userStore.mu.RLock()
+		defer userStore.mu.RUnlock()
+		user, exists := userStore.users[userID]
+		if !exists {
+			return "", false
+		}
+		return string(user.Role), true
+	})
 
-	// Connect SSE handler to webhook handler for broadcasting
-	webhookHandler.SetSSEHandler(sseHandler)
+	// Domain events are published on the bus; SSE is one of its subscribers
+	eventBus = events.NewBus(appLogger)
+	eventBus.Subscribe("sse", sseHandler.HandleEvent, handlers.SSEEventTypes...)
 
-	// Connect SSE handler to reminder handler for manual send broadcasts
-	reminderHandler.SetSSEHandler(sseHandler)
+	// Partner systems receive subscribed events as signed outbound webhooks
+	outboundWebhookStore = models.NewOutboundWebhookStore(saveOutboundWebhooks)
+	loadOutboundWebhooks()
+	outboundDeliveryStore = models.NewOutboundDeliveryStore(appendOutboundDelivery)
+	outboundDeliveryStore.RewriteFunc = rewriteOutboundDeliveries
+	loadOutboundDeliveries()
 
-	// Connect SSE handler to scheduler for auto-send broadcasts
-	scheduler.SetSSEHandler(sseHandler)
+	// Keep the append-only logs within their retention periods
+	go pruneEventLogs()
+	outboundWebhookSender = services.NewOutboundWebhookSender(outboundWebhookStore, outboundDeliveryStore, appConfig, appLogger, generateID)
+	outboundWebhookHandler = handlers.NewOutboundWebhookHandler(outboundWebhookStore, outboundDeliveryStore, outboundWebhookSender, appLogger, generateID)
+	eventBus.Subscribe("outbound_webhooks", outboundWebhookSender.HandleEvent)
+
+	// Webhook acks, manual sends and scheduled sends publish delivery events
+	webhookHandler.SetEventBus(eventBus)
+	reminderHandler.SetEventBus(eventBus)
+	scheduler.SetEventBus(eventBus)
 
 	// Connect content stores to scheduler for attachment lookup
 	scheduler.SetContentStores(contentStore.Articles, contentStore.Videos)
 
+	// Connect message templates to scheduler for localized messages
+	scheduler.SetTemplateStore(templateStore)
+
+	// Connect tracked content links to scheduler
+	scheduler.SetShortLinkStore(shortLinkStore)
+
+	// Connect the blackout calendar to scheduler
+	scheduler.SetBlackoutStore(blackoutStore)
+
 	// Start scheduler after all setters are configured (avoids race conditions)
 	scheduler.Start()
 
+	// Load bulk campaigns and start the throttled campaign runner
+	campaignStore = models.NewCampaignStore(saveCampaigns)
+	loadCampaigns()
+	campaignHandler = handlers.NewCampaignHandler(campaignStore, patientStore, contentStore, appConfig, appLogger, generateID)
+	campaignHandler.SetTemplateStore(templateStore)
+	campaignHandler.SetEventBus(eventBus)
+	campaignRunner = services.NewCampaignRunner(campaignStore, patientStore, scheduler, appConfig, appLogger, generateID)
+	campaignRunner.SetEventBus(eventBus)
+	campaignRunner.Start()
+
+	// Medications are stored on the patient; dose reminders are generated a few days ahead
+	medicationPlanner = services.NewMedicationPlanner(patientStore, appConfig, appLogger, generateID)
+	medicationHandler = handlers.NewMedicationHandler(patientStore, medicationPlanner, appLogger, generateID)
+	webhookHandler.SetMedicationPlanner(medicationPlanner)
+	medicationPlanner.Start()
+
 	// Initialize analytics handler for delivery statistics
 	analyticsHandler = handlers.NewAnalyticsHandler(patientStore)
 
 	// Initialize health handler for system health monitoring
 	healthHandler = handlers.NewHealthHandler(patientStore, gowaClient)
+	healthHandler.SetSSEHandler(sseHandler)
+	healthHandler.SetEventBus(eventBus)
+
+	// Record circuit breaker transitions in health history and publish them
+	gowaClient.OnCircuitStateChange(func(change services.CircuitStateChange) {
+		healthHandler.RecordCircuitStateChange(change)
+		eventBus.Publish(events.CircuitStateChanged{
+			From:     change.From,
+			To:       change.To,
+			Reason:   change.Reason,
+			Failures: change.Failures,
+			At:       change.Timestamp,
+		})
+	})
 
-	// Start health check goroutine (runs every 60 seconds)
-	go func() {
-		ticker := time.NewTicker(60 * time.Second)
-		defer ticker.Stop()
+	// Count rejected GOWA webhooks in the detailed health output
+	webhookHandler.SetRejectionHandler(healthHandler.RecordWebhookRejection)
 
-		for range ticker.C {
-			if gowaClient != nil {
-				connected := gowaClient.IsAvailable()
-				healthHandler.UpdateGOWAPing(connected)
-			}
-		}
-	}()
+	// Device events update GOWA health; a logged-out device suspends sending until it reconnects
+	webhookHandler.SetGOWAClient(gowaClient)
+	webhookHandler.SetDeviceEventHandler(healthHandler.RecordDeviceEvent)
 
-	// Initial health check
-	go func() {
-		if gowaClient != nil {
-			connected := gowaClient.IsAvailable()
-			healthHandler.UpdateGOWAPing(connected)
+	// Start GOWA health checker (probes device status on the configured interval)
+	gowaHealthChecker = services.NewGOWAHealthChecker(
+		gowaClient,
+		appConfig.GOWA.HealthCheckInterval,
+		appConfig.GOWA.HealthCheckTimeout,
+		appLogger,
+	)
+	gowaHealthChecker.SetResultHandler(func(result services.GOWAProbeResult) {
+		healthHandler.UpdateGOWAPing(result)
+		if result.Healthy() {
+			// A logged-in device resumes sending even if the connect webhook was missed
+			gowaClient.ResumeSending("probe_healthy")
 		}
-	}()
+	})
+	gowaHealthChecker.Start()
 
 	// Start reminder checker goroutine (DISABLED - replaced by ReminderScheduler auto-send)
 	// The ReminderScheduler now handles all reminder sending: scheduled, retry, and auto-send
@@ -338,6 +487,9 @@ func main() {
 	// Serve uploaded files
 	router.Static("/uploads", "./uploads")
 
+	// Public redirect for tracked content links sent to patients
+	router.GET("/l/:token", shortLinkHandler.Redirect)
+
 	// Health check (public)
 	router.GET("/api/health", healthHandler.GetHealth)
 	// Health check detailed (admin only)
@@ -375,9 +527,9 @@ func main() {
 		contentPublic.GET("/content/popular", contentStore.GetPopularContent)
 	}
 
-	// SSE endpoint (uses query parameter auth, not header auth)
+	// SSE endpoint (uses a single-use ticket from /api/sse/ticket, not header auth)
 	// Must be outside protected group because EventSource API doesn't support custom headers
-	router.GET("/api/sse/delivery-status", sseAuthMiddleware(), sseHandler.HandleDeliveryStatusSSE)
+	router.GET("/api/sse/delivery-status", sseHandler.TicketAuth(), sseHandler.HandleDeliveryStatusSSE)
 
 	// Protected routes
 	api := router.Group("/api")
@@ -386,6 +538,9 @@ func main() {
 		// Current user
 		api.GET("/auth/me", getCurrentUser)
 
+		// SSE connection ticket
+		api.POST("/sse/ticket", sseHandler.IssueTicket)
+
 		// Patient routes
 		api.GET("/patients", getPatients)
 		api.POST("/patients", createPatient)
@@ -400,7 +555,26 @@ func main() {
 		api.POST("/patients/:id/reminders/:reminderId/toggle", reminderHandler.Toggle)
 		api.DELETE("/patients/:id/reminders/:reminderId", reminderHandler.Delete)
 		api.POST("/patients/:id/reminders/:reminderId/send", reminderHandler.Send)
+		api.POST("/patients/:id/reminders/preview", reminderHandler.PreviewReminder)
+		api.GET("/patients/:id/reminders/:reminderId/preview", reminderHandler.PreviewSavedReminder)
+		api.GET("/patients/:id/care-plans", carePlanHandler.ListEnrollments)
+		api.POST("/patients/:id/care-plans", carePlanHandler.Enroll)
+		api.GET("/patients/:id/care-plans/:enrollmentId", carePlanHandler.GetEnrollment)
+		api.PUT("/patients/:id/care-plans/:enrollmentId", carePlanHandler.RescheduleEnrollment)
+		api.DELETE("/patients/:id/care-plans/:enrollmentId", carePlanHandler.CancelEnrollment)
+		api.GET("/patients/:id/appointments", appointmentHandler.ListAppointments)
+		api.POST("/patients/:id/appointments", appointmentHandler.CreateAppointment)
+		api.PUT("/patients/:id/appointments/:appointmentId", appointmentHandler.UpdateAppointment)
+		api.POST("/patients/:id/appointments/:appointmentId/reschedule", appointmentHandler.RescheduleAppointment)
+		api.POST("/patients/:id/appointments/:appointmentId/status", appointmentHandler.UpdateAppointmentStatus)
+		api.GET("/patients/:id/medications", medicationHandler.ListMedications)
+		api.POST("/patients/:id/medications", medicationHandler.CreateMedication)
+		api.PUT("/patients/:id/medications/:medicationId", medicationHandler.UpdateMedication)
+		api.POST("/patients/:id/medications/:medicationId/stop", medicationHandler.StopMedication)
+		api.GET("/patients/:id/medications/:medicationId/adherence", medicationHandler.GetAdherence)
+		api.POST("/patients/:id/medications/:medicationId/doses/:reminderId", medicationHandler.ConfirmDose)
 		api.GET("/reminders/:id/status", reminderHandler.GetReminderStatus)
+		api.GET("/reminders/:id/timeline", reminderHandler.GetReminderTimeline)
 		api.POST("/reminders/:id/retry", reminderHandler.RetryReminder)
 		api.POST("/reminders/:id/cancel", reminderHandler.CancelReminder)
 
@@ -410,6 +584,11 @@ func main() {
 		api.PUT("/users/:id/role", requireRole(RoleSuperadmin), updateUserRole)
 		api.DELETE("/users/:id", requireRole(RoleSuperadmin), deleteUser)
 
+		// GOWA webhook event log and replay (superadmin only)
+		api.GET("/webhook-events", requireRole(RoleSuperadmin), webhookHandler.ListWebhookEvents)
+		api.GET("/webhook-events/:id", requireRole(RoleSuperadmin), webhookHandler.GetWebhookEvent)
+		api.POST("/webhook-events/:id/replay", requireRole(RoleSuperadmin), webhookHandler.ReplayWebhookEvent)
+
 		// CMS routes (admin+)
 		// Categories
 		api.POST("/categories", requireRole(RoleAdmin, RoleSuperadmin), contentStore.CreateCategory)
@@ -438,7 +617,54 @@ func main() {
 			contentStore.SyncAttachmentCounts(c, patientStore)
 		})
 
+		// Message templates (admin+)
+		api.GET("/message-templates", requireRole(RoleAdmin, RoleSuperadmin), templateHandler.ListTemplates)
+		api.POST("/message-templates", requireRole(RoleAdmin, RoleSuperadmin), templateHandler.CreateTemplate)
+		api.POST("/message-templates/preview", requireRole(RoleAdmin, RoleSuperadmin), templateHandler.PreviewTemplate)
+		api.GET("/message-templates/:id", requireRole(RoleAdmin, RoleSuperadmin), templateHandler.GetTemplate)
+		api.PUT("/message-templates/:id", requireRole(RoleAdmin, RoleSuperadmin), templateHandler.UpdateTemplate)
+		api.DELETE("/message-templates/:id", requireRole(RoleAdmin, RoleSuperadmin), templateHandler.DeleteTemplate)
+		api.POST("/message-templates/:id/restore", requireRole(RoleAdmin, RoleSuperadmin), templateHandler.RestoreTemplate)
+
+		// Reminder template library (read: all users, manage: admin+)
+		api.GET("/reminder-templates", reminderTemplateHandler.ListReminderTemplates)
+		api.GET("/reminder-templates/:id", reminderTemplateHandler.GetReminderTemplate)
+		api.POST("/reminder-templates", requireRole(RoleAdmin, RoleSuperadmin), reminderTemplateHandler.CreateReminderTemplate)
+		api.PUT("/reminder-templates/:id", requireRole(RoleAdmin, RoleSuperadmin), reminderTemplateHandler.UpdateReminderTemplate)
+		api.DELETE("/reminder-templates/:id", requireRole(RoleAdmin, RoleSuperadmin), reminderTemplateHandler.DeleteReminderTemplate)
+
+		// Care plan definitions (read: all users, manage: admin+)
+		api.GET("/care-plans", carePlanHandler.ListCarePlans)
+		api.GET("/care-plans/:id", carePlanHandler.GetCarePlan)
+		api.POST("/care-plans", requireRole(RoleAdmin, RoleSuperadmin), carePlanHandler.CreateCarePlan)
+		api.PUT("/care-plans/:id", requireRole(RoleAdmin, RoleSuperadmin), carePlanHandler.UpdateCarePlan)
+		api.DELETE("/care-plans/:id", requireRole(RoleAdmin, RoleSuperadmin), carePlanHandler.DeleteCarePlan)
+		api.GET("/blackouts", blackoutHandler.ListBlackouts)
+		api.POST("/blackouts", requireRole(RoleAdmin, RoleSuperadmin), blackoutHandler.CreateBlackout)
+		api.PUT("/blackouts/:id", requireRole(RoleAdmin, RoleSuperadmin), blackoutHandler.UpdateBlackout)
+		api.DELETE("/blackouts/:id", requireRole(RoleAdmin, RoleSuperadmin), blackoutHandler.DeleteBlackout)
+
+		// Outbound webhooks to partner systems (admin+)
+		api.GET("/outbound-webhooks", requireRole(RoleAdmin, RoleSuperadmin), outboundWebhookHandler.ListOutboundWebhooks)
+		api.POST("/outbound-webhooks", requireRole(RoleAdmin, RoleSuperadmin), outboundWebhookHandler.CreateOutboundWebhook)
+		api.PUT("/outbound-webhooks/:id", requireRole(RoleAdmin, RoleSuperadmin), outboundWebhookHandler.UpdateOutboundWebhook)
+		api.DELETE("/outbound-webhooks/:id", requireRole(RoleAdmin, RoleSuperadmin), outboundWebhookHandler.DeleteOutboundWebhook)
+		api.POST("/outbound-webhooks/:id/test", requireRole(RoleAdmin, RoleSuperadmin), outboundWebhookHandler.TestOutboundWebhook)
+		api.GET("/outbound-webhooks/:id/deliveries", requireRole(RoleAdmin, RoleSuperadmin), outboundWebhookHandler.ListOutboundDeliveries)
+		api.GET("/outbound-webhooks/:id/deliveries/:deliveryId", requireRole(RoleAdmin, RoleSuperadmin), outboundWebhookHandler.GetOutboundDelivery)
+
+		// Bulk campaigns (admin+)
+		api.GET("/campaigns", requireRole(RoleAdmin, RoleSuperadmin), campaignHandler.ListCampaigns)
+		api.POST("/campaigns", requireRole(RoleAdmin, RoleSuperadmin), campaignHandler.CreateCampaign)
+		api.GET("/campaigns/:id", requireRole(RoleAdmin, RoleSuperadmin), campaignHandler.GetCampaign)
+		api.GET("/campaigns/:id/report", requireRole(RoleAdmin, RoleSuperadmin), campaignHandler.GetCampaignReport)
+		api.POST("/campaigns/:id/pause", requireRole(RoleAdmin, RoleSuperadmin), campaignHandler.PauseCampaign)
+		api.POST("/campaigns/:id/resume", requireRole(RoleAdmin, RoleSuperadmin), campaignHandler.ResumeCampaign)
+		api.POST("/campaigns/:id/cancel", requireRole(RoleAdmin, RoleSuperadmin), campaignHandler.CancelCampaign)
+
 		// Analytics - Delivery statistics
+		api.GET("/analytics/reminder-templates", requireRole(RoleAdmin, RoleSuperadmin), reminderTemplateHandler.GetReminderTemplateAnalytics)
+		api.GET("/analytics/appointments", requireRole(RoleAdmin, RoleSuperadmin), appointmentHandler.GetAppointmentReport)
 		api.GET("/analytics/delivery", requireRole(RoleAdmin, RoleSuperadmin), analyticsHandler.GetDeliveryAnalytics)
 
 		// Analytics - Failed deliveries
@@ -468,6 +694,16 @@ func main() {
 
 	appLogger.Info("Shutting down server...")
 
+	// Stop generating dose reminders
+	if medicationPlanner != nil {
+		medicationPlanner.Stop()
+	}
+
+	// Stop campaign sends before the scheduler they send through
+	if campaignRunner != nil {
+		campaignRunner.Stop()
+	}
+
 	// Stop the scheduler first
 	if scheduler != nil {
 		appLogger.Info("Stopping reminder scheduler...")
@@ -475,6 +711,21 @@ func main() {
 		appLogger.Info("Reminder scheduler stopped")
 	}
 
+	// Stop background GOWA probes
+	if gowaHealthChecker != nil {
+		gowaHealthChecker.Stop()
+	}
+
+	// Deliver events already published, then stop the subscribers
+	if eventBus != nil {
+		eventBus.Close()
+	}
+
+	// Abandon pending outbound webhook retries
+	if outboundWebhookSender != nil {
+		outboundWebhookSender.Stop()
+	}
+
 	// Close all SSE connections before shutting down HTTP server
 	if sseHandler != nil {
 		appLogger.Info("Closing SSE connections...")
@@ -490,6 +741,11 @@ func main() {
 		appLogger.Error("Server forced to shutdown", "error", err)
 	}
 
+	// Save link clicks recorded since the last flush
+	if shortLinkHandler != nil {
+		shortLinkHandler.Stop()
+	}
+
 	appLogger.Info("Server exited gracefully")
 }
 
@@ -525,48 +781,6 @@ func authMiddleware() gin.HandlerFunc {
 	}
 }
 
-// SSE auth middleware - accepts token from query parameter
-// EventSource API doesn't support custom headers, so token must be in query string
-func sseAuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Try to get token from query parameter first (for SSE)
-		token := c.Query("token")
-		if token == "" {
-			// Fallback to Authorization header
-			authHeader := c.GetHeader("Authorization")
-			if authHeader == "" {
-				c.SSEvent("error", `{"error": "authorization required"}`)
-				c.Writer.Flush()
-				c.Abort()
-				return
-			}
-
-			// Extract "Bearer <token>"
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || parts[0] != "Bearer" {
-				c.SSEvent("error", `{"error": "invalid authorization format"}`)
-				c.Writer.Flush()
-				c.Abort()
-				return
-			}
-			token = parts[1]
-		}
-
-		claims, err := verifyToken(token)
-		if err != nil {
-			c.SSEvent("error", `{"error": "invalid or expired token"}`)
-			c.Writer.Flush()
-			c.Abort()
-			return
-		}
-
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", string(claims.Role))
-		c.Next()
-	}
-}
-
 // Role check middleware
 func requireRole(allowedRoles ...Role) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -827,6 +1041,418 @@ func saveUsers() {
 	os.Rename(tmpFile, usersDataFile)
 }
 
+func loadTemplates() {
+	data, err := os.ReadFile(templatesDataFile)
+	if err != nil {
+		return
+	}
+
+	var templates map[string]*models.MessageTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return
+	}
+
+	templateStore.Mu.Lock()
+	templateStore.Templates = templates
+	templateStore.ByName = make(map[string]string)
+	for id, tmpl := range templates {
+		templateStore.ByName[tmpl.Name] = id
+	}
+	templateStore.Mu.Unlock()
+}
+
+func saveTemplates() {
+	go func() {
+		templateStore.Mu.RLock()
+		data, err := json.MarshalIndent(templateStore.Templates, "", "  ")
+		templateStore.Mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		tmpFile := templatesDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return
+		}
+		os.Rename(tmpFile, templatesDataFile)
+	}()
+}
+
+func loadShortLinks() {
+	data, err := os.ReadFile(shortLinksDataFile)
+	if err != nil {
+		return
+	}
+
+	var links map[string]*models.ShortLink
+	if err := json.Unmarshal(data, &links); err != nil {
+		return
+	}
+
+	shortLinkStore.Mu.Lock()
+	shortLinkStore.Links = links
+	shortLinkStore.Mu.Unlock()
+}
+
+func saveShortLinks() {
+	go func() {
+		shortLinkStore.Mu.RLock()
+		data, err := json.MarshalIndent(shortLinkStore.Links, "", "  ")
+		shortLinkStore.Mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		tmpFile := shortLinksDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return
+		}
+		os.Rename(tmpFile, shortLinksDataFile)
+	}()
+}
+
+func loadReminderTemplates() {
+	data, err := os.ReadFile(reminderTemplatesDataFile)
+	if err != nil {
+		return
+	}
+
+	var templates map[string]*models.ReminderTemplate
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return
+	}
+
+	reminderTemplateStore.Mu.Lock()
+	reminderTemplateStore.Templates = templates
+	reminderTemplateStore.Mu.Unlock()
+}
+
+func saveReminderTemplates() {
+	go func() {
+		reminderTemplateStore.Mu.RLock()
+		data, err := json.MarshalIndent(reminderTemplateStore.Templates, "", "  ")
+		reminderTemplateStore.Mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		tmpFile := reminderTemplatesDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return
+		}
+		os.Rename(tmpFile, reminderTemplatesDataFile)
+	}()
+}
+
+func loadCarePlans() {
+	data, err := os.ReadFile(carePlansDataFile)
+	if err != nil {
+		return
+	}
+
+	var plans map[string]*models.CarePlan
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return
+	}
+
+	carePlanStore.Mu.Lock()
+	carePlanStore.Plans = plans
+	carePlanStore.Mu.Unlock()
+}
+
+func saveCarePlans() {
+	go func() {
+		carePlanStore.Mu.RLock()
+		data, err := json.MarshalIndent(carePlanStore.Plans, "", "  ")
+		carePlanStore.Mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		tmpFile := carePlansDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return
+		}
+		os.Rename(tmpFile, carePlansDataFile)
+	}()
+}
+
+func loadBlackouts() {
+	data, err := os.ReadFile(blackoutsDataFile)
+	if err != nil {
+		return
+	}
+
+	var blackouts map[string]*models.Blackout
+	if err := json.Unmarshal(data, &blackouts); err != nil {
+		return
+	}
+
+	blackoutStore.Mu.Lock()
+	blackoutStore.Blackouts = blackouts
+	blackoutStore.Mu.Unlock()
+}
+
+func saveBlackouts() {
+	go func() {
+		blackoutStore.Mu.RLock()
+		data, err := json.MarshalIndent(blackoutStore.Blackouts, "", "  ")
+		blackoutStore.Mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		tmpFile := blackoutsDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return
+		}
+		os.Rename(tmpFile, blackoutsDataFile)
+	}()
+}
+
+func loadOutboundWebhooks() {
+	data, err := os.ReadFile(outboundWebhooksDataFile)
+	if err != nil {
+		return
+	}
+
+	var webhooks map[string]*models.OutboundWebhook
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return
+	}
+
+	outboundWebhookStore.Mu.Lock()
+	outboundWebhookStore.Webhooks = webhooks
+	outboundWebhookStore.Mu.Unlock()
+}
+
+func saveOutboundWebhooks() {
+	go func() {
+		outboundWebhookStore.Mu.RLock()
+		data, err := json.MarshalIndent(outboundWebhookStore.Webhooks, "", "  ")
+		outboundWebhookStore.Mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		tmpFile := outboundWebhooksDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0600); err != nil { // Holds signing secrets
+			return
+		}
+		os.Rename(tmpFile, outboundWebhooksDataFile)
+	}()
+}
+
+func loadOutboundDeliveries() {
+	file, err := os.Open(outboundDeliveriesDataFile)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var deliveries []*models.OutboundDelivery
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineBytes)
+	for scanner.Scan() {
+		var delivery models.OutboundDelivery
+		if err := json.Unmarshal(scanner.Bytes(), &delivery); err != nil {
+			continue // Skip a torn last line from a crash mid-write
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("Failed to read %s after %d deliveries: %v", outboundDeliveriesDataFile, len(deliveries), err)
+	}
+
+	outboundDeliveryStore.Load(deliveries)
+}
+
+// rewriteOutboundDeliveries replaces the delivery log after pruning, under the store lock
+func rewriteOutboundDeliveries(deliveries []*models.OutboundDelivery) {
+	if err := rewriteJSONLines(outboundDeliveriesDataFile, deliveries); err != nil && appLogger != nil {
+		appLogger.Error("Failed to rewrite outbound webhook delivery log", "error", err.Error())
+	}
+}
+
+// appendOutboundDelivery writes one delivery attempt to the end of the log, under the store lock
+func appendOutboundDelivery(delivery *models.OutboundDelivery) {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(outboundDeliveriesDataFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		if appLogger != nil {
+			appLogger.Error("Failed to open outbound webhook delivery log", "error", err.Error())
+		}
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil && appLogger != nil {
+		appLogger.Error("Failed to append outbound webhook delivery", "delivery_id", delivery.ID, "error", err.Error())
+	}
+}
+
+func loadSSEEvents() {
+	data, err := os.ReadFile(sseEventsDataFile)
+	if err != nil {
+		return
+	}
+
+	var events []handlers.SSEEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return
+	}
+
+	sseHandler.RestoreEvents(events)
+}
+
+func saveSSEEvents() {
+	go func() {
+		data, err := json.Marshal(sseHandler.Events())
+		if err != nil {
+			return
+		}
+
+		tmpFile := sseEventsDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return
+		}
+		os.Rename(tmpFile, sseEventsDataFile)
+	}()
+}
+
+func loadWebhookEvents() {
+	file, err := os.Open(webhookEventsDataFile)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var events []*models.WebhookEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineBytes)
+	for scanner.Scan() {
+		var event models.WebhookEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue // Skip a torn last line from a crash mid-write
+		}
+		events = append(events, &event)
+	}
+	// Starting without the rest of the log would forget idempotency keys and reapply webhooks
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("Failed to read %s after %d events: %v", webhookEventsDataFile, len(events), err)
+	}
+
+	webhookEventStore.Load(events)
+}
+
+// rewriteWebhookEvents replaces the event log after pruning, under the store lock
+func rewriteWebhookEvents(events []*models.WebhookEvent) {
+	if err := rewriteJSONLines(webhookEventsDataFile, events); err != nil && appLogger != nil {
+		appLogger.Error("Failed to rewrite webhook event log", "error", err.Error())
+	}
+}
+
+// maxLogLineBytes bounds one line of an append-only log. Webhook bodies are capped at
+// gowa.webhook_max_body_bytes (at most 10MB), which JSON escaping can grow several times.
+const maxLogLineBytes = 64 * 1024 * 1024
+
+// rewriteJSONLines replaces an append-only log with the given entries, one JSON value per
+// line. Callers hold the store lock, so no entry is appended between writing and renaming.
+func rewriteJSONLines[T any](path string, entries []*T) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, path)
+}
+
+// pruneEventLogs removes webhook events and outbound webhook deliveries past their
+// retention periods, at startup and then hourly
+func pruneEventLogs() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		if removed := webhookEventStore.Prune(now.Add(-appConfig.GOWA.WebhookEventRetention)); removed > 0 {
+			appLogger.Info("Pruned webhook events", "removed", removed)
+		}
+		if removed := outboundDeliveryStore.Prune(now.Add(-appConfig.OutboundWebhooks.DeliveryRetention)); removed > 0 {
+			appLogger.Info("Pruned outbound webhook deliveries", "removed", removed)
+		}
+		<-ticker.C
+	}
+}
+
+// appendWebhookEvent writes one event to the end of the log. It runs synchronously, under
+// the store lock, so the file is in arrival order and an event is on disk before it is acknowledged.
+func appendWebhookEvent(event *models.WebhookEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(webhookEventsDataFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		if appLogger != nil {
+			appLogger.Error("Failed to open webhook event log", "error", err.Error())
+		}
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil && appLogger != nil {
+		appLogger.Error("Failed to append webhook event", "event_id", event.ID, "error", err.Error())
+	}
+}
+
+func loadCampaigns() {
+	data, err := os.ReadFile(campaignsDataFile)
+	if err != nil {
+		return
+	}
+
+	var campaigns map[string]*models.Campaign
+	if err := json.Unmarshal(data, &campaigns); err != nil {
+		return
+	}
+
+	campaignStore.Mu.Lock()
+	campaignStore.Campaigns = campaigns
+	campaignStore.Mu.Unlock()
+}
+
+func saveCampaigns() {
+	go func() {
+		campaignStore.Mu.RLock()
+		data, err := json.MarshalIndent(campaignStore.Campaigns, "", "  ")
+		campaignStore.Mu.RUnlock()
+		if err != nil {
+			return
+		}
+
+		tmpFile := campaignsDataFile + ".tmp"
+		if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+			return
+		}
+		os.Rename(tmpFile, campaignsDataFile)
+	}()
+}
+
 // sendWhatsAppMessage sends a WhatsApp message using the GOWA client with circuit breaker
 func sendWhatsAppMessage(phone, message string) error {
 	if gowaClient == nil {
@@ -989,10 +1615,12 @@ func getPatients(c *gin.Context) {
 
 func createPatient(c *gin.Context) {
 	var req struct {
-		Name  string `json:"name" binding:"required"`
-		Phone string `json:"phone" binding:"required"`
-		Email string `json:"email"`
-		Notes string `json:"notes"`
+		Name     string `json:"name" binding:"required"`
+		Phone    string `json:"phone" binding:"required"`
+		Email    string `json:"email"`
+		Notes    string `json:"notes"`
+		Language string `json:"language"`
+		Timezone string `json:"timezone"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1007,6 +1635,16 @@ func createPatient(c *gin.Context) {
 		return
 	}
 
+	if req.Language != "" && !models.IsSupportedLanguage(req.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported language"})
+		return
+	}
+
+	if req.Timezone != "" && !isSupportedTimezone(req.Timezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timezone must be one of WIB, WITA, WIT"})
+		return
+	}
+
 	userID := c.GetString("userID")
 
 	store.mu.Lock()
@@ -1016,6 +1654,8 @@ func createPatient(c *gin.Context) {
 		Phone:     phoneResult.Normalized, // Store normalized phone
 		Email:     req.Email,
 		Notes:     req.Notes,
+		Language:  req.Language,
+		Timezone:  req.Timezone,
 		Reminders: make([]*models.Reminder, 0),
 		CreatedBy: userID,
 		CreatedAt: getCurrentTimestamp(),
@@ -1028,6 +1668,11 @@ func createPatient(c *gin.Context) {
 	c.JSON(http.StatusCreated, patient)
 }
 
+// isSupportedTimezone reports whether tz is an Indonesian timezone region
+func isSupportedTimezone(tz string) bool {
+	return tz == "WIB" || tz == "WITA" || tz == "WIT"
+}
+
 func getPatient(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetString("userID")
@@ -1057,10 +1702,12 @@ func updatePatient(c *gin.Context) {
 	role := c.GetString("role")
 
 	var req struct {
-		Name  string `json:"name"`
-		Phone string `json:"phone"`
-		Email string `json:"email"`
-		Notes string `json:"notes"`
+		Name     string `json:"name"`
+		Phone    string `json:"phone"`
+		Email    string `json:"email"`
+		Notes    string `json:"notes"`
+		Language string `json:"language"`
+		Timezone string `json:"timezone"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -1068,6 +1715,16 @@ func updatePatient(c *gin.Context) {
 		return
 	}
 
+	if req.Language != "" && !models.IsSupportedLanguage(req.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported language"})
+		return
+	}
+
+	if req.Timezone != "" && !isSupportedTimezone(req.Timezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timezone must be one of WIB, WITA, WIT"})
+		return
+	}
+
 	store.mu.Lock()
 	patient, exists := store.patients[id]
 	if !exists {
@@ -1092,6 +1749,12 @@ func updatePatient(c *gin.Context) {
 	if req.Email != "" {
 		patient.Email = req.Email
 	}
+	if req.Language != "" {
+		patient.Language = req.Language
+	}
+	if req.Timezone != "" {
+		patient.Timezone = req.Timezone
+	}
 	patient.Notes = req.Notes
 	patient.UpdatedAt = getCurrentTimestamp()
 	store.mu.Unlock()
@@ -1179,10 +1842,16 @@ func updateUserRole(c *gin.Context) {
 		return
 	}
 
+	previousRole := user.Role
 	user.Role = Role(req.Role)
 	userStore.mu.Unlock()
 	saveUsers()
 
+	// Open event streams were authorized for the old role
+	if previousRole != user.Role {
+		sseHandler.DisconnectUser(userID, "role_changed")
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "role updated successfully",
 		"user": gin.H{
@@ -1216,6 +1885,8 @@ func deleteUser(c *gin.Context) {
 	userStore.mu.Unlock()
 	saveUsers()
 
+	sseHandler.DisconnectUser(userID, "user_deleted")
+
 	c.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
 }
 
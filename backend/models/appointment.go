@@ -0,0 +1,51 @@
+package models
+
+// AppointmentTimeLayout is the local time format of appointments
+const AppointmentTimeLayout = DueDateLayout
+
+// Appointment status values
+//   scheduled → attended / no_show (attendance recorded, correctable between the two)
+//   scheduled → rescheduled (replaced by a new appointment)
+//   scheduled → cancelled
+const (
+	AppointmentStatusScheduled   = "scheduled"
+	AppointmentStatusAttended    = "attended"
+	AppointmentStatusNoShow      = "no_show"
+	AppointmentStatusRescheduled = "rescheduled"
+	AppointmentStatusCancelled   = "cancelled"
+)
+
+// Appointment is a clinic visit of a patient. Notice reminders are linked to it through
+// Reminder.AppointmentID.
+type Appointment struct {
+	ID          string `json:"id"`
+	ScheduledAt string `json:"scheduled_at"` // AppointmentTimeLayout, local time
+	Facility    string `json:"facility"`
+	Purpose     string `json:"purpose"`
+	Notes       string `json:"notes,omitempty"`
+	Status      string `json:"status"`
+
+	// Rescheduling links the replaced and replacing appointments
+	RescheduledFrom string `json:"rescheduled_from,omitempty"`
+	RescheduledTo   string `json:"rescheduled_to,omitempty"`
+
+	CreatedBy       string `json:"created_by"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+	StatusUpdatedAt string `json:"status_updated_at,omitempty"`
+	StatusUpdatedBy string `json:"status_updated_by,omitempty"`
+}
+
+// CanTransition reports whether the appointment may move to status
+func (a *Appointment) CanTransition(status string) bool {
+	switch a.Status {
+	case AppointmentStatusScheduled:
+		return status == AppointmentStatusAttended || status == AppointmentStatusNoShow ||
+			status == AppointmentStatusRescheduled || status == AppointmentStatusCancelled
+	case AppointmentStatusAttended:
+		return status == AppointmentStatusNoShow
+	case AppointmentStatusNoShow:
+		return status == AppointmentStatusAttended
+	}
+	return false
+}
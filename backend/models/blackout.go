@@ -0,0 +1,88 @@
+package models
+
+import (
+	"sync"
+)
+
+// Blackout rule types
+const (
+	BlackoutTypeDates     = "dates"     // Whole days from StartDate to EndDate
+	BlackoutTypeRecurring = "recurring" // StartTime to EndTime every day, or on Weekdays
+)
+
+// BlackoutDateLayout is the date format of blackout start and end dates
+const BlackoutDateLayout = "2006-01-02"
+
+// Blackout is a period during which non-exempt reminders are held back, like quiet hours.
+// Times and dates are local to the patient's region (Patient.Timezone).
+type Blackout struct {
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	Type             string   `json:"type"`
+	StartDate        string   `json:"start_date,omitempty"` // Dates rules, BlackoutDateLayout
+	EndDate          string   `json:"end_date,omitempty"`   // Dates rules, inclusive
+	StartTime        string   `json:"start_time,omitempty"` // Recurring rules, HH:MM
+	EndTime          string   `json:"end_time,omitempty"`   // Recurring rules, HH:MM, may be past midnight
+	Weekdays         []int    `json:"weekdays,omitempty"`   // Recurring rules, 0 = Sunday; every day if empty
+	Region           string   `json:"region,omitempty"`     // WIB, WITA or WIT; all regions if empty
+	ExemptPriorities []string `json:"exempt_priorities,omitempty"`
+	Enabled          bool     `json:"enabled"`
+
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Exempts reports whether reminders of the given priority are sent during the blackout
+func (b *Blackout) Exempts(priority string) bool {
+	for _, p := range b.ExemptPriorities {
+		if p == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// BlackoutStore handles blackout calendar persistence with thread-safe operations
+type BlackoutStore struct {
+	Mu        sync.RWMutex
+	Blackouts map[string]*Blackout
+	SaveFunc  func()
+}
+
+// NewBlackoutStore creates a new blackout store
+func NewBlackoutStore(saveFunc func()) *BlackoutStore {
+	return &BlackoutStore{
+		Blackouts: make(map[string]*Blackout),
+		SaveFunc:  saveFunc,
+	}
+}
+
+// SaveData triggers the save function
+func (s *BlackoutStore) SaveData() {
+	if s.SaveFunc != nil {
+		s.SaveFunc()
+	}
+}
+
+// Active returns copies of the enabled blackouts, safe to use without holding the lock.
+// A nil store has no blackouts.
+func (s *BlackoutStore) Active() []Blackout {
+	if s == nil {
+		return nil
+	}
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	active := make([]Blackout, 0, len(s.Blackouts))
+	for _, b := range s.Blackouts {
+		if !b.Enabled {
+			continue
+		}
+		snapshot := *b
+		snapshot.Weekdays = append([]int(nil), b.Weekdays...)
+		snapshot.ExemptPriorities = append([]string(nil), b.ExemptPriorities...)
+		active = append(active, snapshot)
+	}
+	return active
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"strings"
+	"sync"
+)
+
+// Campaign status values
+//   scheduled → running (at StartAt) → completed
+//   scheduled/running → paused → scheduled/running (resume)
+//   scheduled/running/paused → cancelled
+const (
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusRunning   = "running"
+	CampaignStatusPaused    = "paused"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// Campaign recipient status values. Delivery beyond "sent" is tracked on the recipient's reminder.
+const (
+	RecipientStatusPending   = "pending"
+	RecipientStatusSent      = "sent"
+	RecipientStatusFailed    = "failed"
+	RecipientStatusSkipped   = "skipped"   // Not sent, e.g. invalid phone or patient deleted
+	RecipientStatusCancelled = "cancelled" // Campaign cancelled before this recipient was sent
+	RecipientStatusScheduled = "scheduled" // Held back by quiet hours or a blackout, sent later by the reminder scheduler
+)
+
+// CampaignAudience selects the patients a campaign is sent to. Criteria are combined
+// with AND; a patient matches a list criterion when it matches any value in the list.
+type CampaignAudience struct {
+	All        bool     `json:"all,omitempty"`         // Every patient, other criteria still narrow it down
+	PatientIDs []string `json:"patient_ids,omitempty"` // Explicit patients
+	CreatedBy  []string `json:"created_by,omitempty"`  // Patients registered by these users
+	Languages  []string `json:"languages,omitempty"`   // Patients with these preferred languages
+	Search     string   `json:"search,omitempty"`      // Case-insensitive match on name or notes
+}
+
+// IsEmpty reports whether the audience has no criteria at all
+func (a CampaignAudience) IsEmpty() bool {
+	return !a.All && len(a.PatientIDs) == 0 && len(a.CreatedBy) == 0 &&
+		len(a.Languages) == 0 && strings.TrimSpace(a.Search) == ""
+}
+
+// Matches reports whether a patient belongs to the audience
+func (a CampaignAudience) Matches(p *Patient) bool {
+	if a.IsEmpty() {
+		return false
+	}
+	if len(a.PatientIDs) > 0 && !containsString(a.PatientIDs, p.ID) {
+		return false
+	}
+	if len(a.CreatedBy) > 0 && !containsString(a.CreatedBy, p.CreatedBy) {
+		return false
+	}
+	if len(a.Languages) > 0 {
+		language := p.Language
+		if language == "" {
+			language = DefaultLanguage
+		}
+		if !containsString(a.Languages, language) {
+			return false
+		}
+	}
+	if search := strings.ToLower(strings.TrimSpace(a.Search)); search != "" {
+		if !strings.Contains(strings.ToLower(p.Name), search) && !strings.Contains(strings.ToLower(p.Notes), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CampaignReminder is the reminder created for every recipient of a campaign
+type CampaignReminder struct {
+	Title           string       `json:"title"`
+	Description     string       `json:"description"`
+	Priority        string       `json:"priority"`
+	Attachments     []Attachment `json:"attachments,omitempty"`
+	MessageTemplate string       `json:"message_template,omitempty"`
+}
+
+// CampaignRecipient tracks one patient of a campaign
+type CampaignRecipient struct {
+	PatientID   string `json:"patient_id"`
+	PatientName string `json:"patient_name"`
+	ReminderID  string `json:"reminder_id,omitempty"` // Reminder created for this recipient, set when sending starts
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+	ProcessedAt string `json:"processed_at,omitempty"` // ISO 8601 UTC
+}
+
+// Campaign sends one reminder to a filtered group of patients
+type Campaign struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Audience    CampaignAudience    `json:"audience"`
+	Reminder    CampaignReminder    `json:"reminder"`
+	Status      string              `json:"status"`
+	StartAt     string              `json:"start_at"` // ISO 8601 UTC
+	Recipients  []CampaignRecipient `json:"recipients,omitempty"`
+	CreatedBy   string              `json:"created_by"`
+	CreatedAt   string              `json:"created_at"`
+	UpdatedAt   string              `json:"updated_at"`
+	StartedAt   string              `json:"started_at,omitempty"`
+	CompletedAt string              `json:"completed_at,omitempty"`
+	CancelledAt string              `json:"cancelled_at,omitempty"`
+	CancelledBy string              `json:"cancelled_by,omitempty"`
+}
+
+// IsFinished reports whether the campaign can no longer send
+func (c *Campaign) IsFinished() bool {
+	return c.Status == CampaignStatusCompleted || c.Status == CampaignStatusCancelled
+}
+
+// CampaignStore handles campaign persistence with thread-safe operations.
+// Lock order: CampaignStore before PatientStore.
+type CampaignStore struct {
+	Mu        sync.RWMutex
+	Campaigns map[string]*Campaign
+	SaveFunc  func()
+}
+
+// NewCampaignStore creates a new campaign store
+func NewCampaignStore(saveFunc func()) *CampaignStore {
+	return &CampaignStore{
+		Campaigns: make(map[string]*Campaign),
+		SaveFunc:  saveFunc,
+	}
+}
+
+// SaveData triggers the save function
+func (s *CampaignStore) SaveData() {
+	if s.SaveFunc != nil {
+		s.SaveFunc()
+	}
+}
@@ -0,0 +1,172 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Care plan limits
+const (
+	MaxCarePlanSteps       = 50
+	MaxCarePlanOffsetDays  = 730 // Steps may start up to two years after the anchor date
+	MaxCarePlanOccurrences = 400 // Reminders generated by one enrollment
+	DefaultCarePlanTime    = "09:00"
+)
+
+// CarePlanAnchorLayout is the date format of enrollment anchor dates
+const CarePlanAnchorLayout = "2006-01-02"
+
+// CarePlanDueDateLayout is the local due date format of generated reminders
+const CarePlanDueDateLayout = DueDateLayout
+
+// Enrollment status values
+//   active → completed (every generated reminder finished)
+//   active → cancelled (pending reminders cancelled)
+const (
+	EnrollmentStatusActive    = "active"
+	EnrollmentStatusCompleted = "completed"
+	EnrollmentStatusCancelled = "cancelled"
+)
+
+// CarePlanRepeat repeats a step every IntervalDays, Count times in total
+type CarePlanRepeat struct {
+	Count        int `json:"count"`
+	IntervalDays int `json:"interval_days"`
+}
+
+// CarePlanStep is one reminder of a care plan, scheduled relative to the anchor date
+type CarePlanStep struct {
+	ID         string          `json:"id"`
+	OffsetDays int             `json:"offset_days"`      // Days after the anchor date
+	Time       string          `json:"time,omitempty"`   // Local HH:MM, DefaultCarePlanTime if empty
+	Repeat     *CarePlanRepeat `json:"repeat,omitempty"` // Single reminder if nil
+
+	// Reminder template supplying defaults for the fields below, if any
+	TemplateID string `json:"template_id,omitempty"`
+
+	Title           string            `json:"title"`
+	Description     string            `json:"description,omitempty"`
+	Priority        string            `json:"priority,omitempty"`
+	Attachments     []Attachment      `json:"attachments,omitempty"`
+	MessageTemplate string            `json:"message_template,omitempty"`
+	Escalation      *EscalationPolicy `json:"escalation,omitempty"`
+}
+
+// Occurrences returns the number of reminders the step generates
+func (s CarePlanStep) Occurrences() int {
+	if s.Repeat == nil || s.Repeat.Count < 1 {
+		return 1
+	}
+	return s.Repeat.Count
+}
+
+// DueDate returns the local due date of an occurrence (0-based) for an anchor date
+func (s CarePlanStep) DueDate(anchor time.Time, occurrence int) (string, error) {
+	clock := s.Time
+	if clock == "" {
+		clock = DefaultCarePlanTime
+	}
+	at, err := time.Parse("15:04", clock)
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q, expected HH:MM", s.Time)
+	}
+	days := s.OffsetDays
+	if s.Repeat != nil {
+		days += occurrence * s.Repeat.IntervalDays
+	}
+	due := time.Date(anchor.Year(), anchor.Month(), anchor.Day()+days, at.Hour(), at.Minute(), 0, 0, time.Local)
+	return due.Format(CarePlanDueDateLayout), nil
+}
+
+// CarePlan is a multi-step reminder program definition
+type CarePlan struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Steps       []CarePlanStep `json:"steps"`
+	CreatedBy   string         `json:"created_by"`
+	CreatedAt   string         `json:"created_at"`
+	UpdatedAt   string         `json:"updated_at"`
+}
+
+// CarePlanEnrollment is a care plan instantiated for one patient. Steps are copied at
+// enrollment so later edits to the plan don't change existing enrollments.
+type CarePlanEnrollment struct {
+	ID           string         `json:"id"`
+	CarePlanID   string         `json:"care_plan_id"`
+	CarePlanName string         `json:"care_plan_name"`
+	AnchorDate   string         `json:"anchor_date"` // CarePlanAnchorLayout
+	Status       string         `json:"status"`
+	Steps        []CarePlanStep `json:"steps"`
+	EnrolledBy   string         `json:"enrolled_by"`
+	EnrolledAt   string         `json:"enrolled_at"`
+	UpdatedAt    string         `json:"updated_at"`
+	CompletedAt  string         `json:"completed_at,omitempty"`
+	CancelledAt  string         `json:"cancelled_at,omitempty"`
+	CancelledBy  string         `json:"cancelled_by,omitempty"`
+}
+
+// Step returns the enrollment's copy of a step
+func (e *CarePlanEnrollment) Step(id string) (CarePlanStep, bool) {
+	for _, step := range e.Steps {
+		if step.ID == id {
+			return step, true
+		}
+	}
+	return CarePlanStep{}, false
+}
+
+// CarePlanStore handles care plan definition persistence with thread-safe operations
+type CarePlanStore struct {
+	Mu       sync.RWMutex
+	Plans    map[string]*CarePlan
+	SaveFunc func()
+}
+
+// NewCarePlanStore creates a new care plan store
+func NewCarePlanStore(saveFunc func()) *CarePlanStore {
+	return &CarePlanStore{
+		Plans:    make(map[string]*CarePlan),
+		SaveFunc: saveFunc,
+	}
+}
+
+// SaveData triggers the save function
+func (s *CarePlanStore) SaveData() {
+	if s.SaveFunc != nil {
+		s.SaveFunc()
+	}
+}
+
+// Get returns a copy of a care plan that is safe to use without holding the lock
+func (s *CarePlanStore) Get(id string) (CarePlan, bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	plan, ok := s.Plans[id]
+	if !ok {
+		return CarePlan{}, false
+	}
+	snapshot := *plan
+	snapshot.Steps = CopyCarePlanSteps(plan.Steps)
+	return snapshot, true
+}
+
+// CopyCarePlanSteps deep-copies care plan steps
+func CopyCarePlanSteps(steps []CarePlanStep) []CarePlanStep {
+	copied := make([]CarePlanStep, len(steps))
+	for i, step := range steps {
+		copied[i] = step
+		copied[i].Attachments = append([]Attachment(nil), step.Attachments...)
+		if step.Repeat != nil {
+			repeat := *step.Repeat
+			copied[i].Repeat = &repeat
+		}
+		if step.Escalation != nil {
+			escalation := *step.Escalation
+			copied[i].Escalation = &escalation
+		}
+	}
+	return copied
+}
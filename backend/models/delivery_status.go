@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Actors recorded on delivery status transitions
+const (
+	StatusActorUser      = "user"
+	StatusActorScheduler = "scheduler"
+	StatusActorWebhook   = "webhook"
+)
+
+// StatusTransition is one entry of a reminder's delivery status timeline
+type StatusTransition struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Actor   string `json:"actor"`              // StatusActor*
+	ActorID string `json:"actor_id,omitempty"` // User ID when the actor is a user
+	Reason  string `json:"reason,omitempty"`
+	At      string `json:"at"` // ISO 8601 UTC
+}
+
+// deliveryTransitions lists the statuses each delivery status may move to. Statuses
+// without an entry are final.
+var deliveryTransitions = map[string][]string{
+	DeliveryStatusPending: {
+		DeliveryStatusScheduled, DeliveryStatusQueued, DeliveryStatusSending,
+		DeliveryStatusFailed, DeliveryStatusExpired, DeliveryStatusCancelled,
+	},
+	DeliveryStatusScheduled: {
+		DeliveryStatusPending, DeliveryStatusScheduled, DeliveryStatusQueued, DeliveryStatusSending,
+		DeliveryStatusFailed, DeliveryStatusExpired, DeliveryStatusCancelled,
+	},
+	DeliveryStatusQueued: {
+		DeliveryStatusPending, DeliveryStatusScheduled, DeliveryStatusQueued, DeliveryStatusSending,
+		DeliveryStatusFailed, DeliveryStatusExpired, DeliveryStatusCancelled,
+	},
+	DeliveryStatusSending: {
+		DeliveryStatusSent, DeliveryStatusRetrying, DeliveryStatusQueued, DeliveryStatusFailed,
+	},
+	DeliveryStatusRetrying: {
+		DeliveryStatusScheduled, DeliveryStatusQueued, DeliveryStatusSending, DeliveryStatusSent,
+		DeliveryStatusFailed, DeliveryStatusExpired, DeliveryStatusCancelled,
+	},
+	DeliveryStatusSent: {
+		DeliveryStatusDelivered, DeliveryStatusRead, DeliveryStatusFailed,
+	},
+	DeliveryStatusDelivered: {
+		DeliveryStatusRead,
+	},
+	DeliveryStatusFailed: {
+		DeliveryStatusPending, DeliveryStatusScheduled, DeliveryStatusQueued, DeliveryStatusSending,
+		DeliveryStatusDelivered, DeliveryStatusRead,
+	},
+}
+
+// IllegalTransitionError is returned for a delivery status move the state machine forbids
+type IllegalTransitionError struct {
+	From string
+	To   string
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return fmt.Sprintf("illegal delivery status transition from %q to %q", e.From, e.To)
+}
+
+// CanTransition reports whether a reminder may move from one delivery status to another.
+// An empty status is treated as pending.
+func CanTransition(from, to string) bool {
+	if from == "" {
+		from = DeliveryStatusPending
+	}
+	for _, next := range deliveryTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSentStatus reports whether a delivery status means the message was already sent
+func IsSentStatus(status string) bool {
+	return status == DeliveryStatusSent || status == DeliveryStatusDelivered || status == DeliveryStatusRead
+}
+
+// TransitionTo moves the reminder to a new delivery status and appends the move to its
+// timeline. An illegal move returns an *IllegalTransitionError and leaves the reminder
+// unchanged. The caller must hold the patient store write lock.
+func (r *Reminder) TransitionTo(to, actor, actorID, reason string, now time.Time) error {
+	from := r.DeliveryStatus
+	if !CanTransition(from, to) {
+		return &IllegalTransitionError{From: from, To: to}
+	}
+	r.DeliveryStatus = to
+	r.StatusTimeline = append(r.StatusTimeline, StatusTransition{
+		From:    from,
+		To:      to,
+		Actor:   actor,
+		ActorID: actorID,
+		Reason:  reason,
+		At:      now.UTC().Format(time.RFC3339),
+	})
+	return nil
+}
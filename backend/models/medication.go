@@ -0,0 +1,90 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxDosesPerDay bounds how many dose times a medication may have
+const MaxDosesPerDay = 6
+
+// MedicationDateLayout is the date format of medication start and end dates
+const MedicationDateLayout = "2006-01-02"
+
+// DefaultDoseTimes are the local dose times used when a medication gives only TimesPerDay
+var DefaultDoseTimes = map[int][]string{
+	1: {"08:00"},
+	2: {"08:00", "20:00"},
+	3: {"07:00", "13:00", "19:00"},
+	4: {"07:00", "12:00", "17:00", "22:00"},
+}
+
+// Medication status values
+const (
+	MedicationStatusActive  = "active"
+	MedicationStatusStopped = "stopped"
+)
+
+// Dose confirmation values
+const (
+	DoseTaken   = "taken"
+	DoseSkipped = "skipped"
+)
+
+// Dose confirmation sources
+const (
+	DoseSourcePatientReply = "patient_reply"
+	DoseSourceVolunteer    = "volunteer"
+)
+
+// Medication is a drug a patient takes on a daily schedule. Dose reminders are linked to
+// it through Reminder.MedicationID.
+type Medication struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name"`
+	Dose         string   `json:"dose"` // Free text, e.g. "1 tablet 500 mg"
+	TimesPerDay  int      `json:"times_per_day"`
+	Times        []string `json:"times"`              // Local HH:MM, one per dose
+	StartDate    string   `json:"start_date"`         // MedicationDateLayout
+	EndDate      string   `json:"end_date,omitempty"` // Inclusive, ongoing if empty
+	Instructions string   `json:"instructions,omitempty"`
+	Status       string   `json:"status"`
+
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	StoppedAt string `json:"stopped_at,omitempty"`
+}
+
+// DoseTimes returns the medication's dose times in the given day (local time)
+func (m *Medication) DoseTimes(day time.Time) ([]time.Time, error) {
+	times := make([]time.Time, 0, len(m.Times))
+	for _, clock := range m.Times {
+		at, err := time.Parse("15:04", clock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dose time %q, expected HH:MM", clock)
+		}
+		times = append(times, time.Date(day.Year(), day.Month(), day.Day(), at.Hour(), at.Minute(), 0, 0, time.Local))
+	}
+	return times, nil
+}
+
+// CoversDay reports whether the medication is taken on the given local day
+func (m *Medication) CoversDay(day string) bool {
+	if day < m.StartDate {
+		return false
+	}
+	return m.EndDate == "" || day <= m.EndDate
+}
+
+// DoseConfirmation records whether a dose was taken
+type DoseConfirmation struct {
+	Status      string `json:"status"`       // DoseTaken or DoseSkipped
+	Source      string `json:"source"`       // DoseSourcePatientReply or DoseSourceVolunteer
+	ConfirmedBy string `json:"confirmed_by"` // User ID, or the patient's phone for replies
+	ConfirmedAt string `json:"confirmed_at"` // ISO 8601 UTC
+	Note        string `json:"note,omitempty"`
+
+	// GOWA message ID of the patient reply, so edits and revocations can revise it
+	ReplyMessageID string `json:"reply_message_id,omitempty"`
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// Outbound webhook delivery attempt outcomes
+const (
+	OutboundDeliverySucceeded = "succeeded" // 2xx response
+	OutboundDeliveryRetrying  = "retrying"  // Failed, another attempt is scheduled
+	OutboundDeliveryFailed    = "failed"    // Failed, no further attempts
+	OutboundDeliveryAbandoned = "abandoned" // Not attempted: subscription disabled or deleted, or shutting down
+)
+
+// OutboundWebhook is an admin-managed subscription that receives domain events as signed
+// HTTP POSTs to a partner system
+type OutboundWebhook struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret,omitempty"` // HMAC key; only returned when created or rotated
+	Enabled    bool     `json:"enabled"`
+
+	ConsecutiveFailures int    `json:"consecutive_failures"` // Failed deliveries since the last success
+	DisabledReason      string `json:"disabled_reason,omitempty"`
+	DisabledAt          string `json:"disabled_at,omitempty"`
+	LastDeliveryAt      string `json:"last_delivery_at,omitempty"`
+	LastSuccessAt       string `json:"last_success_at,omitempty"`
+
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook receives events of the given type
+func (w *OutboundWebhook) Subscribes(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// OutboundWebhookStore handles outbound webhook subscription persistence with thread-safe operations
+type OutboundWebhookStore struct {
+	Mu       sync.RWMutex
+	Webhooks map[string]*OutboundWebhook
+	SaveFunc func()
+}
+
+// NewOutboundWebhookStore creates a new outbound webhook store
+func NewOutboundWebhookStore(saveFunc func()) *OutboundWebhookStore {
+	return &OutboundWebhookStore{
+		Webhooks: make(map[string]*OutboundWebhook),
+		SaveFunc: saveFunc,
+	}
+}
+
+// SaveData triggers the save function
+func (s *OutboundWebhookStore) SaveData() {
+	if s.SaveFunc != nil {
+		s.SaveFunc()
+	}
+}
+
+// Get returns a copy of the webhook with the given ID, including its secret
+func (s *OutboundWebhookStore) Get(id string) (OutboundWebhook, bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	w, ok := s.Webhooks[id]
+	if !ok {
+		return OutboundWebhook{}, false
+	}
+	snapshot := *w
+	snapshot.EventTypes = append([]string(nil), w.EventTypes...)
+	return snapshot, true
+}
+
+// Subscribed returns copies of the enabled webhooks that receive the event type
+func (s *OutboundWebhookStore) Subscribed(eventType string) []OutboundWebhook {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	var subscribed []OutboundWebhook
+	for _, w := range s.Webhooks {
+		if w.Enabled && w.Subscribes(eventType) {
+			snapshot := *w
+			snapshot.EventTypes = append([]string(nil), w.EventTypes...)
+			subscribed = append(subscribed, snapshot)
+		}
+	}
+	return subscribed
+}
+
+// OutboundDelivery is one attempt to deliver an event to an outbound webhook, as recorded
+// in the delivery log. All attempts for one event share a DeliveryID.
+type OutboundDelivery struct {
+	ID          string `json:"id"`
+	DeliveryID  string `json:"delivery_id"` // Sent as X-Webhook-Delivery, stable across retries
+	WebhookID   string `json:"webhook_id"`
+	EventType   string `json:"event_type"`
+	Attempt     int    `json:"attempt"`
+	Test        bool   `json:"test,omitempty"` // Sent from the test-fire endpoint
+	Outcome     string `json:"outcome"`        // OutboundDelivery*
+	StatusCode  int    `json:"status_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Response    string `json:"response,omitempty"` // Start of the response body
+	DurationMs  int64  `json:"duration_ms"`
+	Payload     string `json:"payload,omitempty"` // Signed request body, on the first attempt only
+	AttemptedAt string `json:"attempted_at"`      // ISO 8601 UTC
+	NextRetryAt string `json:"next_retry_at,omitempty"`
+}
+
+// OutboundDeliveryStore is the append-only outbound webhook delivery log. Attempts past
+// the retention period are removed by Prune.
+type OutboundDeliveryStore struct {
+	Mu          sync.RWMutex
+	Deliveries  []*OutboundDelivery // In attempt order
+	byID        map[string]*OutboundDelivery
+	AppendFunc  func(delivery *OutboundDelivery)
+	RewriteFunc func(deliveries []*OutboundDelivery) // Replaces the persisted log after pruning, called with the lock held
+}
+
+// NewOutboundDeliveryStore creates a new delivery log. appendFunc persists each appended
+// attempt and is called with the lock held, so attempts are written in order.
+func NewOutboundDeliveryStore(appendFunc func(delivery *OutboundDelivery)) *OutboundDeliveryStore {
+	return &OutboundDeliveryStore{
+		byID:       make(map[string]*OutboundDelivery),
+		AppendFunc: appendFunc,
+	}
+}
+
+// Load replaces the log with previously persisted attempts, in attempt order
+func (s *OutboundDeliveryStore) Load(deliveries []*OutboundDelivery) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	s.Deliveries = nil
+	s.byID = make(map[string]*OutboundDelivery)
+	for _, d := range deliveries {
+		s.Deliveries = append(s.Deliveries, d)
+		s.byID[d.ID] = d
+	}
+}
+
+// Append records an attempt and persists it
+func (s *OutboundDeliveryStore) Append(delivery *OutboundDelivery) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	s.Deliveries = append(s.Deliveries, delivery)
+	s.byID[delivery.ID] = delivery
+	if s.AppendFunc != nil {
+		s.AppendFunc(delivery)
+	}
+}
+
+// Prune removes attempts made before the cutoff and returns how many were removed
+func (s *OutboundDeliveryStore) Prune(cutoff time.Time) int {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	kept := make([]*OutboundDelivery, 0, len(s.Deliveries))
+	for _, d := range s.Deliveries {
+		if attempted, err := time.Parse(time.RFC3339, d.AttemptedAt); err == nil && attempted.Before(cutoff) {
+			delete(s.byID, d.ID)
+			continue
+		}
+		kept = append(kept, d)
+	}
+	removed := len(s.Deliveries) - len(kept)
+	if removed == 0 {
+		return 0
+	}
+
+	s.Deliveries = kept
+	if s.RewriteFunc != nil {
+		s.RewriteFunc(s.Deliveries)
+	}
+	return removed
+}
+
+// Get returns a copy of the attempt with the given ID
+func (s *OutboundDeliveryStore) Get(id string) (OutboundDelivery, bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	delivery, ok := s.byID[id]
+	if !ok {
+		return OutboundDelivery{}, false
+	}
+	return *delivery, true
+}
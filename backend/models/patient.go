@@ -5,18 +5,20 @@ import (
 )
 
 // DeliveryStatus constants for reminder delivery tracking
-// State machine transitions:
-//   pending → scheduled (quiet hours) → sending → sent → delivered → read
+// State machine transitions (enforced by Reminder.TransitionTo, see delivery_status.go):
+//   pending → scheduled (quiet hours, blackout) → sending → sent → delivered → read
 //   pending → queued → sending → sent → delivered → read
 //   sending → failed
 //   scheduled → sending (at scheduled time)
 //   sending → retrying → sending (on transient failure)
 //   retrying → sent (on success)
 //   retrying → failed (after max retries exhausted)
-//   any → cancelled (user cancelled the reminder)
+//   failed → sending or queued (manual retry)
+//   unsent → cancelled (user cancelled the reminder)
+// Sent messages never move back: read is final and delivered only moves to read.
 const (
 	DeliveryStatusPending   = "pending"
-	DeliveryStatusScheduled = "scheduled" // Queued for quiet hours delivery
+	DeliveryStatusScheduled = "scheduled" // Queued for delivery after quiet hours or a blackout
 	DeliveryStatusQueued    = "queued"    // Queued due to circuit breaker open
 	DeliveryStatusSending   = "sending"
 	DeliveryStatusRetrying  = "retrying"  // Waiting for retry after transient failure
@@ -28,6 +30,34 @@ const (
 	DeliveryStatusCancelled = "cancelled" // Reminder was cancelled by user
 )
 
+// Delivery failure reason codes persisted on failed reminders
+const (
+	FailureCodeInvalidPhone    = "invalid_phone"    // Recipient number rejected locally or by GOWA
+	FailureCodeGOWATimeout     = "gowa_timeout"     // GOWA did not answer in time
+	FailureCodeGOWAUnavailable = "gowa_unavailable" // GOWA unreachable, erroring or circuit open
+	FailureCodeMessageRejected = "message_rejected" // GOWA refused the message
+	FailureCodeOther           = "other"
+)
+
+// Delivery attempt outcomes recorded in a reminder's delivery history
+const (
+	AttemptOutcomeSent                = "sent"
+	AttemptOutcomeFailed              = "failed"
+	AttemptOutcomeAmbiguous           = "ambiguous"            // Request may have reached GOWA, result unknown
+	AttemptOutcomeDuplicateSuppressed = "duplicate_suppressed" // Resend skipped, message was already sent
+)
+
+// DeliveryAttempt records one outbound send attempt for a reminder occurrence
+type DeliveryAttempt struct {
+	Attempt        int    `json:"attempt"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Outcome        string `json:"outcome"`
+	MessageID      string `json:"message_id,omitempty"`
+	Error          string `json:"error,omitempty"`
+	Unconfirmed    int    `json:"unconfirmed,omitempty"` // Resends held in a row because an ambiguous send could not be verified
+	At             string `json:"at"`                    // ISO 8601 UTC
+}
+
 // Recurrence represents reminder recurrence settings
 type Recurrence struct {
 	Frequency  string `json:"frequency"`
@@ -44,6 +74,12 @@ type Attachment struct {
 	URL   string `json:"url"`   // URL to the content (article slug or video watch page)
 }
 
+// EscalationPolicy notifies the responsible volunteer and admins when a sent
+// reminder has not been read by the patient in time
+type EscalationPolicy struct {
+	AfterHours int `json:"after_hours"` // Hours after sending without a read receipt
+}
+
 // Reminder represents a patient reminder with delivery tracking
 type Reminder struct {
 	ID          string     `json:"id"`
@@ -58,10 +94,37 @@ type Reminder struct {
 	// Content attachments
 	Attachments []Attachment `json:"attachments,omitempty"`
 
+	// Message template name, DefaultMessageTemplateName if empty
+	MessageTemplate string `json:"message_template,omitempty"`
+
+	// Campaign that created this reminder, if any
+	CampaignID string `json:"campaign_id,omitempty"`
+
+	// Reminder template this reminder was created from, if any
+	TemplateID string `json:"template_id,omitempty"`
+
+	// Care plan enrollment and step that generated this reminder, if any
+	EnrollmentID       string `json:"enrollment_id,omitempty"`
+	CarePlanStepID     string `json:"care_plan_step_id,omitempty"`
+	CarePlanOccurrence int    `json:"care_plan_occurrence,omitempty"` // 0-based repeat of the step
+
+	// Appointment and notice (AppointmentNotice name) this reminder announces, if any
+	AppointmentID     string `json:"appointment_id,omitempty"`
+	AppointmentNotice string `json:"appointment_notice,omitempty"`
+
+	// Medication this dose reminder belongs to, and the dose confirmation, if any
+	MedicationID string            `json:"medication_id,omitempty"`
+	Dose         *DoseConfirmation `json:"dose,omitempty"`
+
+	// Escalation when the patient has not read the message, none if nil
+	Escalation  *EscalationPolicy `json:"escalation,omitempty"`
+	EscalatedAt string            `json:"escalated_at,omitempty"` // ISO 8601 UTC
+
 	// Delivery tracking fields (verbose names per architecture)
 	GOWAMessageID        string `json:"gowa_message_id,omitempty"`
 	DeliveryStatus       string `json:"delivery_status,omitempty"`
 	DeliveryErrorMessage string `json:"delivery_error_message,omitempty"`
+	DeliveryFailureCode  string `json:"delivery_failure_code,omitempty"` // FailureCode* reason for the last failure
 	MessageSentAt        string `json:"message_sent_at,omitempty"`        // ISO 8601 UTC
 	DeliveredAt          string `json:"delivered_at,omitempty"`           // ISO 8601 UTC
 	ReadAt               string `json:"read_at,omitempty"`                // ISO 8601 UTC
@@ -69,19 +132,45 @@ type Reminder struct {
 	ScheduledDeliveryAt  string `json:"scheduled_delivery_at,omitempty"` // ISO 8601 UTC - for quiet hours scheduling
 	CancelledAt          string `json:"cancelled_at,omitempty"`           // ISO 8601 UTC - when reminder was cancelled
 	CancelledBy          string `json:"cancelled_by,omitempty"`           // User ID who cancelled the reminder
+	MessageRevokedAt     string `json:"message_revoked_at,omitempty"`     // ISO 8601 UTC - sent message deleted for everyone
+	MessageEditedAt      string `json:"message_edited_at,omitempty"`      // ISO 8601 UTC - sent message last edited
+
+	// Outbound send history, doubles as the idempotency outbox
+	DeliveryAttempts []DeliveryAttempt `json:"delivery_attempts,omitempty"`
+
+	// Every delivery status change, oldest first
+	StatusTimeline []StatusTransition `json:"status_timeline,omitempty"`
+}
+
+// DueDateLayout is the local time format of Reminder.DueDate as entered in the reminder form
+const DueDateLayout = "2006-01-02T15:04"
+
+// IsUnsent reports whether the reminder has not been sent yet, so its schedule may
+// still be changed
+func (r *Reminder) IsUnsent() bool {
+	if r.Completed || r.Notified {
+		return false
+	}
+	return r.DeliveryStatus == "" || r.DeliveryStatus == DeliveryStatusPending ||
+		r.DeliveryStatus == DeliveryStatusScheduled
 }
 
 // Patient represents a patient record
 type Patient struct {
-	ID        string      `json:"id"`
-	Name      string      `json:"name"`
-	Phone     string      `json:"phone"`
-	Email     string      `json:"email,omitempty"`
-	Notes     string      `json:"notes,omitempty"`
-	Reminders []*Reminder `json:"reminders,omitempty"`
-	CreatedBy string      `json:"createdBy,omitempty"`
-	CreatedAt string      `json:"created_at"`
-	UpdatedAt string      `json:"updated_at"`
+	ID           string                `json:"id"`
+	Name         string                `json:"name"`
+	Phone        string                `json:"phone"`
+	Email        string                `json:"email,omitempty"`
+	Notes        string                `json:"notes,omitempty"`
+	Language     string                `json:"language,omitempty"` // Preferred message language, DefaultLanguage if empty
+	Timezone     string                `json:"timezone,omitempty"` // Region (WIB, WITA or WIT) for blackouts, quiet hours timezone if empty
+	Reminders    []*Reminder           `json:"reminders,omitempty"`
+	CarePlans    []*CarePlanEnrollment `json:"care_plans,omitempty"` // Care plan enrollments
+	Appointments []*Appointment        `json:"appointments,omitempty"`
+	Medications  []*Medication         `json:"medications,omitempty"`
+	CreatedBy    string                `json:"createdBy,omitempty"`
+	CreatedAt    string                `json:"created_at"`
+	UpdatedAt    string                `json:"updated_at"`
 }
 
 // PatientStore handles patient data persistence with thread-safe operations
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"sync"
+)
+
+// MaxEscalationHours bounds how long escalation may wait for a read receipt
+const MaxEscalationHours = 720
+
+// ReminderTemplate is an admin-managed preset that reminders can be created from
+type ReminderTemplate struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Category    string            `json:"category"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Priority    string            `json:"priority"`
+	Recurrence  Recurrence        `json:"recurrence"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+	Escalation  *EscalationPolicy `json:"escalation,omitempty"`
+
+	// Message template name used by reminders created from this template
+	MessageTemplate string `json:"message_template,omitempty"`
+
+	UsageCount int    `json:"usage_count"` // Reminders created from this template
+	CreatedBy  string `json:"created_by"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+// ReminderTemplateStore handles reminder template persistence with thread-safe operations
+type ReminderTemplateStore struct {
+	Mu        sync.RWMutex
+	Templates map[string]*ReminderTemplate
+	SaveFunc  func()
+}
+
+// NewReminderTemplateStore creates a new reminder template store
+func NewReminderTemplateStore(saveFunc func()) *ReminderTemplateStore {
+	return &ReminderTemplateStore{
+		Templates: make(map[string]*ReminderTemplate),
+		SaveFunc:  saveFunc,
+	}
+}
+
+// SaveData triggers the save function
+func (s *ReminderTemplateStore) SaveData() {
+	if s.SaveFunc != nil {
+		s.SaveFunc()
+	}
+}
+
+// Get returns a copy of a template that is safe to use without holding the lock
+func (s *ReminderTemplateStore) Get(id string) (ReminderTemplate, bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	tmpl, ok := s.Templates[id]
+	if !ok {
+		return ReminderTemplate{}, false
+	}
+	preset := *tmpl
+	preset.Attachments = append([]Attachment(nil), tmpl.Attachments...)
+	preset.Recurrence.DaysOfWeek = append([]int(nil), tmpl.Recurrence.DaysOfWeek...)
+	if tmpl.Escalation != nil {
+		escalation := *tmpl.Escalation
+		preset.Escalation = &escalation
+	}
+	return preset, true
+}
+
+// IncrementUsage records that a reminder was created from the template
+func (s *ReminderTemplateStore) IncrementUsage(id string) {
+	s.Mu.Lock()
+	tmpl, ok := s.Templates[id]
+	if ok {
+		tmpl.UsageCount++
+	}
+	s.Mu.Unlock()
+
+	if ok {
+		s.SaveData()
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"sync"
+)
+
+// MaxLinkClicks is how many individual clicks are kept per short link; ClickCount keeps the total
+const MaxLinkClicks = 50
+
+// ShortLink is a tracked redirect for one content link in one sent message
+type ShortLink struct {
+	Token          string      `json:"token"`
+	TargetURL      string      `json:"target_url"`
+	PatientID      string      `json:"patient_id"`
+	ReminderID     string      `json:"reminder_id"`
+	IdempotencyKey string      `json:"idempotency_key,omitempty"` // Occurrence the link was sent for; retries reuse it
+	ContentType    string      `json:"content_type"`              // "article" or "video"
+	ContentID      string      `json:"content_id"`
+	CreatedAt      string      `json:"created_at"`
+	ClickCount     int         `json:"click_count"`
+	FirstClickedAt string      `json:"first_clicked_at,omitempty"`
+	LastClickedAt  string      `json:"last_clicked_at,omitempty"`
+	Clicks         []LinkClick `json:"clicks,omitempty"` // Most recent MaxLinkClicks clicks
+}
+
+// LinkClick records one visit of a short link
+type LinkClick struct {
+	At        string `json:"at"` // ISO 8601 UTC
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// ShortLinkStore handles short link persistence with thread-safe operations
+type ShortLinkStore struct {
+	Mu       sync.RWMutex
+	Links    map[string]*ShortLink // token -> link
+	SaveFunc func()
+}
+
+// NewShortLinkStore creates a new short link store
+func NewShortLinkStore(saveFunc func()) *ShortLinkStore {
+	return &ShortLinkStore{
+		Links:    make(map[string]*ShortLink),
+		SaveFunc: saveFunc,
+	}
+}
+
+// SaveData triggers the save function
+func (s *ShortLinkStore) SaveData() {
+	if s.SaveFunc != nil {
+		s.SaveFunc()
+	}
+}
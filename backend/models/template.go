@@ -0,0 +1,115 @@
+package models
+
+import (
+	"sync"
+)
+
+// Supported message languages
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+
+	// DefaultLanguage is used when a patient has no language preference
+	DefaultLanguage = LanguageIndonesian
+)
+
+// SupportedLanguages lists the languages message templates can be written in
+var SupportedLanguages = []string{LanguageIndonesian, LanguageEnglish}
+
+// IsSupportedLanguage reports whether lang is a supported message language
+func IsSupportedLanguage(lang string) bool {
+	for _, l := range SupportedLanguages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultMessageTemplateName is the template used for reminders that don't name one
+const DefaultMessageTemplateName = "reminder"
+
+// MaxTemplateVersions is how many previous versions are kept per template
+const MaxTemplateVersions = 20
+
+// MessageTemplate is a named, versioned WhatsApp message layout (Go text/template)
+// with one variant per language
+type MessageTemplate struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Variants    map[string]string `json:"variants"` // language code -> template body
+	Version     int               `json:"version"`
+	History     []TemplateVersion `json:"history,omitempty"` // Previous versions, oldest first
+	CreatedBy   string            `json:"created_by,omitempty"`
+	UpdatedBy   string            `json:"updated_by,omitempty"`
+	CreatedAt   string            `json:"created_at"`
+	UpdatedAt   string            `json:"updated_at"`
+}
+
+// TemplateVersion is a snapshot of a template's variants at one version
+type TemplateVersion struct {
+	Version   int               `json:"version"`
+	Variants  map[string]string `json:"variants"`
+	UpdatedBy string            `json:"updated_by,omitempty"`
+	UpdatedAt string            `json:"updated_at"`
+}
+
+// Variant returns the template body for lang, falling back to the default language
+// and then to any variant. The returned language is the one actually used.
+func (t *MessageTemplate) Variant(lang string) (string, string, bool) {
+	if body, ok := t.Variants[lang]; ok && body != "" {
+		return body, lang, true
+	}
+	if body, ok := t.Variants[DefaultLanguage]; ok && body != "" {
+		return body, DefaultLanguage, true
+	}
+	for _, l := range SupportedLanguages {
+		if body, ok := t.Variants[l]; ok && body != "" {
+			return body, l, true
+		}
+	}
+	return "", "", false
+}
+
+// TemplateStore handles message template persistence with thread-safe operations
+type TemplateStore struct {
+	Mu        sync.RWMutex
+	Templates map[string]*MessageTemplate
+	ByName    map[string]string // name -> template ID
+	SaveFunc  func()
+}
+
+// NewTemplateStore creates a new template store
+func NewTemplateStore(saveFunc func()) *TemplateStore {
+	return &TemplateStore{
+		Templates: make(map[string]*MessageTemplate),
+		ByName:    make(map[string]string),
+		SaveFunc:  saveFunc,
+	}
+}
+
+// SaveData triggers the save function
+func (s *TemplateStore) SaveData() {
+	if s.SaveFunc != nil {
+		s.SaveFunc()
+	}
+}
+
+// Resolve returns the body of the named template for lang.
+// Caller must not hold the store lock.
+func (s *TemplateStore) Resolve(name, lang string) (string, bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	id, ok := s.ByName[name]
+	if !ok {
+		return "", false
+	}
+	tmpl, ok := s.Templates[id]
+	if !ok {
+		return "", false
+	}
+	body, _, ok := tmpl.Variant(lang)
+	return body, ok
+}
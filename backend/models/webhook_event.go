@@ -0,0 +1,180 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// Webhook signature check results
+const (
+	WebhookSignatureValid       = "valid"
+	WebhookSignatureMissing     = "missing"
+	WebhookSignatureInvalid     = "invalid"
+	WebhookSignatureNoTimestamp = "no_timestamp" // Timestamp or nonce missing where required
+	WebhookSignatureExpired     = "expired"      // Signed timestamp outside the tolerance window
+	WebhookSignatureReplayed    = "replayed"     // Nonce already used
+)
+
+// Webhook event processing outcomes
+const (
+	WebhookOutcomeProcessed = "processed" // Applied; later deliveries with the same idempotency key are duplicates
+	WebhookOutcomeIgnored   = "ignored"   // Nothing to apply, e.g. unknown event, status or message
+	WebhookOutcomeDuplicate = "duplicate" // Already processed by an earlier event
+	WebhookOutcomeRejected  = "rejected"  // Signature missing or invalid
+	WebhookOutcomeInvalid   = "invalid"   // Payload could not be parsed
+)
+
+// WebhookEvent is one received GOWA webhook, or one replay of it, as recorded in the event log
+type WebhookEvent struct {
+	ID             string `json:"id"`
+	ReceivedAt     string `json:"received_at"` // ISO 8601 UTC
+	Event          string `json:"event,omitempty"`
+	MessageID      string `json:"message_id,omitempty"`
+	Status         string `json:"status,omitempty"`
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	Payload        string `json:"payload,omitempty"` // Raw request body, only kept for events that passed the checks
+	PayloadSize    int    `json:"payload_size,omitempty"`
+	PayloadSHA256  string `json:"payload_sha256,omitempty"` // Hex digest of a rejected or invalid request body
+	Signature      string `json:"signature"`                // WebhookSignature* result
+	Outcome        string `json:"outcome"`                  // WebhookOutcome*
+	Result         string `json:"result,omitempty"`         // Human-readable processing result
+	ReplayOf       string `json:"replay_of,omitempty"`
+	ReplayedBy     string `json:"replayed_by,omitempty"`
+}
+
+// WebhookEventStore is the append-only GOWA webhook event log. Events are never changed
+// once appended; a replay is appended as a new event. Events past the retention period
+// are removed by Prune.
+type WebhookEventStore struct {
+	Mu          sync.RWMutex
+	Events      []*WebhookEvent // In arrival order
+	byID        map[string]*WebhookEvent
+	processed   map[string]string // idempotency key -> ID of the event that processed it
+	claimed     map[string]bool   // idempotency keys being processed
+	AppendFunc  func(event *WebhookEvent)
+	RewriteFunc func(events []*WebhookEvent) // Replaces the persisted log after pruning, called with the lock held
+}
+
+// NewWebhookEventStore creates a new webhook event store. appendFunc persists each
+// appended event and is called with the lock held, so events are written in order.
+func NewWebhookEventStore(appendFunc func(event *WebhookEvent)) *WebhookEventStore {
+	return &WebhookEventStore{
+		byID:       make(map[string]*WebhookEvent),
+		processed:  make(map[string]string),
+		claimed:    make(map[string]bool),
+		AppendFunc: appendFunc,
+	}
+}
+
+// Load replaces the log with previously persisted events, in arrival order
+func (s *WebhookEventStore) Load(events []*WebhookEvent) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	s.Events = nil
+	s.byID = make(map[string]*WebhookEvent)
+	s.processed = make(map[string]string)
+	for _, e := range events {
+		s.index(e)
+	}
+}
+
+// index adds an event to the log and its lookups; the caller must hold the lock
+func (s *WebhookEventStore) index(event *WebhookEvent) {
+	s.Events = append(s.Events, event)
+	s.byID[event.ID] = event
+	if event.Outcome == WebhookOutcomeProcessed && event.IdempotencyKey != "" {
+		if _, seen := s.processed[event.IdempotencyKey]; !seen {
+			s.processed[event.IdempotencyKey] = event.ID
+		}
+	}
+}
+
+// Append records an event and persists it
+func (s *WebhookEventStore) Append(event *WebhookEvent) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	s.index(event)
+	if s.AppendFunc != nil {
+		s.AppendFunc(event)
+	}
+}
+
+// Prune removes events received before the cutoff, and their idempotency keys, and
+// returns how many were removed
+func (s *WebhookEventStore) Prune(cutoff time.Time) int {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	kept := make([]*WebhookEvent, 0, len(s.Events))
+	for _, e := range s.Events {
+		if received, err := time.Parse(time.RFC3339, e.ReceivedAt); err == nil && received.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	removed := len(s.Events) - len(kept)
+	if removed == 0 {
+		return 0
+	}
+
+	s.Events = nil
+	s.byID = make(map[string]*WebhookEvent)
+	s.processed = make(map[string]string)
+	for _, e := range kept {
+		s.index(e)
+	}
+	if s.RewriteFunc != nil {
+		s.RewriteFunc(s.Events)
+	}
+	return removed
+}
+
+// ProcessedBy returns the ID of the event that processed the idempotency key, or "" if none has
+func (s *WebhookEventStore) ProcessedBy(key string) string {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+	return s.processed[key]
+}
+
+// Claim reserves an idempotency key for processing. It returns false if the key was already
+// processed, with the ID of the event that processed it, or if another delivery is
+// processing it, with "". A claimed key must be released once its event is appended.
+// An empty key is never reserved.
+func (s *WebhookEventStore) Claim(key string) (string, bool) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	if processedBy := s.processed[key]; processedBy != "" {
+		return processedBy, false
+	}
+	if key == "" {
+		return "", true
+	}
+	if s.claimed[key] {
+		return "", false
+	}
+	s.claimed[key] = true
+	return "", true
+}
+
+// Release ends a claim on an idempotency key. If the event appended for it was processed,
+// later claims see the key as processed; otherwise the key can be claimed again.
+func (s *WebhookEventStore) Release(key string) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	delete(s.claimed, key)
+}
+
+// Get returns a copy of the event with the given ID
+func (s *WebhookEventStore) Get(id string) (WebhookEvent, bool) {
+	s.Mu.RLock()
+	defer s.Mu.RUnlock()
+
+	event, ok := s.byID[id]
+	if !ok {
+		return WebhookEvent{}, false
+	}
+	return *event, true
+}
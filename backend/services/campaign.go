@@ -0,0 +1,420 @@
+package services
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+// CampaignReport aggregates recipient and delivery counts for a campaign.
+// Sent includes delivered and read messages, Delivered includes read messages.
+type CampaignReport struct {
+	CampaignID string `json:"campaign_id"`
+	Status     string `json:"status"`
+	Total      int    `json:"total"`
+	Pending    int    `json:"pending"`
+	Sent       int    `json:"sent"`
+	Delivered  int    `json:"delivered"`
+	Read       int    `json:"read"`
+	Failed     int    `json:"failed"`
+	Skipped    int    `json:"skipped"`
+	Cancelled  int    `json:"cancelled"`
+}
+
+// BuildCampaignReport counts a campaign's recipients, using each recipient's reminder
+// for delivery progress past sent. Callers must not hold the patient store lock.
+func BuildCampaignReport(campaign *models.Campaign, store *models.PatientStore) CampaignReport {
+	report := CampaignReport{
+		CampaignID: campaign.ID,
+		Status:     campaign.Status,
+		Total:      len(campaign.Recipients),
+	}
+
+	store.RLock()
+	defer store.RUnlock()
+	for _, recipient := range campaign.Recipients {
+		switch recipient.Status {
+		case models.RecipientStatusPending:
+			report.Pending++
+		case models.RecipientStatusSkipped:
+			report.Skipped++
+		case models.RecipientStatusCancelled:
+			report.Cancelled++
+		case models.RecipientStatusFailed:
+			report.Failed++
+		case models.RecipientStatusSent, models.RecipientStatusScheduled:
+			status := models.DeliveryStatusSent
+			if patient, ok := store.Patients[recipient.PatientID]; ok {
+				if reminder := findReminderByID(patient, recipient.ReminderID); reminder != nil {
+					status = reminder.DeliveryStatus
+				}
+			}
+			switch status {
+			case models.DeliveryStatusRead:
+				report.Sent++
+				report.Delivered++
+				report.Read++
+			case models.DeliveryStatusDelivered:
+				report.Sent++
+				report.Delivered++
+			case models.DeliveryStatusFailed:
+				report.Failed++
+			case models.DeliveryStatusScheduled:
+				report.Pending++
+			default:
+				report.Sent++
+			}
+		}
+	}
+	return report
+}
+
+// ResolveCampaignRecipients lists the patients matching the audience, sorted by name.
+// Patients without a valid WhatsApp number are included as skipped.
+func ResolveCampaignRecipients(audience models.CampaignAudience, store *models.PatientStore) []models.CampaignRecipient {
+	store.RLock()
+	defer store.RUnlock()
+
+	recipients := make([]models.CampaignRecipient, 0)
+	for _, patient := range store.Patients {
+		if !audience.Matches(patient) {
+			continue
+		}
+		recipient := models.CampaignRecipient{
+			PatientID:   patient.ID,
+			PatientName: patient.Name,
+			Status:      models.RecipientStatusPending,
+		}
+		if !utils.ValidatePhoneNumber(patient.Phone).Valid {
+			recipient.Status = models.RecipientStatusSkipped
+			recipient.Error = models.FailureCodeInvalidPhone
+			recipient.ProcessedAt = time.Now().UTC().Format(time.RFC3339)
+		}
+		recipients = append(recipients, recipient)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		if recipients[i].PatientName == recipients[j].PatientName {
+			return recipients[i].PatientID < recipients[j].PatientID
+		}
+		return recipients[i].PatientName < recipients[j].PatientName
+	})
+	return recipients
+}
+
+// CampaignRunner sends campaign reminders one at a time at the configured rate.
+// Each recipient gets a regular reminder, so delivery tracking, webhooks and
+// history work as for individually created reminders.
+type CampaignRunner struct {
+	campaigns  *models.CampaignStore
+	store      *models.PatientStore
+	scheduler  *ReminderScheduler
+	gowaClient *GOWAClient
+	config     *config.Config
+	logger     *slog.Logger
+	bus        *events.Bus // Campaign progress events, none published if nil
+	generateID func() string
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+	interval   time.Duration
+}
+
+// NewCampaignRunner creates a campaign runner that sends through the scheduler
+func NewCampaignRunner(campaigns *models.CampaignStore, store *models.PatientStore, scheduler *ReminderScheduler, cfg *config.Config, logger *slog.Logger, generateID func() string) *CampaignRunner {
+	rate := cfg.Campaigns.RatePerMinute
+	if rate <= 0 {
+		rate = 20
+	}
+	return &CampaignRunner{
+		campaigns:  campaigns,
+		store:      store,
+		scheduler:  scheduler,
+		gowaClient: scheduler.gowaClient,
+		config:     cfg,
+		logger:     logger,
+		generateID: generateID,
+		stopCh:     make(chan struct{}),
+		interval:   time.Minute / time.Duration(rate),
+	}
+}
+
+// SetEventBus sets the bus that campaign progress is published on
+func (r *CampaignRunner) SetEventBus(bus *events.Bus) {
+	r.bus = bus
+}
+
+// CampaignProgressEvent builds the campaign progress event for a report
+func CampaignProgressEvent(report CampaignReport) events.CampaignProgress {
+	return events.CampaignProgress{
+		CampaignID: report.CampaignID,
+		Status:     report.Status,
+		Report:     report,
+		At:         time.Now().UTC(),
+	}
+}
+
+// Start begins sending campaign messages
+func (r *CampaignRunner) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Stop gracefully stops the runner
+func (r *CampaignRunner) Stop() {
+	close(r.stopCh)
+	r.wg.Wait()
+}
+
+// run is the main runner loop, one message per tick
+func (r *CampaignRunner) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.processNext()
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// campaignSend is the next recipient to send, captured under the campaign lock
+type campaignSend struct {
+	campaignID string
+	index      int
+	recipient  models.CampaignRecipient
+	reminder   models.CampaignReminder
+}
+
+// processNext starts due campaigns and sends to the next pending recipient.
+// It returns false when there was nothing to send.
+func (r *CampaignRunner) processNext() bool {
+	now := time.Now()
+	r.startDueCampaigns(now)
+
+	// Hold sends while GOWA is known to be down
+	if r.gowaClient != nil && r.gowaClient.GetCircuitBreakerState() == CircuitStateOpen {
+		return false
+	}
+
+	next, ok := r.nextRecipient(now)
+	if !ok {
+		return false
+	}
+
+	status, errMsg := r.send(&next)
+
+	r.campaigns.Mu.Lock()
+	campaign, exists := r.campaigns.Campaigns[next.campaignID]
+	if !exists || next.index >= len(campaign.Recipients) {
+		r.campaigns.Mu.Unlock()
+		return true
+	}
+	if status == models.RecipientStatusPending && campaign.Status == models.CampaignStatusCancelled {
+		status = models.RecipientStatusCancelled
+	}
+	recipient := &campaign.Recipients[next.index]
+	recipient.ReminderID = next.recipient.ReminderID
+	recipient.Status = status
+	recipient.Error = errMsg
+	if status != models.RecipientStatusPending {
+		recipient.ProcessedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	if campaign.Status == models.CampaignStatusRunning && !hasPendingRecipients(campaign) {
+		campaign.Status = models.CampaignStatusCompleted
+		campaign.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+		if r.logger != nil {
+			r.logger.Info("Campaign completed", "campaign_id", campaign.ID)
+		}
+	}
+	campaign.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	snapshot := *campaign
+	snapshot.Recipients = append([]models.CampaignRecipient(nil), campaign.Recipients...)
+	r.campaigns.Mu.Unlock()
+	r.campaigns.SaveData()
+
+	r.notify(&snapshot)
+	return true
+}
+
+// startDueCampaigns moves scheduled campaigns whose start time has passed to running,
+// resolving their recipients from the audience at that moment
+func (r *CampaignRunner) startDueCampaigns(now time.Time) {
+	var started []*models.Campaign
+
+	r.campaigns.Mu.Lock()
+	for _, campaign := range r.campaigns.Campaigns {
+		if campaign.Status != models.CampaignStatusScheduled {
+			continue
+		}
+		startAt, err := time.Parse(time.RFC3339, campaign.StartAt)
+		if err != nil || now.Before(startAt) {
+			continue
+		}
+		if campaign.Recipients == nil {
+			campaign.Recipients = ResolveCampaignRecipients(campaign.Audience, r.store)
+		}
+		campaign.Status = models.CampaignStatusRunning
+		if campaign.StartedAt == "" {
+			campaign.StartedAt = now.UTC().Format(time.RFC3339)
+		}
+		campaign.UpdatedAt = now.UTC().Format(time.RFC3339)
+		if !hasPendingRecipients(campaign) {
+			campaign.Status = models.CampaignStatusCompleted
+			campaign.CompletedAt = now.UTC().Format(time.RFC3339)
+		}
+		snapshot := *campaign
+		snapshot.Recipients = append([]models.CampaignRecipient(nil), campaign.Recipients...)
+		started = append(started, &snapshot)
+
+		if r.logger != nil {
+			r.logger.Info("Campaign started",
+				"campaign_id", campaign.ID,
+				"recipients", len(campaign.Recipients),
+			)
+		}
+	}
+	r.campaigns.Mu.Unlock()
+
+	if len(started) == 0 {
+		return
+	}
+	r.campaigns.SaveData()
+	for _, campaign := range started {
+		r.notify(campaign)
+	}
+}
+
+// nextRecipient picks the first pending recipient of the earliest started running campaign
+// that may be sent at now. Recipients in quiet hours for the campaign's priority stay
+// pending, so they are sent at the campaign's rate once quiet hours end.
+func (r *CampaignRunner) nextRecipient(now time.Time) (campaignSend, bool) {
+	r.campaigns.Mu.RLock()
+	defer r.campaigns.Mu.RUnlock()
+	r.store.RLock()
+	defer r.store.RUnlock()
+	blackouts := r.scheduler.blackouts.Active()
+
+	running := make([]*models.Campaign, 0)
+	for _, campaign := range r.campaigns.Campaigns {
+		if campaign.Status == models.CampaignStatusRunning {
+			running = append(running, campaign)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool {
+		if running[i].StartedAt == running[j].StartedAt {
+			return running[i].ID < running[j].ID
+		}
+		return running[i].StartedAt < running[j].StartedAt
+	})
+
+	for _, campaign := range running {
+		for i, recipient := range campaign.Recipients {
+			if recipient.Status != models.RecipientStatusPending {
+				continue
+			}
+			if patient, ok := r.store.Patients[recipient.PatientID]; ok &&
+				utils.ReminderSendTime(now, r.config, blackouts, patient, campaign.Reminder.Priority).QuietHours {
+				continue
+			}
+			reminder := campaign.Reminder
+			reminder.Attachments = append([]models.Attachment(nil), campaign.Reminder.Attachments...)
+			return campaignSend{
+				campaignID: campaign.ID,
+				index:      i,
+				recipient:  recipient,
+				reminder:   reminder,
+			}, true
+		}
+	}
+	return campaignSend{}, false
+}
+
+// send creates (or reuses) the recipient's reminder and sends it through the scheduler.
+// It returns the new recipient status and error, and sets next.recipient.ReminderID.
+func (r *CampaignRunner) send(next *campaignSend) (string, string) {
+	r.store.Lock()
+	patient, exists := r.store.Patients[next.recipient.PatientID]
+	if !exists {
+		r.store.Unlock()
+		return models.RecipientStatusSkipped, "patient_deleted"
+	}
+
+	var reminder *models.Reminder
+	if next.recipient.ReminderID != "" {
+		reminder = findReminderByID(patient, next.recipient.ReminderID)
+	}
+	if reminder == nil {
+		reminder = &models.Reminder{
+			ID:              r.generateID(),
+			Title:           next.reminder.Title,
+			Description:     next.reminder.Description,
+			Priority:        next.reminder.Priority,
+			Attachments:     next.reminder.Attachments,
+			MessageTemplate: next.reminder.MessageTemplate,
+			DeliveryStatus:  models.DeliveryStatusPending,
+			CampaignID:      next.campaignID,
+		}
+		patient.Reminders = append(patient.Reminders, reminder)
+		patient.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	} else if reminder.DeliveryStatus == models.DeliveryStatusQueued {
+		// Queued by an earlier attempt while GOWA was unavailable, send again
+		r.scheduler.transition(reminder, models.DeliveryStatusPending, "campaign resend")
+	}
+	next.recipient.ReminderID = reminder.ID
+	reminderID := reminder.ID
+	r.store.Unlock()
+	r.store.SaveData()
+
+	r.scheduler.sendScheduledReminder(patient.ID, patient, reminder)
+
+	r.store.RLock()
+	defer r.store.RUnlock()
+	current := findReminderByID(patient, reminderID)
+	if current == nil {
+		return models.RecipientStatusSkipped, "reminder_deleted"
+	}
+	switch current.DeliveryStatus {
+	case models.DeliveryStatusSent, models.DeliveryStatusDelivered, models.DeliveryStatusRead:
+		return models.RecipientStatusSent, ""
+	case models.DeliveryStatusQueued, models.DeliveryStatusPending:
+		// GOWA unavailable, keep pending and try again on a later tick
+		return models.RecipientStatusPending, current.DeliveryFailureCode
+	case models.DeliveryStatusScheduled:
+		// Held back by quiet hours or a blackout, the reminder scheduler sends it when they end
+		return models.RecipientStatusScheduled, ""
+	default:
+		code := current.DeliveryFailureCode
+		if code == "" {
+			code = models.FailureCodeOther
+		}
+		return models.RecipientStatusFailed, code
+	}
+}
+
+// notify publishes campaign progress
+func (r *CampaignRunner) notify(campaign *models.Campaign) {
+	if r.bus == nil {
+		return
+	}
+	r.bus.Publish(CampaignProgressEvent(BuildCampaignReport(campaign, r.store)))
+}
+
+// hasPendingRecipients reports whether a campaign still has recipients to send
+func hasPendingRecipients(campaign *models.Campaign) bool {
+	for _, recipient := range campaign.Recipients {
+		if recipient.Status == models.RecipientStatusPending {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// recordingCampaignNotifier records campaign progress events from the bus
+type recordingCampaignNotifier struct {
+	mu      sync.Mutex
+	reports []CampaignReport
+}
+
+func (n *recordingCampaignNotifier) handle(event events.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.reports = append(n.reports, event.(events.CampaignProgress).Report.(CampaignReport))
+	return nil
+}
+
+func setupCampaignRunner(t *testing.T, handler http.HandlerFunc) (*CampaignRunner, *models.CampaignStore, *models.PatientStore) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	store := models.NewPatientStore(func() {})
+
+	// Quiet hours disabled (start == end)
+	hour := 0
+	cfg := &config.Config{
+		QuietHours: config.QuietHoursConfig{StartHour: &hour, EndHour: &hour, Timezone: "WIB"},
+		Campaigns:  config.CampaignsConfig{RatePerMinute: 60},
+	}
+
+	gowaClient := NewGOWAClient(GOWAConfig{
+		Endpoint:         server.URL,
+		Timeout:          5 * time.Second,
+		FailureThreshold: 5,
+		CooldownDuration: 5 * time.Minute,
+	}, logger)
+	scheduler := NewReminderScheduler(store, gowaClient, cfg, logger)
+
+	var seq int64
+	campaigns := models.NewCampaignStore(nil)
+	runner := NewCampaignRunner(campaigns, store, scheduler, cfg, logger, func() string {
+		return fmt.Sprintf("rem-%d", atomic.AddInt64(&seq, 1))
+	})
+	if runner.interval != time.Second {
+		t.Fatalf("Expected interval 1s for 60 per minute, got %v", runner.interval)
+	}
+	return runner, campaigns, store
+}
+
+func okGOWA(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "msg-1"})
+}
+
+func TestCampaignAudience_Matches(t *testing.T) {
+	patient := &models.Patient{ID: "p1", Name: "Siti Aminah", Notes: "ibu hamil", CreatedBy: "vol-1"}
+
+	tests := []struct {
+		name     string
+		audience models.CampaignAudience
+		want     bool
+	}{
+		{"empty audience matches nothing", models.CampaignAudience{}, false},
+		{"all", models.CampaignAudience{All: true}, true},
+		{"patient id", models.CampaignAudience{PatientIDs: []string{"p1"}}, true},
+		{"other patient id", models.CampaignAudience{PatientIDs: []string{"p2"}}, false},
+		{"created by", models.CampaignAudience{CreatedBy: []string{"vol-1"}}, true},
+		{"default language", models.CampaignAudience{Languages: []string{models.LanguageIndonesian}}, true},
+		{"other language", models.CampaignAudience{Languages: []string{models.LanguageEnglish}}, false},
+		{"search notes", models.CampaignAudience{Search: "HAMIL"}, true},
+		{"criteria combined with and", models.CampaignAudience{CreatedBy: []string{"vol-1"}, Search: "tb"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.audience.Matches(patient); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCampaignRunner_SendsAndCompletes(t *testing.T) {
+	runner, campaigns, store := setupCampaignRunner(t, okGOWA)
+	notifier := &recordingCampaignNotifier{}
+	bus := events.NewBus(nil)
+	bus.Subscribe("test", notifier.handle, events.TypeCampaignProgress)
+	t.Cleanup(bus.Close)
+	runner.SetEventBus(bus)
+
+	store.Patients["p1"] = &models.Patient{ID: "p1", Name: "Ani", Phone: "08123456789"}
+	store.Patients["p2"] = &models.Patient{ID: "p2", Name: "Budi", Phone: "08123456780"}
+	store.Patients["p3"] = &models.Patient{ID: "p3", Name: "Citra", Phone: "123"}
+	campaigns.Campaigns["camp-1"] = &models.Campaign{
+		ID:       "camp-1",
+		Name:     "Pekan Imunisasi",
+		Audience: models.CampaignAudience{All: true},
+		Reminder: models.CampaignReminder{Title: "Imunisasi", Description: "Datang ke posyandu"},
+		Status:   models.CampaignStatusScheduled,
+		StartAt:  time.Now().UTC().Add(-time.Minute).Format(time.RFC3339),
+	}
+
+	if !runner.processNext() || !runner.processNext() {
+		t.Fatal("Expected two sends")
+	}
+	if runner.processNext() {
+		t.Error("Expected nothing left to send")
+	}
+
+	campaign := campaigns.Campaigns["camp-1"]
+	if campaign.Status != models.CampaignStatusCompleted {
+		t.Errorf("Expected campaign completed, got %s", campaign.Status)
+	}
+	if len(campaign.Recipients) != 3 {
+		t.Fatalf("Expected 3 recipients, got %d", len(campaign.Recipients))
+	}
+	// Sorted by name: Ani, Budi, Citra
+	if campaign.Recipients[2].Status != models.RecipientStatusSkipped || campaign.Recipients[2].Error != models.FailureCodeInvalidPhone {
+		t.Errorf("Expected invalid phone recipient skipped, got %+v", campaign.Recipients[2])
+	}
+	for _, recipient := range campaign.Recipients[:2] {
+		if recipient.Status != models.RecipientStatusSent || recipient.ReminderID == "" {
+			t.Errorf("Expected recipient sent with reminder, got %+v", recipient)
+		}
+		reminder := findReminderByID(store.Patients[recipient.PatientID], recipient.ReminderID)
+		if reminder == nil || reminder.CampaignID != "camp-1" || reminder.DeliveryStatus != models.DeliveryStatusSent {
+			t.Errorf("Expected sent campaign reminder, got %+v", reminder)
+		}
+	}
+
+	// Delivery receipts on the reminders feed the report
+	store.Patients["p1"].Reminders[0].DeliveryStatus = models.DeliveryStatusRead
+	report := BuildCampaignReport(campaign, store)
+	if report.Total != 3 || report.Sent != 2 || report.Delivered != 1 || report.Read != 1 || report.Skipped != 1 || report.Pending != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	bus.Flush()
+	if len(notifier.reports) == 0 || notifier.reports[len(notifier.reports)-1].Status != models.CampaignStatusCompleted {
+		t.Errorf("Expected completed progress event, got %+v", notifier.reports)
+	}
+}
+
+func TestCampaignRunner_RespectsStatus(t *testing.T) {
+	var sends int32
+	runner, campaigns, store := setupCampaignRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		okGOWA(w, r)
+	})
+	store.Patients["p1"] = &models.Patient{ID: "p1", Name: "Ani", Phone: "08123456789"}
+
+	campaigns.Campaigns["future"] = &models.Campaign{
+		ID:       "future",
+		Audience: models.CampaignAudience{All: true},
+		Reminder: models.CampaignReminder{Title: "Nanti"},
+		Status:   models.CampaignStatusScheduled,
+		StartAt:  time.Now().UTC().Add(time.Hour).Format(time.RFC3339),
+	}
+	campaigns.Campaigns["paused"] = &models.Campaign{
+		ID:         "paused",
+		Audience:   models.CampaignAudience{All: true},
+		Reminder:   models.CampaignReminder{Title: "Jeda"},
+		Status:     models.CampaignStatusPaused,
+		StartedAt:  time.Now().UTC().Format(time.RFC3339),
+		Recipients: []models.CampaignRecipient{{PatientID: "p1", Status: models.RecipientStatusPending}},
+	}
+
+	if runner.processNext() {
+		t.Error("Expected no send for future or paused campaigns")
+	}
+	if atomic.LoadInt32(&sends) != 0 {
+		t.Errorf("Expected no GOWA calls, got %d", sends)
+	}
+	if campaigns.Campaigns["future"].Status != models.CampaignStatusScheduled {
+		t.Errorf("Expected future campaign still scheduled, got %s", campaigns.Campaigns["future"].Status)
+	}
+}
+
+func TestCampaignRunner_FailedSend(t *testing.T) {
+	runner, campaigns, store := setupCampaignRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"INVALID_JID","message":"invalid phone"}`))
+	})
+	store.Patients["p1"] = &models.Patient{ID: "p1", Name: "Ani", Phone: "08123456789"}
+	campaigns.Campaigns["camp-1"] = &models.Campaign{
+		ID:       "camp-1",
+		Audience: models.CampaignAudience{PatientIDs: []string{"p1"}},
+		Reminder: models.CampaignReminder{Title: "Skrining TB"},
+		Status:   models.CampaignStatusScheduled,
+		StartAt:  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	runner.processNext()
+
+	campaign := campaigns.Campaigns["camp-1"]
+	if campaign.Recipients[0].Status != models.RecipientStatusFailed || campaign.Recipients[0].Error == "" {
+		t.Errorf("Expected failed recipient with reason, got %+v", campaign.Recipients[0])
+	}
+	if campaign.Status != models.CampaignStatusCompleted {
+		t.Errorf("Expected campaign completed, got %s", campaign.Status)
+	}
+	if report := BuildCampaignReport(campaign, store); report.Failed != 1 {
+		t.Errorf("Expected 1 failed in report, got %+v", report)
+	}
+}
+
+func TestCampaignRunner_QuietHours(t *testing.T) {
+	var sends int32
+	runner, campaigns, store := setupCampaignRunner(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sends, 1)
+		okGOWA(w, r)
+	})
+	runner.config.QuietHours = currentQuietHours()
+	runner.config.Priorities = testPriorities()
+	runner.config.Priorities.Levels[0].BypassQuietHours = true
+	store.Patients["p1"] = &models.Patient{ID: "p1", Name: "Ani", Phone: "08123456789"}
+
+	started := time.Now().UTC().Add(-time.Minute)
+	campaigns.Campaigns["routine"] = &models.Campaign{
+		ID:        "routine",
+		Reminder:  models.CampaignReminder{Title: "Senam pagi", Priority: "low"},
+		Status:    models.CampaignStatusRunning,
+		StartedAt: started.Format(time.RFC3339),
+		Recipients: []models.CampaignRecipient{
+			{PatientID: "p1", Status: models.RecipientStatusPending},
+		},
+	}
+	campaigns.Campaigns["urgent"] = &models.Campaign{
+		ID:        "urgent",
+		Reminder:  models.CampaignReminder{Title: "Stok vaksin", Priority: "high"},
+		Status:    models.CampaignStatusRunning,
+		StartedAt: started.Add(time.Second).Format(time.RFC3339),
+		Recipients: []models.CampaignRecipient{
+			{PatientID: "p1", Status: models.RecipientStatusPending},
+		},
+	}
+
+	if !runner.processNext() {
+		t.Fatal("Expected the priority bypassing quiet hours to send")
+	}
+	if runner.processNext() {
+		t.Error("Expected the routine campaign held during quiet hours")
+	}
+	if campaigns.Campaigns["urgent"].Recipients[0].Status != models.RecipientStatusSent || atomic.LoadInt32(&sends) != 1 {
+		t.Errorf("Expected one urgent send, got %+v after %d sends", campaigns.Campaigns["urgent"].Recipients[0], sends)
+	}
+	if recipient := campaigns.Campaigns["routine"].Recipients[0]; recipient.Status != models.RecipientStatusPending || recipient.ReminderID != "" {
+		t.Errorf("Expected the routine recipient left pending without a reminder, got %+v", recipient)
+	}
+}
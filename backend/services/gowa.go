@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -15,76 +16,233 @@ import (
 	"github.com/davidyusaku-13/prima_v2/utils"
 )
 
-// CircuitBreaker implements the circuit breaker pattern for GOWA service
+// Circuit breaker states
+const (
+	CircuitStateClosed   = "closed"
+	CircuitStateOpen     = "open"
+	CircuitStateHalfOpen = "half-open"
+)
+
+// CircuitStateChange describes a circuit breaker state transition
+type CircuitStateChange struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	Failures  int       `json:"failures"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// CircuitBreakerOptions holds the tunables for a circuit breaker
+type CircuitBreakerOptions struct {
+	FailureThreshold    int
+	CooldownDuration    time.Duration
+	FailureWindow       time.Duration // 0 counts every failure since the last success
+	HalfOpenMaxRequests int
+	SuccessThreshold    int
+}
+
+// CircuitBreaker implements the circuit breaker pattern for GOWA service.
+// After the cooldown the breaker moves to half-open and lets a limited number
+// of probe requests through; it closes after enough consecutive probe
+// successes and reopens on the first probe failure.
 type CircuitBreaker struct {
-	mu               sync.Mutex
-	failures         int
-	lastFailure      time.Time
-	state            string // "closed", "open"
-	threshold        int
-	cooldownDuration time.Duration
-	logger           *slog.Logger
+	mu                  sync.Mutex
+	failureTimes        []time.Time
+	lastFailure         time.Time
+	state               string // "closed", "open", "half-open"
+	threshold           int
+	cooldownDuration    time.Duration
+	failureWindow       time.Duration
+	halfOpenMaxRequests int
+	successThreshold    int
+	probesInFlight      int
+	probeSuccesses      int
+	halfOpenGeneration  uint64 // Incremented on every move to half-open, so late probes are ignored
+	heldReason          string // Set while tripped open until Release, e.g. the device logged out
+	listeners           []func(CircuitStateChange)
+	logger              *slog.Logger
 }
 
-// NewCircuitBreaker creates a new circuit breaker with the given configuration
+// NewCircuitBreaker creates a new circuit breaker with the given configuration.
+// A single successful probe closes the breaker again.
 func NewCircuitBreaker(threshold int, cooldownDuration time.Duration, logger *slog.Logger) *CircuitBreaker {
+	return NewCircuitBreakerWithOptions(CircuitBreakerOptions{
+		FailureThreshold: threshold,
+		CooldownDuration: cooldownDuration,
+	}, logger)
+}
+
+// NewCircuitBreakerWithOptions creates a new circuit breaker from options
+func NewCircuitBreakerWithOptions(opts CircuitBreakerOptions, logger *slog.Logger) *CircuitBreaker {
+	if logger == nil {
+		logger = utils.DefaultLogger
+	}
+	if opts.HalfOpenMaxRequests <= 0 {
+		opts.HalfOpenMaxRequests = 1
+	}
+	if opts.SuccessThreshold <= 0 {
+		opts.SuccessThreshold = 1
+	}
 	return &CircuitBreaker{
-		state:            "closed",
-		threshold:        threshold,
-		cooldownDuration: cooldownDuration,
-		logger:           logger,
+		state:               CircuitStateClosed,
+		threshold:           opts.FailureThreshold,
+		cooldownDuration:    opts.CooldownDuration,
+		failureWindow:       opts.FailureWindow,
+		halfOpenMaxRequests: opts.HalfOpenMaxRequests,
+		successThreshold:    opts.SuccessThreshold,
+		logger:              logger,
 	}
 }
 
-// Allow checks if a request is allowed through the circuit breaker
-func (cb *CircuitBreaker) Allow() bool {
+// CircuitTicket is issued by Allow for a request let through the breaker and is passed
+// back with its result. Only the results of probes let through during the current
+// half-open period can close or reopen the breaker.
+type CircuitTicket struct {
+	probe      bool
+	generation uint64
+}
+
+// OnStateChange registers a listener that is called after every state transition.
+// Listeners are invoked outside the breaker lock and must not block.
+func (cb *CircuitBreaker) OnStateChange(fn func(CircuitStateChange)) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
+	cb.listeners = append(cb.listeners, fn)
+}
+
+// Allow checks if a request is allowed through the circuit breaker.
+// While half-open every allowed request counts as a probe and must be
+// followed by RecordSuccess or RecordFailure with the returned ticket.
+func (cb *CircuitBreaker) Allow() (CircuitTicket, bool) {
+	cb.mu.Lock()
+	var change *CircuitStateChange
+	allowed := true
 
-	if cb.state == "open" {
-		// Check if cooldown period has passed
+	if cb.heldReason != "" {
+		cb.mu.Unlock()
+		return CircuitTicket{}, false
+	}
+
+	switch cb.state {
+	case CircuitStateOpen:
 		if time.Since(cb.lastFailure) > cb.cooldownDuration {
-			cb.state = "closed"
-			cb.failures = 0
-			cb.logger.Info("Circuit breaker state transition",
-				"from", "open",
-				"to", "closed",
-				"reason", "cooldown_expired",
-			)
-			return true
+			change = cb.transition(CircuitStateHalfOpen, "cooldown_expired")
+			cb.probesInFlight = 1
+		} else {
+			allowed = false
+		}
+	case CircuitStateHalfOpen:
+		if cb.probesInFlight < cb.halfOpenMaxRequests {
+			cb.probesInFlight++
+		} else {
+			allowed = false
 		}
-		return false
 	}
-	return true
+
+	var ticket CircuitTicket
+	if allowed && cb.state == CircuitStateHalfOpen {
+		ticket = CircuitTicket{probe: true, generation: cb.halfOpenGeneration}
+	}
+
+	cb.mu.Unlock()
+	cb.notify(change)
+	return ticket, allowed
 }
 
-// RecordFailure records a failure and potentially opens the circuit
-func (cb *CircuitBreaker) RecordFailure() {
+// CanAttempt reports whether a request would currently be let through,
+// without reserving a half-open probe slot
+func (cb *CircuitBreaker) CanAttempt() bool {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	cb.failures++
+	if cb.heldReason != "" {
+		return false
+	}
+	switch cb.state {
+	case CircuitStateOpen:
+		return time.Since(cb.lastFailure) > cb.cooldownDuration
+	case CircuitStateHalfOpen:
+		return cb.probesInFlight < cb.halfOpenMaxRequests
+	}
+	return true
+}
+
+// Trip opens the circuit and keeps it open, regardless of the cooldown, until Release
+func (cb *CircuitBreaker) Trip(reason string) {
+	cb.mu.Lock()
+	cb.heldReason = reason
 	cb.lastFailure = time.Now()
+	change := cb.transition(CircuitStateOpen, reason)
+	cb.mu.Unlock()
+	cb.notify(change)
+}
 
-	if cb.failures >= cb.threshold {
-		previousState := cb.state
-		cb.state = "open"
-		if previousState != "open" {
-			cb.logger.Warn("Circuit breaker state transition",
-				"from", previousState,
-				"to", "open",
-				"failures", cb.failures,
-				"threshold", cb.threshold,
-			)
-		}
+// Release ends a Trip and closes the circuit. It does nothing if the circuit is not held open.
+func (cb *CircuitBreaker) Release(reason string) {
+	cb.mu.Lock()
+	if cb.heldReason == "" {
+		cb.mu.Unlock()
+		return
 	}
+	cb.heldReason = ""
+	change := cb.transition(CircuitStateClosed, reason)
+	cb.mu.Unlock()
+	cb.notify(change)
 }
 
-// RecordSuccess records a successful request and resets the failure count
-func (cb *CircuitBreaker) RecordSuccess() {
+// HeldReason returns why the circuit is held open by Trip, or "" if it is not
+func (cb *CircuitBreaker) HeldReason() string {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
-	cb.failures = 0
+	return cb.heldReason
+}
+
+// RecordFailure records a failure and potentially opens the circuit
+func (cb *CircuitBreaker) RecordFailure(ticket CircuitTicket) {
+	cb.mu.Lock()
+	var change *CircuitStateChange
+
+	now := time.Now()
+	cb.failureTimes = append(cb.failureTimes, now)
+	cb.lastFailure = now
+	cb.pruneFailures(now)
+
+	switch cb.state {
+	case CircuitStateHalfOpen:
+		if cb.isProbe(ticket) {
+			cb.releaseProbe()
+			change = cb.transition(CircuitStateOpen, "probe_failed")
+		}
+	case CircuitStateClosed:
+		if len(cb.failureTimes) >= cb.threshold {
+			change = cb.transition(CircuitStateOpen, "failure_threshold_reached")
+		}
+	}
+
+	cb.mu.Unlock()
+	cb.notify(change)
+}
+
+// RecordSuccess records a successful request. Without a failure window it resets the
+// failure count; with one, failures only expire with the window. A half-open probe
+// counts towards the consecutive successes needed to close.
+func (cb *CircuitBreaker) RecordSuccess(ticket CircuitTicket) {
+	cb.mu.Lock()
+	var change *CircuitStateChange
+
+	if cb.failureWindow <= 0 {
+		cb.failureTimes = nil
+	}
+	if cb.state == CircuitStateHalfOpen && cb.isProbe(ticket) {
+		cb.releaseProbe()
+		cb.probeSuccesses++
+		if cb.probeSuccesses >= cb.successThreshold {
+			change = cb.transition(CircuitStateClosed, "probes_succeeded")
+		}
+	}
+
+	cb.mu.Unlock()
+	cb.notify(change)
 }
 
 // State returns the current state of the circuit breaker
@@ -94,11 +252,95 @@ func (cb *CircuitBreaker) State() string {
 	return cb.state
 }
 
-// Failures returns the current failure count
+// Failures returns the number of failures counted within the failure window
 func (cb *CircuitBreaker) Failures() int {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
-	return cb.failures
+	cb.pruneFailures(time.Now())
+	return len(cb.failureTimes)
+}
+
+// transition switches state and returns the change to publish. Caller must hold mu.
+func (cb *CircuitBreaker) transition(to, reason string) *CircuitStateChange {
+	from := cb.state
+	if from == to {
+		return nil
+	}
+
+	cb.state = to
+	if to == CircuitStateHalfOpen {
+		cb.halfOpenGeneration++
+	} else {
+		cb.probesInFlight = 0
+	}
+	cb.probeSuccesses = 0
+	if to == CircuitStateClosed {
+		cb.failureTimes = nil
+	}
+
+	change := &CircuitStateChange{
+		From:      from,
+		To:        to,
+		Reason:    reason,
+		Failures:  len(cb.failureTimes),
+		Timestamp: time.Now().UTC(),
+	}
+
+	logFn := cb.logger.Info
+	if to == CircuitStateOpen {
+		logFn = cb.logger.Warn
+	}
+	logFn("Circuit breaker state transition",
+		"from", from,
+		"to", to,
+		"reason", reason,
+		"failures", change.Failures,
+		"threshold", cb.threshold,
+	)
+
+	return change
+}
+
+// notify calls the registered listeners. Caller must not hold mu.
+func (cb *CircuitBreaker) notify(change *CircuitStateChange) {
+	if change == nil {
+		return
+	}
+
+	cb.mu.Lock()
+	listeners := make([]func(CircuitStateChange), len(cb.listeners))
+	copy(listeners, cb.listeners)
+	cb.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(*change)
+	}
+}
+
+// isProbe reports whether the ticket was issued during the current half-open period.
+// Caller must hold mu.
+func (cb *CircuitBreaker) isProbe(ticket CircuitTicket) bool {
+	return ticket.probe && ticket.generation == cb.halfOpenGeneration
+}
+
+// releaseProbe frees a half-open probe slot. Caller must hold mu.
+func (cb *CircuitBreaker) releaseProbe() {
+	if cb.probesInFlight > 0 {
+		cb.probesInFlight--
+	}
+}
+
+// pruneFailures drops failures that fall outside the failure window. Caller must hold mu.
+func (cb *CircuitBreaker) pruneFailures(now time.Time) {
+	if cb.failureWindow <= 0 {
+		return
+	}
+	cutoff := now.Add(-cb.failureWindow)
+	i := 0
+	for i < len(cb.failureTimes) && cb.failureTimes[i].Before(cutoff) {
+		i++
+	}
+	cb.failureTimes = cb.failureTimes[i:]
 }
 
 // GOWAClient is a client for the GOWA WhatsApp gateway service
@@ -114,12 +356,15 @@ type GOWAClient struct {
 
 // GOWAConfig holds configuration for the GOWA client
 type GOWAConfig struct {
-	Endpoint         string
-	User             string
-	Password         string
-	Timeout          time.Duration
-	FailureThreshold int
-	CooldownDuration time.Duration
+	Endpoint            string
+	User                string
+	Password            string
+	Timeout             time.Duration
+	FailureThreshold    int
+	CooldownDuration    time.Duration
+	FailureWindow       time.Duration
+	HalfOpenMaxRequests int
+	SuccessThreshold    int
 }
 
 // NewGOWAClient creates a new GOWA client with the given configuration
@@ -133,11 +378,13 @@ func NewGOWAClient(cfg GOWAConfig, logger *slog.Logger) *GOWAClient {
 		user:     cfg.User,
 		password: cfg.Password,
 		timeout:  cfg.Timeout,
-		circuitBreaker: NewCircuitBreaker(
-			cfg.FailureThreshold,
-			cfg.CooldownDuration,
-			logger,
-		),
+		circuitBreaker: NewCircuitBreakerWithOptions(CircuitBreakerOptions{
+			FailureThreshold:    cfg.FailureThreshold,
+			CooldownDuration:    cfg.CooldownDuration,
+			FailureWindow:       cfg.FailureWindow,
+			HalfOpenMaxRequests: cfg.HalfOpenMaxRequests,
+			SuccessThreshold:    cfg.SuccessThreshold,
+		}, logger),
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 		},
@@ -148,12 +395,15 @@ func NewGOWAClient(cfg GOWAConfig, logger *slog.Logger) *GOWAClient {
 // NewGOWAClientFromConfig creates a GOWA client from application config
 func NewGOWAClientFromConfig(cfg *config.Config, logger *slog.Logger) *GOWAClient {
 	return NewGOWAClient(GOWAConfig{
-		Endpoint:         cfg.GOWA.Endpoint,
-		User:             cfg.GOWA.User,
-		Password:         cfg.GOWA.Password,
-		Timeout:          cfg.GOWA.Timeout,
-		FailureThreshold: cfg.CircuitBreaker.FailureThreshold,
-		CooldownDuration: cfg.CircuitBreaker.CooldownDuration,
+		Endpoint:            cfg.GOWA.Endpoint,
+		User:                cfg.GOWA.User,
+		Password:            cfg.GOWA.Password,
+		Timeout:             cfg.GOWA.Timeout,
+		FailureThreshold:    cfg.CircuitBreaker.FailureThreshold,
+		CooldownDuration:    cfg.CircuitBreaker.CooldownDuration,
+		FailureWindow:       cfg.CircuitBreaker.FailureWindow,
+		HalfOpenMaxRequests: cfg.CircuitBreaker.HalfOpenMaxRequests,
+		SuccessThreshold:    cfg.CircuitBreaker.SuccessThreshold,
 	}, logger)
 }
 
@@ -172,17 +422,12 @@ type SendMessageResponse struct {
 
 // SendMessage sends a WhatsApp message via GOWA
 func (c *GOWAClient) SendMessage(phone, message string) (*SendMessageResponse, error) {
-	// Check circuit breaker
-	if !c.circuitBreaker.Allow() {
-		c.logger.Warn("GOWA request blocked by circuit breaker",
-			"phone", utils.MaskPhone(phone),
-			"circuit_state", c.circuitBreaker.State(),
-		)
-		return nil, fmt.Errorf("circuit breaker is open, GOWA service temporarily unavailable")
-	}
+	return c.SendMessageWithKey(phone, message, "")
+}
 
-	// Prepare request
-	endpoint := c.endpoint + "/send/message"
+// SendMessageWithKey sends a WhatsApp message via GOWA with an Idempotency-Key header
+// so deduplicating gateways can drop repeats of the same reminder occurrence
+func (c *GOWAClient) SendMessageWithKey(phone, message, idempotencyKey string) (*SendMessageResponse, error) {
 	payload := SendMessageRequest{
 		Phone:   phone,
 		Message: message,
@@ -193,15 +438,36 @@ func (c *GOWAClient) SendMessage(phone, message string) (*SendMessageResponse, e
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+	return c.send("/send/message", "application/json", jsonData, phone, idempotencyKey)
+}
+
+// send posts a prepared send request to GOWA through the circuit breaker
+func (c *GOWAClient) send(path, contentType string, body []byte, phone, idempotencyKey string) (*SendMessageResponse, error) {
+	// Prepare request before taking a circuit breaker slot, which is only released by
+	// recording the request's result
+	endpoint := c.endpoint + path
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Check circuit breaker
+	ticket, allowed := c.circuitBreaker.Allow()
+	if !allowed {
+		c.logger.Warn("GOWA request blocked by circuit breaker",
+			"phone", utils.MaskPhone(phone),
+			"circuit_state", c.circuitBreaker.State(),
+		)
+		return nil, ErrCircuitOpen
+	}
+
 	// Set headers
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 	auth := c.user + ":" + c.password
 	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
 
 	// Log request (with masked phone)
 	c.logger.Debug("Sending GOWA request",
@@ -212,41 +478,42 @@ func (c *GOWAClient) SendMessage(phone, message string) (*SendMessageResponse, e
 	// Execute request
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		c.circuitBreaker.RecordFailure()
+		c.circuitBreaker.RecordFailure(ticket)
 		c.logger.Error("GOWA request failed",
 			"error", err.Error(),
 			"phone", utils.MaskPhone(phone),
 			"circuit_failures", c.circuitBreaker.Failures(),
 		)
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, newNetworkError(err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		c.circuitBreaker.RecordFailure()
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		c.circuitBreaker.RecordFailure(ticket)
+		return nil, newNetworkError(err)
 	}
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		c.circuitBreaker.RecordFailure()
+		c.circuitBreaker.RecordFailure(ticket)
 		c.logger.Error("GOWA returned non-OK status",
 			"status_code", resp.StatusCode,
 			"phone", utils.MaskPhone(phone),
-			"response", string(body),
+			"response", string(respBody),
 			"circuit_failures", c.circuitBreaker.Failures(),
 		)
-		return nil, fmt.Errorf("GOWA returned status %d: %s", resp.StatusCode, string(body))
+		return nil, newProtocolError(resp.StatusCode, respBody)
 	}
 
 	// Parse response
 	var result SendMessageResponse
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := json.Unmarshal(respBody, &result); err != nil {
 		// If we can't parse but got 200, consider it a success
-		c.circuitBreaker.RecordSuccess()
+		c.circuitBreaker.RecordSuccess(ticket)
 		c.logger.Info("GOWA message sent (unparseable response)",
+			"endpoint", path,
 			"phone", utils.MaskPhone(phone),
 			"status_code", resp.StatusCode,
 		)
@@ -254,8 +521,9 @@ func (c *GOWAClient) SendMessage(phone, message string) (*SendMessageResponse, e
 	}
 
 	// Record success
-	c.circuitBreaker.RecordSuccess()
+	c.circuitBreaker.RecordSuccess(ticket)
 	c.logger.Info("GOWA message sent successfully",
+		"endpoint", path,
 		"phone", utils.MaskPhone(phone),
 		"message_id", result.MessageID,
 	)
@@ -263,18 +531,40 @@ func (c *GOWAClient) SendMessage(phone, message string) (*SendMessageResponse, e
 	return &result, nil
 }
 
-// IsAvailable checks if the GOWA service is available (circuit breaker is closed)
+// IsAvailable checks if the GOWA service is available (circuit breaker would let a request through).
+// It does not consume a half-open probe slot.
 func (c *GOWAClient) IsAvailable() bool {
-	return c.circuitBreaker.Allow()
+	return c.circuitBreaker.CanAttempt()
+}
+
+// SuspendSending takes the sender out of the send rotation, e.g. because its device
+// logged out. Sends fail with ErrCircuitOpen until ResumeSending.
+func (c *GOWAClient) SuspendSending(reason string) {
+	c.circuitBreaker.Trip(reason)
+}
+
+// ResumeSending returns a suspended sender to the send rotation. It does nothing if
+// sending is not suspended.
+func (c *GOWAClient) ResumeSending(reason string) {
+	c.circuitBreaker.Release(reason)
+}
+
+// OnCircuitStateChange registers a listener for circuit breaker state transitions
+func (c *GOWAClient) OnCircuitStateChange(fn func(CircuitStateChange)) {
+	c.circuitBreaker.OnStateChange(fn)
 }
 
 // CircuitBreakerDetails represents detailed circuit breaker state
 type CircuitBreakerDetails struct {
-	State              string        `json:"state"`
-	FailureCount       int           `json:"failure_count"`
-	CooldownRemaining  time.Duration `json:"cooldown_remaining_seconds"`
-	Threshold          int           `json:"threshold"`
-	CooldownDuration   time.Duration `json:"cooldown_duration_seconds"`
+	State             string        `json:"state"`
+	FailureCount      int           `json:"failure_count"`
+	CooldownRemaining time.Duration `json:"cooldown_remaining_seconds"`
+	Threshold         int           `json:"threshold"`
+	CooldownDuration  time.Duration `json:"cooldown_duration_seconds"`
+	FailureWindow     time.Duration `json:"failure_window_seconds"`
+	ProbesInFlight    int           `json:"probes_in_flight"`
+	ProbeSuccesses    int           `json:"probe_successes"`
+	HeldReason        string        `json:"held_reason,omitempty"` // Why sending is suspended, if it is
 }
 
 // GetCircuitBreakerState returns the current state of the circuit breaker
@@ -293,8 +583,14 @@ func (c *GOWAClient) SetCircuitBreakerStateForTest(state string, failures int, c
 	c.circuitBreaker.mu.Lock()
 	defer c.circuitBreaker.mu.Unlock()
 	c.circuitBreaker.state = state
-	c.circuitBreaker.failures = failures
 	c.circuitBreaker.lastFailure = time.Now().Add(-cooldown)
+	c.circuitBreaker.failureTimes = make([]time.Time, failures)
+	for i := range c.circuitBreaker.failureTimes {
+		c.circuitBreaker.failureTimes[i] = c.circuitBreaker.lastFailure
+	}
+	c.circuitBreaker.probesInFlight = 0
+	c.circuitBreaker.probeSuccesses = 0
+	c.circuitBreaker.heldReason = ""
 }
 
 // GetEndpoint returns the GOWA endpoint URL
@@ -307,10 +603,11 @@ func (c *GOWAClient) GetCircuitBreakerDetails() CircuitBreakerDetails {
 	c.circuitBreaker.mu.Lock()
 	defer c.circuitBreaker.mu.Unlock()
 
+	c.circuitBreaker.pruneFailures(time.Now())
 	state := c.circuitBreaker.state
 	cooldownRemaining := time.Duration(0)
 
-	if state == "open" {
+	if state == CircuitStateOpen {
 		elapsed := time.Since(c.circuitBreaker.lastFailure)
 		if elapsed < c.circuitBreaker.cooldownDuration {
 			cooldownRemaining = c.circuitBreaker.cooldownDuration - elapsed
@@ -319,10 +616,14 @@ func (c *GOWAClient) GetCircuitBreakerDetails() CircuitBreakerDetails {
 
 	return CircuitBreakerDetails{
 		State:             state,
-		FailureCount:      c.circuitBreaker.failures,
+		FailureCount:      len(c.circuitBreaker.failureTimes),
 		CooldownRemaining: cooldownRemaining,
 		Threshold:         c.circuitBreaker.threshold,
 		CooldownDuration:  c.circuitBreaker.cooldownDuration,
+		FailureWindow:     c.circuitBreaker.failureWindow,
+		ProbesInFlight:    c.circuitBreaker.probesInFlight,
+		ProbeSuccesses:    c.circuitBreaker.probeSuccesses,
+		HeldReason:        c.circuitBreaker.heldReason,
 	}
 }
 
@@ -345,70 +646,18 @@ func GetRetryDelay(attempt int, delays []time.Duration) time.Duration {
 	return delays[idx]
 }
 
-// ShouldRetry determines if an error is retryable
-// Returns true for transient errors like network timeouts, connection issues
+// ShouldRetry determines if an error is retryable.
+// GOWA errors decide by kind and HTTP status; other errors default to retry.
 func ShouldRetry(err error) bool {
 	if err == nil {
 		return false
 	}
 
-	errStr := err.Error()
-
-	// Non-retryable errors
-	nonRetryable := []string{
-		"circuit breaker is open",
-		"invalid phone",
-		"nomor",
-		"400",
-		"401",
-		"403",
-		"404",
-	}
-
-	for _, pattern := range nonRetryable {
-		if containsString(errStr, pattern) {
-			return false
-		}
-	}
-
-	// Retryable errors - network issues, timeouts, server errors
-	retryable := []string{
-		"timeout",
-		"context deadline exceeded",
-		"connection refused",
-		"connection reset",
-		"no such host",
-		"network is unreachable",
-		"i/o timeout",
-		"EOF",
-		"server misbehaving",
-		"500",
-		"502",
-		"503",
-		"504",
-	}
-
-	for _, pattern := range retryable {
-		if containsString(errStr, pattern) {
-			return true
-		}
+	var gowaErr *GOWAError
+	if errors.As(err, &gowaErr) {
+		return gowaErr.Retryable()
 	}
 
 	// Default to retry for unknown errors (conservative approach)
 	return true
 }
-
-// containsString checks if a string contains a pattern (case-insensitive for some)
-func containsString(s, pattern string) bool {
-	return len(s) >= len(pattern) && (s == pattern || len(s) > len(pattern) && containsSubstring(s, pattern))
-}
-
-// containsSubstring is a simple substring check
-func containsSubstring(s, pattern string) bool {
-	for i := 0; i <= len(s)-len(pattern); i++ {
-		if s[i:i+len(pattern)] == pattern {
-			return true
-		}
-	}
-	return false
-}
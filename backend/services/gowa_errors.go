@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// GOWAErrorKind classifies where a GOWA request failed
+type GOWAErrorKind string
+
+const (
+	// GOWAErrorNetwork means no HTTP response was received (DNS, connect, timeout, reset)
+	GOWAErrorNetwork GOWAErrorKind = "network"
+	// GOWAErrorProtocol means GOWA answered with an error status or error body
+	GOWAErrorProtocol GOWAErrorKind = "protocol"
+	// GOWAErrorCircuitOpen means the request was rejected locally by the circuit breaker
+	GOWAErrorCircuitOpen GOWAErrorKind = "circuit_open"
+	// GOWAErrorUnconfirmed means an earlier ambiguous send could not be verified yet
+	GOWAErrorUnconfirmed GOWAErrorKind = "unconfirmed"
+)
+
+// GOWAError is the error type returned by GOWAClient send operations
+type GOWAError struct {
+	Kind       GOWAErrorKind
+	StatusCode int    // HTTP status returned by GOWA (protocol errors only)
+	Code       string // GOWA error code from the response body, e.g. "INVALID_JID"
+	Message    string // GOWA error message or a description of the failure
+	Timeout    bool   // Network error caused by a timeout
+	NotSent    bool   // Network error before the request was written (dial or DNS failure)
+	Exhausted  bool   // Unconfirmed send held MaxUnconfirmedAttempts times; left to an operator
+	Err        error  // Underlying error, if any
+}
+
+// ErrCircuitOpen is returned when the circuit breaker rejects a request
+var ErrCircuitOpen = &GOWAError{
+	Kind:    GOWAErrorCircuitOpen,
+	Message: "circuit breaker is open, GOWA service temporarily unavailable",
+}
+
+// Error implements the error interface
+func (e *GOWAError) Error() string {
+	switch e.Kind {
+	case GOWAErrorNetwork:
+		return fmt.Sprintf("request failed: %v", e.Err)
+	case GOWAErrorProtocol:
+		if e.Code != "" {
+			return fmt.Sprintf("GOWA returned status %d (%s): %s", e.StatusCode, e.Code, e.Message)
+		}
+		return fmt.Sprintf("GOWA returned status %d: %s", e.StatusCode, e.Message)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error
+func (e *GOWAError) Unwrap() error {
+	return e.Err
+}
+
+// Ambiguous reports whether the request may have reached GOWA even though no
+// response was received, so a blind resend could deliver the message twice
+func (e *GOWAError) Ambiguous() bool {
+	return e.Kind == GOWAErrorNetwork && !e.NotSent
+}
+
+// Retryable reports whether sending again later may succeed
+func (e *GOWAError) Retryable() bool {
+	switch e.Kind {
+	case GOWAErrorNetwork:
+		return true
+	case GOWAErrorUnconfirmed:
+		return !e.Exhausted
+	case GOWAErrorProtocol:
+		return e.StatusCode >= 500 ||
+			e.StatusCode == http.StatusRequestTimeout ||
+			e.StatusCode == http.StatusTooManyRequests
+	}
+	// Circuit open is handled by queueing, not by the retry policy
+	return false
+}
+
+// ReasonCode maps the error to the failure reason code persisted on the reminder
+func (e *GOWAError) ReasonCode() string {
+	switch e.Kind {
+	case GOWAErrorCircuitOpen, GOWAErrorUnconfirmed:
+		return models.FailureCodeGOWAUnavailable
+	case GOWAErrorNetwork:
+		if e.Timeout {
+			return models.FailureCodeGOWATimeout
+		}
+		return models.FailureCodeGOWAUnavailable
+	case GOWAErrorProtocol:
+		switch {
+		case e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusGatewayTimeout:
+			return models.FailureCodeGOWATimeout
+		case e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests:
+			return models.FailureCodeGOWAUnavailable
+		case isInvalidRecipientCode(e.Code):
+			return models.FailureCodeInvalidPhone
+		case e.StatusCode >= 400:
+			return models.FailureCodeMessageRejected
+		}
+	}
+	return models.FailureCodeOther
+}
+
+// isInvalidRecipientCode reports whether a GOWA error code refers to the recipient number
+func isInvalidRecipientCode(code string) bool {
+	code = strings.ToUpper(code)
+	return strings.Contains(code, "JID") || strings.Contains(code, "PHONE") || strings.Contains(code, "NUMBER")
+}
+
+// FailureReasonCode returns the failure reason code for any send error
+func FailureReasonCode(err error) string {
+	var gowaErr *GOWAError
+	if errors.As(err, &gowaErr) {
+		return gowaErr.ReasonCode()
+	}
+	return models.FailureCodeOther
+}
+
+// gowaErrorBody is the error body GOWA returns alongside non-2xx statuses
+type gowaErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+// newProtocolError builds a protocol error from a GOWA response
+func newProtocolError(statusCode int, body []byte) *GOWAError {
+	gowaErr := &GOWAError{
+		Kind:       GOWAErrorProtocol,
+		StatusCode: statusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+
+	var parsed gowaErrorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		gowaErr.Code = parsed.Code
+		if parsed.Message != "" {
+			gowaErr.Message = parsed.Message
+		} else if parsed.Error != "" {
+			gowaErr.Message = parsed.Error
+		}
+	}
+
+	return gowaErr
+}
+
+// newNetworkError builds a network error from a transport failure
+func newNetworkError(err error) *GOWAError {
+	timeout := errors.Is(err, context.DeadlineExceeded)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		timeout = true
+	}
+
+	notSent := false
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	if (errors.As(err, &opErr) && opErr.Op == "dial") || errors.As(err, &dnsErr) {
+		notSent = true
+	}
+
+	return &GOWAError{
+		Kind:    GOWAErrorNetwork,
+		Message: err.Error(),
+		Timeout: timeout,
+		NotSent: notSent,
+		Err:     err,
+	}
+}
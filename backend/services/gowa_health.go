@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+// GOWAProbeResult holds the outcome of an active GOWA connectivity check
+type GOWAProbeResult struct {
+	Reachable bool          // GOWA answered the HTTP request
+	Connected bool          // WhatsApp websocket is connected
+	LoggedIn  bool          // A device session is logged in
+	DeviceID  string        // Logged-in device JID, if reported
+	Latency   time.Duration // Round trip of the status request
+	Error     string        // Last error, empty on success
+	CheckedAt time.Time
+}
+
+// Healthy reports whether GOWA is reachable with a logged-in device session
+func (r GOWAProbeResult) Healthy() bool {
+	return r.Reachable && r.Connected && r.LoggedIn
+}
+
+// gowaStatusResponse is the body returned by GET /app/status
+type gowaStatusResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Results struct {
+		IsConnected bool   `json:"is_connected"`
+		IsLoggedIn  bool   `json:"is_logged_in"`
+		DeviceID    string `json:"device_id"`
+	} `json:"results"`
+}
+
+// gowaDevicesResponse is the body returned by GET /app/devices
+type gowaDevicesResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Results []struct {
+		Name   string `json:"name"`
+		Device string `json:"device"`
+	} `json:"results"`
+}
+
+// CheckStatus probes GOWA's app status endpoint and reports connectivity and device session state.
+// Older GOWA versions without /app/status are probed through /app/devices instead.
+// The probe bypasses the circuit breaker so it keeps working while sends are blocked.
+func (c *GOWAClient) CheckStatus(ctx context.Context) GOWAProbeResult {
+	result := GOWAProbeResult{CheckedAt: time.Now().UTC()}
+	start := time.Now()
+
+	status, body, err := c.getJSON(ctx, "/app/status")
+	if err == nil && status == http.StatusNotFound {
+		status, body, err = c.getJSON(ctx, "/app/devices")
+		result.Latency = time.Since(start)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Reachable = true
+		if status != http.StatusOK {
+			result.Error = fmt.Sprintf("GOWA returned status %d", status)
+			return result
+		}
+
+		var devices gowaDevicesResponse
+		if err := json.Unmarshal(body, &devices); err != nil {
+			result.Error = "invalid devices response"
+			return result
+		}
+		if len(devices.Results) > 0 {
+			result.Connected = true
+			result.LoggedIn = true
+			result.DeviceID = devices.Results[0].Device
+		} else {
+			result.Error = "no device logged in"
+		}
+		return result
+	}
+
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Reachable = true
+	if status != http.StatusOK {
+		result.Error = fmt.Sprintf("GOWA returned status %d", status)
+		return result
+	}
+
+	var appStatus gowaStatusResponse
+	if err := json.Unmarshal(body, &appStatus); err != nil {
+		result.Error = "invalid status response"
+		return result
+	}
+	result.Connected = appStatus.Results.IsConnected
+	result.LoggedIn = appStatus.Results.IsLoggedIn
+	result.DeviceID = appStatus.Results.DeviceID
+
+	switch {
+	case !result.Connected:
+		result.Error = "device not connected"
+	case !result.LoggedIn:
+		result.Error = "device not logged in"
+	}
+
+	return result
+}
+
+// getJSON performs an authenticated GET against GOWA and returns status code and body
+func (c *GOWAClient) getJSON(ctx context.Context, path string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint+path, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	auth := c.user + ":" + c.password
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(auth)))
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// GOWAHealthChecker periodically probes GOWA and reports the result
+type GOWAHealthChecker struct {
+	client   *GOWAClient
+	interval time.Duration
+	timeout  time.Duration
+	onResult func(GOWAProbeResult)
+	logger   *slog.Logger
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewGOWAHealthChecker creates a health checker that probes every interval with the given per-probe timeout
+func NewGOWAHealthChecker(client *GOWAClient, interval, timeout time.Duration, logger *slog.Logger) *GOWAHealthChecker {
+	if logger == nil {
+		logger = utils.DefaultLogger
+	}
+	return &GOWAHealthChecker{
+		client:   client,
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// SetResultHandler sets the callback that receives every probe result
+func (h *GOWAHealthChecker) SetResultHandler(fn func(GOWAProbeResult)) {
+	h.onResult = fn
+}
+
+// Start runs an initial probe and then probes on every interval
+func (h *GOWAHealthChecker) Start() {
+	h.wg.Add(1)
+	go h.run()
+	h.logger.Info("GOWA health checker started",
+		"interval", h.interval.String(),
+		"timeout", h.timeout.String(),
+	)
+}
+
+// Stop stops the health checker and waits for the running probe to finish
+func (h *GOWAHealthChecker) Stop() {
+	close(h.stopCh)
+	h.wg.Wait()
+	h.logger.Info("GOWA health checker stopped")
+}
+
+// CheckNow probes GOWA once and reports the result
+func (h *GOWAHealthChecker) CheckNow() GOWAProbeResult {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	result := h.client.CheckStatus(ctx)
+	if !result.Healthy() {
+		h.logger.Warn("GOWA health check failed",
+			"reachable", result.Reachable,
+			"connected", result.Connected,
+			"logged_in", result.LoggedIn,
+			"latency_ms", result.Latency.Milliseconds(),
+			"error", result.Error,
+		)
+	}
+
+	if h.onResult != nil {
+		h.onResult(result)
+	}
+	return result
+}
+
+// run is the main health check loop
+func (h *GOWAHealthChecker) run() {
+	defer h.wg.Done()
+
+	h.CheckNow()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			h.CheckNow()
+		case <-h.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// newFakeGOWA starts a fake GOWA server with the given handlers keyed by path
+func newFakeGOWA(t *testing.T, routes map[string]http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "testuser" || pass != "testpass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if handler, ok := routes[r.URL.Path]; ok {
+			handler(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newHealthTestClient(endpoint string) *GOWAClient {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewGOWAClient(GOWAConfig{
+		Endpoint:         endpoint,
+		User:             "testuser",
+		Password:         "testpass",
+		Timeout:          5 * time.Second,
+		FailureThreshold: 5,
+		CooldownDuration: 5 * time.Minute,
+	}, logger)
+}
+
+func TestGOWAClient_CheckStatus(t *testing.T) {
+	t.Run("reports logged-in device from app status", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/app/status": func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"code":"SUCCESS","message":"ok","results":{"is_connected":true,"is_logged_in":true,"device_id":"628123456789@s.whatsapp.net"}}`))
+			},
+		})
+
+		result := newHealthTestClient(server.URL).CheckStatus(context.Background())
+
+		if !result.Healthy() {
+			t.Errorf("Expected healthy result, got %+v", result)
+		}
+		if result.DeviceID != "628123456789@s.whatsapp.net" {
+			t.Errorf("Expected device ID to be reported, got %q", result.DeviceID)
+		}
+		if result.Error != "" {
+			t.Errorf("Expected no error, got %q", result.Error)
+		}
+	})
+
+	t.Run("reports logged-out session", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/app/status": func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"code":"SUCCESS","results":{"is_connected":true,"is_logged_in":false}}`))
+			},
+		})
+
+		result := newHealthTestClient(server.URL).CheckStatus(context.Background())
+
+		if !result.Reachable {
+			t.Error("Expected GOWA to be reachable")
+		}
+		if result.LoggedIn || result.Healthy() {
+			t.Error("Expected logged-out session to be unhealthy")
+		}
+		if result.Error != "device not logged in" {
+			t.Errorf("Expected 'device not logged in' error, got %q", result.Error)
+		}
+	})
+
+	t.Run("falls back to app devices", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/app/devices": func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"code":"SUCCESS","results":[{"name":"PRIMA","device":"628111@s.whatsapp.net"}]}`))
+			},
+		})
+
+		result := newHealthTestClient(server.URL).CheckStatus(context.Background())
+
+		if !result.Healthy() {
+			t.Errorf("Expected healthy result from devices fallback, got %+v", result)
+		}
+		if result.DeviceID != "628111@s.whatsapp.net" {
+			t.Errorf("Expected device ID from devices list, got %q", result.DeviceID)
+		}
+	})
+
+	t.Run("reports auth failure", func(t *testing.T) {
+		server := newFakeGOWA(t, nil)
+		client := newHealthTestClient(server.URL)
+		client.password = "wrong"
+
+		result := client.CheckStatus(context.Background())
+
+		if !result.Reachable || result.Healthy() {
+			t.Errorf("Expected reachable but unhealthy result, got %+v", result)
+		}
+		if result.Error != "GOWA returned status 401" {
+			t.Errorf("Expected status 401 error, got %q", result.Error)
+		}
+	})
+
+	t.Run("reports unreachable server", func(t *testing.T) {
+		server := newFakeGOWA(t, nil)
+		endpoint := server.URL
+		server.Close()
+
+		result := newHealthTestClient(endpoint).CheckStatus(context.Background())
+
+		if result.Reachable {
+			t.Error("Expected GOWA to be unreachable")
+		}
+		if result.Error == "" {
+			t.Error("Expected error to be recorded")
+		}
+	})
+
+	t.Run("respects probe timeout", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/app/status": func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				w.Write([]byte(`{"code":"SUCCESS","results":{"is_connected":true,"is_logged_in":true}}`))
+			},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		result := newHealthTestClient(server.URL).CheckStatus(ctx)
+
+		if result.Reachable || result.Healthy() {
+			t.Errorf("Expected timed out probe to be unhealthy, got %+v", result)
+		}
+	})
+
+	t.Run("does not touch circuit breaker", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/app/status": func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+		})
+
+		client := newHealthTestClient(server.URL)
+		client.CheckStatus(context.Background())
+
+		if client.GetCircuitBreakerFailures() != 0 {
+			t.Errorf("Expected probe not to count as send failure, got %d", client.GetCircuitBreakerFailures())
+		}
+	})
+}
+
+func TestGOWAHealthChecker(t *testing.T) {
+	server := newFakeGOWA(t, map[string]http.HandlerFunc{
+		"/app/status": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"code":"SUCCESS","results":{"is_connected":true,"is_logged_in":true}}`))
+		},
+	})
+
+	results := make(chan GOWAProbeResult, 10)
+	checker := NewGOWAHealthChecker(newHealthTestClient(server.URL), 20*time.Millisecond, time.Second, nil)
+	checker.SetResultHandler(func(result GOWAProbeResult) {
+		results <- result
+	})
+
+	checker.Start()
+	defer checker.Stop()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case result := <-results:
+			if !result.Healthy() {
+				t.Errorf("Expected healthy probe result, got %+v", result)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for health check result")
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+// MaxImageCaptionLength is the longest caption, in characters, WhatsApp accepts on an
+// image message. Longer reminders go out as text only.
+const MaxImageCaptionLength = 1024
+
+// SendLinkRequest represents the request body for GOWA /send/link
+type SendLinkRequest struct {
+	Phone   string `json:"phone"`
+	Link    string `json:"link"`
+	Caption string `json:"caption"`
+}
+
+// OutboundMessage is one reminder message ready to send. When ImagePath is set the
+// message goes out as an image with Text as its caption.
+type OutboundMessage struct {
+	Phone     string
+	Text      string
+	ImagePath string // Local file under uploads/, optional
+}
+
+// SendImage uploads a local image to GOWA /send/image with an optional caption
+func (c *GOWAClient) SendImage(phone, caption, imagePath, idempotencyKey string) (*SendMessageResponse, error) {
+	body, contentType, err := buildMultipartSend(phone, caption, "image", imagePath)
+	if err != nil {
+		return nil, err
+	}
+	return c.send("/send/image", contentType, body, phone, idempotencyKey)
+}
+
+// SendDocument uploads a local file to GOWA /send/file with an optional caption
+func (c *GOWAClient) SendDocument(phone, caption, filePath, idempotencyKey string) (*SendMessageResponse, error) {
+	body, contentType, err := buildMultipartSend(phone, caption, "file", filePath)
+	if err != nil {
+		return nil, err
+	}
+	return c.send("/send/file", contentType, body, phone, idempotencyKey)
+}
+
+// SendLink sends a link with a rich preview via GOWA /send/link
+func (c *GOWAClient) SendLink(phone, link, caption, idempotencyKey string) (*SendMessageResponse, error) {
+	jsonData, err := json.Marshal(SendLinkRequest{
+		Phone:   phone,
+		Link:    link,
+		Caption: caption,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return c.send("/send/link", "application/json", jsonData, phone, idempotencyKey)
+}
+
+// SendOutbound sends a reminder message, as an image with caption when it has one.
+// If the image send fails definitively the text-only message is sent instead.
+// Circuit-open and ambiguous failures are returned as-is so the image is not
+// followed by a possible duplicate.
+func (c *GOWAClient) SendOutbound(msg OutboundMessage, idempotencyKey string) (*SendMessageResponse, error) {
+	if msg.ImagePath == "" || utf8.RuneCountInString(msg.Text) > MaxImageCaptionLength {
+		return c.SendMessageWithKey(msg.Phone, msg.Text, idempotencyKey)
+	}
+
+	response, err := c.SendImage(msg.Phone, msg.Text, msg.ImagePath, idempotencyKey)
+	if err == nil {
+		return response, nil
+	}
+
+	var gowaErr *GOWAError
+	if errors.Is(err, ErrCircuitOpen) || (errors.As(err, &gowaErr) && gowaErr.Ambiguous()) {
+		return nil, err
+	}
+
+	c.logger.Warn("Image send failed, falling back to text message",
+		"phone", utils.MaskPhone(msg.Phone),
+		"image", msg.ImagePath,
+		"error", err.Error(),
+	)
+	return c.SendMessageWithKey(msg.Phone, msg.Text, idempotencyKey)
+}
+
+// buildMultipartSend builds a multipart form with phone, caption and one file field
+func buildMultipartSend(phone, caption, fileField, filePath string) ([]byte, string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open media file: %w", err)
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.WriteField("phone", phone); err != nil {
+		return nil, "", err
+	}
+	if caption != "" {
+		if err := writer.WriteField("caption", caption); err != nil {
+			return nil, "", err
+		}
+	}
+	part, err := writer.CreateFormFile(fileField, filepath.Base(filePath))
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return nil, "", fmt.Errorf("failed to read media file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestImage(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hero_1x1.jpg")
+	if err := os.WriteFile(path, []byte("fake-jpeg"), 0644); err != nil {
+		t.Fatalf("Failed to write test image: %v", err)
+	}
+	return path
+}
+
+func TestGOWAClient_SendImage(t *testing.T) {
+	imagePath := writeTestImage(t)
+	server := newFakeGOWA(t, map[string]http.HandlerFunc{
+		"/send/image": func(w http.ResponseWriter, r *http.Request) {
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("Expected multipart body: %v", err)
+			}
+			if r.FormValue("phone") != "628123456789@s.whatsapp.net" {
+				t.Errorf("Unexpected phone %q", r.FormValue("phone"))
+			}
+			if r.FormValue("caption") != "Caption" {
+				t.Errorf("Unexpected caption %q", r.FormValue("caption"))
+			}
+			file, header, err := r.FormFile("image")
+			if err != nil {
+				t.Fatalf("Expected image file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			if header.Filename != "hero_1x1.jpg" || string(data) != "fake-jpeg" {
+				t.Errorf("Unexpected upload %q: %q", header.Filename, data)
+			}
+			json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "img-1"})
+		},
+	})
+
+	resp, err := newHealthTestClient(server.URL).SendImage("628123456789@s.whatsapp.net", "Caption", imagePath, "")
+	if err != nil {
+		t.Fatalf("SendImage failed: %v", err)
+	}
+	if resp.MessageID != "img-1" {
+		t.Errorf("Expected img-1, got %q", resp.MessageID)
+	}
+}
+
+func TestGOWAClient_SendDocumentAndLink(t *testing.T) {
+	docPath := writeTestImage(t)
+	server := newFakeGOWA(t, map[string]http.HandlerFunc{
+		"/send/file": func(w http.ResponseWriter, r *http.Request) {
+			if _, _, err := r.FormFile("file"); err != nil {
+				t.Errorf("Expected file field: %v", err)
+			}
+			json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "doc-1"})
+		},
+		"/send/link": func(w http.ResponseWriter, r *http.Request) {
+			var req SendLinkRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Link != "https://prima.app/artikel/test" || req.Caption != "Baca ini" {
+				t.Errorf("Unexpected link request %+v", req)
+			}
+			json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "link-1"})
+		},
+	})
+	client := newHealthTestClient(server.URL)
+
+	if resp, err := client.SendDocument("628123456789", "", docPath, ""); err != nil || resp.MessageID != "doc-1" {
+		t.Errorf("SendDocument: got %+v, %v", resp, err)
+	}
+	if resp, err := client.SendLink("628123456789", "https://prima.app/artikel/test", "Baca ini", ""); err != nil || resp.MessageID != "link-1" {
+		t.Errorf("SendLink: got %+v, %v", resp, err)
+	}
+}
+
+func TestGOWAClient_SendOutbound(t *testing.T) {
+	t.Run("sends image with caption", func(t *testing.T) {
+		textSends := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/image": func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "img-1"})
+			},
+			"/send/message": func(w http.ResponseWriter, r *http.Request) { textSends++ },
+		})
+
+		msg := OutboundMessage{Phone: "628123456789", Text: "Halo", ImagePath: writeTestImage(t)}
+		resp, err := newHealthTestClient(server.URL).SendOutbound(msg, "key")
+		if err != nil || resp.MessageID != "img-1" {
+			t.Fatalf("Expected image send, got %+v, %v", resp, err)
+		}
+		if textSends != 0 {
+			t.Errorf("Expected no text send, got %d", textSends)
+		}
+	})
+
+	t.Run("caption limit counts characters", func(t *testing.T) {
+		var sent []string
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/image": func(w http.ResponseWriter, r *http.Request) {
+				sent = append(sent, "image")
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "img-1"})
+			},
+			"/send/message": func(w http.ResponseWriter, r *http.Request) {
+				sent = append(sent, "text")
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "txt-1"})
+			},
+		})
+		client := newHealthTestClient(server.URL)
+		imagePath := writeTestImage(t)
+
+		// 1000 characters but over 1024 bytes
+		fits := strings.Repeat("📖", 10) + strings.Repeat("a", MaxImageCaptionLength-34)
+		tooLong := strings.Repeat("a", MaxImageCaptionLength+1)
+		for _, text := range []string{fits, tooLong} {
+			if _, err := client.SendOutbound(OutboundMessage{Phone: "628123456789", Text: text, ImagePath: imagePath}, ""); err != nil {
+				t.Fatalf("SendOutbound failed: %v", err)
+			}
+		}
+		if strings.Join(sent, ",") != "image,text" {
+			t.Errorf("Expected image then text send, got %v", sent)
+		}
+	})
+
+	t.Run("falls back to text when image is rejected", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/image": func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":"INVALID_FILE","message":"unsupported image"}`))
+			},
+			"/send/message": func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "txt-1"})
+			},
+		})
+
+		msg := OutboundMessage{Phone: "628123456789", Text: "Halo", ImagePath: writeTestImage(t)}
+		resp, err := newHealthTestClient(server.URL).SendOutbound(msg, "key")
+		if err != nil || resp.MessageID != "txt-1" {
+			t.Fatalf("Expected text fallback, got %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("falls back to text when image file is missing", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "txt-1"})
+			},
+		})
+
+		msg := OutboundMessage{Phone: "628123456789", Text: "Halo", ImagePath: filepath.Join(t.TempDir(), "missing.jpg")}
+		resp, err := newHealthTestClient(server.URL).SendOutbound(msg, "key")
+		if err != nil || resp.MessageID != "txt-1" {
+			t.Fatalf("Expected text fallback, got %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("does not fall back when circuit is open", func(t *testing.T) {
+		textSends := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) { textSends++ },
+		})
+		client := newHealthTestClient(server.URL)
+		client.SetCircuitBreakerStateForTest(CircuitStateOpen, 5, 0)
+
+		msg := OutboundMessage{Phone: "628123456789", Text: "Halo", ImagePath: writeTestImage(t)}
+		if _, err := client.SendOutbound(msg, "key"); err != ErrCircuitOpen {
+			t.Errorf("Expected ErrCircuitOpen, got %v", err)
+		}
+		if textSends != 0 {
+			t.Errorf("Expected no text send, got %d", textSends)
+		}
+	})
+}
@@ -2,21 +2,31 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
+
+	"github.com/davidyusaku-13/prima_v2/models"
 )
 
+// allow reports whether the breaker lets a request through, discarding its ticket
+func allow(cb *CircuitBreaker) bool {
+	_, ok := cb.Allow()
+	return ok
+}
+
 func TestCircuitBreaker(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 
 	t.Run("allows requests when closed", func(t *testing.T) {
 		cb := NewCircuitBreaker(5, 5*time.Minute, logger)
 
-		if !cb.Allow() {
+		if !allow(cb) {
 			t.Error("Circuit breaker should allow requests when closed")
 		}
 		if cb.State() != "closed" {
@@ -28,14 +38,14 @@ func TestCircuitBreaker(t *testing.T) {
 		cb := NewCircuitBreaker(3, 5*time.Minute, logger)
 
 		// Record 3 failures
-		cb.RecordFailure()
-		cb.RecordFailure()
-		cb.RecordFailure()
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
 
 		if cb.State() != "open" {
 			t.Errorf("Expected state 'open' after 3 failures, got '%s'", cb.State())
 		}
-		if cb.Allow() {
+		if allow(cb) {
 			t.Error("Circuit breaker should not allow requests when open")
 		}
 	})
@@ -44,27 +54,27 @@ func TestCircuitBreaker(t *testing.T) {
 		cb := NewCircuitBreaker(5, 5*time.Minute, logger)
 
 		// Record some failures
-		cb.RecordFailure()
-		cb.RecordFailure()
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
 
 		if cb.Failures() != 2 {
 			t.Errorf("Expected 2 failures, got %d", cb.Failures())
 		}
 
 		// Record success
-		cb.RecordSuccess()
+		cb.RecordSuccess(CircuitTicket{})
 
 		if cb.Failures() != 0 {
 			t.Errorf("Expected 0 failures after success, got %d", cb.Failures())
 		}
 	})
 
-	t.Run("closes after cooldown", func(t *testing.T) {
+	t.Run("half-opens after cooldown", func(t *testing.T) {
 		cb := NewCircuitBreaker(2, 100*time.Millisecond, logger)
 
 		// Open the circuit
-		cb.RecordFailure()
-		cb.RecordFailure()
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
 
 		if cb.State() != "open" {
 			t.Errorf("Expected state 'open', got '%s'", cb.State())
@@ -73,12 +83,245 @@ func TestCircuitBreaker(t *testing.T) {
 		// Wait for cooldown
 		time.Sleep(150 * time.Millisecond)
 
-		// Should allow and transition to closed
-		if !cb.Allow() {
+		// Should allow a probe and transition to half-open
+		probe, ok := cb.Allow()
+		if !ok {
 			t.Error("Circuit breaker should allow requests after cooldown")
 		}
+		if cb.State() != "half-open" {
+			t.Errorf("Expected state 'half-open' after cooldown, got '%s'", cb.State())
+		}
+
+		// A single successful probe closes the default breaker
+		cb.RecordSuccess(probe)
+		if cb.State() != "closed" {
+			t.Errorf("Expected state 'closed' after successful probe, got '%s'", cb.State())
+		}
+	})
+
+	t.Run("limits probes while half-open", func(t *testing.T) {
+		cb := NewCircuitBreakerWithOptions(CircuitBreakerOptions{
+			FailureThreshold:    1,
+			CooldownDuration:    50 * time.Millisecond,
+			HalfOpenMaxRequests: 2,
+			SuccessThreshold:    3,
+		}, logger)
+
+		cb.RecordFailure(CircuitTicket{})
+		time.Sleep(75 * time.Millisecond)
+
+		first, ok1 := cb.Allow()
+		second, ok2 := cb.Allow()
+		if !ok1 || !ok2 {
+			t.Fatal("Expected two probes to be allowed")
+		}
+		if allow(cb) {
+			t.Error("Expected third concurrent probe to be rejected")
+		}
+
+		// Completing a probe frees a slot but does not close yet
+		cb.RecordSuccess(first)
+		if cb.State() != "half-open" {
+			t.Errorf("Expected state 'half-open' after 1 success, got '%s'", cb.State())
+		}
+		third, ok := cb.Allow()
+		if !ok {
+			t.Error("Expected probe slot to be released after success")
+		}
+
+		cb.RecordSuccess(second)
+		cb.RecordSuccess(third)
+		if cb.State() != "closed" {
+			t.Errorf("Expected state 'closed' after 3 consecutive successes, got '%s'", cb.State())
+		}
+	})
+
+	t.Run("reopens on first probe failure", func(t *testing.T) {
+		cb := NewCircuitBreakerWithOptions(CircuitBreakerOptions{
+			FailureThreshold: 3,
+			CooldownDuration: 50 * time.Millisecond,
+			SuccessThreshold: 2,
+		}, logger)
+
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
+		time.Sleep(75 * time.Millisecond)
+
+		probe, ok := cb.Allow()
+		if !ok {
+			t.Fatal("Expected probe to be allowed")
+		}
+		cb.RecordSuccess(probe)
+		if probe, ok = cb.Allow(); !ok {
+			t.Fatal("Expected second probe to be allowed")
+		}
+		cb.RecordFailure(probe)
+
+		if cb.State() != "open" {
+			t.Errorf("Expected state 'open' after probe failure, got '%s'", cb.State())
+		}
+		if allow(cb) {
+			t.Error("Expected cooldown to restart after probe failure")
+		}
+	})
+
+	t.Run("only counts failures inside the window", func(t *testing.T) {
+		cb := NewCircuitBreakerWithOptions(CircuitBreakerOptions{
+			FailureThreshold: 2,
+			CooldownDuration: 5 * time.Minute,
+			FailureWindow:    50 * time.Millisecond,
+		}, logger)
+
+		cb.RecordFailure(CircuitTicket{})
+		time.Sleep(75 * time.Millisecond)
+
+		if cb.Failures() != 0 {
+			t.Errorf("Expected expired failure to be dropped, got %d", cb.Failures())
+		}
+
+		cb.RecordFailure(CircuitTicket{})
+		if cb.State() != "closed" {
+			t.Errorf("Expected state 'closed' with one failure in window, got '%s'", cb.State())
+		}
+
+		cb.RecordFailure(CircuitTicket{})
+		if cb.State() != "open" {
+			t.Errorf("Expected state 'open' with two failures in window, got '%s'", cb.State())
+		}
+	})
+
+	t.Run("successes do not reset the failure window", func(t *testing.T) {
+		cb := NewCircuitBreakerWithOptions(CircuitBreakerOptions{
+			FailureThreshold: 3,
+			CooldownDuration: 5 * time.Minute,
+			FailureWindow:    time.Minute,
+		}, logger)
+
+		// Every other request fails
+		for i := 0; i < 3; i++ {
+			cb.RecordFailure(CircuitTicket{})
+			cb.RecordSuccess(CircuitTicket{})
+		}
+		if cb.State() != "open" {
+			t.Errorf("Expected state 'open' with 3 failures in window, got '%s'", cb.State())
+		}
+	})
+
+	t.Run("ignores results of requests that are not current probes", func(t *testing.T) {
+		cb := NewCircuitBreakerWithOptions(CircuitBreakerOptions{
+			FailureThreshold:    1,
+			CooldownDuration:    50 * time.Millisecond,
+			HalfOpenMaxRequests: 2,
+		}, logger)
+
+		// A slow request let through while closed finishes during half-open
+		slow, _ := cb.Allow()
+		cb.RecordFailure(CircuitTicket{})
+		time.Sleep(75 * time.Millisecond)
+		stale, _ := cb.Allow()
+		failed, _ := cb.Allow()
+		cb.RecordFailure(failed)
+		if cb.State() != "open" {
+			t.Fatalf("Expected state 'open' after probe failure, got '%s'", cb.State())
+		}
+
+		time.Sleep(75 * time.Millisecond)
+		probe, ok := cb.Allow()
+		if !ok {
+			t.Fatal("Expected probe to be allowed")
+		}
+		cb.RecordSuccess(slow)
+		cb.RecordFailure(slow)
+		cb.RecordSuccess(stale) // Probe from the previous half-open period
+		if cb.State() != "half-open" {
+			t.Errorf("Expected late results not to close or reopen, got '%s'", cb.State())
+		}
+		if !allow(cb) {
+			t.Error("Expected the second probe slot to still be free")
+		}
+
+		cb.RecordSuccess(probe)
 		if cb.State() != "closed" {
-			t.Errorf("Expected state 'closed' after cooldown, got '%s'", cb.State())
+			t.Errorf("Expected state 'closed' after the probe succeeded, got '%s'", cb.State())
+		}
+	})
+
+	t.Run("emits state change events", func(t *testing.T) {
+		cb := NewCircuitBreaker(1, 50*time.Millisecond, logger)
+
+		var changes []CircuitStateChange
+		cb.OnStateChange(func(change CircuitStateChange) {
+			changes = append(changes, change)
+		})
+
+		cb.RecordFailure(CircuitTicket{})
+		time.Sleep(75 * time.Millisecond)
+		probe, _ := cb.Allow()
+		cb.RecordSuccess(probe)
+
+		expected := []struct{ from, to, reason string }{
+			{"closed", "open", "failure_threshold_reached"},
+			{"open", "half-open", "cooldown_expired"},
+			{"half-open", "closed", "probes_succeeded"},
+		}
+		if len(changes) != len(expected) {
+			t.Fatalf("Expected %d state changes, got %d", len(expected), len(changes))
+		}
+		for i, exp := range expected {
+			if changes[i].From != exp.from || changes[i].To != exp.to || changes[i].Reason != exp.reason {
+				t.Errorf("Change %d: expected %s->%s (%s), got %s->%s (%s)",
+					i, exp.from, exp.to, exp.reason, changes[i].From, changes[i].To, changes[i].Reason)
+			}
+		}
+	})
+
+	t.Run("can attempt does not consume probes", func(t *testing.T) {
+		cb := NewCircuitBreaker(1, 50*time.Millisecond, logger)
+
+		cb.RecordFailure(CircuitTicket{})
+		if cb.CanAttempt() {
+			t.Error("Expected CanAttempt to be false while open")
+		}
+		time.Sleep(75 * time.Millisecond)
+
+		if !cb.CanAttempt() || !cb.CanAttempt() {
+			t.Error("Expected CanAttempt to be true after cooldown")
+		}
+		if cb.State() != "open" {
+			t.Errorf("Expected CanAttempt to leave state unchanged, got '%s'", cb.State())
+		}
+		if !allow(cb) {
+			t.Error("Expected probe to be allowed")
+		}
+	})
+
+	t.Run("trip holds open past the cooldown until release", func(t *testing.T) {
+		cb := NewCircuitBreaker(5, 10*time.Millisecond, logger)
+
+		cb.Trip("device_logged_out")
+		time.Sleep(20 * time.Millisecond)
+		if cb.State() != "open" || cb.CanAttempt() || allow(cb) {
+			t.Errorf("Expected tripped circuit to stay open, got state '%s'", cb.State())
+		}
+		if cb.HeldReason() != "device_logged_out" {
+			t.Errorf("Expected held reason, got '%s'", cb.HeldReason())
+		}
+
+		cb.Release("device_connected")
+		if cb.State() != "closed" || !allow(cb) || cb.HeldReason() != "" {
+			t.Errorf("Expected released circuit to close, got state '%s'", cb.State())
+		}
+
+		// Release without a trip leaves a failure-opened circuit alone
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
+		cb.RecordFailure(CircuitTicket{})
+		cb.Release("device_connected")
+		if cb.State() != "open" {
+			t.Errorf("Expected failure-opened circuit to stay open, got '%s'", cb.State())
 		}
 	})
 }
@@ -212,6 +455,25 @@ func TestGOWAClient(t *testing.T) {
 			t.Error("Client should be available initially")
 		}
 	})
+
+	t.Run("invalid request does not take a probe slot", func(t *testing.T) {
+		client := NewGOWAClient(GOWAConfig{
+			Endpoint:         "http://gowa\x7f.invalid",
+			Timeout:          10 * time.Second,
+			FailureThreshold: 1,
+			CooldownDuration: 50 * time.Millisecond,
+		}, logger)
+
+		client.circuitBreaker.RecordFailure(CircuitTicket{})
+		time.Sleep(75 * time.Millisecond)
+
+		if _, err := client.SendMessage("628123456789@s.whatsapp.net", "Test message"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected a request creation error, got %v", err)
+		}
+		if !allow(client.circuitBreaker) {
+			t.Error("Expected the half-open probe slot to still be free")
+		}
+	})
 }
 
 func TestNewGOWAClientFromConfig(t *testing.T) {
@@ -281,109 +543,74 @@ func TestShouldRetry(t *testing.T) {
 		},
 		{
 			name:        "circuit breaker open",
-			err:         &testError{msg: "circuit breaker is open"},
+			err:         ErrCircuitOpen,
 			shouldRetry: false,
 		},
 		{
-			name:        "invalid phone",
-			err:         &testError{msg: "invalid phone number"},
+			name:        "invalid recipient",
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 400, Code: "INVALID_JID", Message: "invalid phone number"},
 			shouldRetry: false,
 		},
 		{
-			name:        "nomor error",
-			err:         &testError{msg: "nomor HP tidak valid"},
-			shouldRetry: false,
-		},
-		{
-			name:        "400 Bad Request",
-			err:         &testError{msg: "400 Bad Request"},
+			name:        "400 Bad Request mentioning 500 in body",
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 400, Message: "phone 628500500500 is not registered"},
 			shouldRetry: false,
 		},
 		{
 			name:        "401 Unauthorized",
-			err:         &testError{msg: "401 Unauthorized"},
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 401},
 			shouldRetry: false,
 		},
 		{
 			name:        "403 Forbidden",
-			err:         &testError{msg: "403 Forbidden"},
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 403},
 			shouldRetry: false,
 		},
 		{
 			name:        "404 Not Found",
-			err:         &testError{msg: "404 Not Found"},
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 404},
 			shouldRetry: false,
 		},
 		// Retryable errors
 		{
-			name:        "timeout",
-			err:         &testError{msg: "connection timeout"},
-			shouldRetry: true,
-		},
-		{
-			name:        "context deadline exceeded",
-			err:         &testError{msg: "context deadline exceeded"},
+			name:        "network timeout",
+			err:         &GOWAError{Kind: GOWAErrorNetwork, Timeout: true, Err: &testError{msg: "i/o timeout"}},
 			shouldRetry: true,
 		},
 		{
 			name:        "connection refused",
-			err:         &testError{msg: "connection refused"},
-			shouldRetry: true,
-		},
-		{
-			name:        "connection reset",
-			err:         &testError{msg: "connection reset by peer"},
-			shouldRetry: true,
-		},
-		{
-			name:        "no such host",
-			err:         &testError{msg: "dial tcp: lookup gowa.example.com: no such host"},
+			err:         &GOWAError{Kind: GOWAErrorNetwork, Err: &testError{msg: "connection refused"}},
 			shouldRetry: true,
 		},
 		{
-			name:        "network unreachable",
-			err:         &testError{msg: "network is unreachable"},
+			name:        "wrapped network error",
+			err:         fmt.Errorf("send reminder: %w", &GOWAError{Kind: GOWAErrorNetwork, Err: &testError{msg: "EOF"}}),
 			shouldRetry: true,
 		},
 		{
-			name:        "i/o timeout",
-			err:         &testError{msg: "read tcp: i/o timeout"},
+			name:        "408 Request Timeout",
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 408},
 			shouldRetry: true,
 		},
 		{
-			name:        "EOF error",
-			err:         &testError{msg: "unexpected EOF"},
+			name:        "429 Too Many Requests",
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 429},
 			shouldRetry: true,
 		},
 		{
 			name:        "500 Internal Server Error",
-			err:         &testError{msg: "500 Internal Server Error"},
-			shouldRetry: true,
-		},
-		{
-			name:        "502 Bad Gateway",
-			err:         &testError{msg: "502 Bad Gateway"},
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 500},
 			shouldRetry: true,
 		},
 		{
 			name:        "503 Service Unavailable",
-			err:         &testError{msg: "503 Service Unavailable"},
-			shouldRetry: true,
-		},
-		{
-			name:        "504 Gateway Timeout",
-			err:         &testError{msg: "504 Gateway Timeout"},
-			shouldRetry: true,
-		},
-		{
-			name:        "server misbehaving",
-			err:         &testError{msg: "server misbehaving"},
+			err:         &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 503},
 			shouldRetry: true,
 		},
 		// Unknown errors - default to retry
 		{
 			name:        "unknown error",
-			err:         &testError{msg: "something unexpected happened"},
+			err:         &testError{msg: "400 something unexpected happened"},
 			shouldRetry: true,
 		},
 	}
@@ -407,26 +634,115 @@ func (e *testError) Error() string {
 	return e.msg
 }
 
-func TestContainsString(t *testing.T) {
+func TestFailureReasonCode(t *testing.T) {
 	tests := []struct {
-		s        string
-		pattern  string
-		expected bool
+		name     string
+		err      error
+		expected string
 	}{
-		{"hello world", "world", true},
-		{"hello world", "hello", true},
-		{"hello world", "lo wo", true},
-		{"hello world", "xyz", false},
-		{"", "test", false},
-		{"test", "", true},
+		{"circuit open", ErrCircuitOpen, models.FailureCodeGOWAUnavailable},
+		{"network timeout", &GOWAError{Kind: GOWAErrorNetwork, Timeout: true}, models.FailureCodeGOWATimeout},
+		{"connection refused", &GOWAError{Kind: GOWAErrorNetwork}, models.FailureCodeGOWAUnavailable},
+		{"gateway timeout", &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 504}, models.FailureCodeGOWATimeout},
+		{"server error", &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 500}, models.FailureCodeGOWAUnavailable},
+		{"invalid jid", &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 400, Code: "INVALID_JID"}, models.FailureCodeInvalidPhone},
+		{"rejected", &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 400, Code: "BAD_REQUEST"}, models.FailureCodeMessageRejected},
+		{"untyped", &testError{msg: "invalid phone"}, models.FailureCodeOther},
 	}
 
 	for _, tt := range tests {
-		t.Run(tt.s+"_"+tt.pattern, func(t *testing.T) {
-			result := containsString(tt.s, tt.pattern)
-			if result != tt.expected {
-				t.Errorf("containsString(%q, %q) = %v, want %v", tt.s, tt.pattern, result, tt.expected)
+		t.Run(tt.name, func(t *testing.T) {
+			if code := FailureReasonCode(tt.err); code != tt.expected {
+				t.Errorf("FailureReasonCode(%v) = %s, want %s", tt.err, code, tt.expected)
 			}
 		})
 	}
 }
+
+func TestGOWAClient_TypedErrors(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	t.Run("protocol error carries status and GOWA code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"INVALID_JID","message":"your phone number is not valid"}`))
+		}))
+		defer server.Close()
+
+		client := NewGOWAClient(GOWAConfig{
+			Endpoint:         server.URL,
+			Timeout:          5 * time.Second,
+			FailureThreshold: 5,
+			CooldownDuration: 5 * time.Minute,
+		}, logger)
+
+		_, err := client.SendMessage("628123456789", "Test")
+
+		var gowaErr *GOWAError
+		if !errors.As(err, &gowaErr) {
+			t.Fatalf("Expected *GOWAError, got %T", err)
+		}
+		if gowaErr.Kind != GOWAErrorProtocol {
+			t.Errorf("Expected protocol error, got %s", gowaErr.Kind)
+		}
+		if gowaErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", gowaErr.StatusCode)
+		}
+		if gowaErr.Code != "INVALID_JID" {
+			t.Errorf("Expected code INVALID_JID, got %s", gowaErr.Code)
+		}
+		if gowaErr.ReasonCode() != models.FailureCodeInvalidPhone {
+			t.Errorf("Expected invalid_phone reason, got %s", gowaErr.ReasonCode())
+		}
+	})
+
+	t.Run("network error is typed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		endpoint := server.URL
+		server.Close()
+
+		client := NewGOWAClient(GOWAConfig{
+			Endpoint:         endpoint,
+			Timeout:          5 * time.Second,
+			FailureThreshold: 5,
+			CooldownDuration: 5 * time.Minute,
+		}, logger)
+
+		_, err := client.SendMessage("628123456789", "Test")
+
+		var gowaErr *GOWAError
+		if !errors.As(err, &gowaErr) {
+			t.Fatalf("Expected *GOWAError, got %T", err)
+		}
+		if gowaErr.Kind != GOWAErrorNetwork {
+			t.Errorf("Expected network error, got %s", gowaErr.Kind)
+		}
+		if !ShouldRetry(err) {
+			t.Error("Expected network error to be retryable")
+		}
+	})
+
+	t.Run("timeout is flagged", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		client := NewGOWAClient(GOWAConfig{
+			Endpoint:         server.URL,
+			Timeout:          50 * time.Millisecond,
+			FailureThreshold: 5,
+			CooldownDuration: 5 * time.Minute,
+		}, logger)
+
+		_, err := client.SendMessage("628123456789", "Test")
+
+		var gowaErr *GOWAError
+		if !errors.As(err, &gowaErr) || !gowaErr.Timeout {
+			t.Fatalf("Expected timeout GOWAError, got %v", err)
+		}
+		if gowaErr.ReasonCode() != models.FailureCodeGOWATimeout {
+			t.Errorf("Expected gowa_timeout reason, got %s", gowaErr.ReasonCode())
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+// errLookupUnsupported is returned when GOWA has no chat history endpoint
+var errLookupUnsupported = errors.New("GOWA does not support message lookup")
+
+// MaxUnconfirmedAttempts is how many times in a row a resend is held because an
+// ambiguous send could not be verified. After that the send fails without automatic
+// retries, and an operator can force a resend.
+const MaxUnconfirmedAttempts = 3
+
+// IdempotencyKey derives the outbound idempotency key for one occurrence of a reminder
+func IdempotencyKey(reminderID, occurrence string) string {
+	sum := sha256.Sum256([]byte(reminderID + "|" + occurrence))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ReminderOccurrence identifies which occurrence of a reminder is being delivered.
+// Changing the due date starts a new occurrence and therefore a new key.
+func ReminderOccurrence(reminder *models.Reminder) string {
+	if reminder.DueDate != "" {
+		return reminder.DueDate
+	}
+	return "once"
+}
+
+// ReminderIdempotencyKey returns the idempotency key for the reminder's current occurrence
+func ReminderIdempotencyKey(reminder *models.Reminder) string {
+	return IdempotencyKey(reminder.ID, ReminderOccurrence(reminder))
+}
+
+// PreviousDeliveryAttempt returns a copy of the attempt that decides how a
+// resend with the given key is handled: a successful attempt if one exists,
+// otherwise the latest attempt. It returns nil for a fresh occurrence.
+// Caller must hold the store lock.
+func PreviousDeliveryAttempt(reminder *models.Reminder, key string) *models.DeliveryAttempt {
+	var latest *models.DeliveryAttempt
+	for i := range reminder.DeliveryAttempts {
+		attempt := reminder.DeliveryAttempts[i]
+		if attempt.IdempotencyKey != key {
+			continue
+		}
+		if attempt.Outcome == models.AttemptOutcomeSent || attempt.Outcome == models.AttemptOutcomeDuplicateSuppressed {
+			return &attempt
+		}
+		latest = &attempt
+	}
+	return latest
+}
+
+// SendIdempotent sends a reminder message at most once per idempotency key.
+// If the previous attempt succeeded the send is suppressed. If it was ambiguous
+// GOWA's chat history is checked first; the message is only resent once GOWA
+// confirms it never went out, or if GOWA has no chat history, in which case the
+// resend relies on the Idempotency-Key header. build is only called when the
+// message is needed, so tracked links are not created for suppressed duplicates
+// or while GOWA is unavailable. The returned bool reports a suppressed duplicate.
+func (c *GOWAClient) SendIdempotent(key string, build func() OutboundMessage, previous *models.DeliveryAttempt) (*SendMessageResponse, bool, error) {
+	var msg OutboundMessage
+	built := false
+	if previous != nil {
+		switch previous.Outcome {
+		case models.AttemptOutcomeSent, models.AttemptOutcomeDuplicateSuppressed:
+			c.logger.Info("Duplicate send suppressed",
+				"idempotency_key", key,
+				"message_id", previous.MessageID,
+			)
+			return &SendMessageResponse{Success: true, MessageID: previous.MessageID}, true, nil
+
+		case models.AttemptOutcomeAmbiguous:
+			since, _ := time.Parse(time.RFC3339, previous.At)
+			msg, built = build(), true
+			messageID, found, err := c.FindSentMessage(msg.Phone, msg.Text, since)
+			if errors.Is(err, errLookupUnsupported) {
+				c.logger.Warn("GOWA cannot verify ambiguous send, resending with idempotency key",
+					"idempotency_key", key,
+					"phone", utils.MaskPhone(msg.Phone),
+				)
+				break
+			}
+			if err != nil {
+				held := previous.Unconfirmed + 1
+				c.logger.Warn("Cannot verify ambiguous send, holding resend",
+					"idempotency_key", key,
+					"phone", utils.MaskPhone(msg.Phone),
+					"held", held,
+					"error", err.Error(),
+				)
+				unconfirmed := &GOWAError{
+					Kind:    GOWAErrorUnconfirmed,
+					Message: "previous send could not be verified with GOWA",
+					Err:     err,
+				}
+				if held >= MaxUnconfirmedAttempts {
+					unconfirmed.Exhausted = true
+					unconfirmed.Message = fmt.Sprintf("previous send could not be verified with GOWA after %d attempts", held)
+				}
+				return nil, false, unconfirmed
+			}
+			if found {
+				c.logger.Info("Ambiguous send confirmed by GOWA, duplicate suppressed",
+					"idempotency_key", key,
+					"message_id", messageID,
+				)
+				return &SendMessageResponse{Success: true, MessageID: messageID}, true, nil
+			}
+		}
+	}
+
+	if !c.IsAvailable() {
+		c.logger.Warn("GOWA send skipped, circuit breaker is open",
+			"idempotency_key", key,
+			"circuit_state", c.circuitBreaker.State(),
+		)
+		return nil, false, ErrCircuitOpen
+	}
+	if !built {
+		msg = build()
+	}
+	response, err := c.SendOutbound(msg, key)
+	return response, false, err
+}
+
+// RecordDeliveryAttempt appends the outcome of a send to the reminder's delivery history.
+// Requests rejected by the circuit breaker never left the server and are not recorded.
+// Caller must hold the store lock.
+func RecordDeliveryAttempt(reminder *models.Reminder, key string, response *SendMessageResponse, duplicate bool, err error) {
+	if errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+
+	attempt := models.DeliveryAttempt{
+		Attempt:        len(reminder.DeliveryAttempts) + 1,
+		IdempotencyKey: key,
+		At:             time.Now().UTC().Format(time.RFC3339),
+	}
+
+	var gowaErr *GOWAError
+	switch {
+	case err == nil && duplicate:
+		attempt.Outcome = models.AttemptOutcomeDuplicateSuppressed
+	case err == nil:
+		attempt.Outcome = models.AttemptOutcomeSent
+	case errors.As(err, &gowaErr) && gowaErr.Kind == GOWAErrorUnconfirmed:
+		// Still ambiguous; count the held resends so they cannot go on forever
+		attempt.Outcome = models.AttemptOutcomeAmbiguous
+		attempt.Error = err.Error()
+		attempt.Unconfirmed = 1
+		if previous := PreviousDeliveryAttempt(reminder, key); previous != nil {
+			attempt.Unconfirmed += previous.Unconfirmed
+		}
+	case errors.As(err, &gowaErr) && gowaErr.Ambiguous():
+		attempt.Outcome = models.AttemptOutcomeAmbiguous
+		attempt.Error = err.Error()
+	default:
+		attempt.Outcome = models.AttemptOutcomeFailed
+		attempt.Error = err.Error()
+	}
+	if response != nil {
+		attempt.MessageID = response.MessageID
+	}
+
+	reminder.DeliveryAttempts = append(reminder.DeliveryAttempts, attempt)
+}
+
+// chatMessagesResponse is the body returned by GET /chat/:chat_jid/messages
+type chatMessagesResponse struct {
+	Code    string `json:"code"`
+	Results struct {
+		Data []struct {
+			ID        string `json:"id"`
+			Content   string `json:"content"`
+			IsFromMe  bool   `json:"is_from_me"`
+			Timestamp string `json:"timestamp"`
+		} `json:"data"`
+	} `json:"results"`
+}
+
+// FindSentMessage looks up an outgoing message with the given text in GOWA's chat
+// history, sent to phone at or after since. It returns the GOWA message ID if found.
+func (c *GOWAClient) FindSentMessage(phone, message string, since time.Time) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	query := url.Values{}
+	query.Set("is_from_me", "true")
+	query.Set("limit", "50")
+	if !since.IsZero() {
+		query.Set("start_time", since.Add(-time.Minute).UTC().Format(time.RFC3339))
+	}
+	path := "/chat/" + url.PathEscape(phone) + "/messages?" + query.Encode()
+
+	status, body, err := c.getJSON(ctx, path)
+	if err != nil {
+		return "", false, err
+	}
+	if status == http.StatusNotFound {
+		return "", false, errLookupUnsupported
+	}
+	if status != http.StatusOK {
+		return "", false, fmt.Errorf("GOWA returned status %d", status)
+	}
+
+	var history chatMessagesResponse
+	if err := json.Unmarshal(body, &history); err != nil {
+		return "", false, fmt.Errorf("invalid chat history response: %w", err)
+	}
+
+	want := strings.TrimSpace(message)
+	for _, msg := range history.Results.Data {
+		if msg.IsFromMe && strings.TrimSpace(msg.Content) == want {
+			return msg.ID, true, nil
+		}
+	}
+	return "", false, nil
+}
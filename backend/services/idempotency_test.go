@@ -0,0 +1,323 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+const testChatPath = "/chat/628123456789@s.whatsapp.net/messages"
+
+func TestReminderIdempotencyKey(t *testing.T) {
+	reminder := &models.Reminder{ID: "rem-1", DueDate: "2026-01-10T08:00:00Z"}
+
+	key := ReminderIdempotencyKey(reminder)
+	if len(key) != 32 {
+		t.Errorf("Expected 32 character key, got %q", key)
+	}
+	if key != ReminderIdempotencyKey(reminder) {
+		t.Error("Expected key to be stable for the same occurrence")
+	}
+
+	next := &models.Reminder{ID: "rem-1", DueDate: "2026-01-11T08:00:00Z"}
+	if key == ReminderIdempotencyKey(next) {
+		t.Error("Expected a new occurrence to get a new key")
+	}
+	other := &models.Reminder{ID: "rem-2", DueDate: "2026-01-10T08:00:00Z"}
+	if key == ReminderIdempotencyKey(other) {
+		t.Error("Expected different reminders to get different keys")
+	}
+}
+
+func TestPreviousDeliveryAttempt(t *testing.T) {
+	reminder := &models.Reminder{
+		DeliveryAttempts: []models.DeliveryAttempt{
+			{Attempt: 1, IdempotencyKey: "old", Outcome: models.AttemptOutcomeSent},
+			{Attempt: 2, IdempotencyKey: "key", Outcome: models.AttemptOutcomeFailed},
+			{Attempt: 3, IdempotencyKey: "key", Outcome: models.AttemptOutcomeAmbiguous},
+		},
+	}
+
+	if prev := PreviousDeliveryAttempt(reminder, "missing"); prev != nil {
+		t.Errorf("Expected nil for unknown key, got %+v", prev)
+	}
+	if prev := PreviousDeliveryAttempt(reminder, "key"); prev == nil || prev.Attempt != 3 {
+		t.Errorf("Expected latest attempt for key, got %+v", prev)
+	}
+
+	reminder.DeliveryAttempts = append(reminder.DeliveryAttempts,
+		models.DeliveryAttempt{Attempt: 4, IdempotencyKey: "key", Outcome: models.AttemptOutcomeSent, MessageID: "msg-4"},
+		models.DeliveryAttempt{Attempt: 5, IdempotencyKey: "key", Outcome: models.AttemptOutcomeFailed},
+	)
+	if prev := PreviousDeliveryAttempt(reminder, "key"); prev == nil || prev.MessageID != "msg-4" {
+		t.Errorf("Expected successful attempt to win, got %+v", prev)
+	}
+}
+
+func TestGOWAClient_SendIdempotent(t *testing.T) {
+	phone := "628123456789@s.whatsapp.net"
+	hello := func() OutboundMessage { return OutboundMessage{Phone: phone, Text: "Hello"} }
+
+	t.Run("fresh send carries idempotency key", func(t *testing.T) {
+		var gotKey string
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) {
+				gotKey = r.Header.Get("Idempotency-Key")
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "msg-1"})
+			},
+		})
+
+		resp, duplicate, err := newHealthTestClient(server.URL).SendIdempotent("key-1", hello, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if duplicate {
+			t.Error("Expected fresh send not to be a duplicate")
+		}
+		if resp.MessageID != "msg-1" {
+			t.Errorf("Expected msg-1, got %q", resp.MessageID)
+		}
+		if gotKey != "key-1" {
+			t.Errorf("Expected Idempotency-Key header key-1, got %q", gotKey)
+		}
+	})
+
+	t.Run("suppresses resend after confirmed send", func(t *testing.T) {
+		sends := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) { sends++ },
+		})
+
+		previous := &models.DeliveryAttempt{IdempotencyKey: "key-1", Outcome: models.AttemptOutcomeSent, MessageID: "msg-1"}
+		build := func() OutboundMessage {
+			t.Error("Expected no message built for a suppressed duplicate")
+			return hello()
+		}
+		resp, duplicate, err := newHealthTestClient(server.URL).SendIdempotent("key-1", build, previous)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !duplicate || resp.MessageID != "msg-1" {
+			t.Errorf("Expected duplicate with msg-1, got duplicate=%v resp=%+v", duplicate, resp)
+		}
+		if sends != 0 {
+			t.Errorf("Expected no send, got %d", sends)
+		}
+	})
+
+	t.Run("no message built while circuit is open", func(t *testing.T) {
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{})
+		client := newHealthTestClient(server.URL)
+		client.SetCircuitBreakerStateForTest(CircuitStateOpen, 5, 0)
+
+		build := func() OutboundMessage {
+			t.Error("Expected no message built while GOWA is unavailable")
+			return hello()
+		}
+		if _, _, err := client.SendIdempotent("key-1", build, nil); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected ErrCircuitOpen, got %v", err)
+		}
+	})
+
+	t.Run("ambiguous attempt found in chat history", func(t *testing.T) {
+		sends := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) { sends++ },
+			testChatPath: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("is_from_me") != "true" {
+					t.Errorf("Expected is_from_me filter")
+				}
+				w.Write([]byte(`{"code":"SUCCESS","results":{"data":[{"id":"msg-other","content":"Other","is_from_me":true},{"id":"msg-9","content":"Hello","is_from_me":true}]}}`))
+			},
+		})
+
+		previous := &models.DeliveryAttempt{IdempotencyKey: "key-1", Outcome: models.AttemptOutcomeAmbiguous, At: time.Now().UTC().Format(time.RFC3339)}
+		resp, duplicate, err := newHealthTestClient(server.URL).SendIdempotent("key-1", hello, previous)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !duplicate || resp.MessageID != "msg-9" {
+			t.Errorf("Expected duplicate with msg-9, got duplicate=%v resp=%+v", duplicate, resp)
+		}
+		if sends != 0 {
+			t.Errorf("Expected no send, got %d", sends)
+		}
+	})
+
+	t.Run("ambiguous attempt absent from chat history is resent", func(t *testing.T) {
+		sends := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) {
+				sends++
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "msg-2"})
+			},
+			testChatPath: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"code":"SUCCESS","results":{"data":[]}}`))
+			},
+		})
+
+		previous := &models.DeliveryAttempt{IdempotencyKey: "key-1", Outcome: models.AttemptOutcomeAmbiguous}
+		_, duplicate, err := newHealthTestClient(server.URL).SendIdempotent("key-1", hello, previous)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if duplicate || sends != 1 {
+			t.Errorf("Expected one real send, got duplicate=%v sends=%d", duplicate, sends)
+		}
+	})
+
+	t.Run("unverifiable ambiguous attempt is held", func(t *testing.T) {
+		sends := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) { sends++ },
+			testChatPath: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+		})
+
+		previous := &models.DeliveryAttempt{IdempotencyKey: "key-1", Outcome: models.AttemptOutcomeAmbiguous}
+		_, _, err := newHealthTestClient(server.URL).SendIdempotent("key-1", hello, previous)
+
+		var gowaErr *GOWAError
+		if !errors.As(err, &gowaErr) || gowaErr.Kind != GOWAErrorUnconfirmed {
+			t.Fatalf("Expected unconfirmed GOWAError, got %v", err)
+		}
+		if !ShouldRetry(err) {
+			t.Error("Expected unconfirmed send to be retryable")
+		}
+		if sends != 0 {
+			t.Errorf("Expected no send, got %d", sends)
+		}
+	})
+}
+
+func TestGOWAClient_SendIdempotent_Retries(t *testing.T) {
+	phone := "628123456789@s.whatsapp.net"
+	msg := func() OutboundMessage { return OutboundMessage{Phone: phone, Text: "Hello"} }
+
+	// retry sends once more the way the scheduler and the retry endpoint do
+	retry := func(client *GOWAClient, reminder *models.Reminder) error {
+		response, duplicate, err := client.SendIdempotent("key-1", msg, PreviousDeliveryAttempt(reminder, "key-1"))
+		RecordDeliveryAttempt(reminder, "key-1", response, duplicate, err)
+		return err
+	}
+
+	t.Run("lookup unsupported resends with the idempotency key", func(t *testing.T) {
+		var keys []string
+		lookups := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) {
+				keys = append(keys, r.Header.Get("Idempotency-Key"))
+				if len(keys) == 1 {
+					// Response cut off: the first resend is ambiguous again
+					w.Header().Set("Content-Length", "100")
+					w.Write([]byte(`{"su`))
+					return
+				}
+				json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "msg-2"})
+			},
+			// No chat history endpoint: GOWA answers 404
+			testChatPath: func(w http.ResponseWriter, r *http.Request) {
+				lookups++
+				http.NotFound(w, r)
+			},
+		})
+		client := newHealthTestClient(server.URL)
+		reminder := &models.Reminder{DeliveryAttempts: []models.DeliveryAttempt{
+			{Attempt: 1, IdempotencyKey: "key-1", Outcome: models.AttemptOutcomeAmbiguous},
+		}}
+
+		if err := retry(client, reminder); err == nil || !ShouldRetry(err) {
+			t.Fatalf("Expected a retryable error from the lost response, got %v", err)
+		}
+		if err := retry(client, reminder); err != nil {
+			t.Fatalf("Expected the second retry to send, got %v", err)
+		}
+
+		if lookups != 2 || len(keys) != 2 || keys[0] != "key-1" || keys[1] != "key-1" {
+			t.Errorf("Expected 2 lookups and 2 resends with key-1, got %d lookups and keys %v", lookups, keys)
+		}
+		if last := reminder.DeliveryAttempts[len(reminder.DeliveryAttempts)-1]; last.Outcome != models.AttemptOutcomeSent || last.MessageID != "msg-2" {
+			t.Errorf("Expected the reminder sent, got %+v", last)
+		}
+	})
+
+	t.Run("held resends are capped", func(t *testing.T) {
+		sends := 0
+		server := newFakeGOWA(t, map[string]http.HandlerFunc{
+			"/send/message": func(w http.ResponseWriter, r *http.Request) { sends++ },
+			testChatPath: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+		})
+		client := newHealthTestClient(server.URL)
+		reminder := &models.Reminder{DeliveryAttempts: []models.DeliveryAttempt{
+			{Attempt: 1, IdempotencyKey: "key-1", Outcome: models.AttemptOutcomeAmbiguous},
+		}}
+
+		for held := 1; held <= MaxUnconfirmedAttempts; held++ {
+			err := retry(client, reminder)
+			var gowaErr *GOWAError
+			if !errors.As(err, &gowaErr) || gowaErr.Kind != GOWAErrorUnconfirmed {
+				t.Fatalf("Hold %d: expected unconfirmed GOWAError, got %v", held, err)
+			}
+			if last := reminder.DeliveryAttempts[len(reminder.DeliveryAttempts)-1]; last.Unconfirmed != held {
+				t.Errorf("Hold %d: expected the attempt to count %d holds, got %d", held, held, last.Unconfirmed)
+			}
+			if exhausted := held == MaxUnconfirmedAttempts; ShouldRetry(err) == exhausted || gowaErr.Exhausted != exhausted {
+				t.Errorf("Hold %d: expected retryable %v, got %v", held, !exhausted, ShouldRetry(err))
+			}
+		}
+		if sends != 0 {
+			t.Errorf("Expected no send, got %d", sends)
+		}
+	})
+}
+
+func TestRecordDeliveryAttempt(t *testing.T) {
+	tests := []struct {
+		name      string
+		response  *SendMessageResponse
+		duplicate bool
+		err       error
+		want      string
+	}{
+		{"sent", &SendMessageResponse{Success: true, MessageID: "msg-1"}, false, nil, models.AttemptOutcomeSent},
+		{"duplicate", &SendMessageResponse{Success: true, MessageID: "msg-1"}, true, nil, models.AttemptOutcomeDuplicateSuppressed},
+		{"rejected", nil, false, &GOWAError{Kind: GOWAErrorProtocol, StatusCode: 400}, models.AttemptOutcomeFailed},
+		{"connection refused", nil, false, newNetworkError(&net.OpError{Op: "dial", Err: errors.New("refused")}), models.AttemptOutcomeFailed},
+		{"response lost", nil, false, newNetworkError(errors.New("connection reset")), models.AttemptOutcomeAmbiguous},
+		{"unconfirmed", nil, false, &GOWAError{Kind: GOWAErrorUnconfirmed}, models.AttemptOutcomeAmbiguous},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminder := &models.Reminder{}
+			RecordDeliveryAttempt(reminder, "key", tt.response, tt.duplicate, tt.err)
+
+			if len(reminder.DeliveryAttempts) != 1 {
+				t.Fatalf("Expected 1 attempt, got %d", len(reminder.DeliveryAttempts))
+			}
+			attempt := reminder.DeliveryAttempts[0]
+			if attempt.Outcome != tt.want {
+				t.Errorf("Expected outcome %s, got %s", tt.want, attempt.Outcome)
+			}
+			if attempt.Attempt != 1 || attempt.IdempotencyKey != "key" || attempt.At == "" {
+				t.Errorf("Unexpected attempt metadata: %+v", attempt)
+			}
+		})
+	}
+
+	t.Run("circuit open is not recorded", func(t *testing.T) {
+		reminder := &models.Reminder{}
+		RecordDeliveryAttempt(reminder, "key", nil, false, ErrCircuitOpen)
+		if len(reminder.DeliveryAttempts) != 0 {
+			t.Errorf("Expected no attempts, got %d", len(reminder.DeliveryAttempts))
+		}
+	})
+}
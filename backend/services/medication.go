@@ -0,0 +1,394 @@
+package services
+
+import (
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+// medicationTopUpInterval is how often dose reminders are generated up to the horizon
+const medicationTopUpInterval = time.Hour
+
+// MedicationSync counts dose reminder changes made by a sync
+type MedicationSync struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+}
+
+// MedicationAdherence summarises confirmed doses of one medication
+type MedicationAdherence struct {
+	MedicationID string  `json:"medication_id"`
+	Due          int     `json:"due"` // Dose reminders whose time has passed
+	Taken        int     `json:"taken"`
+	Skipped      int     `json:"skipped"`
+	Unconfirmed  int     `json:"unconfirmed"`
+	Percent      float64 `json:"percent"` // Taken as a percentage of due doses
+}
+
+// DoseReplyResult describes a dose confirmed by a patient reply
+type DoseReplyResult struct {
+	PatientID    string
+	ReminderID   string
+	MedicationID string
+	Status       string
+}
+
+// MedicationPlanner keeps dose reminders generated for active medications a fixed
+// number of days ahead, and records dose confirmations
+type MedicationPlanner struct {
+	store      *models.PatientStore
+	config     *config.Config
+	logger     *slog.Logger
+	generateID func() string
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewMedicationPlanner creates a medication planner
+func NewMedicationPlanner(store *models.PatientStore, cfg *config.Config, logger *slog.Logger, generateID func() string) *MedicationPlanner {
+	return &MedicationPlanner{
+		store:      store,
+		config:     cfg,
+		logger:     logger,
+		generateID: generateID,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start begins topping up dose reminders in the background
+func (p *MedicationPlanner) Start() {
+	p.wg.Add(1)
+	go p.run()
+}
+
+// Stop gracefully stops the planner
+func (p *MedicationPlanner) Stop() {
+	close(p.stopCh)
+	p.wg.Wait()
+}
+
+// run tops up dose reminders at start and then every medicationTopUpInterval
+func (p *MedicationPlanner) run() {
+	defer p.wg.Done()
+
+	p.SyncAll()
+
+	ticker := time.NewTicker(medicationTopUpInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.SyncAll()
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// SyncAll syncs the dose reminders of every active medication
+func (p *MedicationPlanner) SyncAll() {
+	now := time.Now()
+	created := 0
+
+	p.store.Lock()
+	for _, patient := range p.store.Patients {
+		for _, medication := range patient.Medications {
+			if medication.Status == models.MedicationStatusActive {
+				created += p.SyncMedication(patient, medication, now).Created
+			}
+		}
+	}
+	p.store.Unlock()
+
+	if created > 0 {
+		p.store.SaveData()
+		if p.logger != nil {
+			p.logger.Info("Dose reminders generated", "count", created)
+		}
+	}
+}
+
+// horizonDays returns how many days ahead dose reminders are generated
+func (p *MedicationPlanner) horizonDays() int {
+	if p.config == nil || p.config.Medications.HorizonDays <= 0 {
+		return 7
+	}
+	return p.config.Medications.HorizonDays
+}
+
+// doseText returns the reminder title and description for a medication in the
+// patient's language
+func doseText(language string, medication *models.Medication) (string, string) {
+	var title, reply string
+	if language == models.LanguageEnglish {
+		title = "Time to take " + medication.Name
+		reply = "Reply YES once taken or NO if not."
+	} else {
+		title = "Waktunya minum " + medication.Name
+		reply = "Balas SUDAH jika sudah diminum atau BELUM jika belum."
+	}
+
+	lines := []string{medication.Dose}
+	if medication.Instructions != "" {
+		lines = append(lines, medication.Instructions)
+	}
+	lines = append(lines, "", reply)
+	return title, strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// SyncMedication brings a medication's upcoming dose reminders in line with its schedule:
+// missing doses within the horizon are created, unsent future doses are updated, and
+// unsent future doses no longer in the schedule (or of a stopped medication) are removed.
+// Doses already sent or past are left untouched. The caller must hold the store write lock.
+func (p *MedicationPlanner) SyncMedication(patient *models.Patient, medication *models.Medication, now time.Time) MedicationSync {
+	var result MedicationSync
+
+	slots := make(map[string]bool)
+	if medication.Status == models.MedicationStatusActive {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		for d := 0; d <= p.horizonDays(); d++ {
+			day := today.AddDate(0, 0, d)
+			if !medication.CoversDay(day.Format(models.MedicationDateLayout)) {
+				continue
+			}
+			times, err := medication.DoseTimes(day)
+			if err != nil {
+				break
+			}
+			for _, at := range times {
+				if at.After(now) {
+					slots[at.Format(models.DueDateLayout)] = true
+				}
+			}
+		}
+	}
+
+	title, description := doseText(patient.Language, medication)
+	filled := make(map[string]bool)
+	kept := patient.Reminders[:0]
+	for _, r := range patient.Reminders {
+		if r.MedicationID != medication.ID {
+			kept = append(kept, r)
+			continue
+		}
+		due, err := time.ParseInLocation(models.DueDateLayout, r.DueDate, time.Local)
+		if err != nil || !due.After(now) || !r.IsUnsent() {
+			filled[r.DueDate] = true
+			kept = append(kept, r)
+			continue
+		}
+		if !slots[r.DueDate] || filled[r.DueDate] {
+			result.Removed++
+			continue
+		}
+		if r.Title != title || r.Description != description {
+			r.Title = title
+			r.Description = description
+			result.Updated++
+		}
+		filled[r.DueDate] = true
+		kept = append(kept, r)
+	}
+	patient.Reminders = kept
+
+	for _, day := range sortedKeys(slots) {
+		if filled[day] {
+			continue
+		}
+		patient.Reminders = append(patient.Reminders, &models.Reminder{
+			ID:             p.generateID(),
+			Title:          title,
+			Description:    description,
+			DueDate:        day,
+			Priority:       "medium",
+			DeliveryStatus: models.DeliveryStatusPending,
+			MedicationID:   medication.ID,
+		})
+		result.Created++
+	}
+	return result
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ComputeAdherence counts the due doses of a medication since the given local date
+// (all doses if empty). Cancelled dose reminders are not counted.
+func ComputeAdherence(patient *models.Patient, medicationID, since string, now time.Time) MedicationAdherence {
+	adherence := MedicationAdherence{MedicationID: medicationID}
+	for _, r := range patient.Reminders {
+		if r.MedicationID != medicationID || r.DeliveryStatus == models.DeliveryStatusCancelled {
+			continue
+		}
+		if since != "" && r.DueDate < since {
+			continue
+		}
+		due, err := time.ParseInLocation(models.DueDateLayout, r.DueDate, time.Local)
+		if err != nil || due.After(now) {
+			continue
+		}
+		adherence.Due++
+		switch {
+		case r.Dose == nil:
+			adherence.Unconfirmed++
+		case r.Dose.Status == models.DoseTaken:
+			adherence.Taken++
+		default:
+			adherence.Skipped++
+		}
+	}
+	if adherence.Due > 0 {
+		adherence.Percent = math.Round(float64(adherence.Taken)/float64(adherence.Due)*1000) / 10
+	}
+	return adherence
+}
+
+// ConfirmDoseReply records a patient's WhatsApp reply as a dose confirmation. The reply
+// applies to the dose reminder it quotes, or else to the latest dose reminder sent to the
+// patient within the reply window that is not confirmed yet. It returns false if the
+// message is not a dose reply or there is no dose to confirm.
+func (p *MedicationPlanner) ConfirmDoseReply(from, messageID, text, repliedMessageID string, now time.Time) (DoseReplyResult, bool) {
+	status, ok := utils.ParseDoseReply(text)
+	if !ok {
+		return DoseReplyResult{}, false
+	}
+	if at := strings.Index(from, "@"); at >= 0 {
+		from = from[:at]
+	}
+	phone := utils.NormalizePhoneNumber(from)
+	if phone == "" {
+		return DoseReplyResult{}, false
+	}
+
+	window := 12 * time.Hour
+	if p.config != nil && p.config.Medications.ReplyWindowHours > 0 {
+		window = time.Duration(p.config.Medications.ReplyWindowHours) * time.Hour
+	}
+
+	p.store.Lock()
+	var patient *models.Patient
+	var target *models.Reminder
+	var targetSentAt time.Time
+	for _, candidate := range p.store.Patients {
+		if utils.NormalizePhoneNumber(candidate.Phone) != phone {
+			continue
+		}
+		for _, r := range candidate.Reminders {
+			if r.MedicationID == "" || r.MessageSentAt == "" {
+				continue
+			}
+			if repliedMessageID != "" && r.GOWAMessageID == repliedMessageID {
+				patient, target = candidate, r
+				break
+			}
+			if r.Dose != nil {
+				continue
+			}
+			sentAt, err := time.Parse(time.RFC3339, r.MessageSentAt)
+			if err != nil || now.Sub(sentAt) > window || sentAt.After(now) {
+				continue
+			}
+			if target == nil || sentAt.After(targetSentAt) {
+				patient, target, targetSentAt = candidate, r, sentAt
+			}
+		}
+		if target != nil && repliedMessageID != "" && target.GOWAMessageID == repliedMessageID {
+			break
+		}
+	}
+	if target == nil {
+		p.store.Unlock()
+		return DoseReplyResult{}, false
+	}
+	target.Dose = &models.DoseConfirmation{
+		Status:      status,
+		Source:      models.DoseSourcePatientReply,
+		ConfirmedBy: phone,
+		ConfirmedAt: now.UTC().Format(time.RFC3339),
+
+		ReplyMessageID: messageID,
+	}
+	result := DoseReplyResult{
+		PatientID:    patient.ID,
+		ReminderID:   target.ID,
+		MedicationID: target.MedicationID,
+		Status:       status,
+	}
+	p.store.Unlock()
+	p.store.SaveData()
+
+	if p.logger != nil {
+		p.logger.Info("Dose confirmed by patient reply",
+			"patient_id", result.PatientID,
+			"reminder_id", result.ReminderID,
+			"medication_id", result.MedicationID,
+			"status", status,
+		)
+	}
+	return result, true
+}
+
+// ReviseDoseReply applies an edited or revoked patient reply to the dose it confirmed.
+// An edit to another dose answer changes the status; an edit to anything else, or a
+// revocation, withdraws the confirmation and the result has an empty Status. It returns
+// false if the message did not confirm a dose.
+func (p *MedicationPlanner) ReviseDoseReply(messageID, text string, revoked bool, now time.Time) (DoseReplyResult, bool) {
+	if messageID == "" {
+		return DoseReplyResult{}, false
+	}
+
+	p.store.Lock()
+	var result DoseReplyResult
+	found := false
+	for _, patient := range p.store.Patients {
+		for _, r := range patient.Reminders {
+			if r.Dose == nil || r.Dose.Source != models.DoseSourcePatientReply || r.Dose.ReplyMessageID != messageID {
+				continue
+			}
+			result = DoseReplyResult{PatientID: patient.ID, ReminderID: r.ID, MedicationID: r.MedicationID}
+			if status, ok := utils.ParseDoseReply(text); ok && !revoked {
+				r.Dose.Status = status
+				r.Dose.ConfirmedAt = now.UTC().Format(time.RFC3339)
+				result.Status = status
+			} else {
+				r.Dose = nil
+			}
+			found = true
+			break
+		}
+		if found {
+			break
+		}
+	}
+	p.store.Unlock()
+	if !found {
+		return DoseReplyResult{}, false
+	}
+	p.store.SaveData()
+
+	if p.logger != nil {
+		p.logger.Info("Dose reply revised by patient",
+			"patient_id", result.PatientID,
+			"reminder_id", result.ReminderID,
+			"status", result.Status,
+			"revoked", revoked,
+		)
+	}
+	return result, true
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func newTestMedicationPlanner(store *models.PatientStore) *MedicationPlanner {
+	cfg := &config.Config{Medications: config.MedicationsConfig{HorizonDays: 7, ReplyWindowHours: 12}}
+	next := 0
+	return NewMedicationPlanner(store, cfg, nil, func() string {
+		next++
+		return fmt.Sprintf("dose-%d", next)
+	})
+}
+
+// doseDueDates returns the due dates of a medication's dose reminders
+func doseDueDates(patient *models.Patient, medicationID string) []string {
+	var dates []string
+	for _, r := range patient.Reminders {
+		if r.MedicationID == medicationID {
+			dates = append(dates, r.DueDate)
+		}
+	}
+	return dates
+}
+
+func TestMedicationPlanner_SyncMedication(t *testing.T) {
+	now := time.Date(2026, 3, 10, 10, 0, 0, 0, time.Local)
+	newMedication := func() *models.Medication {
+		return &models.Medication{
+			ID:          "med-1",
+			Name:        "Rifampisin",
+			Dose:        "1 tablet 450 mg",
+			TimesPerDay: 2,
+			Times:       []string{"08:00", "20:00"},
+			StartDate:   "2026-03-10",
+			EndDate:     "2026-03-12",
+			Status:      models.MedicationStatusActive,
+		}
+	}
+
+	t.Run("creates upcoming doses within the schedule", func(t *testing.T) {
+		planner := newTestMedicationPlanner(models.NewPatientStore(func() {}))
+		patient := &models.Patient{ID: "patient-1"}
+		medication := newMedication()
+
+		result := planner.SyncMedication(patient, medication, now)
+		if result.Created != 5 {
+			t.Fatalf("Expected 5 doses created, got %+v", result)
+		}
+		want := []string{"2026-03-10T20:00", "2026-03-11T08:00", "2026-03-11T20:00", "2026-03-12T08:00", "2026-03-12T20:00"}
+		got := doseDueDates(patient, "med-1")
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Expected doses %v, got %v", want, got)
+		}
+		if patient.Reminders[0].Title != "Waktunya minum Rifampisin" {
+			t.Errorf("Unexpected dose title %q", patient.Reminders[0].Title)
+		}
+
+		// A second sync is a no-op
+		if again := planner.SyncMedication(patient, medication, now); again != (MedicationSync{}) {
+			t.Errorf("Expected no changes on resync, got %+v", again)
+		}
+	})
+
+	t.Run("moves unsent doses when the schedule changes", func(t *testing.T) {
+		planner := newTestMedicationPlanner(models.NewPatientStore(func() {}))
+		patient := &models.Patient{ID: "patient-1"}
+		medication := newMedication()
+		planner.SyncMedication(patient, medication, now)
+		patient.Reminders[0].DeliveryStatus = models.DeliveryStatusSent
+		patient.Reminders[0].MessageSentAt = now.UTC().Format(time.RFC3339)
+
+		medication.Times = []string{"09:00"}
+		medication.TimesPerDay = 1
+		result := planner.SyncMedication(patient, medication, now)
+		if result.Removed != 4 || result.Created != 2 {
+			t.Fatalf("Expected 4 removed and 2 created, got %+v", result)
+		}
+		want := []string{"2026-03-10T20:00", "2026-03-11T09:00", "2026-03-12T09:00"}
+		if got := doseDueDates(patient, "med-1"); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("Expected doses %v, got %v", want, got)
+		}
+	})
+
+	t.Run("stopping removes unsent future doses only", func(t *testing.T) {
+		planner := newTestMedicationPlanner(models.NewPatientStore(func() {}))
+		patient := &models.Patient{ID: "patient-1", Reminders: []*models.Reminder{
+			{ID: "past", MedicationID: "med-1", DueDate: "2026-03-10T08:00", DeliveryStatus: models.DeliveryStatusSent},
+			{ID: "other", Title: "Kontrol", DueDate: "2026-03-11T08:00", DeliveryStatus: models.DeliveryStatusPending},
+		}}
+		medication := newMedication()
+		planner.SyncMedication(patient, medication, now)
+
+		medication.Status = models.MedicationStatusStopped
+		result := planner.SyncMedication(patient, medication, now)
+		if result.Removed != 5 {
+			t.Fatalf("Expected 5 doses removed, got %+v", result)
+		}
+		if len(patient.Reminders) != 2 || patient.Reminders[0].ID != "past" || patient.Reminders[1].ID != "other" {
+			t.Errorf("Expected past dose and unrelated reminder kept, got %d reminders", len(patient.Reminders))
+		}
+	})
+}
+
+func TestComputeAdherence(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	patient := &models.Patient{Reminders: []*models.Reminder{
+		{MedicationID: "med-1", DueDate: "2026-03-08T08:00", Dose: &models.DoseConfirmation{Status: models.DoseTaken}},
+		{MedicationID: "med-1", DueDate: "2026-03-09T08:00", Dose: &models.DoseConfirmation{Status: models.DoseSkipped}},
+		{MedicationID: "med-1", DueDate: "2026-03-10T08:00", Dose: &models.DoseConfirmation{Status: models.DoseTaken}},
+		{MedicationID: "med-1", DueDate: "2026-03-10T09:00"},
+		{MedicationID: "med-1", DueDate: "2026-03-10T10:00", DeliveryStatus: models.DeliveryStatusCancelled},
+		{MedicationID: "med-1", DueDate: "2026-03-10T20:00"},
+		{MedicationID: "med-2", DueDate: "2026-03-10T08:00"},
+	}}
+
+	all := ComputeAdherence(patient, "med-1", "", now)
+	if all.Due != 4 || all.Taken != 2 || all.Skipped != 1 || all.Unconfirmed != 1 {
+		t.Fatalf("Unexpected adherence %+v", all)
+	}
+	if all.Percent != 50 {
+		t.Errorf("Expected 50%%, got %v", all.Percent)
+	}
+
+	recent := ComputeAdherence(patient, "med-1", "2026-03-09", now)
+	if recent.Due != 3 || recent.Percent != 33.3 {
+		t.Errorf("Expected 3 due at 33.3%%, got %+v", recent)
+	}
+
+	if none := ComputeAdherence(patient, "med-3", "", now); none.Due != 0 || none.Percent != 0 {
+		t.Errorf("Expected empty adherence, got %+v", none)
+	}
+}
+
+func TestMedicationPlanner_ConfirmDoseReply(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	newStore := func() *models.PatientStore {
+		store := models.NewPatientStore(func() {})
+		store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Phone: "08123456789", Reminders: []*models.Reminder{
+			{ID: "old", MedicationID: "med-1", GOWAMessageID: "msg-old", MessageSentAt: now.Add(-20 * time.Hour).Format(time.RFC3339)},
+			{ID: "morning", MedicationID: "med-1", GOWAMessageID: "msg-morning", MessageSentAt: now.Add(-4 * time.Hour).Format(time.RFC3339)},
+			{ID: "noon", MedicationID: "med-1", GOWAMessageID: "msg-noon", MessageSentAt: now.Add(-time.Hour).Format(time.RFC3339)},
+			{ID: "appointment", GOWAMessageID: "msg-appt", MessageSentAt: now.Add(-time.Minute).Format(time.RFC3339)},
+		}}
+		return store
+	}
+
+	t.Run("confirms the latest unconfirmed dose", func(t *testing.T) {
+		store := newStore()
+		planner := newTestMedicationPlanner(store)
+		result, ok := planner.ConfirmDoseReply("628123456789@s.whatsapp.net", "", "Sudah, terima kasih", "", now)
+		if !ok || result.ReminderID != "noon" || result.Status != models.DoseTaken {
+			t.Fatalf("Expected noon dose taken, got %+v (ok=%v)", result, ok)
+		}
+		dose := store.Patients["patient-1"].Reminders[2].Dose
+		if dose == nil || dose.Source != models.DoseSourcePatientReply {
+			t.Errorf("Expected patient reply confirmation, got %+v", dose)
+		}
+
+		// The next reply applies to the earlier dose still in the window
+		result, ok = planner.ConfirmDoseReply("628123456789", "", "belum", "", now)
+		if !ok || result.ReminderID != "morning" || result.Status != models.DoseSkipped {
+			t.Errorf("Expected morning dose skipped, got %+v (ok=%v)", result, ok)
+		}
+	})
+
+	t.Run("confirms the quoted dose", func(t *testing.T) {
+		planner := newTestMedicationPlanner(newStore())
+		result, ok := planner.ConfirmDoseReply("628123456789", "", "ya", "msg-old", now)
+		if !ok || result.ReminderID != "old" {
+			t.Errorf("Expected quoted dose confirmed, got %+v (ok=%v)", result, ok)
+		}
+	})
+
+	t.Run("ignores other messages", func(t *testing.T) {
+		planner := newTestMedicationPlanner(newStore())
+		if _, ok := planner.ConfirmDoseReply("628123456789", "", "Kapan jadwal kontrol?", "", now); ok {
+			t.Error("Expected a question not to confirm a dose")
+		}
+		if _, ok := planner.ConfirmDoseReply("628999999999", "", "sudah", "", now); ok {
+			t.Error("Expected an unknown sender not to confirm a dose")
+		}
+	})
+}
+
+func TestMedicationPlanner_ReviseDoseReply(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store := models.NewPatientStore(func() {})
+	store.Patients["patient-1"] = &models.Patient{ID: "patient-1", Phone: "08123456789", Reminders: []*models.Reminder{
+		{ID: "noon", MedicationID: "med-1", GOWAMessageID: "msg-noon", MessageSentAt: now.Add(-time.Hour).Format(time.RFC3339)},
+	}}
+	planner := newTestMedicationPlanner(store)
+	dose := func() *models.DoseConfirmation { return store.Patients["patient-1"].Reminders[0].Dose }
+
+	if _, ok := planner.ConfirmDoseReply("628123456789", "reply-1", "belum", "", now); !ok {
+		t.Fatal("Expected reply to confirm the dose")
+	}
+	if _, ok := planner.ReviseDoseReply("reply-other", "sudah", false, now); ok {
+		t.Error("Expected an unrelated message not to revise a dose")
+	}
+
+	result, ok := planner.ReviseDoseReply("reply-1", "sudah", false, now)
+	if !ok || result.ReminderID != "noon" || result.Status != models.DoseTaken || dose().Status != models.DoseTaken {
+		t.Errorf("Expected edit to mark the dose taken, got %+v (ok=%v)", result, ok)
+	}
+
+	result, ok = planner.ReviseDoseReply("reply-1", "", true, now)
+	if !ok || result.Status != "" || dose() != nil {
+		t.Errorf("Expected revocation to withdraw the confirmation, got %+v (ok=%v)", result, ok)
+	}
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+// Headers sent with every outbound webhook. The signature is computed as for inbound
+// GOWA webhooks: HMAC-SHA256 over "<timestamp>.<nonce>.<body>", hex-encoded.
+const (
+	OutboundHeaderEvent     = "X-Webhook-Event"
+	OutboundHeaderDelivery  = "X-Webhook-Delivery" // Stable across retries, for deduplication
+	OutboundHeaderTimestamp = "X-Webhook-Timestamp"
+	OutboundHeaderNonce     = "X-Webhook-Nonce"
+	OutboundHeaderSignature = "X-Webhook-Signature"
+)
+
+// OutboundTestEventType is the event type of deliveries sent from the test-fire endpoint
+const OutboundTestEventType = "webhook.test"
+
+// maxOutboundResponse bounds the response body kept in the delivery log
+const maxOutboundResponse = 512
+
+// ErrOutboundWebhookNotFound is returned for an unknown outbound webhook ID
+var ErrOutboundWebhookNotFound = errors.New("outbound webhook not found")
+
+// OutboundPayload is the JSON body POSTed to an outbound webhook
+type OutboundPayload struct {
+	ID        string      `json:"id"` // Delivery ID, same as X-Webhook-Delivery
+	Type      string      `json:"type"`
+	CreatedAt string      `json:"created_at"` // ISO 8601 UTC
+	Data      interface{} `json:"data"`
+}
+
+// OutboundWebhookSender delivers domain events to admin-managed webhook subscriptions.
+// Each delivery is retried with backoff on its own goroutine, every attempt is recorded
+// in the delivery log, and a subscription is disabled after repeated failed deliveries.
+type OutboundWebhookSender struct {
+	webhooks   *models.OutboundWebhookStore
+	deliveries *models.OutboundDeliveryStore
+	config     *config.Config
+	logger     *slog.Logger
+	client     *http.Client
+	generateID func() string
+
+	mu      sync.Mutex
+	stopped bool
+	ctx     context.Context // Cancelled by Stop, aborting requests and retry waits
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewOutboundWebhookSender creates an outbound webhook sender
+func NewOutboundWebhookSender(webhooks *models.OutboundWebhookStore, deliveries *models.OutboundDeliveryStore, cfg *config.Config, logger *slog.Logger, generateID func() string) *OutboundWebhookSender {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OutboundWebhookSender{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		config:     cfg,
+		logger:     logger,
+		client: &http.Client{
+			Timeout: cfg.OutboundWebhooks.Timeout,
+			// A redirect would resend the signed payload elsewhere; treat it as a failure
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		generateID: generateID,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Stop abandons pending retries and waits for in-flight deliveries to finish
+func (s *OutboundWebhookSender) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+
+	s.cancel()
+	s.wg.Wait()
+}
+
+// HandleEvent is the event bus subscriber that delivers events to the enabled webhooks
+// subscribed to their type. It does not wait for the deliveries.
+func (s *OutboundWebhookSender) HandleEvent(event events.Event) error {
+	for _, webhook := range s.webhooks.Subscribed(event.Type()) {
+		deliveryID := s.generateID()
+		body, err := json.Marshal(OutboundPayload{
+			ID:        deliveryID,
+			Type:      event.Type(),
+			CreatedAt: time.Now().UTC().Format(time.RFC3339),
+			Data:      event,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode outbound webhook payload: %w", err)
+		}
+
+		s.mu.Lock()
+		if s.stopped {
+			s.mu.Unlock()
+			return nil
+		}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.deliver(webhook.ID, deliveryID, event.Type(), body)
+	}
+	return nil
+}
+
+// Test sends a single webhook.test delivery, even to a disabled webhook. It is recorded
+// in the delivery log but is not retried and does not count towards disabling.
+func (s *OutboundWebhookSender) Test(webhookID string) (models.OutboundDelivery, error) {
+	webhook, ok := s.webhooks.Get(webhookID)
+	if !ok {
+		return models.OutboundDelivery{}, ErrOutboundWebhookNotFound
+	}
+
+	deliveryID := s.generateID()
+	body, err := json.Marshal(OutboundPayload{
+		ID:        deliveryID,
+		Type:      OutboundTestEventType,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Data: map[string]string{
+			"webhook_id": webhook.ID,
+			"message":    "Test delivery from PRIMA",
+		},
+	})
+	if err != nil {
+		return models.OutboundDelivery{}, err
+	}
+
+	record, _ := s.attempt(webhook, deliveryID, OutboundTestEventType, body, 1)
+	record.Test = true
+	if record.Outcome != models.OutboundDeliverySucceeded {
+		record.Outcome = models.OutboundDeliveryFailed
+	}
+	s.deliveries.Append(record)
+	return *record, nil
+}
+
+// deliver sends one event to one webhook, retrying with the configured delays
+func (s *OutboundWebhookSender) deliver(webhookID, deliveryID, eventType string, body []byte) {
+	defer s.wg.Done()
+	cfg := s.config.OutboundWebhooks
+
+	for attempt := 1; ; attempt++ {
+		webhook, ok := s.webhooks.Get(webhookID)
+		if !ok || !webhook.Enabled {
+			s.abandon(webhookID, deliveryID, eventType, attempt, "webhook disabled or deleted")
+			return
+		}
+
+		record, retryable := s.attempt(webhook, deliveryID, eventType, body, attempt)
+		if record.Outcome == models.OutboundDeliverySucceeded {
+			s.recordResult(webhookID, true)
+			s.deliveries.Append(record)
+			return
+		}
+
+		if !retryable || attempt >= cfg.MaxAttempts {
+			record.Outcome = models.OutboundDeliveryFailed
+			s.recordResult(webhookID, false)
+			s.deliveries.Append(record)
+			if s.logger != nil {
+				s.logger.Warn("Outbound webhook delivery failed",
+					"webhook_id", webhookID,
+					"delivery_id", deliveryID,
+					"event", eventType,
+					"attempts", attempt,
+					"error", record.Error,
+				)
+			}
+			return
+		}
+
+		delay := GetRetryDelay(attempt, cfg.Delays)
+		record.Outcome = models.OutboundDeliveryRetrying
+		record.NextRetryAt = time.Now().UTC().Add(delay).Format(time.RFC3339)
+		s.deliveries.Append(record)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+			s.abandon(webhookID, deliveryID, eventType, attempt+1, "server shutting down")
+			return
+		}
+	}
+}
+
+// attempt makes one signed request and returns its log record, with the outcome set to
+// succeeded or failed, and whether a failure is worth retrying. Only the first attempt
+// records the payload.
+func (s *OutboundWebhookSender) attempt(webhook models.OutboundWebhook, deliveryID, eventType string, body []byte, attempt int) (*models.OutboundDelivery, bool) {
+	record := &models.OutboundDelivery{
+		ID:          s.generateID(),
+		DeliveryID:  deliveryID,
+		WebhookID:   webhook.ID,
+		EventType:   eventType,
+		Attempt:     attempt,
+		Outcome:     models.OutboundDeliveryFailed,
+		AttemptedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if attempt == 1 {
+		record.Payload = string(body)
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		record.Error = err.Error()
+		return record, false
+	}
+	nonce, err := outboundNonce()
+	if err != nil {
+		record.Error = err.Error()
+		return record, true
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PRIMA-Webhooks/1.0")
+	req.Header.Set(OutboundHeaderEvent, eventType)
+	req.Header.Set(OutboundHeaderDelivery, deliveryID)
+	req.Header.Set(OutboundHeaderTimestamp, timestamp)
+	req.Header.Set(OutboundHeaderNonce, nonce)
+	req.Header.Set(OutboundHeaderSignature, utils.SignWebhookPayload(utils.TimestampedWebhookPayload(timestamp, nonce, body), webhook.Secret))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	record.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		record.Error = err.Error()
+		return record, true
+	}
+	defer resp.Body.Close()
+
+	response, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutboundResponse))
+	record.StatusCode = resp.StatusCode
+	record.Response = string(response)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		record.Outcome = models.OutboundDeliverySucceeded
+		return record, false
+	}
+	record.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	retryable := resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= 500
+	return record, retryable
+}
+
+// abandon records an attempt that was not made
+func (s *OutboundWebhookSender) abandon(webhookID, deliveryID, eventType string, attempt int, reason string) {
+	s.deliveries.Append(&models.OutboundDelivery{
+		ID:          s.generateID(),
+		DeliveryID:  deliveryID,
+		WebhookID:   webhookID,
+		EventType:   eventType,
+		Attempt:     attempt,
+		Outcome:     models.OutboundDeliveryAbandoned,
+		Error:       reason,
+		AttemptedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// recordResult updates the webhook's delivery counters and disables it once
+// outbound_webhooks.disable_after_failures deliveries in a row have failed
+func (s *OutboundWebhookSender) recordResult(webhookID string, success bool) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	limit := s.config.OutboundWebhooks.DisableAfterFailures
+
+	s.webhooks.Mu.Lock()
+	webhook, ok := s.webhooks.Webhooks[webhookID]
+	if !ok {
+		s.webhooks.Mu.Unlock()
+		return
+	}
+	webhook.LastDeliveryAt = now
+	disabled := false
+	if success {
+		webhook.ConsecutiveFailures = 0
+		webhook.LastSuccessAt = now
+	} else {
+		webhook.ConsecutiveFailures++
+		if webhook.Enabled && webhook.ConsecutiveFailures >= limit {
+			webhook.Enabled = false
+			webhook.DisabledReason = fmt.Sprintf("%d consecutive failed deliveries", webhook.ConsecutiveFailures)
+			webhook.DisabledAt = now
+			disabled = true
+		}
+	}
+	s.webhooks.Mu.Unlock()
+	s.webhooks.SaveData()
+
+	if disabled && s.logger != nil {
+		s.logger.Warn("Outbound webhook disabled after repeated failures",
+			"webhook_id", webhookID,
+			"failures", limit,
+		)
+	}
+}
+
+// outboundNonce returns a random nonce for one request
+func outboundNonce() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
+	"github.com/davidyusaku-13/prima_v2/models"
+	"github.com/davidyusaku-13/prima_v2/utils"
+)
+
+const testOutboundSecret = "partner-secret-0123456789"
+
+func setupOutboundSender(t *testing.T, handler http.HandlerFunc) (*OutboundWebhookSender, *models.OutboundWebhookStore, *models.OutboundDeliveryStore) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{OutboundWebhooks: config.OutboundWebhooksConfig{
+		Timeout:              time.Second,
+		MaxAttempts:          3,
+		Delays:               []time.Duration{time.Millisecond},
+		DisableAfterFailures: 2,
+	}}
+	webhooks := models.NewOutboundWebhookStore(func() {})
+	webhooks.Webhooks["hook-1"] = &models.OutboundWebhook{
+		ID:         "hook-1",
+		URL:        server.URL,
+		EventTypes: []string{events.TypeDeliveryFailed},
+		Secret:     testOutboundSecret,
+		Enabled:    true,
+	}
+	deliveries := models.NewOutboundDeliveryStore(nil)
+
+	var next atomic.Int64
+	sender := NewOutboundWebhookSender(webhooks, deliveries, cfg, nil, func() string {
+		return fmt.Sprintf("id-%d", next.Add(1))
+	})
+	t.Cleanup(sender.Stop)
+	return sender, webhooks, deliveries
+}
+
+// outcomes returns the attempt outcomes in the delivery log
+func outcomes(deliveries *models.OutboundDeliveryStore) []string {
+	deliveries.Mu.RLock()
+	defer deliveries.Mu.RUnlock()
+	var got []string
+	for _, d := range deliveries.Deliveries {
+		got = append(got, d.Outcome)
+	}
+	return got
+}
+
+// waitForDeliveries waits until n attempts are logged
+func waitForDeliveries(t *testing.T, deliveries *models.OutboundDeliveryStore, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(outcomes(deliveries)) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d delivery attempts, got %v", n, outcomes(deliveries))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOutboundWebhookSender_SignsAndRetries(t *testing.T) {
+	var mu sync.Mutex
+	var requests []*http.Request
+	var bodies [][]byte
+	sender, webhooks, deliveries := setupOutboundSender(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		attempt := len(requests)
+		mu.Unlock()
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Not subscribed to status changes
+	sender.HandleEvent(events.DeliveryStatusChanged{ReminderID: "reminder-1"})
+	sender.HandleEvent(events.DeliveryFailed{ReminderID: "reminder-1", Error: "failed"})
+	waitForDeliveries(t, deliveries, 2)
+
+	if got := outcomes(deliveries); got[0] != models.OutboundDeliveryRetrying || got[1] != models.OutboundDeliverySucceeded {
+		t.Errorf("Expected a retry then success, got %v", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, r := range requests {
+		signed := utils.TimestampedWebhookPayload(r.Header.Get(OutboundHeaderTimestamp), r.Header.Get(OutboundHeaderNonce), bodies[i])
+		if !utils.ValidateWebhookSignature(signed, r.Header.Get(OutboundHeaderSignature), testOutboundSecret) {
+			t.Errorf("Expected attempt %d signed like inbound webhooks", i+1)
+		}
+	}
+	if requests[0].Header.Get(OutboundHeaderNonce) == requests[1].Header.Get(OutboundHeaderNonce) {
+		t.Error("Expected a new nonce for each attempt")
+	}
+	if requests[0].Header.Get(OutboundHeaderDelivery) != requests[1].Header.Get(OutboundHeaderDelivery) {
+		t.Error("Expected the delivery ID to be stable across retries")
+	}
+
+	var payload OutboundPayload
+	json.Unmarshal(bodies[0], &payload)
+	if payload.Type != events.TypeDeliveryFailed || payload.ID != requests[0].Header.Get(OutboundHeaderDelivery) {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+	if w, _ := webhooks.Get("hook-1"); w.ConsecutiveFailures != 0 || w.LastSuccessAt == "" {
+		t.Errorf("Expected a recorded success, got %+v", w)
+	}
+}
+
+func TestOutboundWebhookSender_DisablesAfterRepeatedFailures(t *testing.T) {
+	var calls atomic.Int32
+	sender, webhooks, deliveries := setupOutboundSender(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest) // Not retried
+	})
+
+	sender.HandleEvent(events.DeliveryFailed{ReminderID: "reminder-1"})
+	waitForDeliveries(t, deliveries, 1)
+	if w, _ := webhooks.Get("hook-1"); !w.Enabled || w.ConsecutiveFailures != 1 {
+		t.Fatalf("Expected one failure and still enabled, got %+v", w)
+	}
+
+	sender.HandleEvent(events.DeliveryFailed{ReminderID: "reminder-2"})
+	waitForDeliveries(t, deliveries, 2)
+	w, _ := webhooks.Get("hook-1")
+	if w.Enabled || w.DisabledReason == "" || w.DisabledAt == "" {
+		t.Errorf("Expected the webhook disabled after 2 failed deliveries, got %+v", w)
+	}
+	if got := outcomes(deliveries); got[0] != models.OutboundDeliveryFailed || got[1] != models.OutboundDeliveryFailed {
+		t.Errorf("Expected failed deliveries without retries, got %v", got)
+	}
+
+	// Disabled webhooks receive no events, but can still be test-fired
+	sender.HandleEvent(events.DeliveryFailed{ReminderID: "reminder-3"})
+	delivery, err := sender.Test("hook-1")
+	if err != nil || !delivery.Test || delivery.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a logged test delivery, got %+v, %v", delivery, err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %d", calls.Load())
+	}
+	if w, _ := webhooks.Get("hook-1"); w.ConsecutiveFailures != 2 {
+		t.Errorf("Expected test deliveries not to count as failures, got %d", w.ConsecutiveFailures)
+	}
+	if _, err := sender.Test("missing"); err != ErrOutboundWebhookNotFound {
+		t.Errorf("Expected ErrOutboundWebhookNotFound, got %v", err)
+	}
+}
+
+func TestOutboundWebhookSender_GivesUpAfterMaxAttempts(t *testing.T) {
+	sender, webhooks, deliveries := setupOutboundSender(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	sender.HandleEvent(events.DeliveryFailed{ReminderID: "reminder-1"})
+	waitForDeliveries(t, deliveries, 3)
+
+	got := outcomes(deliveries)
+	if got[0] != models.OutboundDeliveryRetrying || got[1] != models.OutboundDeliveryRetrying || got[2] != models.OutboundDeliveryFailed {
+		t.Errorf("Expected two retries then failure, got %v", got)
+	}
+	if w, _ := webhooks.Get("hook-1"); w.ConsecutiveFailures != 1 {
+		t.Errorf("Expected one failed delivery, got %d", w.ConsecutiveFailures)
+	}
+}
+
+func TestOutboundDeliveryStore_Prune(t *testing.T) {
+	var rewritten []*models.OutboundDelivery
+	deliveries := models.NewOutboundDeliveryStore(nil)
+	deliveries.RewriteFunc = func(kept []*models.OutboundDelivery) { rewritten = kept }
+
+	now := time.Now().UTC()
+	deliveries.Append(&models.OutboundDelivery{ID: "attempt-1", AttemptedAt: now.Add(-40 * 24 * time.Hour).Format(time.RFC3339)})
+	deliveries.Append(&models.OutboundDelivery{ID: "attempt-2", AttemptedAt: now.Format(time.RFC3339)})
+
+	if removed := deliveries.Prune(now.Add(-30 * 24 * time.Hour)); removed != 1 {
+		t.Fatalf("Expected 1 attempt pruned, got %d", removed)
+	}
+	if _, ok := deliveries.Get("attempt-1"); ok {
+		t.Error("Expected the old attempt removed")
+	}
+	if len(rewritten) != 1 || rewritten[0].ID != "attempt-2" {
+		t.Errorf("Expected the persisted log rewritten with the recent attempt, got %v", rewritten)
+	}
+}
@@ -1,32 +1,34 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
 	"github.com/davidyusaku-13/prima_v2/models"
 	"github.com/davidyusaku-13/prima_v2/utils"
 )
 
-// SSEHandler interface for broadcasting delivery status updates
-type SSEHandler interface {
-	BroadcastDeliveryStatusUpdate(reminderID, status, timestamp string)
-}
-
 // ReminderScheduler handles automatic sending of scheduled reminders
 type ReminderScheduler struct {
-	store         *models.PatientStore
-	gowaClient    *GOWAClient
-	config        *config.Config
-	logger        *slog.Logger
-	sseHandler    SSEHandler // SSE handler for broadcasting delivery status updates
-	articleStore  *models.ArticleStore
-	videoStore    *models.VideoStore
-	stopCh        chan struct{}
-	wg            sync.WaitGroup
-	interval      time.Duration
+	store          *models.PatientStore
+	gowaClient     *GOWAClient
+	config         *config.Config
+	logger         *slog.Logger
+	bus            *events.Bus // Delivery and escalation events, none published if nil
+	articleStore   *models.ArticleStore
+	videoStore     *models.VideoStore
+	templateStore  *models.TemplateStore  // Message templates, built-in layout if nil
+	shortLinkStore *models.ShortLinkStore // Tracked content links, raw links if nil
+	blackouts      *models.BlackoutStore  // Blackout calendar, none if nil
+	stopCh         chan struct{}
+	wg             sync.WaitGroup
+	interval       time.Duration
 }
 
 // NewReminderScheduler creates a new reminder scheduler
@@ -36,15 +38,15 @@ func NewReminderScheduler(store *models.PatientStore, gowaClient *GOWAClient, cf
 		gowaClient: gowaClient,
 		config:     cfg,
 		logger:     logger,
-		sseHandler: nil, // Will be set via SetSSEHandler
+		bus:        nil, // Will be set via SetEventBus
 		stopCh:     make(chan struct{}),
 		interval:   1 * time.Minute,
 	}
 }
 
-// SetSSEHandler sets the SSE handler for broadcasting delivery status updates
-func (s *ReminderScheduler) SetSSEHandler(sseHandler SSEHandler) {
-	s.sseHandler = sseHandler
+// SetEventBus sets the bus that delivery and escalation events are published on
+func (s *ReminderScheduler) SetEventBus(bus *events.Bus) {
+	s.bus = bus
 }
 
 // SetContentStores sets the article and video stores for attachment lookup
@@ -53,6 +55,28 @@ func (s *ReminderScheduler) SetContentStores(articleStore *models.ArticleStore,
 	s.videoStore = videoStore
 }
 
+// SetTemplateStore sets the message template store used to format reminders
+func (s *ReminderScheduler) SetTemplateStore(templateStore *models.TemplateStore) {
+	s.templateStore = templateStore
+}
+
+// SetShortLinkStore sets the store used to track content links in sent messages
+func (s *ReminderScheduler) SetShortLinkStore(shortLinkStore *models.ShortLinkStore) {
+	s.shortLinkStore = shortLinkStore
+}
+
+// SetBlackoutStore sets the blackout calendar that holds back non-exempt reminders
+func (s *ReminderScheduler) SetBlackoutStore(blackouts *models.BlackoutStore) {
+	s.blackouts = blackouts
+}
+
+// sendDeferral returns when a reminder may be sent at or after now. Like a manual send,
+// it is held back by quiet hours and blackouts in the patient's region unless its
+// priority bypasses them.
+func (s *ReminderScheduler) sendDeferral(patient *models.Patient, reminder *models.Reminder, now time.Time) utils.SendDeferral {
+	return utils.ReminderSendTime(now, s.config, s.blackouts.Active(), patient, reminder.Priority)
+}
+
 // Start begins the scheduler goroutine
 func (s *ReminderScheduler) Start() {
 	s.wg.Add(1)
@@ -78,29 +102,34 @@ func (s *ReminderScheduler) run() {
 
 	// Check immediately on start for any pending scheduled reminders
 	s.processScheduledReminders()
+	s.processEscalations()
 
 	for {
 		select {
 		case <-ticker.C:
 			s.processScheduledReminders()
+			s.processEscalations()
 		case <-s.stopCh:
 			return
 		}
 	}
 }
 
+// dueReminder is a reminder collected for sending by processScheduledReminders
+type dueReminder struct {
+	patientID string
+	patient   *models.Patient
+	reminder  *models.Reminder
+	rank      int // Priority send order, 0 first
+}
+
 // processScheduledReminders finds and sends all due scheduled reminders
 func (s *ReminderScheduler) processScheduledReminders() {
 	now := time.Now().UTC()
 
 	// Collect reminders to send (read lock)
 	s.store.RLock()
-	var toSend []struct {
-		patientID  string
-		patient    *models.Patient
-		reminderID string
-		reminder   *models.Reminder
-	}
+	var toSend []dueReminder
 
 	reminderCount := 0
 	for patientID, patient := range s.store.Patients {
@@ -126,16 +155,11 @@ func (s *ReminderScheduler) processScheduledReminders() {
 
 				// Check if it's time to send
 				if now.After(scheduledTime) || now.Equal(scheduledTime) {
-					toSend = append(toSend, struct {
-						patientID  string
-						patient    *models.Patient
-						reminderID string
-						reminder   *models.Reminder
-					}{
-						patientID:  patientID,
-						patient:    patient,
-						reminderID: reminder.ID,
-						reminder:   reminder,
+					toSend = append(toSend, dueReminder{
+						patientID: patientID,
+						patient:   patient,
+						reminder:  reminder,
+						rank:      s.config.Priorities.Rank(reminder.Priority),
 					})
 				}
 				continue
@@ -161,16 +185,11 @@ func (s *ReminderScheduler) processScheduledReminders() {
 
 				// Check if it's time to retry
 				if now.After(scheduledTime) || now.Equal(scheduledTime) {
-					toSend = append(toSend, struct {
-						patientID  string
-						patient    *models.Patient
-						reminderID string
-						reminder   *models.Reminder
-					}{
-						patientID:  patientID,
-						patient:    patient,
-						reminderID: reminder.ID,
-						reminder:   reminder,
+					toSend = append(toSend, dueReminder{
+						patientID: patientID,
+						patient:   patient,
+						reminder:  reminder,
+						rank:      s.config.Priorities.Rank(reminder.Priority),
 					})
 				}
 				continue
@@ -180,47 +199,27 @@ func (s *ReminderScheduler) processScheduledReminders() {
 			if (reminder.DeliveryStatus == "" || reminder.DeliveryStatus == models.DeliveryStatusPending) &&
 				!reminder.Completed && !reminder.Notified && reminder.DueDate != "" {
 
-				// Parse due date (support multiple formats for compatibility)
-				var dueTime time.Time
-				var err error
-
-				// Try RFC3339 first (new format with timezone)
-				dueTime, err = time.Parse(time.RFC3339, reminder.DueDate)
+				dueTime, err := ParseDueDate(reminder.DueDate)
 				if err != nil {
-					// Try local time format (old format from checkReminders - no timezone)
-					// IMPORTANT: Use ParseInLocation with time.Local to interpret as local time
-					dueTime, err = time.ParseInLocation("2006-01-02T15:04", reminder.DueDate, time.Local)
-					if err != nil {
-						dueTime, err = time.ParseInLocation("2006-01-02T15:04:05", reminder.DueDate, time.Local)
-						if err != nil {
-							if s.logger != nil {
-								s.logger.Error("Failed to parse due date",
-									"reminder_id", reminder.ID,
-									"due_date", reminder.DueDate,
-									"error", err.Error(),
-								)
-							}
-							continue
-						}
+					if s.logger != nil {
+						s.logger.Error("Failed to parse due date",
+							"reminder_id", reminder.ID,
+							"due_date", reminder.DueDate,
+							"error", err.Error(),
+						)
 					}
-					// Convert to UTC for comparison with now (which is UTC)
-					dueTime = dueTime.UTC()
+					continue
 				}
 
 				// Check if due date has passed
 				if now.After(dueTime) || now.Equal(dueTime) {
 					// Only send if within reasonable window (24 hours) to avoid sending very old reminders
 					if now.Before(dueTime.Add(24 * time.Hour)) {
-						toSend = append(toSend, struct {
-							patientID  string
-							patient    *models.Patient
-							reminderID string
-							reminder   *models.Reminder
-						}{
-							patientID:  patientID,
-							patient:    patient,
-							reminderID: reminder.ID,
-							reminder:   reminder,
+						toSend = append(toSend, dueReminder{
+							patientID: patientID,
+							patient:   patient,
+							reminder:  reminder,
+							rank:      s.config.Priorities.Rank(reminder.Priority),
 						})
 					} else {
 						if s.logger != nil {
@@ -237,6 +236,11 @@ func (s *ReminderScheduler) processScheduledReminders() {
 	}
 	s.store.RUnlock()
 
+	// Send higher priority reminders first
+	sort.SliceStable(toSend, func(i, j int) bool {
+		return toSend[i].rank < toSend[j].rank
+	})
+
 	// Send each reminder
 	for _, item := range toSend {
 		if item.reminder.DeliveryStatus == models.DeliveryStatusRetrying {
@@ -266,8 +270,10 @@ func (s *ReminderScheduler) sendScheduledReminder(patientID string, patient *mod
 			s.store.Unlock()
 			return
 		}
-		currentReminder.DeliveryStatus = models.DeliveryStatusFailed
+		s.transition(currentReminder, models.DeliveryStatusFailed, "invalid phone number")
 		currentReminder.DeliveryErrorMessage = "Nomor WhatsApp tidak valid"
+		currentReminder.DeliveryFailureCode = models.FailureCodeInvalidPhone
+		s.publishFailed(currentPatient, currentReminder)
 		s.store.Unlock()
 		s.store.SaveData()
 
@@ -300,11 +306,38 @@ func (s *ReminderScheduler) sendScheduledReminder(patientID string, patient *mod
 		s.store.Unlock()
 		return
 	}
-	currentReminder.DeliveryStatus = models.DeliveryStatusSending
+	// Hold back reminders during quiet hours or a blackout until they end
+	if deferral := s.sendDeferral(currentPatient, currentReminder, time.Now()); deferral.Deferred() {
+		reason := "quiet hours"
+		blackoutID := ""
+		if deferral.Blackout != nil {
+			reason = "held back by blackout " + deferral.Blackout.Name
+			blackoutID = deferral.Blackout.ID
+		}
+		s.transition(currentReminder, models.DeliveryStatusScheduled, reason)
+		currentReminder.ScheduledDeliveryAt = deferral.SendAt.UTC().Format(time.RFC3339)
+		s.store.Unlock()
+		s.store.SaveData()
+
+		if s.logger != nil {
+			s.logger.Info("Reminder held back for quiet hours or blackout",
+				"reminder_id", reminderID,
+				"patient_id", patientID,
+				"blackout_id", blackoutID,
+				"scheduled_at", currentReminder.ScheduledDeliveryAt,
+			)
+		}
+		return
+	}
+	s.transition(currentReminder, models.DeliveryStatusSending, "scheduled send")
+	idempotencyKey := ReminderIdempotencyKey(currentReminder)
+	previousAttempt := PreviousDeliveryAttempt(currentReminder, idempotencyKey)
 	// Capture current state for message formatting
 	patientName := currentPatient.Name
+	patientLanguage := currentPatient.Language
 	reminderTitle := currentReminder.Title
 	reminderDescription := currentReminder.Description
+	templateName := currentReminder.MessageTemplate
 	// Capture attachments for content lookup (copy to avoid holding lock)
 	var attachments []models.Attachment
 	if len(currentReminder.Attachments) > 0 {
@@ -314,22 +347,35 @@ func (s *ReminderScheduler) sendScheduledReminder(patientID string, patient *mod
 	s.store.Unlock()
 	s.store.SaveData()
 
-	// Build content attachments with excerpts/URLs from content stores
-	contentAttachments := utils.BuildContentAttachments(attachments, s.articleStore, s.videoStore)
+	// Format message with attachments, only once it is known to be sent
+	whatsappPhone := utils.FormatWhatsAppNumber(patient.Phone)
+	buildMessage := func() OutboundMessage {
+		// Build content attachments with excerpts/URLs from content stores
+		contentAttachments := utils.BuildContentAttachmentsWithBaseURL(attachments, s.articleStore, s.videoStore, s.config.Links.PublicBaseURL)
+		imagePath := ""
+		if s.config.GOWA.MediaMessages != nil && *s.config.GOWA.MediaMessages {
+			imagePath = utils.HeroImagePath(contentAttachments)
+		}
+		if s.config.Links.ShortLinksEnabled() {
+			contentAttachments = utils.TrackContentLinks(s.shortLinkStore, s.config.Links.ShortLinkBaseURL, patientID, reminderID, idempotencyKey, contentAttachments)
+		}
 
-	// Format message with attachments
-	disclaimerEnabled := s.config.Disclaimer.Enabled != nil && *s.config.Disclaimer.Enabled
-	message := utils.FormatReminderMessageWithExcerpts(utils.ReminderMessageParams{
-		PatientName:         patientName,
-		ReminderTitle:       reminderTitle,
-		ReminderDescription: reminderDescription,
-		DisclaimerText:      s.config.Disclaimer.Text,
-		DisclaimerEnabled:   disclaimerEnabled,
-	}, contentAttachments)
+		disclaimerEnabled := s.config.Disclaimer.Enabled != nil && *s.config.Disclaimer.Enabled
+		return OutboundMessage{
+			Phone:     whatsappPhone,
+			ImagePath: imagePath,
+			Text: utils.FormatReminderMessageFromTemplate(s.templateStore, templateName, patientLanguage, utils.ReminderMessageParams{
+				PatientName:         patientName,
+				ReminderTitle:       reminderTitle,
+				ReminderDescription: reminderDescription,
+				DisclaimerText:      s.config.Disclaimer.Text,
+				DisclaimerEnabled:   disclaimerEnabled,
+			}, contentAttachments),
+		}
+	}
 
-	// Send via GOWA (outside lock)
-	whatsappPhone := utils.FormatWhatsAppNumber(patient.Phone)
-	response, err := s.gowaClient.SendMessage(whatsappPhone, message)
+	// Send via GOWA, at most once per reminder occurrence (outside lock)
+	response, duplicate, err := s.gowaClient.SendIdempotent(idempotencyKey, buildMessage, previousAttempt)
 
 	// Update status based on result (with re-fetch)
 	s.store.Lock()
@@ -343,12 +389,14 @@ func (s *ReminderScheduler) sendScheduledReminder(patientID string, patient *mod
 		s.store.Unlock()
 		return
 	}
+	RecordDeliveryAttempt(currentReminder, idempotencyKey, response, duplicate, err)
 
 	if err != nil {
 		// Check if circuit breaker is open - requeue
-		if s.gowaClient.GetCircuitBreakerState() == "open" {
-			currentReminder.DeliveryStatus = models.DeliveryStatusQueued
+		if errors.Is(err, ErrCircuitOpen) || s.gowaClient.GetCircuitBreakerState() == CircuitStateOpen {
+			s.transition(currentReminder, models.DeliveryStatusQueued, "GOWA unavailable")
 			currentReminder.DeliveryErrorMessage = "GOWA sedang tidak tersedia. Akan dicoba lagi."
+			currentReminder.DeliveryFailureCode = models.FailureCodeGOWAUnavailable
 			currentReminder.RetryCount++
 
 			if s.logger != nil {
@@ -359,8 +407,10 @@ func (s *ReminderScheduler) sendScheduledReminder(patientID string, patient *mod
 				)
 			}
 		} else {
-			currentReminder.DeliveryStatus = models.DeliveryStatusFailed
+			s.transition(currentReminder, models.DeliveryStatusFailed, err.Error())
 			currentReminder.DeliveryErrorMessage = err.Error()
+			currentReminder.DeliveryFailureCode = FailureReasonCode(err)
+			s.publishFailed(currentPatient, currentReminder)
 
 			if s.logger != nil {
 				s.logger.Error("Scheduled reminder failed",
@@ -373,25 +423,94 @@ func (s *ReminderScheduler) sendScheduledReminder(patientID string, patient *mod
 		}
 	} else {
 		// Success
-		currentReminder.DeliveryStatus = models.DeliveryStatusSent
+		s.transition(currentReminder, models.DeliveryStatusSent, SentReason(duplicate))
 		currentReminder.GOWAMessageID = response.MessageID
 		sentAt := time.Now().UTC()
-		currentReminder.MessageSentAt = sentAt.Format(time.RFC3339)
+		if !duplicate || currentReminder.MessageSentAt == "" {
+			currentReminder.MessageSentAt = sentAt.Format(time.RFC3339)
+		}
 		currentReminder.DeliveryErrorMessage = ""
+		currentReminder.DeliveryFailureCode = ""
 		currentReminder.ScheduledDeliveryAt = "" // Clear scheduled time
 		currentReminder.Completed = true // Mark as completed when successfully sent
 
-		// Broadcast SSE event for real-time UI updates (before unlock)
-		if s.sseHandler != nil {
-			s.sseHandler.BroadcastDeliveryStatusUpdate(
-				reminderID,
-				string(models.DeliveryStatusSent),
-				sentAt.Format(time.RFC3339),
-			)
+		// Publish the status change for real-time UI updates (before unlock)
+		if s.bus != nil {
+			s.bus.Publish(events.DeliveryStatusChanged{
+				ReminderID:  reminderID,
+				PatientID:   patientID,
+				VolunteerID: currentPatient.CreatedBy,
+				Status:      models.DeliveryStatusSent,
+				At:          sentAt,
+			})
+		}
+	}
+	s.store.Unlock()
+	s.store.SaveData()
+}
+
+// processEscalations flags sent reminders with an escalation policy, or whose priority
+// escalates, that the patient has not read in time, and notifies the volunteer and admins once
+func (s *ReminderScheduler) processEscalations() {
+	now := time.Now().UTC()
+
+	var escalated []events.ReminderEscalated
+
+	s.store.Lock()
+	for patientID, patient := range s.store.Patients {
+		for _, reminder := range patient.Reminders {
+			// The reminder's own policy wins over its priority's escalation timing
+			afterHours := s.config.Priorities.Level(reminder.Priority).EscalationAfterHours
+			if reminder.Escalation != nil {
+				afterHours = reminder.Escalation.AfterHours
+			}
+			if afterHours <= 0 || reminder.EscalatedAt != "" || reminder.MessageSentAt == "" {
+				continue
+			}
+			// A revoked message can no longer be read by the patient
+			if reminder.MessageRevokedAt != "" {
+				continue
+			}
+			if reminder.DeliveryStatus != models.DeliveryStatusSent && reminder.DeliveryStatus != models.DeliveryStatusDelivered {
+				continue
+			}
+			sentAt, err := time.Parse(time.RFC3339, reminder.MessageSentAt)
+			if err != nil {
+				continue
+			}
+			if now.Before(sentAt.Add(time.Duration(afterHours) * time.Hour)) {
+				continue
+			}
+			reminder.EscalatedAt = now.Format(time.RFC3339)
+			escalated = append(escalated, events.ReminderEscalated{
+				ReminderID:  reminder.ID,
+				PatientID:   patientID,
+				VolunteerID: patient.CreatedBy,
+				PatientName: patient.Name,
+				AfterHours:  afterHours,
+				At:          now,
+			})
 		}
 	}
 	s.store.Unlock()
+
+	if len(escalated) == 0 {
+		return
+	}
 	s.store.SaveData()
+
+	for _, e := range escalated {
+		if s.logger != nil {
+			s.logger.Warn("Reminder escalated - not read in time",
+				"reminder_id", e.ReminderID,
+				"patient_id", e.PatientID,
+				"after_hours", e.AfterHours,
+			)
+		}
+		if s.bus != nil {
+			s.bus.Publish(e)
+		}
+	}
 }
 
 // findReminderByID finds a reminder by ID in a patient's reminders
@@ -404,6 +523,41 @@ func findReminderByID(patient *models.Patient, reminderID string) *models.Remind
 	return nil
 }
 
+// transition moves a reminder to a new delivery status on behalf of the scheduler,
+// logging moves the state machine rejects. The caller must hold the store write lock.
+func (s *ReminderScheduler) transition(reminder *models.Reminder, to, reason string) {
+	if err := reminder.TransitionTo(to, models.StatusActorScheduler, "", reason, time.Now()); err != nil && s.logger != nil {
+		s.logger.Warn("Delivery status transition rejected",
+			"reminder_id", reminder.ID,
+			"error", err.Error(),
+		)
+	}
+}
+
+// publishFailed publishes a failed delivery. The caller must hold the store write lock.
+func (s *ReminderScheduler) publishFailed(patient *models.Patient, reminder *models.Reminder) {
+	if s.bus == nil {
+		return
+	}
+	s.bus.Publish(events.DeliveryFailed{
+		ReminderID:  reminder.ID,
+		PatientID:   patient.ID,
+		VolunteerID: patient.CreatedBy,
+		PatientName: patient.Name,
+		Error:       reminder.DeliveryErrorMessage,
+		FailureCode: reminder.DeliveryFailureCode,
+		At:          time.Now().UTC(),
+	})
+}
+
+// SentReason is the status timeline reason of a successful send
+func SentReason(duplicate bool) string {
+	if duplicate {
+		return "already sent, resend suppressed"
+	}
+	return "sent via GOWA"
+}
+
 // processRetryReminder handles retrying a failed reminder
 func (s *ReminderScheduler) processRetryReminder(patientID string, patient *models.Patient, reminder *models.Reminder) {
 	reminderID := reminder.ID
@@ -422,8 +576,10 @@ func (s *ReminderScheduler) processRetryReminder(patientID string, patient *mode
 			s.store.Unlock()
 			return
 		}
-		currentReminder.DeliveryStatus = models.DeliveryStatusFailed
+		s.transition(currentReminder, models.DeliveryStatusFailed, "invalid phone number")
 		currentReminder.DeliveryErrorMessage = "Nomor WhatsApp tidak valid"
+		currentReminder.DeliveryFailureCode = models.FailureCodeInvalidPhone
+		s.publishFailed(currentPatient, currentReminder)
 		s.store.Unlock()
 		s.store.SaveData()
 
@@ -449,10 +605,34 @@ func (s *ReminderScheduler) processRetryReminder(patientID string, patient *mode
 		s.store.Unlock()
 		return
 	}
-	currentReminder.DeliveryStatus = models.DeliveryStatusSending
+	// Retry after quiet hours or the blackout instead of during them
+	if deferral := s.sendDeferral(currentPatient, currentReminder, time.Now()); deferral.Deferred() {
+		currentReminder.ScheduledDeliveryAt = deferral.SendAt.UTC().Format(time.RFC3339)
+		s.store.Unlock()
+		s.store.SaveData()
+
+		if s.logger != nil {
+			blackoutID := ""
+			if deferral.Blackout != nil {
+				blackoutID = deferral.Blackout.ID
+			}
+			s.logger.Info("Retry held back for quiet hours or blackout",
+				"reminder_id", reminderID,
+				"patient_id", patientID,
+				"blackout_id", blackoutID,
+				"scheduled_at", currentReminder.ScheduledDeliveryAt,
+			)
+		}
+		return
+	}
+	s.transition(currentReminder, models.DeliveryStatusSending, fmt.Sprintf("retry %d", currentReminder.RetryCount))
+	idempotencyKey := ReminderIdempotencyKey(currentReminder)
+	previousAttempt := PreviousDeliveryAttempt(currentReminder, idempotencyKey)
 	patientName := currentPatient.Name
+	patientLanguage := currentPatient.Language
 	reminderTitle := currentReminder.Title
 	reminderDescription := currentReminder.Description
+	templateName := currentReminder.MessageTemplate
 	// Capture attachments for content lookup (copy to avoid holding lock)
 	var attachments []models.Attachment
 	if len(currentReminder.Attachments) > 0 {
@@ -462,22 +642,35 @@ func (s *ReminderScheduler) processRetryReminder(patientID string, patient *mode
 	s.store.Unlock()
 	s.store.SaveData()
 
-	// Build content attachments with excerpts/URLs from content stores
-	contentAttachments := utils.BuildContentAttachments(attachments, s.articleStore, s.videoStore)
+	// Format message with attachments, only once it is known to be sent
+	whatsappPhone := utils.FormatWhatsAppNumber(patient.Phone)
+	buildMessage := func() OutboundMessage {
+		// Build content attachments with excerpts/URLs from content stores
+		contentAttachments := utils.BuildContentAttachmentsWithBaseURL(attachments, s.articleStore, s.videoStore, s.config.Links.PublicBaseURL)
+		imagePath := ""
+		if s.config.GOWA.MediaMessages != nil && *s.config.GOWA.MediaMessages {
+			imagePath = utils.HeroImagePath(contentAttachments)
+		}
+		if s.config.Links.ShortLinksEnabled() {
+			contentAttachments = utils.TrackContentLinks(s.shortLinkStore, s.config.Links.ShortLinkBaseURL, patientID, reminderID, idempotencyKey, contentAttachments)
+		}
 
-	// Format message with attachments
-	disclaimerEnabled := s.config.Disclaimer.Enabled != nil && *s.config.Disclaimer.Enabled
-	message := utils.FormatReminderMessageWithExcerpts(utils.ReminderMessageParams{
-		PatientName:         patientName,
-		ReminderTitle:       reminderTitle,
-		ReminderDescription: reminderDescription,
-		DisclaimerText:      s.config.Disclaimer.Text,
-		DisclaimerEnabled:   disclaimerEnabled,
-	}, contentAttachments)
+		disclaimerEnabled := s.config.Disclaimer.Enabled != nil && *s.config.Disclaimer.Enabled
+		return OutboundMessage{
+			Phone:     whatsappPhone,
+			ImagePath: imagePath,
+			Text: utils.FormatReminderMessageFromTemplate(s.templateStore, templateName, patientLanguage, utils.ReminderMessageParams{
+				PatientName:         patientName,
+				ReminderTitle:       reminderTitle,
+				ReminderDescription: reminderDescription,
+				DisclaimerText:      s.config.Disclaimer.Text,
+				DisclaimerEnabled:   disclaimerEnabled,
+			}, contentAttachments),
+		}
+	}
 
-	// Send via GOWA
-	whatsappPhone := utils.FormatWhatsAppNumber(patient.Phone)
-	response, err := s.gowaClient.SendMessage(whatsappPhone, message)
+	// Send via GOWA, at most once per reminder occurrence
+	response, duplicate, err := s.gowaClient.SendIdempotent(idempotencyKey, buildMessage, previousAttempt)
 
 	// Update status based on result
 	s.store.Lock()
@@ -491,19 +684,23 @@ func (s *ReminderScheduler) processRetryReminder(patientID string, patient *mode
 		s.store.Unlock()
 		return
 	}
+	RecordDeliveryAttempt(currentReminder, idempotencyKey, response, duplicate, err)
 
 	if err != nil {
 		// Check if error is retryable
-		if ShouldRetry(err) && currentReminder.RetryCount < s.config.Retry.MaxAttempts {
+		if ShouldRetry(err) && currentReminder.RetryCount < s.config.MaxAttemptsFor(currentReminder.Priority) {
 			// Schedule next retry
 			retryDelay := GetRetryDelay(currentReminder.RetryCount, s.config.Retry.Delays)
 			nextRetryTime := time.Now().UTC().Add(retryDelay)
-			currentReminder.DeliveryStatus = models.DeliveryStatusRetrying
+			s.transition(currentReminder, models.DeliveryStatusRetrying, err.Error())
 			currentReminder.ScheduledDeliveryAt = nextRetryTime.Format(time.RFC3339)
+			currentReminder.RetryCount++
 		} else {
 			// Max retries exceeded or non-retryable error
-			currentReminder.DeliveryStatus = models.DeliveryStatusFailed
+			s.transition(currentReminder, models.DeliveryStatusFailed, err.Error())
 			currentReminder.DeliveryErrorMessage = err.Error()
+			currentReminder.DeliveryFailureCode = FailureReasonCode(err)
+			s.publishFailed(currentPatient, currentReminder)
 
 			if s.logger != nil {
 				s.logger.Error("Retry reminder failed - max retries exceeded",
@@ -516,28 +713,54 @@ func (s *ReminderScheduler) processRetryReminder(patientID string, patient *mode
 		}
 	} else {
 		// Success
-		currentReminder.DeliveryStatus = models.DeliveryStatusSent
+		s.transition(currentReminder, models.DeliveryStatusSent, SentReason(duplicate))
 		currentReminder.GOWAMessageID = response.MessageID
 		sentAt := time.Now().UTC()
-		currentReminder.MessageSentAt = sentAt.Format(time.RFC3339)
+		if !duplicate || currentReminder.MessageSentAt == "" {
+			currentReminder.MessageSentAt = sentAt.Format(time.RFC3339)
+		}
 		currentReminder.DeliveryErrorMessage = ""
+		currentReminder.DeliveryFailureCode = ""
 		currentReminder.ScheduledDeliveryAt = ""
 		currentReminder.RetryCount = 0
 		currentReminder.Completed = true // Mark as completed when successfully sent
 
-		// Broadcast SSE event for real-time UI updates (before unlock)
-		if s.sseHandler != nil {
-			s.sseHandler.BroadcastDeliveryStatusUpdate(
-				reminderID,
-				string(models.DeliveryStatusSent),
-				sentAt.Format(time.RFC3339),
-			)
+		// Publish the status change for real-time UI updates (before unlock)
+		if s.bus != nil {
+			s.bus.Publish(events.DeliveryStatusChanged{
+				ReminderID:  reminderID,
+				PatientID:   patientID,
+				VolunteerID: currentPatient.CreatedBy,
+				Status:      models.DeliveryStatusSent,
+				At:          sentAt,
+			})
 		}
 	}
 	s.store.Unlock()
 	s.store.SaveData()
 }
 
+// ParseDueDate parses a reminder due date, supporting multiple formats for compatibility:
+// RFC3339, or local time without a timezone as entered in the reminder form
+func ParseDueDate(dueDate string) (time.Time, error) {
+	// Try RFC3339 first (new format with timezone)
+	dueTime, err := time.Parse(time.RFC3339, dueDate)
+	if err == nil {
+		return dueTime, nil
+	}
+	// Try local time format (old format from checkReminders - no timezone)
+	// IMPORTANT: Use ParseInLocation with time.Local to interpret as local time
+	dueTime, err = time.ParseInLocation(models.DueDateLayout, dueDate, time.Local)
+	if err != nil {
+		dueTime, err = time.ParseInLocation("2006-01-02T15:04:05", dueDate, time.Local)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	// Convert to UTC for comparison with now (which is UTC)
+	return dueTime.UTC(), nil
+}
+
 // SetInterval allows changing the check interval (useful for testing)
 func (s *ReminderScheduler) SetInterval(interval time.Duration) {
 	s.interval = interval
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/events"
 	"github.com/davidyusaku-13/prima_v2/models"
 	"github.com/davidyusaku-13/prima_v2/utils"
 )
@@ -37,6 +38,7 @@ func TestReminderScheduler_ProcessScheduledReminders(t *testing.T) {
 				Text:    "Test disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		gowaClient := NewGOWAClient(GOWAConfig{
@@ -79,6 +81,10 @@ func TestReminderScheduler_ProcessScheduledReminders(t *testing.T) {
 		if reminder.GOWAMessageID != "msg-scheduled-123" {
 			t.Errorf("Expected GOWA message ID 'msg-scheduled-123', got '%s'", reminder.GOWAMessageID)
 		}
+		if timeline := reminder.StatusTimeline; len(timeline) != 2 || timeline[0].From != models.DeliveryStatusScheduled ||
+			timeline[1].To != models.DeliveryStatusSent || timeline[1].Actor != models.StatusActorScheduler {
+			t.Errorf("Expected scheduled -> sending -> sent by the scheduler, got %+v", timeline)
+		}
 		if reminder.MessageSentAt == "" {
 			t.Error("Expected MessageSentAt to be set")
 		}
@@ -97,6 +103,7 @@ func TestReminderScheduler_ProcessScheduledReminders(t *testing.T) {
 				Text:    "Test disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		scheduler := NewReminderScheduler(store, nil, cfg, logger)
@@ -152,6 +159,7 @@ func TestReminderScheduler_ProcessScheduledReminders(t *testing.T) {
 				Text:    "Test disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		scheduler := NewReminderScheduler(store, nil, cfg, logger)
@@ -193,6 +201,7 @@ func TestReminderScheduler_ProcessScheduledReminders(t *testing.T) {
 				Text:    "Test disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		scheduler := NewReminderScheduler(store, nil, cfg, logger)
@@ -239,6 +248,7 @@ func TestReminderScheduler_StartStop(t *testing.T) {
 				Text:    "Test disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		scheduler := NewReminderScheduler(store, nil, cfg, logger)
@@ -301,6 +311,7 @@ func TestReminderScheduler_ProcessScheduledReminders_WithAttachments(t *testing.
 				Text:    "Test health disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		gowaClient := NewGOWAClient(GOWAConfig{
@@ -443,6 +454,7 @@ func TestReminderScheduler_ProcessScheduledReminders_WithAttachments(t *testing.
 				Text:    "Test disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		gowaClient := NewGOWAClient(GOWAConfig{
@@ -533,6 +545,7 @@ func TestReminderScheduler_ProcessScheduledReminders_WithAttachments(t *testing.
 				Text:    "Test disclaimer",
 				Enabled: &enabled,
 			},
+			QuietHours: noQuietHours(),
 		}
 
 		gowaClient := NewGOWAClient(GOWAConfig{
@@ -666,3 +679,322 @@ func TestBuildContentAttachments(t *testing.T) {
 		}
 	})
 }
+
+// escalationRecorder records escalation events published by a scheduler
+type escalationRecorder struct {
+	bus        *events.Bus
+	mu         sync.Mutex
+	escalated  []string
+	volunteers []string
+}
+
+// newEscalationRecorder subscribes a recorder to a new bus used by the scheduler
+func newEscalationRecorder(t *testing.T, scheduler *ReminderScheduler) *escalationRecorder {
+	r := &escalationRecorder{bus: events.NewBus(nil)}
+	r.bus.Subscribe("test", r.handle, events.TypeReminderEscalated)
+	t.Cleanup(r.bus.Close)
+	scheduler.SetEventBus(r.bus)
+	return r
+}
+
+func (r *escalationRecorder) handle(event events.Event) error {
+	e := event.(events.ReminderEscalated)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.escalated = append(r.escalated, e.ReminderID)
+	r.volunteers = append(r.volunteers, e.VolunteerID)
+	return nil
+}
+
+func TestReminderScheduler_ProcessEscalations(t *testing.T) {
+	store := models.NewPatientStore(func() {})
+	scheduler := NewReminderScheduler(store, nil, &config.Config{QuietHours: noQuietHours()}, nil)
+	recorder := newEscalationRecorder(t, scheduler)
+
+	longAgo := time.Now().UTC().Add(-5 * time.Hour).Format(time.RFC3339)
+	recent := time.Now().UTC().Add(-30 * time.Minute).Format(time.RFC3339)
+	store.Patients["patient-1"] = &models.Patient{
+		ID:        "patient-1",
+		Name:      "Budi",
+		CreatedBy: "volunteer-1",
+		Reminders: []*models.Reminder{
+			{ID: "overdue", DeliveryStatus: models.DeliveryStatusDelivered, MessageSentAt: longAgo, Escalation: &models.EscalationPolicy{AfterHours: 4}},
+			{ID: "not-yet", DeliveryStatus: models.DeliveryStatusSent, MessageSentAt: recent, Escalation: &models.EscalationPolicy{AfterHours: 4}},
+			{ID: "read", DeliveryStatus: models.DeliveryStatusRead, MessageSentAt: longAgo, Escalation: &models.EscalationPolicy{AfterHours: 4}},
+			{ID: "no-policy", DeliveryStatus: models.DeliveryStatusSent, MessageSentAt: longAgo},
+		},
+	}
+
+	scheduler.processEscalations()
+	scheduler.processEscalations()
+	recorder.bus.Flush()
+
+	if len(recorder.escalated) != 1 || recorder.escalated[0] != "overdue" {
+		t.Fatalf("Expected only the overdue reminder escalated once, got %v", recorder.escalated)
+	}
+	if recorder.volunteers[0] != "volunteer-1" {
+		t.Errorf("Expected volunteer-1 notified, got %s", recorder.volunteers[0])
+	}
+	for _, r := range store.Patients["patient-1"].Reminders {
+		if (r.EscalatedAt != "") != (r.ID == "overdue") {
+			t.Errorf("Reminder %s: unexpected EscalatedAt %q", r.ID, r.EscalatedAt)
+		}
+	}
+}
+
+func TestReminderScheduler_Blackouts(t *testing.T) {
+	var sends int
+	var mu sync.Mutex
+	gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		sends++
+		mu.Unlock()
+		json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "msg-1"})
+	}))
+	defer gowaServer.Close()
+
+	store := models.NewPatientStore(func() {})
+	gowaClient := NewGOWAClient(GOWAConfig{
+		Endpoint:         gowaServer.URL,
+		Timeout:          10 * time.Second,
+		FailureThreshold: 5,
+		CooldownDuration: 5 * time.Minute,
+	}, nil)
+	scheduler := NewReminderScheduler(store, gowaClient, &config.Config{QuietHours: noQuietHours()}, nil)
+
+	// A whole-day blackout covering today in the patient's region, exempting high priority
+	today := time.Now().In(utils.WITLocation).Format(models.BlackoutDateLayout)
+	blackouts := models.NewBlackoutStore(func() {})
+	blackouts.Blackouts["holiday"] = &models.Blackout{
+		ID: "holiday", Name: "Libur", Type: models.BlackoutTypeDates, StartDate: today, EndDate: today,
+		ExemptPriorities: []string{"high"}, Enabled: true,
+	}
+	scheduler.SetBlackoutStore(blackouts)
+
+	due := time.Now().Add(-time.Minute).Format(models.DueDateLayout)
+	store.Patients["patient-1"] = &models.Patient{
+		ID:       "patient-1",
+		Phone:    "08123456789",
+		Timezone: "WIT",
+		Reminders: []*models.Reminder{
+			{ID: "routine", DueDate: due, Priority: "low", DeliveryStatus: models.DeliveryStatusPending},
+			{ID: "urgent", DueDate: due, Priority: "high", DeliveryStatus: models.DeliveryStatusPending},
+		},
+	}
+
+	scheduler.processScheduledReminders()
+
+	routine := store.Patients["patient-1"].Reminders[0]
+	if routine.DeliveryStatus != models.DeliveryStatusScheduled {
+		t.Fatalf("Expected routine reminder held back, got status %s", routine.DeliveryStatus)
+	}
+	scheduledAt, err := time.Parse(time.RFC3339, routine.ScheduledDeliveryAt)
+	tomorrow := time.Now().In(utils.WITLocation).AddDate(0, 0, 1)
+	if err != nil || scheduledAt.In(utils.WITLocation).Day() != tomorrow.Day() {
+		t.Errorf("Expected routine reminder scheduled tomorrow (WIT), got %q", routine.ScheduledDeliveryAt)
+	}
+	if urgent := store.Patients["patient-1"].Reminders[1]; urgent.DeliveryStatus != models.DeliveryStatusSent {
+		t.Errorf("Expected exempt reminder sent, got status %s", urgent.DeliveryStatus)
+	}
+
+	// Disabling the blackout releases the held reminder at its scheduled time
+	blackouts.Blackouts["holiday"].Enabled = false
+	routine.ScheduledDeliveryAt = time.Now().UTC().Add(-time.Minute).Format(time.RFC3339)
+	scheduler.processScheduledReminders()
+	if routine.DeliveryStatus != models.DeliveryStatusSent {
+		t.Errorf("Expected held reminder sent after the blackout, got status %s", routine.DeliveryStatus)
+	}
+	if sends != 2 {
+		t.Errorf("Expected 2 sends, got %d", sends)
+	}
+}
+
+// noQuietHours disables quiet hours (start == end), so sends do not depend on the time of day
+func noQuietHours() config.QuietHoursConfig {
+	hour := 0
+	return config.QuietHoursConfig{StartHour: &hour, EndHour: &hour, Timezone: "WIB"}
+}
+
+// currentQuietHours returns quiet hours covering the current hour
+func currentQuietHours() config.QuietHoursConfig {
+	start := time.Now().In(utils.WIBLocation).Hour()
+	end := (start + 1) % 24
+	return config.QuietHoursConfig{StartHour: &start, EndHour: &end, Timezone: "WIB"}
+}
+
+// testPriorities mirrors the default priority levels
+func testPriorities() config.PrioritiesConfig {
+	return config.PrioritiesConfig{Default: "medium", Levels: []config.PriorityLevel{
+		{Name: "high", MaxAttempts: 8, EscalationAfterHours: 4},
+		{Name: "medium"},
+		{Name: "low"},
+	}}
+}
+
+func TestReminderScheduler_Priorities(t *testing.T) {
+	t.Run("sends higher priority reminders first", func(t *testing.T) {
+		var sent []string
+		var mu sync.Mutex
+		gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req SendMessageRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			mu.Lock()
+			sent = append(sent, req.Message)
+			mu.Unlock()
+			json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "msg-1"})
+		}))
+		defer gowaServer.Close()
+
+		store := models.NewPatientStore(func() {})
+		gowaClient := NewGOWAClient(GOWAConfig{
+			Endpoint:         gowaServer.URL,
+			Timeout:          10 * time.Second,
+			FailureThreshold: 5,
+			CooldownDuration: 5 * time.Minute,
+		}, nil)
+		scheduler := NewReminderScheduler(store, gowaClient, &config.Config{QuietHours: noQuietHours(), Priorities: testPriorities()}, nil)
+
+		due := time.Now().Add(-time.Minute).Format(models.DueDateLayout)
+		store.Patients["patient-1"] = &models.Patient{
+			ID:    "patient-1",
+			Phone: "08123456789",
+			Reminders: []*models.Reminder{
+				{ID: "r-low", Title: "Senam pagi", DueDate: due, Priority: "low", DeliveryStatus: models.DeliveryStatusPending},
+				{ID: "r-medium", Title: "Kontrol gula darah", DueDate: due, Priority: "medium", DeliveryStatus: models.DeliveryStatusPending},
+				{ID: "r-high", Title: "Minum insulin", DueDate: due, Priority: "high", DeliveryStatus: models.DeliveryStatusPending},
+			},
+		}
+
+		scheduler.processScheduledReminders()
+
+		want := []string{"Minum insulin", "Kontrol gula darah", "Senam pagi"}
+		if len(sent) != len(want) {
+			t.Fatalf("Expected %d sends, got %d", len(want), len(sent))
+		}
+		for i, title := range want {
+			if !strings.Contains(sent[i], title) {
+				t.Errorf("Send %d: expected %q, got %q", i, title, sent[i])
+			}
+		}
+	})
+
+	t.Run("retries up to the priority's max attempts", func(t *testing.T) {
+		gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer gowaServer.Close()
+
+		store := models.NewPatientStore(func() {})
+		gowaClient := NewGOWAClient(GOWAConfig{
+			Endpoint:         gowaServer.URL,
+			Timeout:          10 * time.Second,
+			FailureThreshold: 100,
+			CooldownDuration: 5 * time.Minute,
+		}, nil)
+		cfg := &config.Config{QuietHours: noQuietHours(), Priorities: testPriorities()}
+		cfg.Retry.MaxAttempts = 5
+		cfg.Retry.Delays = []time.Duration{time.Minute, 5 * time.Minute}
+		scheduler := NewReminderScheduler(store, gowaClient, cfg, nil)
+
+		patient := &models.Patient{
+			ID:    "patient-1",
+			Phone: "08123456789",
+			Reminders: []*models.Reminder{
+				{ID: "r-high", Title: "Minum insulin", Priority: "high", DeliveryStatus: models.DeliveryStatusRetrying},
+				{ID: "r-low", Title: "Senam pagi", Priority: "low", DeliveryStatus: models.DeliveryStatusRetrying},
+			},
+		}
+		store.Patients["patient-1"] = patient
+
+		// Run retry cycles until both reminders give up, counting sends per reminder
+		sends := make(map[string]int)
+		for cycle := 0; cycle < 20; cycle++ {
+			for _, reminder := range patient.Reminders {
+				if reminder.DeliveryStatus != models.DeliveryStatusRetrying {
+					continue
+				}
+				before := time.Now().UTC()
+				scheduler.processRetryReminder("patient-1", patient, reminder)
+				sends[reminder.ID]++
+
+				// Backs off once the first delay step is used up
+				if reminder.DeliveryStatus == models.DeliveryStatusRetrying && reminder.RetryCount > 2 {
+					next, _ := time.Parse(time.RFC3339, reminder.ScheduledDeliveryAt)
+					if next.Sub(before) < 4*time.Minute {
+						t.Errorf("%s: expected retry %d delayed by the second backoff step, got %v", reminder.ID, reminder.RetryCount, next.Sub(before))
+					}
+				}
+			}
+		}
+
+		if status := patient.Reminders[0].DeliveryStatus; status != models.DeliveryStatusFailed || sends["r-high"] != 9 {
+			t.Errorf("Expected high priority reminder failed after 9 sends (8 retries), got %s after %d", status, sends["r-high"])
+		}
+		if status := patient.Reminders[1].DeliveryStatus; status != models.DeliveryStatusFailed || sends["r-low"] != 6 {
+			t.Errorf("Expected low priority reminder failed after 6 sends (5 retries), got %s after %d", status, sends["r-low"])
+		}
+	})
+
+	t.Run("bypasses quiet hours when configured", func(t *testing.T) {
+		gowaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(SendMessageResponse{Success: true, MessageID: "msg-1"})
+		}))
+		defer gowaServer.Close()
+
+		store := models.NewPatientStore(func() {})
+		gowaClient := NewGOWAClient(GOWAConfig{
+			Endpoint:         gowaServer.URL,
+			Timeout:          10 * time.Second,
+			FailureThreshold: 5,
+			CooldownDuration: 5 * time.Minute,
+		}, nil)
+		cfg := &config.Config{QuietHours: currentQuietHours(), Priorities: testPriorities()}
+		cfg.Priorities.Levels[0].BypassQuietHours = true
+		scheduler := NewReminderScheduler(store, gowaClient, cfg, nil)
+
+		due := time.Now().Add(-time.Minute).Format(models.DueDateLayout)
+		store.Patients["patient-1"] = &models.Patient{
+			ID:    "patient-1",
+			Phone: "08123456789",
+			Reminders: []*models.Reminder{
+				{ID: "r-high", Title: "Minum insulin", DueDate: due, Priority: "high", DeliveryStatus: models.DeliveryStatusPending},
+				{ID: "r-low", Title: "Senam pagi", DueDate: due, Priority: "low", DeliveryStatus: models.DeliveryStatusPending},
+			},
+		}
+
+		scheduler.processScheduledReminders()
+
+		if status := store.Patients["patient-1"].Reminders[0].DeliveryStatus; status != models.DeliveryStatusSent {
+			t.Errorf("Expected high priority reminder sent during quiet hours, got %s", status)
+		}
+		low := store.Patients["patient-1"].Reminders[1]
+		if low.DeliveryStatus != models.DeliveryStatusScheduled || low.ScheduledDeliveryAt == "" {
+			t.Errorf("Expected low priority reminder scheduled after quiet hours, got %s at %q", low.DeliveryStatus, low.ScheduledDeliveryAt)
+		}
+	})
+
+	t.Run("escalates by priority without a reminder policy", func(t *testing.T) {
+		store := models.NewPatientStore(func() {})
+		scheduler := NewReminderScheduler(store, nil, &config.Config{QuietHours: noQuietHours(), Priorities: testPriorities()}, nil)
+		recorder := newEscalationRecorder(t, scheduler)
+
+		longAgo := time.Now().UTC().Add(-5 * time.Hour).Format(time.RFC3339)
+		store.Patients["patient-1"] = &models.Patient{
+			ID:        "patient-1",
+			Name:      "Budi",
+			CreatedBy: "volunteer-1",
+			Reminders: []*models.Reminder{
+				{ID: "urgent", Priority: "high", DeliveryStatus: models.DeliveryStatusSent, MessageSentAt: longAgo},
+				{ID: "routine", Priority: "low", DeliveryStatus: models.DeliveryStatusSent, MessageSentAt: longAgo},
+				{ID: "own-policy", Priority: "high", DeliveryStatus: models.DeliveryStatusSent, MessageSentAt: longAgo, Escalation: &models.EscalationPolicy{AfterHours: 12}},
+			},
+		}
+
+		scheduler.processEscalations()
+		recorder.bus.Flush()
+
+		if len(recorder.escalated) != 1 || recorder.escalated[0] != "urgent" {
+			t.Errorf("Expected only the high priority reminder escalated, got %v", recorder.escalated)
+		}
+	})
+}
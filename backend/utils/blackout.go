@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// maxSendDeferrals bounds how many back-to-back quiet hours and blackout periods
+// NextSendTime steps over before giving up
+const maxSendDeferrals = 64
+
+// SendDeferral describes when a reminder may be sent and what held it back
+type SendDeferral struct {
+	SendAt     time.Time
+	QuietHours bool             // Held back by quiet hours
+	Blackout   *models.Blackout // Last blackout that held it back, nil if none
+}
+
+// Deferred reports whether the reminder cannot be sent at the requested time
+func (d SendDeferral) Deferred() bool {
+	return d.QuietHours || d.Blackout != nil
+}
+
+// PatientRegion returns the patient's timezone region, or the quiet hours timezone if unset
+func PatientRegion(patient *models.Patient, cfg *config.QuietHoursConfig) string {
+	if patient != nil && patient.Timezone != "" {
+		return patient.Timezone
+	}
+	return cfg.Timezone
+}
+
+// parseClock parses an HH:MM time into minutes since midnight
+func parseClock(clock string) (int, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// onWeekday reports whether a recurring blackout applies on the given day
+func onWeekday(b *models.Blackout, day time.Weekday) bool {
+	if len(b.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range b.Weekdays {
+		if time.Weekday(d) == day {
+			return true
+		}
+	}
+	return false
+}
+
+// BlackoutEnd reports whether the blackout is in effect at t for a patient in the region,
+// and if so when it ends. Exemptions are not considered.
+func BlackoutEnd(t time.Time, b *models.Blackout, region string) (time.Time, bool) {
+	if b.Region != "" && b.Region != region {
+		return time.Time{}, false
+	}
+	loc := GetTimezoneLocation(region)
+	local := t.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	switch b.Type {
+	case models.BlackoutTypeDates:
+		day := local.Format(models.BlackoutDateLayout)
+		if day < b.StartDate || day > b.EndDate {
+			return time.Time{}, false
+		}
+		last, err := time.ParseInLocation(models.BlackoutDateLayout, b.EndDate, loc)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return last.AddDate(0, 0, 1), true
+
+	case models.BlackoutTypeRecurring:
+		start, okStart := parseClock(b.StartTime)
+		end, okEnd := parseClock(b.EndTime)
+		if !okStart || !okEnd || start == end {
+			return time.Time{}, false
+		}
+		minute := local.Hour()*60 + local.Minute()
+		endToday := midnight.Add(time.Duration(end) * time.Minute)
+		if start < end {
+			if minute >= start && minute < end && onWeekday(b, local.Weekday()) {
+				return endToday, true
+			}
+			return time.Time{}, false
+		}
+		// Spans midnight: the weekday is the day the period starts
+		if minute >= start && onWeekday(b, local.Weekday()) {
+			return endToday.AddDate(0, 0, 1), true
+		}
+		if minute < end && onWeekday(b, midnight.AddDate(0, 0, -1).Weekday()) {
+			return endToday, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ActiveBlackout returns the blackout holding back a reminder of the given priority at t
+// for a patient in the region, and when it ends. If several apply, the one ending last
+// is returned.
+func ActiveBlackout(t time.Time, blackouts []models.Blackout, region, priority string) (*models.Blackout, time.Time) {
+	var active *models.Blackout
+	var until time.Time
+	for i := range blackouts {
+		b := &blackouts[i]
+		if b.Exempts(priority) {
+			continue
+		}
+		if end, ok := BlackoutEnd(t, b, region); ok && end.After(until) {
+			active, until = b, end
+		}
+	}
+	return active, until
+}
+
+// NextSendTime returns the earliest time at or after t that is outside quiet hours and
+// every blackout that applies to the reminder's priority and patient region. Quiet hours
+// are not applied if quiet is nil.
+func NextSendTime(t time.Time, quiet *config.QuietHoursConfig, blackouts []models.Blackout, region, priority string) SendDeferral {
+	deferral := SendDeferral{SendAt: t}
+	for i := 0; i < maxSendDeferrals; i++ {
+		if quiet != nil && IsQuietHours(deferral.SendAt, quiet) {
+			deferral.QuietHours = true
+			deferral.SendAt = GetNextActiveTime(deferral.SendAt, quiet)
+			continue
+		}
+		if b, until := ActiveBlackout(deferral.SendAt, blackouts, region, priority); b != nil {
+			deferral.Blackout = b
+			deferral.SendAt = until.UTC()
+			continue
+		}
+		break
+	}
+	return deferral
+}
+
+// ReminderSendTime returns when a reminder of the given priority to the patient may be sent,
+// at or after t. Priority levels configured to bypass quiet hours or blackouts skip them.
+func ReminderSendTime(t time.Time, cfg *config.Config, blackouts []models.Blackout, patient *models.Patient, priority string) SendDeferral {
+	quiet := &cfg.QuietHours
+	region := PatientRegion(patient, quiet)
+	level := cfg.Priorities.Level(priority)
+	if level.BypassQuietHours {
+		quiet = nil
+	}
+	if level.BypassBlackouts {
+		blackouts = nil
+	}
+	return NextSendTime(t, quiet, blackouts, region, priority)
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/config"
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func TestBlackoutEnd(t *testing.T) {
+	lebaran := models.Blackout{ID: "lebaran", Type: models.BlackoutTypeDates, StartDate: "2026-03-20", EndDate: "2026-03-21"}
+	maghrib := models.Blackout{ID: "maghrib", Type: models.BlackoutTypeRecurring, StartTime: "18:00", EndTime: "18:30"}
+	friday := models.Blackout{ID: "jumat", Type: models.BlackoutTypeRecurring, StartTime: "11:30", EndTime: "13:00", Weekdays: []int{5}}
+	overnight := models.Blackout{ID: "overnight", Type: models.BlackoutTypeRecurring, StartTime: "23:00", EndTime: "01:00", Weekdays: []int{6}}
+	witaOnly := models.Blackout{ID: "wita", Type: models.BlackoutTypeRecurring, StartTime: "18:00", EndTime: "18:30", Region: "WITA"}
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		blackout models.Blackout
+		region   string
+		wantEnd  time.Time // zero if the blackout is not in effect
+	}{
+		{"inside dates", time.Date(2026, 3, 20, 9, 0, 0, 0, WIBLocation), lebaran, "WIB", time.Date(2026, 3, 22, 0, 0, 0, 0, WIBLocation)},
+		{"last day of dates", time.Date(2026, 3, 21, 23, 59, 0, 0, WIBLocation), lebaran, "WIB", time.Date(2026, 3, 22, 0, 0, 0, 0, WIBLocation)},
+		{"after dates", time.Date(2026, 3, 22, 0, 0, 0, 0, WIBLocation), lebaran, "WIB", time.Time{}},
+		{"dates are local to the region", time.Date(2026, 3, 19, 23, 30, 0, 0, WIBLocation), lebaran, "WIT", time.Date(2026, 3, 22, 0, 0, 0, 0, WITLocation)},
+		{"inside daily range", time.Date(2026, 3, 10, 18, 10, 0, 0, WIBLocation), maghrib, "WIB", time.Date(2026, 3, 10, 18, 30, 0, 0, WIBLocation)},
+		{"end of daily range", time.Date(2026, 3, 10, 18, 30, 0, 0, WIBLocation), maghrib, "WIB", time.Time{}},
+		{"weekday matches", time.Date(2026, 3, 13, 12, 0, 0, 0, WIBLocation), friday, "WIB", time.Date(2026, 3, 13, 13, 0, 0, 0, WIBLocation)},
+		{"weekday does not match", time.Date(2026, 3, 12, 12, 0, 0, 0, WIBLocation), friday, "WIB", time.Time{}},
+		{"overnight on start day", time.Date(2026, 3, 14, 23, 30, 0, 0, WIBLocation), overnight, "WIB", time.Date(2026, 3, 15, 1, 0, 0, 0, WIBLocation)},
+		{"overnight after midnight", time.Date(2026, 3, 15, 0, 30, 0, 0, WIBLocation), overnight, "WIB", time.Date(2026, 3, 15, 1, 0, 0, 0, WIBLocation)},
+		{"overnight after midnight of other day", time.Date(2026, 3, 14, 0, 30, 0, 0, WIBLocation), overnight, "WIB", time.Time{}},
+		{"region matches", time.Date(2026, 3, 10, 18, 10, 0, 0, WITALocation), witaOnly, "WITA", time.Date(2026, 3, 10, 18, 30, 0, 0, WITALocation)},
+		{"region does not match", time.Date(2026, 3, 10, 18, 10, 0, 0, WIBLocation), witaOnly, "WIB", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end, ok := BlackoutEnd(tt.at, &tt.blackout, tt.region)
+			if ok != !tt.wantEnd.IsZero() {
+				t.Fatalf("Expected in effect = %v, got %v", !tt.wantEnd.IsZero(), ok)
+			}
+			if ok && !end.Equal(tt.wantEnd) {
+				t.Errorf("Expected end %v, got %v", tt.wantEnd, end)
+			}
+		})
+	}
+}
+
+func TestNextSendTime(t *testing.T) {
+	quiet := &config.QuietHoursConfig{StartHour: intPtr(21), EndHour: intPtr(6), Timezone: "WIB"}
+	blackouts := []models.Blackout{
+		{ID: "lebaran", Name: "Idul Fitri", Type: models.BlackoutTypeDates, StartDate: "2026-03-20", EndDate: "2026-03-21", ExemptPriorities: []string{"high"}},
+		{ID: "subuh", Name: "Subuh", Type: models.BlackoutTypeRecurring, StartTime: "06:00", EndTime: "06:30"},
+	}
+
+	t.Run("outside every period", func(t *testing.T) {
+		at := time.Date(2026, 3, 10, 10, 0, 0, 0, WIBLocation)
+		d := NextSendTime(at, quiet, blackouts, "WIB", "low")
+		if d.Deferred() || !d.SendAt.Equal(at) {
+			t.Errorf("Expected send at %v, got %+v", at, d)
+		}
+	})
+
+	t.Run("quiet hours then recurring blackout", func(t *testing.T) {
+		at := time.Date(2026, 3, 10, 22, 0, 0, 0, WIBLocation)
+		d := NextSendTime(at, quiet, blackouts, "WIB", "low")
+		want := time.Date(2026, 3, 11, 6, 30, 0, 0, WIBLocation)
+		if !d.QuietHours || d.Blackout == nil || d.Blackout.ID != "subuh" || !d.SendAt.Equal(want) {
+			t.Errorf("Expected quiet hours and Subuh deferral to %v, got %+v", want, d)
+		}
+	})
+
+	t.Run("holiday then quiet hours and recurring blackout", func(t *testing.T) {
+		at := time.Date(2026, 3, 20, 10, 0, 0, 0, WIBLocation)
+		d := NextSendTime(at, quiet, blackouts, "WIB", "low")
+		want := time.Date(2026, 3, 22, 6, 30, 0, 0, WIBLocation)
+		if !d.SendAt.Equal(want) {
+			t.Errorf("Expected send at %v, got %v", want, d.SendAt)
+		}
+	})
+
+	t.Run("exempt priority", func(t *testing.T) {
+		at := time.Date(2026, 3, 20, 10, 0, 0, 0, WIBLocation)
+		d := NextSendTime(at, quiet, blackouts, "WIB", "high")
+		if d.Deferred() {
+			t.Errorf("Expected high priority to be exempt, got %+v", d)
+		}
+	})
+}
+
+func TestReminderSendTime(t *testing.T) {
+	cfg := &config.Config{
+		QuietHours: config.QuietHoursConfig{StartHour: intPtr(21), EndHour: intPtr(6), Timezone: "WIB"},
+		Priorities: config.PrioritiesConfig{Default: "medium", Levels: []config.PriorityLevel{
+			{Name: "high", BypassQuietHours: true},
+			{Name: "medium", BypassBlackouts: true},
+			{Name: "low"},
+		}},
+	}
+	blackouts := []models.Blackout{
+		{ID: "maghrib", Name: "Maghrib", Type: models.BlackoutTypeRecurring, StartTime: "18:00", EndTime: "18:30"},
+	}
+	night := time.Date(2026, 3, 10, 22, 0, 0, 0, WIBLocation)
+	dusk := time.Date(2026, 3, 10, 18, 10, 0, 0, WIBLocation)
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		priority string
+		deferred bool
+	}{
+		{"high bypasses quiet hours", night, "high", false},
+		{"high still honours blackouts", dusk, "high", true},
+		{"medium bypasses blackouts", dusk, "medium", false},
+		{"medium still honours quiet hours", night, "medium", true},
+		{"low honours both", dusk, "low", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ReminderSendTime(tt.at, cfg, blackouts, nil, tt.priority)
+			if d.Deferred() != tt.deferred {
+				t.Errorf("Expected deferred = %v, got %+v", tt.deferred, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// doseReplyWords maps the first word of a patient reply to a dose confirmation
+var doseReplyWords = map[string]string{
+	"sudah": models.DoseTaken,
+	"sdh":   models.DoseTaken,
+	"udah":  models.DoseTaken,
+	"ya":    models.DoseTaken,
+	"iya":   models.DoseTaken,
+	"yes":   models.DoseTaken,
+	"y":     models.DoseTaken,
+	"done":  models.DoseTaken,
+	"taken": models.DoseTaken,
+
+	"belum": models.DoseSkipped,
+	"blm":   models.DoseSkipped,
+	"tidak": models.DoseSkipped,
+	"tdk":   models.DoseSkipped,
+	"lupa":  models.DoseSkipped,
+	"no":    models.DoseSkipped,
+	"n":     models.DoseSkipped,
+	"skip":  models.DoseSkipped,
+}
+
+// ParseDoseReply interprets a patient's WhatsApp reply to a dose reminder. It returns
+// DoseTaken or DoseSkipped, and false if the reply is not a dose confirmation.
+func ParseDoseReply(text string) (string, bool) {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(fields) == 0 {
+		return "", false
+	}
+	status, ok := doseReplyWords[fields[0]]
+	return status, ok
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func TestParseDoseReply(t *testing.T) {
+	tests := []struct {
+		text   string
+		status string
+		ok     bool
+	}{
+		{"Sudah", models.DoseTaken, true},
+		{"sudah minum obat, terima kasih", models.DoseTaken, true},
+		{"  YA!", models.DoseTaken, true},
+		{"Yes", models.DoseTaken, true},
+		{"belum", models.DoseSkipped, true},
+		{"Tidak sempat", models.DoseSkipped, true},
+		{"no", models.DoseSkipped, true},
+		{"jam berapa kontrol besok?", "", false},
+		{"👍", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		status, ok := ParseDoseReply(tt.text)
+		if status != tt.status || ok != tt.ok {
+			t.Errorf("ParseDoseReply(%q) = %q, %v; want %q, %v", tt.text, status, ok, tt.status, tt.ok)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"sync"
+	"time"
 )
 
 // ValidateWebhookSignature validates the HMAC-SHA256 signature of a webhook payload.
@@ -15,14 +17,78 @@ func ValidateWebhookSignature(payload []byte, signature, secret string) bool {
 		return false
 	}
 
-	mac := hmac.New(sha256.New, []byte(secret))
-	mac.Write(payload)
-	expectedMAC := hex.EncodeToString(mac.Sum(nil))
+	expectedMAC := SignWebhookPayload(payload, secret)
 
 	// Use constant-time comparison to prevent timing attacks
 	return hmac.Equal([]byte(signature), []byte(expectedMAC))
 }
 
+// SignWebhookPayload returns the hex-encoded HMAC-SHA256 signature of a webhook payload,
+// as checked by ValidateWebhookSignature
+func SignWebhookPayload(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateWebhookSignatureAny validates the signature against each accepted secret, so a
+// secret can be rotated without rejecting webhooks signed with the previous one.
+func ValidateWebhookSignatureAny(payload []byte, signature string, secrets []string) bool {
+	for _, secret := range secrets {
+		if ValidateWebhookSignature(payload, signature, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// TimestampedWebhookPayload returns the content signed for a timestamped webhook:
+// "<timestamp>.<nonce>.<body>", binding the timestamp and nonce to the body.
+func TimestampedWebhookPayload(timestamp, nonce string, body []byte) []byte {
+	signed := make([]byte, 0, len(timestamp)+len(nonce)+2+len(body))
+	signed = append(signed, timestamp...)
+	signed = append(signed, '.')
+	signed = append(signed, nonce...)
+	signed = append(signed, '.')
+	return append(signed, body...)
+}
+
+// NonceCache remembers webhook nonces for a limited time to reject replayed requests
+type NonceCache struct {
+	mu   sync.Mutex
+	seen map[string]time.Time // nonce -> when it expires
+	ttl  time.Duration
+}
+
+// NewNonceCache creates a nonce cache that remembers each nonce for ttl
+func NewNonceCache(ttl time.Duration) *NonceCache {
+	return &NonceCache{
+		seen: make(map[string]time.Time),
+		ttl:  ttl,
+	}
+}
+
+// Seen records the nonce and reports whether it was already recorded and has not expired
+func (c *NonceCache) Seen(nonce string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if expires, ok := c.seen[nonce]; ok && now.Before(expires) {
+		return true
+	}
+	c.seen[nonce] = now.Add(c.ttl)
+
+	// Clean up expired nonces periodically
+	if len(c.seen) > 1000 {
+		for n, expires := range c.seen {
+			if !now.Before(expires) {
+				delete(c.seen, n)
+			}
+		}
+	}
+	return false
+}
+
 // GenerateWebhookSignature generates an HMAC-SHA256 signature for testing purposes.
 func GenerateWebhookSignature(payload []byte, secret string) string {
 	mac := hmac.New(sha256.New, []byte(secret))
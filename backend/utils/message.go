@@ -2,6 +2,8 @@ package utils
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -44,81 +46,49 @@ type ReminderMessageParams struct {
 
 // ContentAttachment represents an attachment with content details for message formatting
 type ContentAttachment struct {
+	ID       string // Content ID in the article/video store
 	Type     string // "article" or "video"
 	Title    string
 	Excerpt  string // Only for articles
 	URL      string
 	Slug     string // For article URL generation
 	YouTubeID string // For video URL generation
+	ImageURL string // Article 1:1 hero image web path, e.g. /uploads/x_1x1.jpg
 }
 
 // FormatReminderMessageWithExcerpts creates a WhatsApp message with content excerpts
+// using the built-in Indonesian template
 // Articles show: title + excerpt (max 100 chars) + link
 // Videos show: title + link only
 func FormatReminderMessageWithExcerpts(params ReminderMessageParams, attachments []ContentAttachment) string {
-	var sb strings.Builder
-
-	sb.WriteString(fmt.Sprintf("Halo %s,\n\n", params.PatientName))
-	sb.WriteString(fmt.Sprintf("*%s*\n\n", params.ReminderTitle))
-
-	if params.ReminderDescription != "" {
-		sb.WriteString(params.ReminderDescription)
-		sb.WriteString("\n\n")
-	}
-
-	// Add attachments with excerpts if present
-	if len(attachments) > 0 {
-		sb.WriteString("---\n")
-		sb.WriteString("Konten Edukasi:\n")
-
-		for _, att := range attachments {
-			if att.Type == "article" {
-				// Format: 📖 Title\nExcerpt...\n🔗 link
-				sb.WriteString(fmt.Sprintf("📖 %s\n", att.Title))
-
-				// Add excerpt (truncated to 100 chars)
-				// If excerpt is empty, use title as fallback per AC #1
-				excerptText := att.Excerpt
-				if excerptText == "" {
-					excerptText = att.Title
-				}
-				truncated := TruncateString(excerptText, MaxExcerptLength)
-				sb.WriteString(fmt.Sprintf("%s\n", truncated))
-
-				// Add link
-				if att.URL != "" {
-					sb.WriteString(fmt.Sprintf("🔗 %s\n", att.URL))
-				}
-			} else if att.Type == "video" {
-				// Format: 🎬 Title\n🔗 link
-				sb.WriteString(fmt.Sprintf("🎬 %s\n", att.Title))
-
-				if att.URL != "" {
-					sb.WriteString(fmt.Sprintf("🔗 %s\n", att.URL))
-				}
-			}
-			sb.WriteString("\n")
-		}
-	}
+	return renderDefaultTemplate(models.DefaultLanguage, NewMessageTemplateData(params, attachments))
+}
 
-	// Add health disclaimer if enabled
-	if params.DisclaimerEnabled && params.DisclaimerText != "" {
-		sb.WriteString("---\n")
-		sb.WriteString(fmt.Sprintf("_%s_", params.DisclaimerText))
-	}
+// DefaultPublicBaseURL is the public site used for article links when none is configured
+const DefaultPublicBaseURL = "https://prima.app"
 
-	return sb.String()
+// BuildContentAttachments builds ContentAttachment slice from reminder attachments
+// with article links on DefaultPublicBaseURL
+func BuildContentAttachments(attachments []models.Attachment, articleStore *models.ArticleStore, videoStore *models.VideoStore) []ContentAttachment {
+	return BuildContentAttachmentsWithBaseURL(attachments, articleStore, videoStore, DefaultPublicBaseURL)
 }
 
-// BuildContentAttachments builds ContentAttachment slice from reminder attachments
+// BuildContentAttachmentsWithBaseURL builds ContentAttachment slice from reminder attachments
 // Looks up article/video content from content stores to get excerpts and URLs
+// Article links are built on baseURL (DefaultPublicBaseURL if empty)
 // Sorts attachments: articles first, then videos
 // Thread-safe: handles mutex locking internally for content store access
-func BuildContentAttachments(attachments []models.Attachment, articleStore *models.ArticleStore, videoStore *models.VideoStore) []ContentAttachment {
+func BuildContentAttachmentsWithBaseURL(attachments []models.Attachment, articleStore *models.ArticleStore, videoStore *models.VideoStore, baseURL string) []ContentAttachment {
+	if baseURL == "" {
+		baseURL = DefaultPublicBaseURL
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+
 	var contentAttachments []ContentAttachment
 
 	for _, att := range attachments {
 		contentAtt := ContentAttachment{
+			ID:    att.ID,
 			Type:  att.Type,
 			Title: att.Title,
 		}
@@ -128,9 +98,10 @@ func BuildContentAttachments(attachments []models.Attachment, articleStore *mode
 			articleStore.Mu.RLock()
 			if article, exists := articleStore.Articles[att.ID]; exists {
 				contentAtt.Excerpt = article.Excerpt
+				contentAtt.ImageURL = article.HeroImages.Hero1x1
 				// Generate article URL from slug
 				if article.Slug != "" {
-					contentAtt.URL = fmt.Sprintf("https://prima.app/artikel/%s", article.Slug)
+					contentAtt.URL = fmt.Sprintf("%s/artikel/%s", baseURL, article.Slug)
 				}
 			} else {
 				// Article not found - use fallback text
@@ -171,3 +142,38 @@ func BuildContentAttachments(attachments []models.Attachment, articleStore *mode
 
 	return contentAttachments
 }
+
+// UploadsDir is the local directory served under /uploads
+const UploadsDir = "uploads"
+
+// LocalUploadPath maps an /uploads/ web path to its file under UploadsDir.
+// It returns false for paths outside the uploads directory.
+func LocalUploadPath(webPath string) (string, bool) {
+	name, ok := strings.CutPrefix(webPath, "/"+UploadsDir+"/")
+	if !ok || name == "" {
+		return "", false
+	}
+	name = filepath.Clean(name)
+	if name == "." || filepath.IsAbs(name) || strings.HasPrefix(name, "..") {
+		return "", false
+	}
+	return filepath.Join(UploadsDir, name), true
+}
+
+// HeroImagePath returns the local file of the first article hero image that exists on disk,
+// or an empty string if no attachment has one
+func HeroImagePath(attachments []ContentAttachment) string {
+	for _, att := range attachments {
+		if att.Type != "article" || att.ImageURL == "" {
+			continue
+		}
+		path, ok := LocalUploadPath(att.ImageURL)
+		if !ok {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
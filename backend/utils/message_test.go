@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalUploadPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		ok       bool
+	}{
+		{"hero image", "/uploads/abc_1x1.jpg", filepath.Join("uploads", "abc_1x1.jpg"), true},
+		{"nested file", "/uploads/docs/leaflet.pdf", filepath.Join("uploads", "docs", "leaflet.pdf"), true},
+		{"empty", "", "", false},
+		{"outside uploads", "/static/logo.png", "", false},
+		{"directory only", "/uploads/", "", false},
+		{"path traversal", "/uploads/../config.yaml", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := LocalUploadPath(tt.input)
+			if result != tt.expected || ok != tt.ok {
+				t.Errorf("LocalUploadPath(%q) = (%q, %v), expected (%q, %v)", tt.input, result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestHeroImagePath(t *testing.T) {
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to chdir: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	os.MkdirAll(UploadsDir, 0755)
+	os.WriteFile(filepath.Join(UploadsDir, "present_1x1.jpg"), []byte("jpg"), 0644)
+
+	attachments := []ContentAttachment{
+		{Type: "video", ImageURL: "/uploads/present_1x1.jpg"},
+		{Type: "article", ImageURL: "/uploads/missing_1x1.jpg"},
+		{Type: "article", ImageURL: "/uploads/present_1x1.jpg"},
+	}
+
+	if got := HeroImagePath(attachments); got != filepath.Join(UploadsDir, "present_1x1.jpg") {
+		t.Errorf("Expected first existing article image, got %q", got)
+	}
+	if got := HeroImagePath(attachments[:2]); got != "" {
+		t.Errorf("Expected no image, got %q", got)
+	}
+}
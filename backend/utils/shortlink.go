@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// shortLinkAlphabet is URL-safe and avoids characters WhatsApp might treat as punctuation
+const shortLinkAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// ShortLinkTokenLength is the length of generated redirect tokens
+const ShortLinkTokenLength = 10
+
+// ShortLinkPath is the path prefix served by the redirect endpoint
+const ShortLinkPath = "/l/"
+
+// GenerateLinkToken returns a random short link token
+func GenerateLinkToken() string {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(shortLinkAlphabet)))
+	for i := 0; i < ShortLinkTokenLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			// crypto/rand failure: fall back to time-derived index (should never happen in practice)
+			n = big.NewInt(time.Now().UnixNano() % int64(len(shortLinkAlphabet)))
+		}
+		sb.WriteByte(shortLinkAlphabet[n.Int64()])
+	}
+	return sb.String()
+}
+
+// TrackContentLinks returns a copy of attachments whose URLs are replaced with tracked
+// short links for this patient and reminder. Links are created once per idempotency key
+// and reused by retries of the same occurrence, so a resent message has the same text.
+// Attachments without a URL are left untouched. A nil store returns attachments unchanged.
+func TrackContentLinks(store *models.ShortLinkStore, baseURL, patientID, reminderID, idempotencyKey string, attachments []ContentAttachment) []ContentAttachment {
+	if store == nil || len(attachments) == 0 {
+		return attachments
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	tracked := make([]ContentAttachment, len(attachments))
+	copy(tracked, attachments)
+
+	created := false
+	store.Mu.Lock()
+	existing := make(map[string]string) // target URL -> token sent for this occurrence
+	if idempotencyKey != "" {
+		for token, link := range store.Links {
+			if link.ReminderID == reminderID && link.IdempotencyKey == idempotencyKey {
+				existing[link.TargetURL] = token
+			}
+		}
+	}
+	for i := range tracked {
+		if tracked[i].URL == "" {
+			continue
+		}
+		if token, ok := existing[tracked[i].URL]; ok {
+			tracked[i].URL = baseURL + ShortLinkPath + token
+			continue
+		}
+		token := GenerateLinkToken()
+		for _, exists := store.Links[token]; exists; _, exists = store.Links[token] {
+			token = GenerateLinkToken()
+		}
+		store.Links[token] = &models.ShortLink{
+			Token:          token,
+			TargetURL:      tracked[i].URL,
+			PatientID:      patientID,
+			ReminderID:     reminderID,
+			IdempotencyKey: idempotencyKey,
+			ContentType:    tracked[i].Type,
+			ContentID:      tracked[i].ID,
+			CreatedAt:      now,
+		}
+		existing[tracked[i].URL] = token
+		tracked[i].URL = baseURL + ShortLinkPath + token
+		created = true
+	}
+	store.Mu.Unlock()
+
+	if created {
+		store.SaveData()
+	}
+	return tracked
+}
+
+// ShortLinkPlaceholder returns a link of the same length as a tracked short link,
+// used when previewing a message without creating real tokens
+func ShortLinkPlaceholder(baseURL string) string {
+	return strings.TrimRight(baseURL, "/") + ShortLinkPath + strings.Repeat("x", ShortLinkTokenLength)
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func TestGenerateLinkToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token := GenerateLinkToken()
+		if len(token) != ShortLinkTokenLength {
+			t.Fatalf("token length = %d, want %d", len(token), ShortLinkTokenLength)
+		}
+		for _, ch := range token {
+			if !strings.ContainsRune(shortLinkAlphabet, ch) {
+				t.Fatalf("token %q contains invalid character %q", token, ch)
+			}
+		}
+		if seen[token] {
+			t.Fatalf("duplicate token %q", token)
+		}
+		seen[token] = true
+	}
+}
+
+func TestTrackContentLinks(t *testing.T) {
+	store := models.NewShortLinkStore(nil)
+	attachments := []ContentAttachment{
+		{Type: "article", ID: "art-1", Title: "Artikel", URL: "https://staging.prima.app/artikel/art-1"},
+		{Type: "video", ID: "vid-1", Title: "Video", URL: "https://www.youtube.com/watch?v=abc"},
+		{Type: "article", ID: "art-2", Title: "Tanpa URL"},
+	}
+
+	tracked := TrackContentLinks(store, "https://go.prima.app/", "patient-1", "reminder-1", "key-1", attachments)
+
+	if len(tracked) != 3 {
+		t.Fatalf("got %d attachments, want 3", len(tracked))
+	}
+	if attachments[0].URL != "https://staging.prima.app/artikel/art-1" {
+		t.Error("input attachments must not be modified")
+	}
+	if tracked[2].URL != "" {
+		t.Errorf("attachment without URL should stay empty, got %q", tracked[2].URL)
+	}
+	if len(store.Links) != 2 {
+		t.Fatalf("store has %d links, want 2", len(store.Links))
+	}
+
+	for i, original := range attachments[:2] {
+		prefix := "https://go.prima.app" + ShortLinkPath
+		if !strings.HasPrefix(tracked[i].URL, prefix) {
+			t.Fatalf("tracked URL %q does not start with %q", tracked[i].URL, prefix)
+		}
+		link := store.Links[strings.TrimPrefix(tracked[i].URL, prefix)]
+		if link == nil {
+			t.Fatalf("no link stored for %q", tracked[i].URL)
+		}
+		if link.TargetURL != original.URL {
+			t.Errorf("TargetURL = %q, want %q", link.TargetURL, original.URL)
+		}
+		if link.PatientID != "patient-1" || link.ReminderID != "reminder-1" || link.IdempotencyKey != "key-1" {
+			t.Errorf("link not bound to patient/reminder occurrence: %+v", link)
+		}
+		if link.ContentID != original.ID || link.ContentType != original.Type {
+			t.Errorf("link content = %s/%s, want %s/%s", link.ContentType, link.ContentID, original.Type, original.ID)
+		}
+	}
+
+	// Retries of the same occurrence reuse its tokens, so the message text is unchanged
+	retried := TrackContentLinks(store, "https://go.prima.app", "patient-1", "reminder-1", "key-1", attachments)
+	if retried[0].URL != tracked[0].URL || retried[1].URL != tracked[1].URL {
+		t.Errorf("expected retry to reuse tokens, got %q and %q", retried[0].URL, retried[1].URL)
+	}
+	if len(store.Links) != 2 {
+		t.Errorf("store has %d links after retry, want 2", len(store.Links))
+	}
+
+	// The next occurrence gets its own tokens
+	next := TrackContentLinks(store, "https://go.prima.app", "patient-1", "reminder-1", "key-2", attachments)
+	if next[0].URL == tracked[0].URL {
+		t.Error("expected a new token for the next occurrence")
+	}
+}
+
+func TestTrackContentLinks_NilStore(t *testing.T) {
+	attachments := []ContentAttachment{{Type: "article", ID: "art-1", URL: "https://prima.app/artikel/art-1"}}
+	tracked := TrackContentLinks(nil, "https://prima.app", "patient-1", "reminder-1", "key-1", attachments)
+	if tracked[0].URL != attachments[0].URL {
+		t.Errorf("nil store should leave URL unchanged, got %q", tracked[0].URL)
+	}
+}
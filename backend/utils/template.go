@@ -0,0 +1,238 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+// MaxTemplateLength is the maximum size of a single message template body
+const MaxTemplateLength = 4000
+
+// MaxRenderedMessageLength caps rendered output so a template can't produce runaway messages
+const MaxRenderedMessageLength = 16000
+
+// DefaultReminderTemplates are the built-in reminder layouts, seeded into the template
+// store and used whenever no stored template applies
+var DefaultReminderTemplates = map[string]string{
+	models.LanguageIndonesian: `Halo {{.PatientName}},
+
+*{{.Title}}*
+
+{{if .Description}}{{.Description}}
+
+{{end}}{{if .Attachments}}---
+Konten Edukasi:
+{{range .Attachments}}{{if .IsArticle}}📖 {{.Title}}
+{{.Excerpt}}
+{{if .URL}}🔗 {{.URL}}
+{{end}}{{else if .IsVideo}}🎬 {{.Title}}
+{{if .URL}}🔗 {{.URL}}
+{{end}}{{end}}
+{{end}}{{end}}{{if .Disclaimer}}---
+_{{.Disclaimer}}_{{end}}`,
+
+	models.LanguageEnglish: `Hello {{.PatientName}},
+
+*{{.Title}}*
+
+{{if .Description}}{{.Description}}
+
+{{end}}{{if .Attachments}}---
+Educational Content:
+{{range .Attachments}}{{if .IsArticle}}📖 {{.Title}}
+{{.Excerpt}}
+{{if .URL}}🔗 {{.URL}}
+{{end}}{{else if .IsVideo}}🎬 {{.Title}}
+{{if .URL}}🔗 {{.URL}}
+{{end}}{{end}}
+{{end}}{{end}}{{if .Disclaimer}}---
+_{{.Disclaimer}}_{{end}}`,
+}
+
+// MessageTemplateData is the data available to message templates
+type MessageTemplateData struct {
+	PatientName string
+	Title       string
+	Description string
+	Attachments []TemplateAttachment
+	Disclaimer  string // Empty when the disclaimer is disabled
+}
+
+// TemplateAttachment is an educational content link as seen by message templates
+type TemplateAttachment struct {
+	Type      string
+	Title     string
+	Excerpt   string // Articles only, truncated to MaxExcerptLength
+	URL       string
+	IsArticle bool
+	IsVideo   bool
+}
+
+// templateFuncs is the function set available to message templates in addition to
+// text/template builtins. It only formats strings and has no side effects.
+var templateFuncs = template.FuncMap{
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"trim":     strings.TrimSpace,
+	"truncate": func(n int, s string) string { return TruncateString(s, n) },
+	"default": func(fallback, s string) string {
+		if strings.TrimSpace(s) == "" {
+			return fallback
+		}
+		return s
+	},
+}
+
+var defaultTemplates = func() map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(DefaultReminderTemplates))
+	for lang, body := range DefaultReminderTemplates {
+		parsed[lang] = template.Must(ParseMessageTemplate(body))
+	}
+	return parsed
+}()
+
+// NewMessageTemplateData builds template data from reminder message parameters
+func NewMessageTemplateData(params ReminderMessageParams, attachments []ContentAttachment) MessageTemplateData {
+	data := MessageTemplateData{
+		PatientName: params.PatientName,
+		Title:       params.ReminderTitle,
+		Description: params.ReminderDescription,
+	}
+	if params.DisclaimerEnabled {
+		data.Disclaimer = params.DisclaimerText
+	}
+
+	for _, att := range attachments {
+		ta := TemplateAttachment{
+			Type:      att.Type,
+			Title:     att.Title,
+			URL:       att.URL,
+			IsArticle: att.Type == "article",
+			IsVideo:   att.Type == "video",
+		}
+		if ta.IsArticle {
+			// If excerpt is empty, use title as fallback
+			excerpt := att.Excerpt
+			if excerpt == "" {
+				excerpt = att.Title
+			}
+			ta.Excerpt = TruncateString(excerpt, MaxExcerptLength)
+		}
+		data.Attachments = append(data.Attachments, ta)
+	}
+
+	return data
+}
+
+// ParseMessageTemplate parses a message template body with the safe function set
+func ParseMessageTemplate(body string) (*template.Template, error) {
+	if len(body) > MaxTemplateLength {
+		return nil, fmt.Errorf("template exceeds %d characters", MaxTemplateLength)
+	}
+	return template.New("message").Funcs(templateFuncs).Option("missingkey=error").Parse(body)
+}
+
+// errMessageTooLong is returned when rendered output exceeds MaxRenderedMessageLength
+var errMessageTooLong = fmt.Errorf("rendered message exceeds %d characters", MaxRenderedMessageLength)
+
+// limitedBuffer is a bytes.Buffer that refuses writes past MaxRenderedMessageLength
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > MaxRenderedMessageLength {
+		return 0, errMessageTooLong
+	}
+	return b.Buffer.Write(p)
+}
+
+// RenderParsedTemplate executes a parsed message template
+func RenderParsedTemplate(tmpl *template.Template, data MessageTemplateData) (string, error) {
+	var buf limitedBuffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderMessageTemplate parses and executes a message template body
+func RenderMessageTemplate(body string, data MessageTemplateData) (string, error) {
+	tmpl, err := ParseMessageTemplate(body)
+	if err != nil {
+		return "", err
+	}
+	return RenderParsedTemplate(tmpl, data)
+}
+
+// sampleTemplateData exercises every field and attachment type during validation
+var sampleTemplateData = MessageTemplateData{
+	PatientName: "Budi",
+	Title:       "Minum obat",
+	Description: "Jangan lupa minum obat setelah makan.",
+	Attachments: []TemplateAttachment{
+		{Type: "article", Title: "Artikel", Excerpt: "Ringkasan artikel", URL: "https://prima.app/artikel/contoh", IsArticle: true},
+		{Type: "video", Title: "Video", URL: "https://youtube.com/watch?v=contoh", IsVideo: true},
+	},
+	Disclaimer: "Informasi ini untuk tujuan edukasi.",
+}
+
+// ValidateMessageTemplate checks that a template body parses, renders against sample
+// data and produces a non-empty message
+func ValidateMessageTemplate(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return errors.New("template is empty")
+	}
+	rendered, err := RenderMessageTemplate(body, sampleTemplateData)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(rendered) == "" {
+		return errors.New("template renders an empty message")
+	}
+	return nil
+}
+
+// FormatReminderMessageFromTemplate renders a reminder with the named stored template in
+// the given language. It falls back to the built-in template for the language if the
+// store has no usable template or rendering fails.
+func FormatReminderMessageFromTemplate(templates *models.TemplateStore, name, language string, params ReminderMessageParams, attachments []ContentAttachment) string {
+	if name == "" {
+		name = models.DefaultMessageTemplateName
+	}
+	data := NewMessageTemplateData(params, attachments)
+
+	if templates != nil {
+		if body, ok := templates.Resolve(name, language); ok {
+			rendered, err := RenderMessageTemplate(body, data)
+			if err == nil {
+				return rendered
+			}
+			if DefaultLogger != nil {
+				DefaultLogger.Warn("Message template failed to render, using built-in template",
+					"template", name,
+					"language", language,
+					"error", err.Error(),
+				)
+			}
+		}
+	}
+
+	return renderDefaultTemplate(language, data)
+}
+
+// renderDefaultTemplate renders the built-in template for language (Indonesian if unknown)
+func renderDefaultTemplate(language string, data MessageTemplateData) string {
+	tmpl, ok := defaultTemplates[language]
+	if !ok {
+		tmpl = defaultTemplates[models.DefaultLanguage]
+	}
+	// Built-in templates only reference fields of MessageTemplateData and cannot fail
+	rendered, _ := RenderParsedTemplate(tmpl, data)
+	return rendered
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/davidyusaku-13/prima_v2/models"
+)
+
+func TestValidateMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"default indonesian", DefaultReminderTemplates[models.LanguageIndonesian], false},
+		{"default english", DefaultReminderTemplates[models.LanguageEnglish], false},
+		{"functions", `{{upper .PatientName}} {{truncate 5 .Description}} {{default "-" .Disclaimer}}`, false},
+		{"empty", "   ", true},
+		{"syntax error", "Halo {{.PatientName", true},
+		{"unknown field", "Halo {{.Password}}", true},
+		{"unknown function", `{{exec "rm"}}`, true},
+		{"renders empty", `{{if false}}x{{end}}`, true},
+		{"too long", strings.Repeat("a", MaxTemplateLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMessageTemplate(tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMessageTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderMessageTemplate_OutputLimit(t *testing.T) {
+	body := `{{range .Attachments}}{{range $.Attachments}}{{printf "%9000s" .Title}}{{end}}{{end}}`
+	if _, err := RenderMessageTemplate(body, sampleTemplateData); err == nil {
+		t.Error("Expected error for output exceeding the limit")
+	}
+}
+
+func TestFormatReminderMessageFromTemplate(t *testing.T) {
+	params := ReminderMessageParams{
+		PatientName:   "Budi",
+		ReminderTitle: "Minum obat",
+	}
+
+	t.Run("built-in template per language", func(t *testing.T) {
+		id := FormatReminderMessageFromTemplate(nil, "", models.LanguageIndonesian, params, nil)
+		if !strings.HasPrefix(id, "Halo Budi,") {
+			t.Errorf("Expected Indonesian greeting, got %q", id)
+		}
+		en := FormatReminderMessageFromTemplate(nil, "", models.LanguageEnglish, params, nil)
+		if !strings.HasPrefix(en, "Hello Budi,") {
+			t.Errorf("Expected English greeting, got %q", en)
+		}
+		unknown := FormatReminderMessageFromTemplate(nil, "", "fr", params, nil)
+		if unknown != id {
+			t.Errorf("Expected unknown language to use Indonesian, got %q", unknown)
+		}
+	})
+
+	t.Run("stored template with language fallback", func(t *testing.T) {
+		store := models.NewTemplateStore(nil)
+		store.Templates["t1"] = &models.MessageTemplate{
+			ID:       "t1",
+			Name:     "short",
+			Variants: map[string]string{models.LanguageIndonesian: "Hai {{.PatientName}}: {{.Title}}"},
+		}
+		store.ByName["short"] = "t1"
+
+		got := FormatReminderMessageFromTemplate(store, "short", models.LanguageEnglish, params, nil)
+		if got != "Hai Budi: Minum obat" {
+			t.Errorf("Expected stored Indonesian variant, got %q", got)
+		}
+	})
+
+	t.Run("broken stored template falls back to built-in", func(t *testing.T) {
+		store := models.NewTemplateStore(nil)
+		store.Templates["t1"] = &models.MessageTemplate{
+			ID:       "t1",
+			Name:     models.DefaultMessageTemplateName,
+			Variants: map[string]string{models.LanguageIndonesian: "{{.Missing}}"},
+		}
+		store.ByName[models.DefaultMessageTemplateName] = "t1"
+
+		got := FormatReminderMessageFromTemplate(store, "", models.LanguageIndonesian, params, nil)
+		if got != FormatReminderMessageWithExcerpts(params, nil) {
+			t.Errorf("Expected built-in fallback, got %q", got)
+		}
+	})
+}
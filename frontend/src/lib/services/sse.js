@@ -1,3 +1,5 @@
+import { createSSETicket } from '$lib/utils/api.js';
+
 /**
  * SSE Client Service for real-time delivery status updates
  * Uses native browser EventSource API (NOT SvelteKit-specific)
@@ -5,7 +7,8 @@
  * CRITICAL: This is Vite + Svelte 5, NOT SvelteKit!
  * - Uses native EventSource (browser API)
  * - No SvelteKit imports ($app/*)
- * - Token passed via query parameter (EventSource doesn't support headers)
+ * - Single-use ticket passed via query parameter (EventSource doesn't support headers);
+ *   the JWT never goes in the URL
  */
 class SSEService {
   constructor() {
@@ -15,14 +18,16 @@ class SSEService {
     this.reconnectDelay = 1000; // Start with 1 second
     this.listeners = new Map();
     this.connectionStatus = "disconnected"; // 'connected' | 'connecting' | 'disconnected'
+    this.lastEventId = null; // Last event ID seen, sent on reconnect to replay missed events
+    this.connectAttempt = 0; // Bumped on disconnect so a pending ticket request is discarded
   }
 
   /**
-   * Connect to SSE endpoint with JWT authentication
+   * Connect to SSE endpoint with a ticket obtained using JWT authentication
    */
-  connect() {
-    if (this.eventSource) {
-      return; // Already connected
+  async connect() {
+    if (this.eventSource || this.connectionStatus === "connecting") {
+      return; // Already connected or connecting
     }
 
     // Get token from localStorage (where App.svelte stores it)
@@ -35,13 +40,35 @@ class SSEService {
     this.connectionStatus = "connecting";
     this.notifyStatusChange();
 
-    // Pass JWT token via query parameter (EventSource doesn't support headers)
-    const url = `http://localhost:8080/api/sse/delivery-status?token=${token}`;
+    // Tickets are single use, so every connection and reconnection needs a new one
+    const attempt = this.connectAttempt;
+    let ticket;
+    try {
+      ticket = (await createSSETicket(token)).ticket;
+    } catch (error) {
+      if (attempt !== this.connectAttempt) return;
+      this.connectionStatus = "disconnected";
+      this.notifyStatusChange();
+      if (error.status === 401 || error.status === 403) {
+        console.error("❌ SSE ticket refused, not reconnecting:", error.message);
+        return;
+      }
+      this.attemptReconnect();
+      return;
+    }
+    if (attempt !== this.connectAttempt) return; // Disconnected while waiting
+
+    let url = `http://localhost:8080/api/sse/delivery-status?ticket=${encodeURIComponent(ticket)}`;
+    // We reconnect with a new EventSource, which does not send Last-Event-ID itself
+    if (this.lastEventId) {
+      url += `&last_event_id=${encodeURIComponent(this.lastEventId)}`;
+    }
 
     this.eventSource = new EventSource(url);
 
     // Connection established
     this.eventSource.addEventListener("connection.established", (e) => {
+      this.trackEventId(e);
       console.info("✅ SSE connected");
       this.connectionStatus = "connected";
       this.reconnectAttempts = 0;
@@ -51,10 +78,33 @@ class SSEService {
 
     // Delivery status updated
     this.eventSource.addEventListener("delivery.status.updated", (e) => {
+      this.trackEventId(e);
       const data = JSON.parse(e.data);
       this.notifyListeners("delivery.status.updated", data);
     });
 
+    // Bulk campaign progress (admins only)
+    this.eventSource.addEventListener("campaign.progress", (e) => {
+      this.trackEventId(e);
+      const data = JSON.parse(e.data);
+      this.notifyListeners("campaign.progress", data);
+    });
+
+    // Reminder not read within its escalation window
+    this.eventSource.addEventListener("reminder.escalated", (e) => {
+      this.trackEventId(e);
+      const data = JSON.parse(e.data);
+      this.notifyListeners("reminder.escalated", data);
+    });
+
+    // Missed events are no longer buffered on the server; state must be refetched
+    this.eventSource.addEventListener("reset", (e) => {
+      this.trackEventId(e);
+      console.info("🔁 SSE gap too large, refetching state");
+      const data = JSON.parse(e.data);
+      this.notifyListeners("reset", data);
+    });
+
     // Connection error
     this.eventSource.onerror = (error) => {
       const state = this.eventSource?.readyState;
@@ -71,6 +121,15 @@ class SSEService {
     };
   }
 
+  /**
+   * Remember the ID of the last event received so a reconnect can resume from it
+   */
+  trackEventId(e) {
+    if (e.lastEventId) {
+      this.lastEventId = e.lastEventId;
+    }
+  }
+
   /**
    * Attempt to reconnect with exponential backoff
    */
@@ -153,10 +212,12 @@ class SSEService {
    * Disconnect from SSE endpoint
    */
   disconnect() {
+    this.connectAttempt++;
     if (this.eventSource) {
       this.eventSource.close();
       this.eventSource = null;
     }
+    this.lastEventId = null; // A new session starts from fresh state
     this.connectionStatus = "disconnected";
     this.notifyStatusChange();
   }
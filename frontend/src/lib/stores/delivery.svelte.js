@@ -30,6 +30,13 @@ class DeliveryStore {
             this.connectionStatus = status;
         });
 
+        // Events were missed while disconnected: drop cached statuses and ask the app
+        // to refetch patients, which rehydrates the store
+        sseService.on('reset', () => {
+            this.deliveryStatuses = {};
+            window.dispatchEvent(new CustomEvent('delivery-reset'));
+        });
+
         // Handle delivery.failed event
         sseService.on('delivery.failed', (data) => {
             // Add to failed reminders list
@@ -72,6 +79,26 @@ class DeliveryStore {
         };
     }
 
+    /**
+     * Load delivery statuses from fetched patients
+     * CRITICAL: Single assignment so hydrating many reminders triggers one update
+     */
+    hydrate(patients) {
+        const statuses = { ...this.deliveryStatuses };
+        patients.forEach(p => {
+            p.reminders?.forEach(r => {
+                if (r.delivery_status) {
+                    statuses[r.id] = {
+                        status: r.delivery_status,
+                        timestamp: r.message_sent_at || new Date().toISOString(),
+                        updatedAt: new Date().toISOString(),
+                    };
+                }
+            });
+        });
+        this.deliveryStatuses = statuses;
+    }
+
     /**
      * Get delivery status for a reminder
      */
@@ -180,6 +180,25 @@ describe('DeliveryStore', () => {
     expect(status).toBeNull();
   });
 
+  it('should hydrate delivery statuses from fetched patients', () => {
+    deliveryStore.updateStatus('reminder-0', 'read', '2025-12-30T09:00:00Z');
+
+    deliveryStore.hydrate([
+      {
+        id: 'p1',
+        reminders: [
+          { id: 'reminder-1', delivery_status: 'delivered', message_sent_at: '2025-12-30T10:00:00Z' },
+          { id: 'reminder-2' }
+        ]
+      },
+      { id: 'p2' }
+    ]);
+
+    expect(deliveryStore.getStatus('reminder-0')).toBe('read');
+    expect(deliveryStore.getStatus('reminder-1')).toBe('delivered');
+    expect(deliveryStore.getStatus('reminder-2')).toBeNull();
+  });
+
   it('should create new object reference when updating status (Svelte 5 reactivity)', () => {
     const originalRef = deliveryStore.deliveryStatuses;
 
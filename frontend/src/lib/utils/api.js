@@ -127,8 +127,10 @@ export async function sendReminder(token, patientId, reminderId) {
   return data;
 }
 
-export async function retryReminder(token, reminderId) {
-  const res = await fetch(`${API_URL}/reminders/${reminderId}/retry`, {
+// force (admins only) resends a send that GOWA could not verify (code SEND_UNVERIFIED)
+export async function retryReminder(token, reminderId, force = false) {
+  const query = force ? '?force=true' : '';
+  const res = await fetch(`${API_URL}/reminders/${reminderId}/retry${query}`, {
     method: 'POST',
     headers: getHeaders(token)
   });
@@ -498,6 +500,21 @@ export async function getHealthDetailed(token) {
   return data.data;
 }
 
+// SSE - Single-use ticket for opening the event stream
+export async function createSSETicket(token) {
+  const res = await fetch(`${API_URL}/sse/ticket`, {
+    method: 'POST',
+    headers: getHeaders(token)
+  });
+  const data = await res.json();
+  if (!res.ok) {
+    const error = new Error(data.error || 'Failed to get SSE ticket');
+    error.status = res.status;
+    throw error;
+  }
+  return data.data;
+}
+
 /**
  * Check if current time is within quiet hours
  * @param {Object} config - Quiet hours config {start_hour, end_hour, timezone}